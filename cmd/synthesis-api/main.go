@@ -7,10 +7,20 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/prismon/synthesis/internal/auth"
+	"github.com/prismon/synthesis/internal/authz"
+	"github.com/prismon/synthesis/internal/blob"
 	"github.com/prismon/synthesis/internal/config"
+	"github.com/prismon/synthesis/internal/events"
 	"github.com/prismon/synthesis/internal/postgres"
+	"github.com/prismon/synthesis/internal/quota"
+	"github.com/prismon/synthesis/internal/render"
 	"github.com/prismon/synthesis/internal/rest"
+	"github.com/prismon/synthesis/internal/secrets"
+	"github.com/prismon/synthesis/internal/validation"
+	"github.com/prismon/synthesis/internal/webhook"
 )
 
 func main() {
@@ -37,8 +47,71 @@ func main() {
 
 	fmt.Println("Connected to database successfully")
 
+	// Authenticate HTTP requests with OIDC bearer tokens when an issuer is configured
+	var authn *auth.Authenticator
+	if cfg.Security.AuthEnabled() {
+		authn, err = auth.NewAuthenticator(ctx, cfg.Security, postgres.NewAPIKeyRepository(db))
+		if err != nil {
+			log.Fatalf("Failed to set up authentication: %v", err)
+		}
+	} else {
+		log.Println("OIDC_ISSUER_URL is not set; HTTP requests are not authenticated")
+	}
+
+	// Check actions against the OPA policies unless explicitly disabled
+	var authorizer *authz.Authorizer
+	if cfg.Security.AuthzEnabled() {
+		authorizer = authz.NewAuthorizer(cfg.Security, postgres.NewAuthzRepository(db))
+	} else {
+		log.Println("AUTHZ_DISABLED is set; actions are not checked against policy")
+	}
+
+	// Start domain event dispatch, webhook delivery and feature expiry
+	webhooks := webhook.NewDispatcher(postgres.NewWebhookRepository(db), cfg.Webhook)
+	indexer := events.NewIndexer(postgres.NewResourceIndexRepository(db))
+
+	dispatcher := events.NewDispatcher(postgres.NewEventRepository(db), cfg.Database.URL, cfg.Events)
+	dispatcher.Subscribe("indexer", indexer.HandleEvent)
+	dispatcher.Subscribe("webhooks", webhooks.HandleEvent)
+
+	// Background workers act for every tenant
+	workerCtx := postgres.WithSystemScope(ctx)
+
+	go webhooks.Run(workerCtx)
+	go events.RunFeatureExpiry(workerCtx, postgres.NewFeatureRepository(db), time.Duration(cfg.Events.ExpiryInterval)*time.Second)
+
+	quotas := quota.NewEnforcer(postgres.NewQuotaRepository(db), cfg.Quota)
+
+	// Seal sensitive values with the master keys in the key file when one is configured
+	var sealer *secrets.Sealer
+	if cfg.Security.EncryptionEnabled() {
+		keys, err := secrets.LoadKeyFile(cfg.Security.EncryptionKeyFile)
+		if err != nil {
+			log.Fatalf("Failed to load encryption keys: %v", err)
+		}
+		sealer = secrets.NewSealer(keys)
+	} else {
+		log.Println("ENCRYPTION_KEY_FILE is not set; sensitive values cannot be stored")
+	}
+
+	// Store binary content as blobs in the configured backend
+	blobStore, err := blob.NewStore(cfg.Blob, db)
+	if err != nil {
+		log.Fatalf("Failed to create blob store: %v", err)
+	}
+	blobs := blob.NewService(blobStore, postgres.NewBlobRepository(db), int64(cfg.Blob.MaxBytes))
+
+	// Check content against the constraints of its types
+	validator := validation.NewValidator(postgres.NewTypeRepository(db), postgres.NewBlobRepository(db), validation.NewRegistry())
+
+	// Render notebooks with the renderers their blocks' types name
+	renderer := render.NewRenderer(postgres.NewTypeRepository(db), render.NewRegistry())
+
 	// Create REST API server
-	server := rest.NewServer(db)
+	server := rest.NewServer(db, authn, authorizer, quotas, sealer, validator, renderer, blobs)
+	dispatcher.SubscribeLive("websocket", server.HandleEvent)
+
+	go dispatcher.Run(workerCtx)
 
 	fmt.Printf("Starting Synthesis REST API Server on port %d...\n", cfg.API.Port)
 	fmt.Println("Press Ctrl+C to stop")
@@ -48,12 +121,17 @@ func main() {
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
 	// Start server in a goroutine
-	errChan := make(chan error, 1)
+	errChan := make(chan error, 2)
 	go func() {
 		if err := server.Start(cfg.API.Port); err != nil {
 			errChan <- err
 		}
 	}()
+	go func() {
+		if err := server.StartWebSocket(cfg.API.WSPort); err != nil {
+			errChan <- err
+		}
+	}()
 
 	// Wait for shutdown signal or error
 	select {
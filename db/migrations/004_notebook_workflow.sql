@@ -0,0 +1,28 @@
+-- Migration 004: Notebook status workflow
+-- Restricts notebook status to the defined lifecycle and records every transition
+
+-- Normalise existing statuses so the constraint can be added;
+-- anything outside the lifecycle returns to draft
+UPDATE notebook SET status = LOWER(TRIM(status))
+WHERE status IS NOT NULL AND status <> LOWER(TRIM(status));
+
+UPDATE notebook SET status = 'draft'
+WHERE status IS NULL OR status NOT IN ('draft', 'in_review', 'approved', 'archived');
+
+-- Restrict status to the notebook lifecycle
+ALTER TABLE notebook
+    ADD CONSTRAINT notebook_status_check
+    CHECK (status IN ('draft', 'in_review', 'approved', 'archived'));
+
+-- Notebook status transition history
+CREATE TABLE IF NOT EXISTS notebook_status_transition (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    notebook_id VARCHAR(255) NOT NULL REFERENCES notebook(id) ON DELETE CASCADE,
+    from_status VARCHAR(50) NOT NULL,
+    to_status VARCHAR(50) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notebook_status_transition_notebook ON notebook_status_transition(notebook_id, created_at);
@@ -0,0 +1,50 @@
+-- Migration 005: Webhook delivery
+-- Per-tenant signing secrets and a persistent outbox of notification deliveries
+
+-- Per-tenant secret used to sign webhook payloads (HMAC-SHA256)
+CREATE TABLE IF NOT EXISTS webhook_secret (
+    tenant_id VARCHAR(255) PRIMARY KEY REFERENCES tenant(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Webhook delivery outbox
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id VARCHAR(255) NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    resource_uri TEXT NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    url TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_delivery_due ON webhook_delivery(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_delivery_tenant ON webhook_delivery(tenant_id, created_at);
+CREATE INDEX idx_webhook_delivery_resource ON webhook_delivery(resource_uri);
+
+-- Individual delivery attempts
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempt (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    delivery_id UUID NOT NULL REFERENCES webhook_delivery(id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    duration_ms INTEGER,
+    attempted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_delivery_attempt_delivery ON webhook_delivery_attempt(delivery_id, attempt);
+
+CREATE TRIGGER update_webhook_secret_updated_at BEFORE UPDATE ON webhook_secret
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_webhook_delivery_updated_at BEFORE UPDATE ON webhook_delivery
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
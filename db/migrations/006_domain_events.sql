@@ -0,0 +1,26 @@
+-- Migration 006: Domain event outbox
+-- Events are written in the same transaction as the change they describe and
+-- fanned out to subscribers (indexer, webhooks, MCP subscriptions) after commit
+
+CREATE TABLE IF NOT EXISTS domain_event (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    seq BIGSERIAL UNIQUE NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    tenant_id VARCHAR(255),
+    resource_type VARCHAR(100) NOT NULL,
+    resource_id VARCHAR(255) NOT NULL,
+    resource_uri TEXT NOT NULL,
+    payload JSONB DEFAULT '{}',
+    occurred_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    -- Outbox delivery state
+    status VARCHAR(50) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'published', 'failed')),
+    handled_by TEXT[] NOT NULL DEFAULT '{}',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_domain_event_pending ON domain_event(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_domain_event_tenant ON domain_event(tenant_id, seq);
+CREATE INDEX idx_domain_event_resource ON domain_event(resource_uri, seq);
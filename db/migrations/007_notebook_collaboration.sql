@@ -0,0 +1,19 @@
+-- Migration 007: Collaborative notebook editing
+-- Sequences block operations per notebook and keeps a log for clients catching up
+
+-- Revision of the notebook's content blocks, bumped by every applied block operation
+ALTER TABLE notebook ADD COLUMN IF NOT EXISTS revision BIGINT NOT NULL DEFAULT 0;
+
+-- Applied block operations, in revision order
+CREATE TABLE IF NOT EXISTS notebook_operation (
+    notebook_id VARCHAR(255) NOT NULL REFERENCES notebook(id) ON DELETE CASCADE,
+    revision BIGINT NOT NULL,
+    op_id VARCHAR(255) NOT NULL,
+    op_type VARCHAR(20) NOT NULL CHECK (op_type IN ('insert', 'update', 'move', 'delete')),
+    block_uid VARCHAR(255) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    operation JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (notebook_id, revision),
+    UNIQUE (notebook_id, op_id)
+);
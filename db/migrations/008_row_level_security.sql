@@ -0,0 +1,136 @@
+-- Migration 008: Tenant isolation with row-level security
+-- Every tenant-owned row is visible only when the session is scoped to its tenant
+-- (synthesis.tenant_id) or to the whole system (synthesis.system = 'on', used by
+-- background workers and administrators). An unscoped session sees no tenant rows.
+-- FORCE applies the policies to the table owner too. Superusers and roles with
+-- BYPASSRLS skip them regardless, so the servers connect as the unprivileged
+-- synthesis_app role (migration 019).
+
+CREATE OR REPLACE FUNCTION synthesis_tenant_visible(row_tenant_id TEXT) RETURNS BOOLEAN AS $$
+    SELECT current_setting('synthesis.system', true) = 'on'
+        OR row_tenant_id = NULLIF(current_setting('synthesis.tenant_id', true), '')
+$$ LANGUAGE sql STABLE;
+
+-- Tables with a tenant column
+ALTER TABLE tenant ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tenant
+    USING (synthesis_tenant_visible(id))
+    WITH CHECK (synthesis_tenant_visible(id));
+
+ALTER TABLE library ENABLE ROW LEVEL SECURITY;
+ALTER TABLE library FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON library
+    USING (synthesis_tenant_visible(tenant_id))
+    WITH CHECK (synthesis_tenant_visible(tenant_id));
+
+ALTER TABLE notebook ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notebook FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON notebook
+    USING (synthesis_tenant_visible(tenant_id))
+    WITH CHECK (synthesis_tenant_visible(tenant_id));
+
+ALTER TABLE feature ENABLE ROW LEVEL SECURITY;
+ALTER TABLE feature FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON feature
+    USING (synthesis_tenant_visible(tenant_id))
+    WITH CHECK (synthesis_tenant_visible(tenant_id));
+
+ALTER TABLE product ENABLE ROW LEVEL SECURITY;
+ALTER TABLE product FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON product
+    USING (synthesis_tenant_visible(tenant_id))
+    WITH CHECK (synthesis_tenant_visible(tenant_id));
+
+ALTER TABLE tool ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tool FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tool
+    USING (synthesis_tenant_visible(tenant_id))
+    WITH CHECK (synthesis_tenant_visible(tenant_id));
+
+ALTER TABLE webhook_secret ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_secret FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON webhook_secret
+    USING (synthesis_tenant_visible(tenant_id))
+    WITH CHECK (synthesis_tenant_visible(tenant_id));
+
+ALTER TABLE webhook_delivery ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_delivery FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON webhook_delivery
+    USING (synthesis_tenant_visible(tenant_id))
+    WITH CHECK (synthesis_tenant_visible(tenant_id));
+
+-- Global resources (no tenant) stay visible to everyone
+ALTER TABLE resource_index ENABLE ROW LEVEL SECURITY;
+ALTER TABLE resource_index FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON resource_index
+    USING (tenant_id IS NULL OR synthesis_tenant_visible(tenant_id))
+    WITH CHECK (tenant_id IS NULL OR synthesis_tenant_visible(tenant_id));
+
+ALTER TABLE domain_event ENABLE ROW LEVEL SECURITY;
+ALTER TABLE domain_event FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON domain_event
+    USING (tenant_id IS NULL OR synthesis_tenant_visible(tenant_id))
+    WITH CHECK (tenant_id IS NULL OR synthesis_tenant_visible(tenant_id));
+
+-- Child tables are visible when their parent row is; the parent's own policy applies
+-- inside the subquery
+ALTER TABLE notebook_content ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notebook_content FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON notebook_content
+    USING (EXISTS (SELECT 1 FROM notebook n WHERE n.id = notebook_id));
+
+ALTER TABLE content_block ENABLE ROW LEVEL SECURITY;
+ALTER TABLE content_block FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON content_block
+    USING (EXISTS (SELECT 1 FROM notebook n WHERE n.id = notebook_id));
+
+ALTER TABLE content_block_type ENABLE ROW LEVEL SECURITY;
+ALTER TABLE content_block_type FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON content_block_type
+    USING (EXISTS (SELECT 1 FROM content_block cb WHERE cb.id = content_block_id));
+
+ALTER TABLE notebook_notification ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notebook_notification FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON notebook_notification
+    USING (EXISTS (SELECT 1 FROM notebook n WHERE n.id = notebook_id));
+
+ALTER TABLE notebook_embedding ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notebook_embedding FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON notebook_embedding
+    USING (EXISTS (SELECT 1 FROM notebook n WHERE n.id = notebook_id));
+
+ALTER TABLE notebook_status_transition ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notebook_status_transition FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON notebook_status_transition
+    USING (EXISTS (SELECT 1 FROM notebook n WHERE n.id = notebook_id));
+
+ALTER TABLE notebook_operation ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notebook_operation FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON notebook_operation
+    USING (EXISTS (SELECT 1 FROM notebook n WHERE n.id = notebook_id));
+
+ALTER TABLE feature_resource ENABLE ROW LEVEL SECURITY;
+ALTER TABLE feature_resource FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON feature_resource
+    USING (EXISTS (SELECT 1 FROM feature f WHERE f.id = feature_id));
+
+ALTER TABLE feature_notification ENABLE ROW LEVEL SECURITY;
+ALTER TABLE feature_notification FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON feature_notification
+    USING (EXISTS (SELECT 1 FROM feature f WHERE f.id = feature_id));
+
+ALTER TABLE feature_embedding ENABLE ROW LEVEL SECURITY;
+ALTER TABLE feature_embedding FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON feature_embedding
+    USING (EXISTS (SELECT 1 FROM feature f WHERE f.id = feature_id));
+
+ALTER TABLE product_user ENABLE ROW LEVEL SECURITY;
+ALTER TABLE product_user FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON product_user
+    USING (EXISTS (SELECT 1 FROM product p WHERE p.id = product_id));
+
+ALTER TABLE webhook_delivery_attempt ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_delivery_attempt FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON webhook_delivery_attempt
+    USING (EXISTS (SELECT 1 FROM webhook_delivery d WHERE d.id = delivery_id));
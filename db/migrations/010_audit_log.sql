@@ -0,0 +1,40 @@
+-- Migration 010: Audit log
+-- An append-only record of every mutating tool call and API request. Entries are
+-- not tied to their tenant by foreign key so they outlive deleted tenants.
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    seq BIGSERIAL UNIQUE NOT NULL,
+    tenant_id VARCHAR(255),
+    principal VARCHAR(255) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    action VARCHAR(255) NOT NULL,
+    target_uri TEXT,
+    request_id VARCHAR(255),
+    outcome VARCHAR(50) NOT NULL CHECK (outcome IN ('succeeded', 'failed')),
+    error TEXT,
+    before_summary JSONB,
+    after_summary JSONB,
+    occurred_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_log_tenant ON audit_log(tenant_id, seq);
+CREATE INDEX idx_audit_log_principal ON audit_log(principal, seq);
+CREATE INDEX idx_audit_log_target ON audit_log(target_uri, seq);
+CREATE INDEX idx_audit_log_occurred ON audit_log(occurred_at);
+
+CREATE OR REPLACE FUNCTION prevent_audit_log_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION prevent_audit_log_change();
+
+ALTER TABLE audit_log ENABLE ROW LEVEL SECURITY;
+ALTER TABLE audit_log FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON audit_log
+    USING (synthesis_tenant_visible(tenant_id))
+    WITH CHECK (synthesis_tenant_visible(tenant_id));
@@ -0,0 +1,40 @@
+-- Migration 011: Product membership and resources
+-- Members hold a product role; notebooks and features are attached to products,
+-- mirrored as PART_OF_PRODUCT edges in the graph. Members' roles extend to the
+-- resources attached to the product.
+
+ALTER TABLE product_user
+    ADD CONSTRAINT product_user_role_check CHECK (role IN ('owner', 'contributor', 'viewer'));
+
+CREATE TABLE IF NOT EXISTS product_resource (
+    product_id VARCHAR(255) NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(255) NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    resource_type VARCHAR(50) NOT NULL CHECK (resource_type IN ('notebook', 'feature')),
+    resource_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, resource_type, resource_id)
+);
+
+CREATE INDEX idx_product_resource_resource ON product_resource(resource_type, resource_id);
+
+-- Attachments go with the resource
+CREATE OR REPLACE FUNCTION delete_product_resource()
+RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM product_resource
+    WHERE resource_type = TG_ARGV[0] AND resource_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER delete_notebook_product_resource AFTER DELETE ON notebook
+    FOR EACH ROW EXECUTE FUNCTION delete_product_resource('notebook');
+
+CREATE TRIGGER delete_feature_product_resource AFTER DELETE ON feature
+    FOR EACH ROW EXECUTE FUNCTION delete_product_resource('feature');
+
+ALTER TABLE product_resource ENABLE ROW LEVEL SECURITY;
+ALTER TABLE product_resource FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON product_resource
+    USING (synthesis_tenant_visible(tenant_id))
+    WITH CHECK (synthesis_tenant_visible(tenant_id));
@@ -0,0 +1,18 @@
+-- Migration 012: Tenant quotas
+-- Per-tenant overrides of the configured limits. A NULL limit falls back to the
+-- configured default; 0 means unlimited.
+
+CREATE TABLE IF NOT EXISTS tenant_quota (
+    tenant_id VARCHAR(255) PRIMARY KEY REFERENCES tenant(id) ON DELETE CASCADE,
+    max_notebooks BIGINT CHECK (max_notebooks >= 0),
+    max_features BIGINT CHECK (max_features >= 0),
+    max_content_bytes BIGINT CHECK (max_content_bytes >= 0),
+    max_requests_per_second BIGINT CHECK (max_requests_per_second >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE tenant_quota ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_quota FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tenant_quota
+    USING (synthesis_tenant_visible(tenant_id))
+    WITH CHECK (synthesis_tenant_visible(tenant_id));
@@ -0,0 +1,10 @@
+-- Migration 013: Sensitive fields
+-- Names the feature values and tool config entries that are stored sealed with
+-- envelope encryption (enc:v1:<key ID>:<wrapped data key>:<ciphertext>) and
+-- redacted in responses to callers who may not reveal them.
+
+ALTER TABLE feature ADD COLUMN IF NOT EXISTS sensitive_keys TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE tool ADD COLUMN IF NOT EXISTS sensitive_keys TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_feature_sensitive ON feature(tenant_id) WHERE cardinality(sensitive_keys) > 0;
+CREATE INDEX idx_tool_sensitive ON tool(tenant_id) WHERE cardinality(sensitive_keys) > 0;
@@ -0,0 +1,5 @@
+-- Migration 014: Feature value types
+-- Names, for each feature value, the types whose constraints it must meet,
+-- as {"value key": ["type name", ...]}.
+
+ALTER TABLE feature ADD COLUMN IF NOT EXISTS value_types_json JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,19 @@
+-- Migration 015: Versioned type schemas
+-- Each change to the JSON Schema of a type's json-schema constraint records a
+-- new version; content blocks remember the version of each of their types they
+-- were last validated against, so blocks written under an older schema can be
+-- found and checked again.
+
+CREATE TABLE IF NOT EXISTS type_schema (
+    type_name VARCHAR(255) NOT NULL REFERENCES type_def(name) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    schema_json JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (type_name, version)
+);
+
+ALTER TABLE type_def ADD COLUMN IF NOT EXISTS schema_version INTEGER;
+
+ALTER TABLE content_block_type ADD COLUMN IF NOT EXISTS schema_version INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_content_block_type_name_version ON content_block_type(type_name, schema_version);
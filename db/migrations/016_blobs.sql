@@ -0,0 +1,26 @@
+-- Migration 016: Blob storage
+-- Binary content, such as images, is stored outside content blocks as blobs
+-- addressed within their tenant by the SHA-256 digest of their bytes; blocks
+-- reference them as "blob:sha256:<digest>". The bytes live in the configured
+-- blob store; blob_object holds them for the Postgres large-object store.
+
+CREATE TABLE IF NOT EXISTS blob (
+    tenant_id VARCHAR(255) NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    digest CHAR(64) NOT NULL,
+    media_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, digest)
+);
+
+ALTER TABLE blob ENABLE ROW LEVEL SECURITY;
+ALTER TABLE blob FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON blob
+    USING (synthesis_tenant_visible(tenant_id))
+    WITH CHECK (synthesis_tenant_visible(tenant_id));
+
+CREATE TABLE IF NOT EXISTS blob_object (
+    key TEXT PRIMARY KEY,
+    oid OID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,32 @@
+-- Migration 017: Tool registry
+-- Each tool configuration has a kind, such as an HTTP or Slack-style incoming
+-- webhook, whose config schema its config must meet and which executes it.
+-- Every invocation is logged with its outcome.
+
+ALTER TABLE tool ADD COLUMN IF NOT EXISTS kind VARCHAR(100) NOT NULL DEFAULT 'http-webhook';
+
+-- Configurations from before kinds, such as the seeded Slack integration
+UPDATE tool SET kind = 'slack-webhook' WHERE config_json ? 'webhook_url';
+
+CREATE TABLE IF NOT EXISTS tool_invocation (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id VARCHAR(255) NOT NULL REFERENCES tenant(id) ON DELETE CASCADE,
+    tool_id VARCHAR(255) NOT NULL REFERENCES tool(id) ON DELETE CASCADE,
+    kind VARCHAR(100) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    source_uri TEXT,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    status_code INTEGER,
+    error TEXT,
+    duration_ms BIGINT NOT NULL,
+    result_json JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_tool_invocation_tool ON tool_invocation(tool_id, created_at DESC);
+
+ALTER TABLE tool_invocation ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tool_invocation FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tool_invocation
+    USING (synthesis_tenant_visible(tenant_id))
+    WITH CHECK (synthesis_tenant_visible(tenant_id));
@@ -0,0 +1,29 @@
+-- Migration 018: Keyset pagination
+-- Listings are paged by a cursor holding the sort key and ID of a page's last
+-- row, so the columns they sort by must not be NULL. The indexes serve the
+-- default order of each listing within its parent.
+
+UPDATE tenant SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE tenant SET last_modified = created_at WHERE last_modified IS NULL;
+ALTER TABLE tenant ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE tenant ALTER COLUMN last_modified SET NOT NULL;
+
+UPDATE library SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+ALTER TABLE library ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE notebook SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE notebook SET updated_at = created_at WHERE updated_at IS NULL;
+ALTER TABLE notebook ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE notebook ALTER COLUMN updated_at SET NOT NULL;
+
+UPDATE product SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+ALTER TABLE product ALTER COLUMN created_at SET NOT NULL;
+
+UPDATE tool SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+ALTER TABLE tool ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tenant_created ON tenant(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_library_tenant_created ON library(tenant_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_notebook_library_created ON notebook(library_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_product_tenant_name ON product(tenant_id, display_name, id);
+CREATE INDEX IF NOT EXISTS idx_tool_tenant_name ON tool(tenant_id, display_name, id);
@@ -0,0 +1,45 @@
+-- Migration 019: Application role
+-- Row-level security (migration 008) does not apply to superusers or to roles
+-- with BYPASSRLS, even on tables that FORCE it, and the role the postgres image
+-- creates from POSTGRES_USER is a superuser. The servers connect as
+-- synthesis_app instead: a role with neither attribute, granted the data it
+-- uses. Migrations still run as the superuser, which keeps owning the tables.
+-- The password is for development; set another with
+-- ALTER ROLE synthesis_app PASSWORD '...' elsewhere.
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'synthesis_app') THEN
+        CREATE ROLE synthesis_app LOGIN PASSWORD 'synthesis_app_dev_password';
+    END IF;
+END
+$$;
+
+-- Stated rather than relied on as defaults, in case the role existed before
+ALTER ROLE synthesis_app NOSUPERUSER NOBYPASSRLS NOCREATEDB NOCREATEROLE;
+
+-- Relational tables, including those later migrations create
+GRANT USAGE ON SCHEMA public TO synthesis_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO synthesis_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO synthesis_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO synthesis_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO synthesis_app;
+
+-- The graph: AGE's catalog, and a table per label in the graph's schema
+GRANT USAGE ON SCHEMA ag_catalog TO synthesis_app;
+GRANT SELECT ON ALL TABLES IN SCHEMA ag_catalog TO synthesis_app;
+GRANT USAGE ON SCHEMA synthesis_graph TO synthesis_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA synthesis_graph TO synthesis_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA synthesis_graph TO synthesis_app;
+
+-- Blobs stored as large objects before now; those the role creates are its own,
+-- and only an owner may unlink one
+DO $$
+DECLARE
+    object_id OID;
+BEGIN
+    FOR object_id IN SELECT oid FROM pg_largeobject_metadata LOOP
+        EXECUTE format('ALTER LARGE OBJECT %s OWNER TO synthesis_app', object_id);
+    END LOOP;
+END
+$$;
@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/prismon/synthesis/internal/auth"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// Recorder writes an audit entry for each mutating action
+type Recorder struct {
+	repo *postgres.AuditRepository
+}
+
+// NewRecorder creates a recorder that appends to the audit log
+func NewRecorder(repo *postgres.AuditRepository) *Recorder {
+	return &Recorder{repo: repo}
+}
+
+// Action is a mutating action being audited
+type Action struct {
+	recorder *Recorder
+	entry    *domain.AuditEntry
+}
+
+// Begin starts auditing an action on the resource at targetURI, the one the
+// action is authorized against, and captures how that resource looks
+// beforehand. End must be called once the action has run.
+func (r *Recorder) Begin(ctx context.Context, source, action, targetURI string) *Action {
+	entry := &domain.AuditEntry{
+		Principal: principalName(ctx),
+		Source:    source,
+		Action:    action,
+		TargetURI: targetURI,
+		RequestID: RequestIDFromContext(ctx),
+	}
+
+	if entry.RequestID == "" {
+		entry.RequestID = uuid.NewString()
+	}
+
+	if ref, err := domain.ParseURI(entry.TargetURI); err == nil && ref.TenantID != "" {
+		entry.TenantID = ref.TenantID
+	} else if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		entry.TenantID = principal.TenantID
+	}
+
+	entry.Before = r.summary(ctx, entry.TargetURI)
+
+	return &Action{recorder: r, entry: entry}
+}
+
+// End records the action's outcome; failure is the error it failed with, or ""
+// if it succeeded. Audit failures are logged rather than failing the action,
+// which has already taken effect.
+func (a *Action) End(ctx context.Context, failure string) {
+	a.entry.Outcome = domain.AuditOutcomeSucceeded
+	if failure != "" {
+		a.entry.Outcome = domain.AuditOutcomeFailed
+		a.entry.Error = failure
+	}
+
+	a.entry.After = a.recorder.summary(ctx, a.entry.TargetURI)
+
+	// The log is written by the server on the caller's behalf, whatever the
+	// caller's own scope
+	if err := a.recorder.repo.Record(postgres.WithSystemScope(ctx), a.entry); err != nil {
+		log.Printf("failed to audit %s by %s: %v", a.entry.Action, a.entry.Principal, err)
+	}
+}
+
+// summary returns the audited fields of the resource at uri, if any
+func (r *Recorder) summary(ctx context.Context, uri string) []byte {
+	if uri == "" {
+		return nil
+	}
+
+	summary, err := r.repo.Summary(postgres.WithSystemScope(ctx), uri)
+	if err != nil {
+		log.Printf("failed to summarise %s for audit: %v", uri, err)
+		return nil
+	}
+
+	return summary
+}
+
+// principalName names the caller on ctx in the audit log
+func principalName(ctx context.Context) string {
+	if identity := auth.IdentityFromContext(ctx, ""); identity != "" {
+		return identity
+	}
+	if auth.IsLocalOperator(ctx) {
+		return "local"
+	}
+	return "anonymous"
+}
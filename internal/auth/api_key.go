@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// APIKeyPrefix starts every API key, telling keys apart from OIDC tokens
+const APIKeyPrefix = "syn_"
+
+// ServiceAccountPrefix starts the subject of service-account principals
+const ServiceAccountPrefix = "apikey:"
+
+// NewAPIKey creates an API key for a tenant and returns it together with the
+// secret key, which is not stored and cannot be shown again. Keys are scoped to
+// their tenant, so the admin role cannot be granted to one.
+func NewAPIKey(tenantID, name string, scopes []string, expiresAt *time.Time, createdBy string) (*domain.APIKey, string, error) {
+	if tenantID == "" || name == "" {
+		return nil, "", fmt.Errorf("tenantId and name are required")
+	}
+
+	for _, scope := range scopes {
+		if scope == "" || strings.ContainsAny(scope, " \t\n") {
+			return nil, "", fmt.Errorf("invalid scope %q", scope)
+		}
+		if scope == "admin" {
+			return nil, "", fmt.Errorf("API keys cannot be granted the admin role")
+		}
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", fmt.Errorf("expires_at must be in the future")
+	}
+
+	id, err := randomHex(6)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	prefix := APIKeyPrefix + id
+	raw := prefix + "_" + secret
+
+	key := &domain.APIKey{
+		TenantID:  tenantID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashAPIKey(raw),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedBy: createdBy,
+	}
+
+	return key, raw, nil
+}
+
+// AuthenticateAPIKey verifies an API key and returns its service-account principal
+func (a *Authenticator) AuthenticateAPIKey(ctx context.Context, raw string) (*Principal, error) {
+	if a.apiKeys == nil {
+		return nil, fmt.Errorf("API keys are not accepted")
+	}
+
+	// syn_<id>_<secret>: the prefix is everything before the last separator
+	sep := strings.LastIndex(raw, "_")
+	if !strings.HasPrefix(raw, APIKeyPrefix) || sep <= len(APIKeyPrefix) {
+		return nil, fmt.Errorf("invalid API key")
+	}
+
+	// The key that authenticates a request also decides its tenant, so it is
+	// looked up across tenants
+	ctx = postgres.WithSystemScope(ctx)
+
+	key, err := a.apiKeys.GetByPrefix(ctx, raw[:sep])
+	if err != nil {
+		return nil, fmt.Errorf("invalid API key")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(raw)), []byte(key.KeyHash)) != 1 {
+		return nil, fmt.Errorf("invalid API key")
+	}
+
+	if !key.Active(time.Now()) {
+		return nil, fmt.Errorf("API key %s is revoked or expired", key.Prefix)
+	}
+
+	if err := a.apiKeys.TouchLastUsed(ctx, key.ID); err != nil {
+		log.Printf("failed to record use of API key %s: %v", key.Prefix, err)
+	}
+
+	return &Principal{
+		Subject:        ServiceAccountPrefix + key.ID,
+		TenantID:       key.TenantID,
+		Roles:          key.Scopes,
+		ServiceAccount: true,
+	}, nil
+}
+
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/prismon/synthesis/internal/config"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// Authenticator validates OIDC bearer tokens and API keys. Provider metadata comes
+// from the issuer's discovery document and signing keys from its JWKS, which is
+// cached and refetched when a token is signed with an unknown key.
+type Authenticator struct {
+	verifier    *oidc.IDTokenVerifier
+	tenantClaim string
+	rolesClaim  string
+	apiKeys     APIKeyStore
+}
+
+// APIKeyStore looks up the API keys presented in place of tokens;
+// *postgres.APIKeyRepository implements it
+type APIKeyStore interface {
+	GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+	TouchLastUsed(ctx context.Context, id string) error
+}
+
+// NewAuthenticator discovers the configured OIDC issuer. Tokens must be issued by
+// it, list the client ID as an audience and be unexpired. API keys are accepted
+// in place of tokens when apiKeys is not nil.
+func NewAuthenticator(ctx context.Context, cfg config.SecurityConfig, apiKeys APIKeyStore) (*Authenticator, error) {
+	if cfg.OIDCIssuerURL == "" || cfg.OIDCClientID == "" {
+		return nil, fmt.Errorf("OIDC_ISSUER_URL and OIDC_CLIENT_ID are required for authentication")
+	}
+
+	// ctx must outlive the authenticator: JWKS refreshes run under it
+	provider, err := oidc.NewProvider(ctx, cfg.OIDCIssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC issuer %s: %w", cfg.OIDCIssuerURL, err)
+	}
+
+	return &Authenticator{
+		verifier:    provider.Verifier(&oidc.Config{ClientID: cfg.OIDCClientID}),
+		tenantClaim: cfg.OIDCTenantClaim,
+		rolesClaim:  cfg.OIDCRolesClaim,
+		apiKeys:     apiKeys,
+	}, nil
+}
+
+// Authenticate verifies a raw bearer token and returns its principal
+func (a *Authenticator) Authenticate(ctx context.Context, rawToken string) (*Principal, error) {
+	token, err := a.verifier.Verify(ctx, rawToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	var claims map[string]interface{}
+	if err := token.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to decode token claims: %w", err)
+	}
+
+	principal := &Principal{
+		Issuer:   token.Issuer,
+		Subject:  token.Subject,
+		TenantID: stringClaim(claims, a.tenantClaim),
+		Roles:    stringsClaim(claims, a.rolesClaim),
+	}
+
+	// Only an email address the issuer verified may stand in for the subject;
+	// one without an email_verified claim is not known to be verified
+	if verified, _ := claims["email_verified"].(bool); verified {
+		principal.Email = stringClaim(claims, "email")
+	}
+
+	return principal, nil
+}
+
+// Middleware rejects requests without a valid bearer token or API key and puts
+// the principal of valid ones on the request context
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawToken := bearerToken(r)
+		if rawToken == "" {
+			unauthorized(w, "missing bearer token")
+			return
+		}
+
+		var principal *Principal
+		var err error
+		if strings.HasPrefix(rawToken, APIKeyPrefix) {
+			principal, err = a.AuthenticateAPIKey(r.Context(), rawToken)
+		} else {
+			principal, err = a.Authenticate(r.Context(), rawToken)
+		}
+		if err != nil {
+			unauthorized(w, err.Error())
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// bearerToken reads the token from the Authorization header. Browsers cannot set
+// headers on WebSocket handshakes, so those may pass it as access_token instead.
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return r.URL.Query().Get("access_token")
+	}
+
+	return ""
+}
+
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]string{"error": message, "code": "unauthorized"})
+}
+
+// claim looks up a claim by name; dots address nested claims, e.g. realm_access.roles
+func claim(claims map[string]interface{}, name string) interface{} {
+	var value interface{} = claims
+	for _, part := range strings.Split(name, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[part]
+	}
+	return value
+}
+
+func stringClaim(claims map[string]interface{}, name string) string {
+	s, _ := claim(claims, name).(string)
+	return s
+}
+
+// stringsClaim reads a claim that is either a list of strings or a single
+// space-separated string
+func stringsClaim(claims map[string]interface{}, name string) []string {
+	switch value := claim(claims, name).(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		var values []string
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prismon/synthesis/internal/config"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+const testClientID = "synthesis-test"
+
+// fakeIssuer is a local OIDC issuer serving a discovery document and a JWKS,
+// and signing tokens with its RSA key
+type fakeIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	keyID  string
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	issuer := &fakeIssuer{key: key, keyID: "test-key"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                issuer.server.URL,
+			"authorization_endpoint":                issuer.server.URL + "/authorize",
+			"token_endpoint":                        issuer.server.URL + "/token",
+			"jwks_uri":                              issuer.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"kid": issuer.keyID,
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+// claims returns valid claims for a token of subject, overridden by extra
+func (f *fakeIssuer) claims(subject string, extra map[string]interface{}) map[string]interface{} {
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss": f.server.URL,
+		"sub": subject,
+		"aud": testClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	for name, value := range extra {
+		claims[name] = value
+	}
+	return claims
+}
+
+// sign returns claims as a JWT signed with RS256 by the issuer's key
+func (f *fakeIssuer) sign(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": f.keyID})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("failed to encode claims: %v", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, f.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newTestAuthenticator(t *testing.T, issuer *fakeIssuer, apiKeys APIKeyStore) *Authenticator {
+	t.Helper()
+
+	authn, err := NewAuthenticator(context.Background(), config.SecurityConfig{
+		OIDCIssuerURL:   issuer.server.URL,
+		OIDCClientID:    testClientID,
+		OIDCTenantClaim: "tenant",
+		OIDCRolesClaim:  "realm_access.roles",
+	}, apiKeys)
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	return authn
+}
+
+func TestAuthenticateValidToken(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	authn := newTestAuthenticator(t, issuer, nil)
+
+	token := issuer.sign(t, issuer.claims("user-1", map[string]interface{}{
+		"email":          "ada@example.com",
+		"email_verified": true,
+		"tenant":         "acme",
+		"realm_access":   map[string]interface{}{"roles": []string{"editor", "approver"}},
+	}))
+
+	principal, err := authn.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("expected token to be accepted: %v", err)
+	}
+
+	if principal.Subject != "user-1" || principal.Email != "ada@example.com" || principal.TenantID != "acme" {
+		t.Errorf("unexpected principal: %+v", principal)
+	}
+	if !principal.HasRole("editor") || !principal.HasRole("approver") {
+		t.Errorf("expected roles from the nested claim, got %v", principal.Roles)
+	}
+	if principal.Identity() != "ada@example.com" {
+		t.Errorf("expected identity to be the email, got %s", principal.Identity())
+	}
+}
+
+func TestAuthenticateTrustsOnlyVerifiedEmail(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	authn := newTestAuthenticator(t, issuer, nil)
+	subjectID := issuer.server.URL + "#user-1"
+
+	tests := []struct {
+		name     string
+		claims   map[string]interface{}
+		identity string
+	}{
+		{"verified", map[string]interface{}{"email": "owner@example.com", "email_verified": true}, "owner@example.com"},
+		{"unverified", map[string]interface{}{"email": "owner@example.com", "email_verified": false}, subjectID},
+		{"verification not claimed", map[string]interface{}{"email": "owner@example.com"}, subjectID},
+		{"verification as a string", map[string]interface{}{"email": "owner@example.com", "email_verified": "true"}, subjectID},
+		{"no email", map[string]interface{}{"email_verified": true}, subjectID},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			principal, err := authn.Authenticate(context.Background(), issuer.sign(t, issuer.claims("user-1", tc.claims)))
+			if err != nil {
+				t.Fatalf("expected token to be accepted: %v", err)
+			}
+			if principal.Identity() != tc.identity {
+				t.Errorf("expected identity %s, got %s", tc.identity, principal.Identity())
+			}
+			if tc.identity == subjectID && principal.Email != "" {
+				t.Errorf("expected no email, got %s", principal.Email)
+			}
+		})
+	}
+}
+
+func TestAuthenticateRejectsInvalidTokens(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	other := newFakeIssuer(t)
+	authn := newTestAuthenticator(t, issuer, nil)
+
+	tests := map[string]string{
+		"expired": issuer.sign(t, issuer.claims("user-1", map[string]interface{}{
+			"iat": time.Now().Add(-2 * time.Hour).Unix(),
+			"exp": time.Now().Add(-time.Hour).Unix(),
+		})),
+		"wrong audience": issuer.sign(t, issuer.claims("user-1", map[string]interface{}{
+			"aud": "another-client",
+		})),
+		"wrong issuer": issuer.sign(t, issuer.claims("user-1", map[string]interface{}{
+			"iss": other.server.URL,
+		})),
+		"unknown signing key": other.sign(t, issuer.claims("user-1", nil)),
+		"malformed":           "not-a-token",
+	}
+
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := authn.Authenticate(context.Background(), token); err == nil {
+				t.Fatal("expected token to be rejected")
+			}
+		})
+	}
+}
+
+func TestAuthenticateWrongTenantClaim(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	authn := newTestAuthenticator(t, issuer, nil)
+
+	tests := map[string]map[string]interface{}{
+		// The tenant is carried under a claim other than OIDC_TENANT_CLAIM
+		"other claim": {"tenant_id": "acme"},
+		// The tenant claim is not a string
+		"non-string claim": {"tenant": []string{"acme", "globex"}},
+	}
+
+	for name, extra := range tests {
+		t.Run(name, func(t *testing.T) {
+			principal, err := authn.Authenticate(context.Background(), issuer.sign(t, issuer.claims("user-1", extra)))
+			if err != nil {
+				t.Fatalf("expected token to be accepted: %v", err)
+			}
+			// A principal without a tenant is scoped to no tenant's rows
+			if principal.TenantID != "" {
+				t.Errorf("expected no tenant, got %q", principal.TenantID)
+			}
+		})
+	}
+}
+
+// fakeAPIKeys is an in-memory APIKeyStore
+type fakeAPIKeys struct {
+	keys    map[string]*domain.APIKey
+	touched []string
+}
+
+func (f *fakeAPIKeys) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	key, ok := f.keys[prefix]
+	if !ok {
+		return nil, fmt.Errorf("API key not found: %s", prefix)
+	}
+	return key, nil
+}
+
+func (f *fakeAPIKeys) TouchLastUsed(ctx context.Context, id string) error {
+	f.touched = append(f.touched, id)
+	return nil
+}
+
+func (f *fakeAPIKeys) add(t *testing.T, tenantID string, scopes []string) (*domain.APIKey, string) {
+	t.Helper()
+
+	key, raw, err := NewAPIKey(tenantID, "ci", scopes, nil, "ada@example.com")
+	if err != nil {
+		t.Fatalf("failed to create API key: %v", err)
+	}
+	key.ID = "key-" + key.Prefix
+	f.keys[key.Prefix] = key
+	return key, raw
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	store := &fakeAPIKeys{keys: map[string]*domain.APIKey{}}
+	authn := newTestAuthenticator(t, issuer, store)
+
+	key, raw := store.add(t, "acme", []string{"editor"})
+
+	principal, err := authn.AuthenticateAPIKey(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("expected API key to be accepted: %v", err)
+	}
+	if !principal.ServiceAccount || principal.TenantID != "acme" || !principal.HasRole("editor") {
+		t.Errorf("unexpected principal: %+v", principal)
+	}
+	if principal.Subject != ServiceAccountPrefix+key.ID {
+		t.Errorf("unexpected subject %s", principal.Subject)
+	}
+	if len(store.touched) != 1 || store.touched[0] != key.ID {
+		t.Errorf("expected last use to be recorded, got %v", store.touched)
+	}
+
+	// Same prefix, different secret
+	forged := raw[:strings.LastIndex(raw, "_")+1] + strings.Repeat("0", 64)
+	if _, err := authn.AuthenticateAPIKey(context.Background(), forged); err == nil {
+		t.Error("expected a key with the wrong secret to be rejected")
+	}
+
+	if _, err := authn.AuthenticateAPIKey(context.Background(), "syn_unknown_secret"); err == nil {
+		t.Error("expected an unknown key to be rejected")
+	}
+
+	revoked := time.Now()
+	key.RevokedAt = &revoked
+	if _, err := authn.AuthenticateAPIKey(context.Background(), raw); err == nil {
+		t.Error("expected a revoked key to be rejected")
+	}
+}
+
+func TestNewAPIKeyRejectsAdminScope(t *testing.T) {
+	if _, _, err := NewAPIKey("acme", "ci", []string{"admin"}, nil, ""); err == nil {
+		t.Fatal("expected the admin scope to be rejected")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	store := &fakeAPIKeys{keys: map[string]*domain.APIKey{}}
+	authn := newTestAuthenticator(t, issuer, store)
+	_, apiKey := store.add(t, "acme", []string{"viewer"})
+
+	handler := authn.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ := PrincipalFromContext(r.Context())
+		w.Write([]byte(principal.Subject))
+	}))
+
+	tests := []struct {
+		name    string
+		header  string
+		status  int
+		subject string
+	}{
+		{"missing token", "", http.StatusUnauthorized, ""},
+		{"valid token", "Bearer " + issuer.sign(t, issuer.claims("user-1", nil)), http.StatusOK, "user-1"},
+		{"expired token", "Bearer " + issuer.sign(t, issuer.claims("user-1", map[string]interface{}{
+			"exp": time.Now().Add(-time.Minute).Unix(),
+		})), http.StatusUnauthorized, ""},
+		{"API key", "Bearer " + apiKey, http.StatusOK, ServiceAccountPrefix},
+		{"invalid API key", "Bearer syn_bad_key", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tenants", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.status == http.StatusOK && !strings.HasPrefix(rec.Body.String(), tt.subject) {
+				t.Errorf("expected subject %q, got %q", tt.subject, rec.Body.String())
+			}
+			if tt.status == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate header")
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package auth
+
+import "context"
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Issuer is the issuer of the token the principal presented; empty for API keys
+	Issuer   string   `json:"iss,omitempty"`
+	Subject  string   `json:"sub"`
+	Email    string   `json:"email,omitempty"`
+	TenantID string   `json:"tenantId,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+	// ServiceAccount is true for machine clients authenticated with an API key
+	ServiceAccount bool `json:"serviceAccount,omitempty"`
+}
+
+// ID returns the principal's subject, qualified by the issuer of its token as
+// <issuer>#<subject>. A subject is only unique within its issuer, and one alone
+// could read as another principal's email address.
+func (p *Principal) ID() string {
+	if p.Issuer == "" {
+		return p.Subject
+	}
+	return p.Issuer + "#" + p.Subject
+}
+
+// Identity returns the name the principal is recorded under as an owner or actor:
+// the email address when the token carries a verified one, otherwise its ID
+func (p *Principal) Identity() string {
+	if p.Email != "" {
+		return p.Email
+	}
+	return p.ID()
+}
+
+// HasRole reports whether the principal was granted a role
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal of an authenticated request
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// IdentityFromContext returns the identity of the authenticated principal,
+// or fallback when the request is not authenticated (stdio, or OIDC disabled)
+func IdentityFromContext(ctx context.Context, fallback string) string {
+	if p, ok := PrincipalFromContext(ctx); ok {
+		return p.Identity()
+	}
+	return fallback
+}
+
+type localOperatorKey struct{}
+
+// WithLocalOperator marks ctx as coming from the operator running the process,
+// i.e. the stdio transport, which has no bearer token to authenticate
+func WithLocalOperator(ctx context.Context) context.Context {
+	return context.WithValue(ctx, localOperatorKey{}, true)
+}
+
+// IsLocalOperator reports whether ctx was marked by WithLocalOperator
+func IsLocalOperator(ctx context.Context) bool {
+	local, _ := ctx.Value(localOperatorKey{}).(bool)
+	return local
+}
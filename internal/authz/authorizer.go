@@ -0,0 +1,342 @@
+package authz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prismon/synthesis/internal/auth"
+	"github.com/prismon/synthesis/internal/config"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// DecisionPath is the OPA document holding the authorization decision
+const DecisionPath = "synthesis/authz/decision"
+
+// maxCacheEntries bounds the decision cache; expired entries are pruned beyond it
+const maxCacheEntries = 10000
+
+// DeniedError reports an action the policies do not allow
+type DeniedError struct {
+	Action  string
+	URI     string
+	Reasons []string
+}
+
+func (e *DeniedError) Error() string {
+	target := e.URI
+	if target == "" {
+		target = "(no resource)"
+	}
+	return fmt.Sprintf("permission denied: %s on %s: %s", e.Action, target, strings.Join(e.Reasons, "; "))
+}
+
+type cachedDecision struct {
+	decision *Decision
+	expires  time.Time
+}
+
+// Authorizer evaluates actions against the OPA policies. Decisions are cached
+// briefly per subject, action and resource.
+type Authorizer struct {
+	url    string
+	client *http.Client
+	attrs  *postgres.AuthzRepository
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedDecision
+}
+
+// NewAuthorizer creates an authorizer that queries the OPA server at cfg.OPAURL
+func NewAuthorizer(cfg config.SecurityConfig, attrs *postgres.AuthzRepository) *Authorizer {
+	return &Authorizer{
+		url:    strings.TrimRight(cfg.OPAURL, "/") + "/v1/data/" + DecisionPath,
+		client: &http.Client{Timeout: 5 * time.Second},
+		attrs:  attrs,
+		ttl:    time.Duration(cfg.OPACacheTTL) * time.Second,
+		cache:  make(map[string]cachedDecision),
+	}
+}
+
+// Authorize decides whether the caller on ctx may perform action on the resource at uri.
+// uri may be empty for actions without a target.
+func (a *Authorizer) Authorize(ctx context.Context, action, uri string) (*Decision, error) {
+	subject := subjectFromContext(ctx)
+
+	key, err := json.Marshal([]interface{}{subject, action, uri})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build cache key: %w", err)
+	}
+
+	if decision, ok := a.cached(string(key)); ok {
+		return decision, nil
+	}
+
+	// Attributes are read across tenants: a resource in another tenant must still
+	// be recognised as such to be denied
+	resource, err := a.resource(postgres.WithSystemScope(ctx), uri, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	decision, err := a.evaluate(ctx, &Input{Subject: subject, Action: action, Resource: *resource})
+	if err != nil {
+		return nil, err
+	}
+
+	a.store(string(key), decision)
+
+	return decision, nil
+}
+
+// Check returns a *DeniedError if the caller on ctx may not perform action on uri
+func (a *Authorizer) Check(ctx context.Context, action, uri string) error {
+	decision, err := a.Authorize(ctx, action, uri)
+	if err != nil {
+		return fmt.Errorf("authorization failed: %w", err)
+	}
+
+	if !decision.Allow {
+		return &DeniedError{Action: action, URI: uri, Reasons: decision.Reasons}
+	}
+
+	return nil
+}
+
+// CheckAll returns a *DeniedError unless the caller on ctx may perform action on
+// each of uris. With no uris, the action is checked without a resource.
+func (a *Authorizer) CheckAll(ctx context.Context, action string, uris []string) error {
+	if len(uris) == 0 {
+		return a.Check(ctx, action, "")
+	}
+
+	for _, uri := range uris {
+		if err := a.Check(ctx, action, uri); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// targetArguments maps ID arguments to the resource types they name, most specific first
+var targetArguments = []struct {
+	arg          string
+	resourceType string
+}{
+	{"notebookId", "notebook"},
+	{"featureId", "feature"},
+	{"toolId", "tool"},
+	{"productId", "product"},
+	{"libraryId", "library"},
+	{"typeName", "type"},
+}
+
+// uriArguments are the arguments that name a resource by URI
+var uriArguments = []string{"uri", "resource_uri", "sourceUri"}
+
+// ResolveTargets returns the URIs of every resource that tool or route arguments
+// address, most specific first: each existing resource an ID argument names, the
+// tenantId argument's tenant and each URI argument. Calls are authorized against
+// all of them, so that naming one allowed resource cannot carry an action onto
+// another. An ID naming no resource yet, such as that of one being created, is
+// left out: its parent decides. The tenant is left out when another target lies
+// within it, as that target's policy covers the tenant's.
+func ResolveTargets(ctx context.Context, attrs *postgres.AuthzRepository, args map[string]interface{}) ([]string, error) {
+	tenantID, _ := args["tenantId"].(string)
+	ctx = postgres.WithSystemScope(ctx)
+
+	var targets []string
+	tenantCovered := false
+	for _, target := range targetArguments {
+		id, _ := args[target.arg].(string)
+		if id == "" {
+			continue
+		}
+
+		// Types are global: their URI names no tenant
+		ref := &domain.ResourceRef{Type: target.resourceType, ID: id}
+		if target.resourceType != "type" {
+			owner, err := attrs.TenantOf(ctx, target.resourceType, id)
+			if errors.Is(err, postgres.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			ref.TenantID = owner
+			tenantCovered = tenantCovered || owner == tenantID
+		}
+		targets = append(targets, ref.URI())
+	}
+
+	if tenantID != "" && !tenantCovered {
+		ref := &domain.ResourceRef{TenantID: tenantID, Type: "tenant", ID: tenantID}
+		targets = append(targets, ref.URI())
+	}
+
+	for _, arg := range uriArguments {
+		uri, _ := args[arg].(string)
+		if uri == "" {
+			continue
+		}
+		ref, err := domain.ParseURI(uri)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", arg, err)
+		}
+		targets = append(targets, ref.URI())
+	}
+
+	return targets, nil
+}
+
+// PrimaryTarget returns the most specific of the targets ResolveTargets returns,
+// which audit entries and request quotas are recorded against, or "" if none
+func PrimaryTarget(targets []string) string {
+	if len(targets) == 0 {
+		return ""
+	}
+	return targets[0]
+}
+
+// subjectFromContext describes the caller on ctx
+func subjectFromContext(ctx context.Context) Subject {
+	subject := Subject{Roles: []string{}, Local: auth.IsLocalOperator(ctx)}
+
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		subject.ID = principal.ID()
+		subject.Email = principal.Email
+		subject.TenantID = principal.TenantID
+		subject.Authenticated = true
+		subject.ServiceAccount = principal.ServiceAccount
+		if principal.Roles != nil {
+			subject.Roles = principal.Roles
+		}
+	}
+
+	return subject
+}
+
+// resource describes the resource at uri with the attributes policies decide on
+func (a *Authorizer) resource(ctx context.Context, uri string, subject Subject) (*Resource, error) {
+	if uri == "" {
+		return &Resource{}, nil
+	}
+
+	ref, err := domain.ParseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := &Resource{URI: ref.URI(), Type: ref.Type, TenantID: ref.TenantID, ID: ref.ID}
+
+	if resource.Owner, err = a.attrs.Owner(ctx, ref.Type, ref.ID); err != nil {
+		return nil, err
+	}
+
+	if ref.TenantID != "" {
+		if resource.TenantOwner, err = a.attrs.Owner(ctx, "tenant", ref.TenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	if subject.Authenticated {
+		var userIDs []string
+		for _, id := range []string{subject.Email, subject.ID} {
+			if id != "" {
+				userIDs = append(userIDs, id)
+			}
+		}
+
+		switch ref.Type {
+		case "product":
+			resource.ProductRole, err = a.attrs.ProductRole(ctx, ref.ID, userIDs...)
+		case "notebook", "feature":
+			resource.ProductRole, err = a.attrs.AttachedProductRole(ctx, ref.Type, ref.ID, userIDs...)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resource, nil
+}
+
+// evaluate queries the OPA decision document for input
+func (a *Authorizer) evaluate(ctx context.Context, input *Input) (*Decision, error) {
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal policy input: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create policy request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query OPA: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OPA returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Result *Decision `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode OPA response: %w", err)
+	}
+
+	// OPA omits the result when the document is undefined, i.e. the policy is not loaded
+	if result.Result == nil {
+		return nil, fmt.Errorf("OPA has no %s document; are the policies loaded?", DecisionPath)
+	}
+
+	return result.Result, nil
+}
+
+func (a *Authorizer) cached(key string) (*Decision, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entry, ok := a.cache[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.decision, true
+}
+
+func (a *Authorizer) store(key string, decision *Decision) {
+	if a.ttl <= 0 {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if len(a.cache) >= maxCacheEntries {
+		for k, entry := range a.cache {
+			if now.After(entry.expires) {
+				delete(a.cache, k)
+			}
+		}
+		if len(a.cache) >= maxCacheEntries {
+			a.cache = make(map[string]cachedDecision)
+		}
+	}
+
+	a.cache[key] = cachedDecision{decision: decision, expires: now.Add(a.ttl)}
+}
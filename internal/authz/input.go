@@ -0,0 +1,45 @@
+package authz
+
+// Input is the document policies are evaluated against
+type Input struct {
+	Subject  Subject  `json:"subject"`
+	Action   string   `json:"action"`
+	Resource Resource `json:"resource"`
+}
+
+// Subject is the caller an authorization decision is made for
+type Subject struct {
+	// ID is the token subject qualified by its issuer, as auth.Principal.ID
+	// returns it; empty when the caller is not authenticated
+	ID       string   `json:"id"`
+	Email    string   `json:"email,omitempty"`
+	TenantID string   `json:"tenant,omitempty"`
+	Roles    []string `json:"roles"`
+	// Authenticated is true when the caller presented a valid bearer token
+	Authenticated bool `json:"authenticated"`
+	// Local is true for the operator running the process over stdio
+	Local bool `json:"local"`
+	// ServiceAccount is true for machine clients authenticated with an API key
+	ServiceAccount bool `json:"service_account"`
+}
+
+// Resource is the target of the action. URI is empty for actions without a target.
+type Resource struct {
+	URI      string `json:"uri,omitempty"`
+	Type     string `json:"type,omitempty"`
+	TenantID string `json:"tenant,omitempty"`
+	ID       string `json:"id,omitempty"`
+	// Owner is the owner of a tenant, library or notebook
+	Owner string `json:"owner,omitempty"`
+	// TenantOwner is the owner of the resource's tenant
+	TenantOwner string `json:"tenant_owner,omitempty"`
+	// ProductRole is the subject's role on a product, or on a notebook or feature
+	// its most privileged role on the products the resource is part of
+	ProductRole string `json:"product_role,omitempty"`
+}
+
+// Decision is the outcome of a policy evaluation
+type Decision struct {
+	Allow   bool     `json:"allow"`
+	Reasons []string `json:"reasons"`
+}
@@ -0,0 +1,33 @@
+package authz
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prismon/synthesis/internal/auth"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// CallerScope returns ctx with the database scope of its caller: the whole system
+// for the local operator, administrators and unauthenticated deployments, else the
+// principal's tenant. A principal without a tenant sees no tenant-owned rows.
+func CallerScope(ctx context.Context) context.Context {
+	if auth.IsLocalOperator(ctx) {
+		return postgres.WithSystemScope(ctx)
+	}
+
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.HasRole("admin") {
+		return postgres.WithSystemScope(ctx)
+	}
+
+	return postgres.WithTenantScope(ctx, principal.TenantID)
+}
+
+// ScopeMiddleware runs each request's queries in its caller's database scope. It
+// must come after authentication, which puts the principal on the request.
+func ScopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(CallerScope(r.Context())))
+	})
+}
@@ -0,0 +1,77 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// PostgresStore stores blobs as Postgres large objects, keeping each key's
+// object ID in blob_object
+type PostgresStore struct {
+	db *postgres.DB
+}
+
+// NewPostgresStore creates a store of blobs in db's large objects
+func NewPostgresStore(db *postgres.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Put writes a blob's large object unless the key has one already
+func (s *PostgresStore) Put(ctx context.Context, key string, data []byte) error {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Uploads of the same blob wait for each other, so only one object is made
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key); err != nil {
+		return fmt.Errorf("failed to lock blob: %w", err)
+	}
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM blob_object WHERE key = $1)`, key).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check blob: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO blob_object (key, oid) VALUES ($1, lo_from_bytea(0, $2))`, key, data); err != nil {
+		return fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Get reads a blob's large object
+func (s *PostgresStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT lo_get(oid) FROM blob_object WHERE key = $1`, key).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Delete removes a blob's large object
+func (s *PostgresStore) Delete(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, `
+		WITH removed AS (DELETE FROM blob_object WHERE key = $1 RETURNING oid)
+		SELECT lo_unlink(oid) FROM removed
+	`, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+
+	return nil
+}
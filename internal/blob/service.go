@@ -0,0 +1,239 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// ErrTooLarge is returned for content larger than the service's maximum blob size
+var ErrTooLarge = errors.New("blob is too large")
+
+// ErrCorrupt is returned when reading a blob whose stored bytes no longer match its digest
+var ErrCorrupt = errors.New("blob does not match its digest")
+
+// Service stores blobs: their bytes in a Store and their metadata in Postgres
+type Service struct {
+	store    Store
+	blobs    *postgres.BlobRepository
+	maxBytes int64
+}
+
+// NewService creates a blob service storing bytes in store. Blobs larger than
+// maxBytes are refused; 0 means no limit.
+func NewService(store Store, blobs *postgres.BlobRepository, maxBytes int64) *Service {
+	return &Service{store: store, blobs: blobs, maxBytes: maxBytes}
+}
+
+// MaxBytes returns the largest blob the service stores; 0 means no limit
+func (s *Service) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// Put reads a blob from r and stores it in a tenant, sniffing its media type
+// from its bytes. check, if not nil, is called with the blob's metadata before
+// anything is stored and may refuse it by returning an error.
+func (s *Service) Put(ctx context.Context, tenantID string, r io.Reader, check func(*domain.Blob) error) (*domain.Blob, error) {
+	if s.maxBytes > 0 {
+		r = io.LimitReader(r, s.maxBytes+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+
+	return s.put(ctx, tenantID, data, check)
+}
+
+func (s *Service) put(ctx context.Context, tenantID string, data []byte, check func(*domain.Blob) error) (*domain.Blob, error) {
+	blob, err := s.describe(tenantID, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if check != nil {
+		if err := check(blob); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.save(ctx, blob, data); err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+// describe returns the metadata of data as a tenant's blob, without storing it
+func (s *Service) describe(tenantID string, data []byte) (*domain.Blob, error) {
+	if s.maxBytes > 0 && int64(len(data)) > s.maxBytes {
+		return nil, fmt.Errorf("%w: the maximum is %d bytes", ErrTooLarge, s.maxBytes)
+	}
+
+	sum := sha256.Sum256(data)
+	return &domain.Blob{
+		TenantID:  tenantID,
+		Digest:    hex.EncodeToString(sum[:]),
+		MediaType: http.DetectContentType(data),
+		Size:      int64(len(data)),
+	}, nil
+}
+
+// save stores the bytes of blob and records it
+func (s *Service) save(ctx context.Context, blob *domain.Blob, data []byte) error {
+	// The bytes are stored first: a blob's metadata must never outlive them
+	if err := s.store.Put(ctx, key(blob.TenantID, blob.Digest), data); err != nil {
+		return err
+	}
+	return s.blobs.Create(ctx, blob)
+}
+
+// Stat returns the metadata of a tenant's blob
+func (s *Service) Stat(ctx context.Context, tenantID, digest string) (*domain.Blob, error) {
+	if !domain.ValidBlobDigest(digest) {
+		return nil, fmt.Errorf("invalid blob digest %q", digest)
+	}
+	return s.blobs.Get(ctx, tenantID, digest)
+}
+
+// Open returns the bytes of a blob Stat returned. The bytes are checked
+// against the blob's digest as they are read: reading to the end returns
+// ErrCorrupt instead of io.EOF if they do not match.
+func (s *Service) Open(ctx context.Context, blob *domain.Blob) (io.ReadCloser, error) {
+	r, err := s.store.Get(ctx, key(blob.TenantID, blob.Digest))
+	if err != nil {
+		return nil, err
+	}
+	return &verifier{ReadCloser: r, hash: sha256.New(), digest: blob.Digest}, nil
+}
+
+// verifier hashes the bytes read through it and checks them against a digest at the end
+type verifier struct {
+	io.ReadCloser
+	hash   hash.Hash
+	digest string
+}
+
+func (v *verifier) Read(p []byte) (int, error) {
+	n, err := v.ReadCloser.Read(p)
+	v.hash.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(v.hash.Sum(nil)) != v.digest {
+		return n, fmt.Errorf("%w: %s", ErrCorrupt, v.digest)
+	}
+	return n, err
+}
+
+// Pending is the data of a content block about to be written, as Prepare
+// prepares it
+type Pending struct {
+	// Data is the data to write
+	Data string
+	// Blob is the blob Data references, if it references one
+	Blob *domain.Blob
+	// content is the binary content Blob is to be stored from by Offload, if
+	// it was held inline
+	content []byte
+}
+
+// Prepare prepares the data of a content block about to be written to a
+// tenant's notebook, storing nothing. Data referencing a blob must reference
+// one of the tenant's. Binary content held inline, as base64 or a base64 data:
+// URI, is replaced by a reference to the blob Offload stores it as. Other data
+// is kept as it is. The block can be validated with the pending blob before
+// it is stored, so content that is refused leaves no blob behind.
+func (s *Service) Prepare(ctx context.Context, tenantID, contentType, data string) (*Pending, error) {
+	if strings.HasPrefix(data, domain.BlobRefPrefix) {
+		digest, ok := domain.ParseBlobRef(data)
+		if !ok {
+			return nil, fmt.Errorf("invalid blob reference %q", data)
+		}
+		blob, err := s.blobs.Get(ctx, tenantID, digest)
+		if err != nil {
+			return nil, err
+		}
+		return &Pending{Data: data, Blob: blob}, nil
+	}
+
+	if !binaryContentType(contentType) {
+		return &Pending{Data: data}, nil
+	}
+
+	decoded, ok := decodeInline(data)
+	if !ok {
+		return &Pending{Data: data}, nil
+	}
+
+	blob, err := s.describe(tenantID, decoded)
+	if err != nil {
+		return nil, err
+	}
+	return &Pending{Data: blob.Ref(), Blob: blob, content: decoded}, nil
+}
+
+// Offload stores the blob of binary content pending held inline. It does
+// nothing for data that held none.
+func (s *Service) Offload(ctx context.Context, pending *Pending) error {
+	if pending.content == nil {
+		return nil
+	}
+	return s.save(ctx, pending.Blob, pending.content)
+}
+
+// key returns the store key of a tenant's blob. A two-character prefix of the
+// digest spreads a tenant's blobs over directories.
+func key(tenantID, digest string) string {
+	return url.PathEscape(tenantID) + "/" + digest[:2] + "/" + digest
+}
+
+// binaryContentType reports whether content blocks of contentType hold binary content
+func binaryContentType(contentType string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = strings.TrimSpace(contentType[:i])
+	}
+
+	for _, prefix := range []string{"image/", "audio/", "video/", "binary/"} {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return contentType == "application/octet-stream" || contentType == "application/pdf"
+}
+
+// decodeInline decodes binary content held as base64 or a base64 data: URI
+func decodeInline(data string) ([]byte, bool) {
+	if strings.HasPrefix(data, "data:") {
+		i := strings.Index(data, ";base64,")
+		if i < 0 {
+			return nil, false
+		}
+		data = data[i+len(";base64,"):]
+	}
+
+	data = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, data)
+	if data == "" {
+		return nil, false
+	}
+
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if decoded, err := encoding.DecodeString(data); err == nil {
+			return decoded, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,70 @@
+package blob
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+func TestPrepareStoresNothing(t *testing.T) {
+	image := []byte("\x89PNG\r\n\x1a\nnot really an image")
+	ref := (&domain.Blob{Digest: sha256Hex(image)}).Ref()
+	encoded := base64.StdEncoding.EncodeToString(image)
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        string
+		want        string
+	}{
+		{"base64 image", "image/png", encoded, ref},
+		{"data URI image", "image/png", "data:image/png;base64," + encoded, ref},
+		{"binary content type with parameters", "application/octet-stream; x=y", encoded, ref},
+		{"text is kept", "text/markdown", encoded, encoded},
+		{"undecodable binary is kept", "image/png", "not base64!", "not base64!"},
+		{"data URI without base64 is kept", "image/png", "data:image/png,x", "data:image/png,x"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store, err := NewFileStore(dir)
+			if err != nil {
+				t.Fatalf("failed to create store: %v", err)
+			}
+			service := NewService(store, nil, 0)
+
+			pending, err := service.Prepare(context.Background(), "acme", tc.contentType, tc.data)
+			if err != nil {
+				t.Fatalf("failed to prepare: %v", err)
+			}
+			if pending.Data != tc.want {
+				t.Errorf("expected data %q, got %q", tc.want, pending.Data)
+			}
+			if tc.want == ref && (pending.Blob == nil || pending.Blob.Size != int64(len(image)) || pending.Blob.MediaType != "image/png") {
+				t.Errorf("expected the pending blob to describe the image, got %+v", pending.Blob)
+			}
+
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Errorf("expected nothing to be stored before Offload, found %d entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestPrepareRefusesLargeContent(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	service := NewService(store, nil, 4)
+
+	_, err = service.Prepare(context.Background(), "acme", "image/png", base64.StdEncoding.EncodeToString([]byte("12345")))
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
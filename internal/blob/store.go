@@ -0,0 +1,48 @@
+// Package blob stores binary content, such as images, outside the content
+// blocks referencing it. Blobs are content-addressed within their tenant by
+// the SHA-256 digest of their bytes; their metadata is kept in Postgres and
+// their bytes in a Store: the filesystem, Postgres large objects or an
+// S3-compatible object store.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/prismon/synthesis/internal/config"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// ErrNotFound is returned by a Store for a key it does not hold
+var ErrNotFound = errors.New("blob not found")
+
+// Store holds blob bytes by key. Keys are slash-separated paths of
+// URL-escaped segments. Putting a key that exists already must succeed and
+// may leave the stored bytes as they are, since a key names the same bytes
+// every time.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStore creates the store cfg configures
+func NewStore(cfg config.BlobConfig, db *postgres.DB) (Store, error) {
+	switch cfg.Backend {
+	case config.BlobBackendFilesystem:
+		return NewFileStore(cfg.Dir)
+	case config.BlobBackendS3:
+		return NewS3Store(S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Bucket:          cfg.S3Bucket,
+			Region:          cfg.S3Region,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+		})
+	case config.BlobBackendPostgres, "":
+		return NewPostgresStore(db), nil
+	}
+	return nil, fmt.Errorf("unknown blob backend %q", cfg.Backend)
+}
@@ -0,0 +1,246 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prismon/synthesis/internal/config"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// testStore puts, gets and deletes blobs in store
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	data := []byte("\x89PNG\r\n\x1a\nnot really an image")
+	k := key("acme corp", sha256Hex(data))
+
+	if _, err := store.Get(ctx, k); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound before put, got %v", err)
+	}
+
+	if err := store.Put(ctx, k, data); err != nil {
+		t.Fatalf("failed to put: %v", err)
+	}
+	// Putting a key again succeeds, since a key always names the same bytes
+	if err := store.Put(ctx, k, data); err != nil {
+		t.Fatalf("failed to put again: %v", err)
+	}
+
+	if got := readBlob(t, store, k); !bytes.Equal(got, data) {
+		t.Fatalf("expected %q, got %q", data, got)
+	}
+
+	if err := store.Delete(ctx, k); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if _, err := store.Get(ctx, k); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+	// Deleting a missing key succeeds
+	if err := store.Delete(ctx, k); err != nil {
+		t.Fatalf("failed to delete a missing blob: %v", err)
+	}
+}
+
+func readBlob(t *testing.T, store Store, k string) []byte {
+	t.Helper()
+
+	r, err := store.Get(context.Background(), k)
+	if err != nil {
+		t.Fatalf("failed to get: %v", err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	return data
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	testStore(t, store)
+
+	for _, k := range []string{"../escape", "a/../../escape", "/absolute", "a//b", "a/."} {
+		if err := store.Put(context.Background(), k, []byte("x")); err == nil {
+			t.Errorf("expected key %q to be refused", k)
+		}
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(dir)); len(entries) != 1 {
+		t.Errorf("expected nothing to be written outside the blob directory, found %d entries", len(entries))
+	}
+}
+
+func TestPostgresStore(t *testing.T) {
+	url := os.Getenv("SYNTHESIS_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("SYNTHESIS_TEST_DATABASE_URL is not set")
+	}
+
+	db, err := postgres.New(config.DatabaseConfig{URL: url, MaxOpenConns: 2, MaxIdleConns: 1, ConnMaxLifetime: 60})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	testStore(t, NewPostgresStore(db))
+}
+
+// fakeS3 is an in-memory S3-compatible endpoint holding one bucket. It checks
+// each request is signed and that the declared payload hash matches the body.
+type fakeS3 struct {
+	t       *testing.T
+	bucket  string
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/us-east-1/s3/aws4_request") {
+		f.t.Errorf("unexpected Authorization header %q", auth)
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	if got := r.Header.Get("X-Amz-Content-Sha256"); got != sha256Hex(body) {
+		f.t.Errorf("payload hash %s does not match the body", got)
+		http.Error(w, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+		return
+	}
+
+	object, ok := strings.CutPrefix(r.URL.EscapedPath(), "/"+f.bucket+"/")
+	if !ok {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[object] = body
+	case http.MethodGet:
+		data, ok := f.objects[object]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, object)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	fake := &fakeS3{t: t, bucket: "blobs", objects: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{Endpoint: server.URL, Bucket: "blobs", AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	testStore(t, store)
+}
+
+func TestS3StoreReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "SlowDown", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{Endpoint: server.URL, Bucket: "blobs"})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := store.Put(ctx, "a/b", []byte("x")); err == nil || !strings.Contains(err.Error(), "SlowDown") {
+		t.Errorf("expected put to fail with the store's error, got %v", err)
+	}
+	if _, err := store.Get(ctx, "a/b"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected get to fail, got %v", err)
+	}
+	if err := store.Delete(ctx, "a/b"); err == nil {
+		t.Error("expected delete to fail")
+	}
+}
+
+func TestNewS3StoreRejectsInvalidConfig(t *testing.T) {
+	for _, cfg := range []S3Config{
+		{Endpoint: "", Bucket: "blobs"},
+		{Endpoint: "ftp://example.com", Bucket: "blobs"},
+		{Endpoint: "https://example.com"},
+	} {
+		if _, err := NewS3Store(cfg); err == nil {
+			t.Errorf("expected %+v to be rejected", cfg)
+		}
+	}
+}
+
+func TestOpenVerifiesDigest(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	service := NewService(store, nil, 0)
+
+	data := []byte("hello, blob")
+	sum := sha256.Sum256(data)
+	b := &domain.Blob{TenantID: "acme", Digest: hex.EncodeToString(sum[:]), Size: int64(len(data))}
+
+	if err := store.Put(ctx, key(b.TenantID, b.Digest), data); err != nil {
+		t.Fatalf("failed to put: %v", err)
+	}
+	r, err := service.Open(ctx, b)
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("expected %q, got %q, %v", data, got, err)
+	}
+
+	// Bytes altered in the store no longer match the digest
+	if err := store.Delete(ctx, key(b.TenantID, b.Digest)); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := store.Put(ctx, key(b.TenantID, b.Digest), []byte("hello, blub")); err != nil {
+		t.Fatalf("failed to put: %v", err)
+	}
+	r, err = service.Open(ctx, b)
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	defer r.Close()
+	if _, err := io.ReadAll(r); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("expected ErrCorrupt, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// APIKey is a tenant-scoped credential for a machine client. Requests made with
+// it act as a service account holding the key's scopes as roles.
+type APIKey struct {
+	ID       string `json:"keyId" db:"id"`
+	TenantID string `json:"tenantId" db:"tenant_id"`
+	Name     string `json:"name" db:"name"`
+	// Prefix is the public part of the key, shown in listings to identify it
+	Prefix     string     `json:"prefix" db:"prefix"`
+	KeyHash    string     `json:"-" db:"key_hash"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedBy  string     `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Active reports whether the key may still be used at the given time
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit outcomes
+const (
+	AuditOutcomeSucceeded = "succeeded"
+	AuditOutcomeFailed    = "failed"
+)
+
+// Audit sources
+const (
+	AuditSourceMCP  = "mcp"
+	AuditSourceREST = "rest"
+)
+
+// AuditEntry records a mutating action: who performed it, on what, and how the
+// target looked before and after
+type AuditEntry struct {
+	ID       string `json:"auditId" db:"id"`
+	Sequence int64  `json:"seq" db:"seq"`
+	TenantID string `json:"tenantId,omitempty" db:"tenant_id"`
+	// Principal is the caller's identity, "local" for the stdio operator or
+	// "anonymous" for unauthenticated requests
+	Principal string `json:"principal" db:"principal"`
+	Source    string `json:"source" db:"source"`
+	// Action is the tool name or the REST method and route template
+	Action     string          `json:"action" db:"action"`
+	TargetURI  string          `json:"target_uri,omitempty" db:"target_uri"`
+	RequestID  string          `json:"request_id,omitempty" db:"request_id"`
+	Outcome    string          `json:"outcome" db:"outcome"`
+	Error      string          `json:"error,omitempty" db:"error"`
+	Before     json.RawMessage `json:"before,omitempty" db:"before_summary"`
+	After      json.RawMessage `json:"after,omitempty" db:"after_summary"`
+	OccurredAt time.Time       `json:"occurred_at" db:"occurred_at"`
+}
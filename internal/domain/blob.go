@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// BlobRefPrefix starts the data of a content block whose content is a blob
+const BlobRefPrefix = "blob:sha256:"
+
+// blobDigest matches a hex SHA-256 digest
+var blobDigest = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Blob is binary content stored outside the content blocks referencing it,
+// addressed within its tenant by the SHA-256 digest of its bytes
+type Blob struct {
+	TenantID  string    `json:"tenantId" db:"tenant_id"`
+	Digest    string    `json:"digest" db:"digest"`
+	MediaType string    `json:"mediaType" db:"media_type"`
+	Size      int64     `json:"size" db:"size"`
+	CreatedAt time.Time `json:"createdAt,omitempty" db:"created_at"`
+}
+
+// Ref returns the content block data referencing the blob
+func (b *Blob) Ref() string {
+	return BlobRefPrefix + b.Digest
+}
+
+// URL returns the REST API path the blob is downloaded from
+func (b *Blob) URL() string {
+	return "/api/v1/blobs/by-tenant/" + b.TenantID + "/" + b.Digest
+}
+
+// ParseBlobRef returns the digest of the blob content block data references
+func ParseBlobRef(data string) (string, bool) {
+	if !strings.HasPrefix(data, BlobRefPrefix) {
+		return "", false
+	}
+	digest := strings.TrimPrefix(data, BlobRefPrefix)
+	return digest, ValidBlobDigest(digest)
+}
+
+// ValidBlobDigest reports whether digest is a lowercase hex SHA-256 digest
+func ValidBlobDigest(digest string) bool {
+	return blobDigest.MatchString(digest)
+}
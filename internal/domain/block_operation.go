@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Block operation types
+const (
+	BlockOpInsert = "insert"
+	BlockOpUpdate = "update"
+	BlockOpMove   = "move"
+	BlockOpDelete = "delete"
+)
+
+// Block operation outcomes
+const (
+	BlockOpApplied   = "applied"
+	BlockOpDiscarded = "discarded"
+)
+
+// BlockOperation is a single edit to a notebook's content blocks. The server
+// sequences operations per notebook: each applied operation gets the next revision
+// and is broadcast to every collaborator in revision order.
+//
+// Concurrent edits are resolved by that order, so every client converges on the same blocks:
+//   - update replaces the block's content; of two concurrent updates, the later revision wins
+//   - update, move and delete of a block that no longer exists are discarded
+//   - delete removes the block and all of its descendants
+//   - insert of a UID that already exists, under a parent that no longer exists,
+//     or a move beneath the block's own descendant, is discarded
+//   - insert and move place the block after AfterUID; when AfterUID is no longer
+//     a child of the parent, the block goes last
+type BlockOperation struct {
+	// ID is chosen by the client and makes retries idempotent
+	ID         string `json:"opId"`
+	NotebookID string `json:"notebookId"`
+	// Revision is assigned by the server when the operation is applied
+	Revision int64 `json:"revision"`
+	// BaseRevision is the last revision the client had seen when it made the edit
+	BaseRevision int64  `json:"baseRevision"`
+	Type         string `json:"type"`
+	BlockUID     string `json:"uid"`
+
+	// ParentUID and AfterUID position the block for insert and move. A nil ParentUID
+	// is the top level. A nil AfterUID puts the block last, an empty one first.
+	ParentUID *string `json:"parent_uid,omitempty"`
+	AfterUID  *string `json:"after_uid,omitempty"`
+
+	// ContentType, Data and Types are the block content for insert and update
+	ContentType string   `json:"content_type,omitempty"`
+	Data        string   `json:"data,omitempty"`
+	Types       []string `json:"types,omitempty"`
+
+	Actor     string    `json:"actor"`
+	Status    string    `json:"status,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	AppliedAt time.Time `json:"applied_at,omitempty"`
+}
+
+// Validate checks that the operation is well formed
+func (op *BlockOperation) Validate() error {
+	if op.ID == "" {
+		return fmt.Errorf("opId is required")
+	}
+	if op.BlockUID == "" {
+		return fmt.Errorf("uid is required")
+	}
+
+	switch op.Type {
+	case BlockOpInsert, BlockOpUpdate:
+		if op.ContentType == "" {
+			return fmt.Errorf("content_type is required for %s", op.Type)
+		}
+	case BlockOpMove, BlockOpDelete:
+	default:
+		return fmt.Errorf("unknown block operation %q", op.Type)
+	}
+
+	if op.ParentUID != nil && *op.ParentUID == op.BlockUID {
+		return fmt.Errorf("block %s cannot be its own parent", op.BlockUID)
+	}
+
+	return nil
+}
+
+// Discard marks the operation as not applied
+func (op *BlockOperation) Discard(reason string, args ...interface{}) {
+	op.Status = BlockOpDiscarded
+	op.Reason = fmt.Sprintf(reason, args...)
+}
+
+// BlockTree is a notebook's blocks, as resolving an operation against them sees them
+type BlockTree interface {
+	// Exists reports whether the notebook has block uid
+	Exists(ctx context.Context, uid string) (bool, error)
+	// IsDescendant reports whether block uid lies beneath block ancestorUID
+	IsDescendant(ctx context.Context, uid, ancestorUID string) (bool, error)
+}
+
+// Resolve discards op if it conflicts with the blocks it is applied to, by the
+// rules described on BlockOperation. An operation it leaves is applied.
+func (op *BlockOperation) Resolve(ctx context.Context, blocks BlockTree) error {
+	exists, err := blocks.Exists(ctx, op.BlockUID)
+	if err != nil {
+		return err
+	}
+
+	if op.Type == BlockOpInsert {
+		if exists {
+			op.Discard("block %s already exists", op.BlockUID)
+			return nil
+		}
+	} else if !exists {
+		op.Discard("block %s no longer exists", op.BlockUID)
+		return nil
+	}
+
+	if op.ParentUID == nil || (op.Type != BlockOpInsert && op.Type != BlockOpMove) {
+		return nil
+	}
+
+	parentExists, err := blocks.Exists(ctx, *op.ParentUID)
+	if err != nil {
+		return err
+	}
+	if !parentExists {
+		op.Discard("parent block %s no longer exists", *op.ParentUID)
+		return nil
+	}
+
+	if op.Type == BlockOpMove {
+		descendant, err := blocks.IsDescendant(ctx, *op.ParentUID, op.BlockUID)
+		if err != nil {
+			return err
+		}
+		if descendant {
+			op.Discard("block %s cannot move beneath its own descendant %s", op.BlockUID, *op.ParentUID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// blockTree is an in-memory BlockTree mapping each block to its parent; "" is the top level
+type blockTree map[string]string
+
+func (t blockTree) Exists(ctx context.Context, uid string) (bool, error) {
+	_, ok := t[uid]
+	return ok, nil
+}
+
+func (t blockTree) IsDescendant(ctx context.Context, uid, ancestorUID string) (bool, error) {
+	for parent, ok := t[uid]; ok && parent != ""; parent, ok = t[parent] {
+		if parent == ancestorUID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// apply resolves op against the tree and, unless it is discarded, applies it
+func (t blockTree) apply(op *BlockOperation) error {
+	if err := op.Resolve(context.Background(), t); err != nil {
+		return err
+	}
+	if op.Status == BlockOpDiscarded {
+		return nil
+	}
+	op.Status = BlockOpApplied
+
+	switch op.Type {
+	case BlockOpInsert, BlockOpMove:
+		t[op.BlockUID] = ""
+		if op.ParentUID != nil {
+			t[op.BlockUID] = *op.ParentUID
+		}
+	case BlockOpDelete:
+		deleted := []string{op.BlockUID}
+		for uid := range t {
+			if descendant, _ := t.IsDescendant(context.Background(), uid, op.BlockUID); descendant {
+				deleted = append(deleted, uid)
+			}
+		}
+		for _, uid := range deleted {
+			delete(t, uid)
+		}
+	}
+	return nil
+}
+
+func under(parent string) *string {
+	return &parent
+}
+
+func TestResolveBlockOperations(t *testing.T) {
+	tests := []struct {
+		name string
+		// tree is the notebook's blocks before the operations, in revision order
+		tree   blockTree
+		ops    []*BlockOperation
+		status []string
+		reason string
+	}{
+		{
+			name:   "concurrent updates both apply",
+			tree:   blockTree{"a": ""},
+			ops:    []*BlockOperation{{Type: BlockOpUpdate, BlockUID: "a"}, {Type: BlockOpUpdate, BlockUID: "a"}},
+			status: []string{BlockOpApplied, BlockOpApplied},
+		},
+		{
+			name:   "insert of an existing UID",
+			tree:   blockTree{},
+			ops:    []*BlockOperation{{Type: BlockOpInsert, BlockUID: "a"}, {Type: BlockOpInsert, BlockUID: "a"}},
+			status: []string{BlockOpApplied, BlockOpDiscarded},
+			reason: "block a already exists",
+		},
+		{
+			name:   "update of a deleted block",
+			tree:   blockTree{"a": ""},
+			ops:    []*BlockOperation{{Type: BlockOpDelete, BlockUID: "a"}, {Type: BlockOpUpdate, BlockUID: "a"}},
+			status: []string{BlockOpApplied, BlockOpDiscarded},
+			reason: "block a no longer exists",
+		},
+		{
+			name:   "move of a deleted block",
+			tree:   blockTree{"a": "", "b": ""},
+			ops:    []*BlockOperation{{Type: BlockOpDelete, BlockUID: "a"}, {Type: BlockOpMove, BlockUID: "a", ParentUID: under("b")}},
+			status: []string{BlockOpApplied, BlockOpDiscarded},
+			reason: "block a no longer exists",
+		},
+		{
+			name:   "delete of a deleted block",
+			tree:   blockTree{"a": ""},
+			ops:    []*BlockOperation{{Type: BlockOpDelete, BlockUID: "a"}, {Type: BlockOpDelete, BlockUID: "a"}},
+			status: []string{BlockOpApplied, BlockOpDiscarded},
+			reason: "block a no longer exists",
+		},
+		{
+			name:   "update of a descendant of a deleted block",
+			tree:   blockTree{"a": "", "b": "a", "c": "b"},
+			ops:    []*BlockOperation{{Type: BlockOpDelete, BlockUID: "a"}, {Type: BlockOpUpdate, BlockUID: "c"}},
+			status: []string{BlockOpApplied, BlockOpDiscarded},
+			reason: "block c no longer exists",
+		},
+		{
+			name:   "insert under a deleted parent",
+			tree:   blockTree{"a": ""},
+			ops:    []*BlockOperation{{Type: BlockOpDelete, BlockUID: "a"}, {Type: BlockOpInsert, BlockUID: "b", ParentUID: under("a")}},
+			status: []string{BlockOpApplied, BlockOpDiscarded},
+			reason: "parent block a no longer exists",
+		},
+		{
+			name:   "move under a deleted parent",
+			tree:   blockTree{"a": "", "b": ""},
+			ops:    []*BlockOperation{{Type: BlockOpDelete, BlockUID: "a"}, {Type: BlockOpMove, BlockUID: "b", ParentUID: under("a")}},
+			status: []string{BlockOpApplied, BlockOpDiscarded},
+			reason: "parent block a no longer exists",
+		},
+		{
+			name:   "update ignores the parent",
+			tree:   blockTree{"a": ""},
+			ops:    []*BlockOperation{{Type: BlockOpUpdate, BlockUID: "a", ParentUID: under("missing")}},
+			status: []string{BlockOpApplied},
+		},
+		{
+			name:   "move to the top level",
+			tree:   blockTree{"a": "", "b": "a"},
+			ops:    []*BlockOperation{{Type: BlockOpMove, BlockUID: "b"}},
+			status: []string{BlockOpApplied},
+		},
+		{
+			name:   "move beneath its own descendant",
+			tree:   blockTree{"a": "", "b": "a", "c": "b"},
+			ops:    []*BlockOperation{{Type: BlockOpMove, BlockUID: "a", ParentUID: under("c")}},
+			status: []string{BlockOpDiscarded},
+			reason: "block a cannot move beneath its own descendant c",
+		},
+		{
+			// Each move is valid against the blocks it was made against, but
+			// applying both would make a and b each other's ancestor
+			name:   "concurrent crossing moves",
+			tree:   blockTree{"a": "", "b": ""},
+			ops:    []*BlockOperation{{Type: BlockOpMove, BlockUID: "a", ParentUID: under("b")}, {Type: BlockOpMove, BlockUID: "b", ParentUID: under("a")}},
+			status: []string{BlockOpApplied, BlockOpDiscarded},
+			reason: "block b cannot move beneath its own descendant a",
+		},
+		{
+			name:   "insert after a concurrent insert of its parent",
+			tree:   blockTree{},
+			ops:    []*BlockOperation{{Type: BlockOpInsert, BlockUID: "a"}, {Type: BlockOpInsert, BlockUID: "b", ParentUID: under("a")}},
+			status: []string{BlockOpApplied, BlockOpApplied},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var status []string
+			var reason string
+			for _, op := range tc.ops {
+				if err := tc.tree.apply(op); err != nil {
+					t.Fatalf("failed to resolve %s of %s: %v", op.Type, op.BlockUID, err)
+				}
+				status = append(status, op.Status)
+				if op.Status == BlockOpDiscarded {
+					reason = op.Reason
+				}
+			}
+
+			if !reflect.DeepEqual(status, tc.status) {
+				t.Errorf("expected %v, got %v", tc.status, status)
+			}
+			if reason != tc.reason {
+				t.Errorf("expected reason %q, got %q", tc.reason, reason)
+			}
+		})
+	}
+}
+
+// failingTree is a BlockTree that cannot be read
+type failingTree struct{}
+
+func (failingTree) Exists(ctx context.Context, uid string) (bool, error) {
+	return false, fmt.Errorf("connection lost")
+}
+
+func (failingTree) IsDescendant(ctx context.Context, uid, ancestorUID string) (bool, error) {
+	return false, fmt.Errorf("connection lost")
+}
+
+func TestResolveReturnsTreeErrors(t *testing.T) {
+	op := &BlockOperation{Type: BlockOpUpdate, BlockUID: "a"}
+	if err := op.Resolve(context.Background(), failingTree{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if op.Status != "" {
+		t.Errorf("expected the operation to be left unresolved, got %s", op.Status)
+	}
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Domain event types
+const (
+	EventTenantCreated = "tenant.created"
+	EventTenantUpdated = "tenant.updated"
+	EventTenantDeleted = "tenant.deleted"
+
+	EventLibraryCreated = "library.created"
+	EventLibraryUpdated = "library.updated"
+	EventLibraryDeleted = "library.deleted"
+
+	EventNotebookCreated       = "notebook.created"
+	EventNotebookUpdated       = "notebook.updated"
+	EventNotebookDeleted       = "notebook.deleted"
+	EventNotebookStatusChanged = "notebook.status_changed"
+	EventNotebookBlocksChanged = "notebook.blocks_changed"
+
+	EventFeatureCreated = "feature.created"
+	EventFeatureUpdated = "feature.updated"
+	EventFeatureDeleted = "feature.deleted"
+	EventFeatureExpired = "feature.expired"
+
+	EventProductCreated = "product.created"
+	EventProductUpdated = "product.updated"
+	EventProductDeleted = "product.deleted"
+
+	EventToolCreated = "tool.created"
+	EventToolUpdated = "tool.updated"
+	EventToolDeleted = "tool.deleted"
+)
+
+// Event is a domain event recorded in the outbox in the same transaction as the change it describes
+type Event struct {
+	ID           string          `json:"id" db:"id"`
+	Sequence     int64           `json:"sequence" db:"seq"`
+	Type         string          `json:"type" db:"event_type"`
+	TenantID     string          `json:"tenantId" db:"tenant_id"`
+	ResourceType string          `json:"resource_type" db:"resource_type"`
+	ResourceID   string          `json:"resource_id" db:"resource_id"`
+	URI          string          `json:"uri" db:"resource_uri"`
+	Data         json.RawMessage `json:"data,omitempty" db:"payload"`
+	OccurredAt   time.Time       `json:"occurred_at" db:"occurred_at"`
+}
+
+// NewEvent creates an event of the given type for a resource. The resource type is
+// taken from the event type prefix and data is stored as the event payload.
+func NewEvent(eventType, tenantID, resourceID, uri string, data interface{}) (*Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	return &Event{
+		Type:         eventType,
+		TenantID:     tenantID,
+		ResourceType: strings.SplitN(eventType, ".", 2)[0],
+		ResourceID:   resourceID,
+		URI:          uri,
+		Data:         payload,
+	}, nil
+}
+
+// Removes reports whether the event describes a resource that no longer exists
+func (e *Event) Removes() bool {
+	return strings.HasSuffix(e.Type, ".deleted") || strings.HasSuffix(e.Type, ".expired")
+}
+
+// NotebookStatusChangedData is the payload of a notebook.status_changed event:
+// the notebook's fields alongside the change that was applied
+type NotebookStatusChangedData struct {
+	*Notebook
+	Change *NotebookStatusChange `json:"change"`
+}
+
+// NotebookBlocksChangedData is the payload of a notebook.blocks_changed event:
+// the notebook's fields alongside the block operation that was applied
+type NotebookBlocksChangedData struct {
+	*Notebook
+	Operation *BlockOperation `json:"operation"`
+}
@@ -12,6 +12,25 @@ type Feature struct {
 	Notifications []Notification      `json:"notification,omitempty"`
 	TTL           time.Duration       `json:"ttl,omitempty" db:"ttl"`
 	Values        map[string]string   `json:"values" db:"values_json"`
+	// SensitiveKeys names the values stored encrypted and redacted in responses
+	SensitiveKeys []string            `json:"sensitive_keys,omitempty" db:"sensitive_keys"`
+	// ValueTypes names the types whose constraints each value must meet
+	ValueTypes    map[string][]string `json:"value_types,omitempty" db:"value_types_json"`
+}
+
+// Redacted returns a copy of the feature with its sensitive values replaced by RedactedValue
+func (f *Feature) Redacted() *Feature {
+	redacted := *f
+	redacted.Values = make(map[string]string, len(f.Values))
+	for key, value := range f.Values {
+		redacted.Values[key] = value
+	}
+	for _, key := range f.SensitiveKeys {
+		if _, ok := redacted.Values[key]; ok {
+			redacted.Values[key] = RedactedValue
+		}
+	}
+	return &redacted
 }
 
 // ExternalResource represents a URL reference to an external resource
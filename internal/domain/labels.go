@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operators of a label requirement
+const (
+	LabelEquals       = "="
+	LabelNotEquals    = "!="
+	LabelIn           = "in"
+	LabelNotIn        = "notin"
+	LabelExists       = "exists"
+	LabelDoesNotExist = "!"
+)
+
+// LabelRequirement is one requirement of a label selector: that the label Key
+// equals, differs from, is or is not one of Values, or exists or not
+type LabelRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// LabelSelector selects the resources whose labels meet every requirement
+type LabelSelector []LabelRequirement
+
+// ParseLabelSelector parses a comma-separated label selector, e.g.
+// "environment=production,app in (nginx, api),!deprecated". Requirements are
+// key=value (or key==value), key!=value, key in (values), key notin (values),
+// key for a label that exists and !key for one that does not. A label that
+// does not exist meets != and notin requirements.
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	var requirements LabelSelector
+
+	for _, term := range splitSelector(selector) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			if strings.TrimSpace(selector) == "" {
+				break
+			}
+			return nil, fmt.Errorf("invalid label selector %q: empty requirement", selector)
+		}
+
+		requirement, err := parseLabelRequirement(term)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %v", selector, err)
+		}
+		requirements = append(requirements, requirement)
+	}
+
+	return requirements, nil
+}
+
+// splitSelector splits a selector at the commas outside parentheses
+func splitSelector(selector string) []string {
+	var terms []string
+	depth, start := 0, 0
+	for i, r := range selector {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, selector[start:])
+}
+
+func parseLabelRequirement(term string) (LabelRequirement, error) {
+	if strings.HasPrefix(term, "!") && !strings.Contains(term, "=") {
+		key := strings.TrimSpace(term[1:])
+		return LabelRequirement{Key: key, Operator: LabelDoesNotExist}, checkLabelKey(key)
+	}
+
+	// A set is parsed first, as its values may hold the = of a malformed value
+	if fields := strings.Fields(term); len(fields) >= 2 && (fields[1] == LabelIn || fields[1] == LabelNotIn) {
+		key := fields[0]
+		if err := checkLabelKey(key); err != nil {
+			return LabelRequirement{}, err
+		}
+
+		set := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(term[len(key):]), fields[1]))
+		if !strings.HasPrefix(set, "(") || !strings.HasSuffix(set, ")") {
+			return LabelRequirement{}, fmt.Errorf("%s %s needs a parenthesized list of values", key, fields[1])
+		}
+
+		var values []string
+		for _, value := range strings.Split(set[1:len(set)-1], ",") {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return LabelRequirement{}, fmt.Errorf("%s %s has an empty value", key, fields[1])
+			}
+			if err := checkLabelValue(value); err != nil {
+				return LabelRequirement{}, err
+			}
+			values = append(values, value)
+		}
+		return LabelRequirement{Key: key, Operator: fields[1], Values: values}, nil
+	}
+
+	for _, op := range []string{"!=", "==", "="} {
+		if i := strings.Index(term, op); i >= 0 {
+			key := strings.TrimSpace(term[:i])
+			value := strings.TrimSpace(term[i+len(op):])
+			if err := checkLabelKey(key); err != nil {
+				return LabelRequirement{}, err
+			}
+			if err := checkLabelValue(value); err != nil {
+				return LabelRequirement{}, err
+			}
+
+			operator := LabelEquals
+			if op == "!=" {
+				operator = LabelNotEquals
+			}
+			return LabelRequirement{Key: key, Operator: operator, Values: []string{value}}, nil
+		}
+	}
+
+	return LabelRequirement{Key: term, Operator: LabelExists}, checkLabelKey(term)
+}
+
+// checkLabelKey checks that a key is non-empty and holds no spaces or selector syntax
+func checkLabelKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("empty label key")
+	}
+	if strings.ContainsAny(key, " \t\n=!(),") {
+		return fmt.Errorf("invalid label key %q", key)
+	}
+	return nil
+}
+
+// checkLabelValue checks that a value holds no selector syntax
+func checkLabelValue(value string) error {
+	if strings.ContainsAny(value, "=!(),") {
+		return fmt.Errorf("invalid label value %q", value)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     LabelSelector
+	}{
+		{"", nil},
+		{"  ", nil},
+		{"a=b", LabelSelector{{Key: "a", Operator: LabelEquals, Values: []string{"b"}}}},
+		{"a==b", LabelSelector{{Key: "a", Operator: LabelEquals, Values: []string{"b"}}}},
+		{" a = b ", LabelSelector{{Key: "a", Operator: LabelEquals, Values: []string{"b"}}}},
+		{"a=", LabelSelector{{Key: "a", Operator: LabelEquals, Values: []string{""}}}},
+		{"a!=b", LabelSelector{{Key: "a", Operator: LabelNotEquals, Values: []string{"b"}}}},
+		{"a", LabelSelector{{Key: "a", Operator: LabelExists}}},
+		{"!a", LabelSelector{{Key: "a", Operator: LabelDoesNotExist}}},
+		{"! a", LabelSelector{{Key: "a", Operator: LabelDoesNotExist}}},
+		{"a in (x)", LabelSelector{{Key: "a", Operator: LabelIn, Values: []string{"x"}}}},
+		{"a in (x, y)", LabelSelector{{Key: "a", Operator: LabelIn, Values: []string{"x", "y"}}}},
+		{"a notin (x,y)", LabelSelector{{Key: "a", Operator: LabelNotIn, Values: []string{"x", "y"}}}},
+		{"a  in  ( x )", LabelSelector{{Key: "a", Operator: LabelIn, Values: []string{"x"}}}},
+		{"in", LabelSelector{{Key: "in", Operator: LabelExists}}},
+		{"environment=production,app in (nginx, api),!deprecated", LabelSelector{
+			{Key: "environment", Operator: LabelEquals, Values: []string{"production"}},
+			{Key: "app", Operator: LabelIn, Values: []string{"nginx", "api"}},
+			{Key: "deprecated", Operator: LabelDoesNotExist},
+		}},
+	}
+
+	for _, tc := range tests {
+		got, err := ParseLabelSelector(tc.selector)
+		if err != nil {
+			t.Errorf("%q: %v", tc.selector, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: expected %+v, got %+v", tc.selector, tc.want, got)
+		}
+	}
+}
+
+func TestParseLabelSelectorErrors(t *testing.T) {
+	tests := map[string]string{
+		",":                "empty requirement",
+		"a,":               "empty requirement",
+		",a":               "empty requirement",
+		"a,,b":             "empty requirement",
+		"a, ,b":            "empty requirement",
+		"!":                "empty label key",
+		"=b":               "empty label key",
+		"!=b":              "empty label key",
+		"a b":              `invalid label key "a b"`,
+		"!a=b":             `invalid label key "!a"`,
+		"a=b=c":            `invalid label value "b=c"`,
+		"a!=b!":            `invalid label value "b!"`,
+		"a=(b)":            `invalid label value "(b)"`,
+		"a in (x=y)":       `invalid label value "x=y"`,
+		"a in (x, y!=z)":   `invalid label value "y!=z"`,
+		"a notin (x=y)":    `invalid label value "x=y"`,
+		"a in ()":          "a in has an empty value",
+		"a in (x,)":        "a in has an empty value",
+		"a in (,x)":        "a in has an empty value",
+		"a in x":           "a in needs a parenthesized list of values",
+		"a in (x":          "a in needs a parenthesized list of values",
+		"a in (x) (y)":     `invalid label value "x) (y"`,
+		"a in (x)) ,b":     "a in needs a parenthesized list of values",
+		"a in (x),b in (y": "b in needs a parenthesized list of values",
+		"a(b":              `invalid label key "a(b"`,
+	}
+
+	for selector, want := range tests {
+		_, err := ParseLabelSelector(selector)
+		if err == nil {
+			t.Errorf("%q: expected an error", selector)
+			continue
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected an error containing %q, got %v", selector, want, err)
+		}
+	}
+}
@@ -9,6 +9,7 @@ type Notebook struct {
 	Owner         string              `json:"owner" db:"owner"`
 	DisplayName   string              `json:"display_name" db:"display_name"`
 	Description   string              `json:"description" db:"description"`
+	Revision      int64               `json:"revision" db:"revision"`
 	Contents      NotebookContents    `json:"contents"`
 	Notifications []Notification      `json:"notification,omitempty"`
 }
@@ -32,6 +33,8 @@ type ContentBlock struct {
 	Data        string   `json:"data" db:"data"`
 	Order       int      `json:"order" db:"order"`
 	Types       []string `json:"types" db:"-"`
+	// Blob describes the blob Data references, if any; its bytes are not loaded
+	Blob *Blob `json:"blob,omitempty" db:"-"`
 }
 
 // Notification represents a webhook URL for notifications
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Notebook lifecycle statuses
+const (
+	NotebookStatusDraft    = "draft"
+	NotebookStatusInReview = "in_review"
+	NotebookStatusApproved = "approved"
+	NotebookStatusArchived = "archived"
+)
+
+// Notebook workflow roles
+const (
+	// NotebookRoleAuthor is held by the owner of the notebook
+	NotebookRoleAuthor = "author"
+	// NotebookRoleApprover is held by the owner of the notebook's tenant
+	NotebookRoleApprover = "approver"
+)
+
+// NotebookTransition describes an allowed status change and the roles that may perform it
+type NotebookTransition struct {
+	Action string   `json:"action"`
+	From   string   `json:"from"`
+	To     string   `json:"to"`
+	Roles  []string `json:"roles"`
+}
+
+// NotebookStatusChange records a status transition performed on a notebook
+type NotebookStatusChange struct {
+	NotebookID string    `json:"notebookId" db:"notebook_id"`
+	FromStatus string    `json:"fromStatus" db:"from_status"`
+	ToStatus   string    `json:"toStatus" db:"to_status"`
+	Actor      string    `json:"actor" db:"actor"`
+	Reason     string    `json:"reason" db:"reason"`
+	ChangedAt  time.Time `json:"changedAt" db:"changed_at"`
+}
+
+// notebookTransitions defines the notebook lifecycle:
+// draft → in_review → approved → archived, with rejection back to draft
+var notebookTransitions = []NotebookTransition{
+	{Action: "submit", From: NotebookStatusDraft, To: NotebookStatusInReview, Roles: []string{NotebookRoleAuthor, NotebookRoleApprover}},
+	{Action: "approve", From: NotebookStatusInReview, To: NotebookStatusApproved, Roles: []string{NotebookRoleApprover}},
+	{Action: "reject", From: NotebookStatusInReview, To: NotebookStatusDraft, Roles: []string{NotebookRoleApprover}},
+	{Action: "archive", From: NotebookStatusApproved, To: NotebookStatusArchived, Roles: []string{NotebookRoleAuthor, NotebookRoleApprover}},
+}
+
+// IsValidNotebookStatus reports whether status is a known notebook status
+func IsValidNotebookStatus(status string) bool {
+	switch status {
+	case NotebookStatusDraft, NotebookStatusInReview, NotebookStatusApproved, NotebookStatusArchived:
+		return true
+	}
+	return false
+}
+
+// FindNotebookTransition returns the transition for an action applied to a notebook in the given status
+func FindNotebookTransition(action, from string) (*NotebookTransition, error) {
+	known := false
+	for i := range notebookTransitions {
+		t := notebookTransitions[i]
+		if t.Action != action {
+			continue
+		}
+		known = true
+		if t.From == from {
+			return &t, nil
+		}
+	}
+
+	if !known {
+		return nil, fmt.Errorf("unknown notebook action: %s", action)
+	}
+	return nil, fmt.Errorf("cannot %s a notebook in status %q", action, from)
+}
+
+// Allows reports whether any of the given roles may perform the transition
+func (t *NotebookTransition) Allows(roles []string) bool {
+	for _, role := range roles {
+		for _, allowed := range t.Roles {
+			if role == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
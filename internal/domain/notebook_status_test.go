@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+var notebookStatuses = []string{NotebookStatusDraft, NotebookStatusInReview, NotebookStatusApproved, NotebookStatusArchived}
+
+func TestFindNotebookTransition(t *testing.T) {
+	// to maps each action and the status it is applied in to the status it
+	// leads to; an action missing for a status is refused in it
+	to := map[string]map[string]string{
+		"submit":  {NotebookStatusDraft: NotebookStatusInReview},
+		"approve": {NotebookStatusInReview: NotebookStatusApproved},
+		"reject":  {NotebookStatusInReview: NotebookStatusDraft},
+		"archive": {NotebookStatusApproved: NotebookStatusArchived},
+	}
+
+	for action, transitions := range to {
+		for _, from := range notebookStatuses {
+			transition, err := FindNotebookTransition(action, from)
+
+			want, allowed := transitions[from]
+			if !allowed {
+				if err == nil {
+					t.Errorf("%s from %s: expected to be refused, got %s", action, from, transition.To)
+				} else if !strings.Contains(err.Error(), "cannot "+action) {
+					t.Errorf("%s from %s: unexpected error %v", action, from, err)
+				}
+				continue
+			}
+
+			if err != nil {
+				t.Errorf("%s from %s: %v", action, from, err)
+				continue
+			}
+			if transition.Action != action || transition.From != from || transition.To != want {
+				t.Errorf("%s from %s: expected %s → %s, got %+v", action, from, from, want, transition)
+			}
+		}
+	}
+
+	for _, action := range []string{"", "publish", "Submit"} {
+		if _, err := FindNotebookTransition(action, NotebookStatusDraft); err == nil || !strings.Contains(err.Error(), "unknown notebook action") {
+			t.Errorf("%q: expected an unknown action error, got %v", action, err)
+		}
+	}
+}
+
+func TestNotebookTransitionsLeadToKnownStatuses(t *testing.T) {
+	for _, transition := range notebookTransitions {
+		if !IsValidNotebookStatus(transition.From) || !IsValidNotebookStatus(transition.To) {
+			t.Errorf("%s: %s → %s names an unknown status", transition.Action, transition.From, transition.To)
+		}
+	}
+
+	// Archived is final
+	for _, transition := range notebookTransitions {
+		if transition.From == NotebookStatusArchived {
+			t.Errorf("%s leaves the archived status", transition.Action)
+		}
+	}
+}
+
+func TestNotebookTransitionAllows(t *testing.T) {
+	tests := []struct {
+		action string
+		from   string
+		roles  []string
+		want   bool
+	}{
+		{"submit", NotebookStatusDraft, []string{NotebookRoleAuthor}, true},
+		{"submit", NotebookStatusDraft, []string{NotebookRoleApprover}, true},
+		{"approve", NotebookStatusInReview, []string{NotebookRoleApprover}, true},
+		{"approve", NotebookStatusInReview, []string{NotebookRoleAuthor}, false},
+		{"reject", NotebookStatusInReview, []string{NotebookRoleAuthor}, false},
+		{"reject", NotebookStatusInReview, []string{"editor", NotebookRoleApprover}, true},
+		{"archive", NotebookStatusApproved, []string{NotebookRoleAuthor}, true},
+		{"archive", NotebookStatusApproved, nil, false},
+		{"approve", NotebookStatusInReview, []string{"Approver"}, false},
+	}
+
+	for _, tc := range tests {
+		transition, err := FindNotebookTransition(tc.action, tc.from)
+		if err != nil {
+			t.Fatalf("%s from %s: %v", tc.action, tc.from, err)
+		}
+		if got := transition.Allows(tc.roles); got != tc.want {
+			t.Errorf("%s allows %v = %v, want %v", tc.action, tc.roles, got, tc.want)
+		}
+	}
+}
+
+func TestIsValidNotebookStatus(t *testing.T) {
+	tests := map[string]bool{
+		NotebookStatusDraft:    true,
+		NotebookStatusInReview: true,
+		NotebookStatusApproved: true,
+		NotebookStatusArchived: true,
+		"":                     false,
+		"in-review":            false,
+		"Draft":                false,
+		"published":            false,
+	}
+	for status, want := range tests {
+		if got := IsValidNotebookStatus(status); got != want {
+			t.Errorf("IsValidNotebookStatus(%q) = %v, want %v", status, got, want)
+		}
+	}
+}
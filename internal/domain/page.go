@@ -0,0 +1,8 @@
+package domain
+
+// Page is one page of a listing. NextCursor continues the listing after the
+// page's last item; it is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -1,11 +1,38 @@
 package domain
 
+// Product roles, from most to least privileged. Members' roles extend to the
+// notebooks and features attached to the product.
+const (
+	// ProductRoleOwner manages the product, its members and its resources
+	ProductRoleOwner = "owner"
+	// ProductRoleContributor edits the product's notebooks and features
+	ProductRoleContributor = "contributor"
+	// ProductRoleViewer reads the product and its notebooks and features
+	ProductRoleViewer = "viewer"
+)
+
+// ProductRoles lists the product roles from most to least privileged
+var ProductRoles = []string{ProductRoleOwner, ProductRoleContributor, ProductRoleViewer}
+
+// ValidProductRole reports whether role is a product role
+func ValidProductRole(role string) bool {
+	for _, r := range ProductRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 // Product represents a business product associated with a tenant
 type Product struct {
-	TenantID    string `json:"tenantId" db:"tenant_id"`
-	ID          string `json:"productId" db:"id"`
-	DisplayName string `json:"display_name" db:"display_name"`
-	Description string `json:"description" db:"description"`
+	TenantID    string        `json:"tenantId" db:"tenant_id"`
+	ID          string        `json:"productId" db:"id"`
+	DisplayName string        `json:"display_name" db:"display_name"`
+	Description string        `json:"description" db:"description"`
+	Members     []ProductUser `json:"members,omitempty"`
+	// Resources are the URIs of the notebooks and features attached to the product
+	Resources []string `json:"resources,omitempty"`
 }
 
 // ProductUser represents a user's association with a product
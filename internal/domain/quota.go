@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// Quota limits. Each is enforced per tenant.
+const (
+	QuotaNotebooks         = "notebooks"
+	QuotaFeatures          = "features"
+	QuotaContentBytes      = "content_bytes"
+	QuotaRequestsPerSecond = "requests_per_second"
+)
+
+// TenantQuota overrides the configured limits for one tenant. A nil limit falls
+// back to the configured default; 0 means unlimited.
+type TenantQuota struct {
+	TenantID             string    `json:"tenantId" db:"tenant_id" description:"ID of the tenant"`
+	MaxNotebooks         *int64    `json:"max_notebooks,omitempty" db:"max_notebooks" jsonschema:"minimum=0" description:"Maximum number of notebooks; 0 for unlimited, omitted for the configured default"`
+	MaxFeatures          *int64    `json:"max_features,omitempty" db:"max_features" jsonschema:"minimum=0" description:"Maximum number of features; 0 for unlimited, omitted for the configured default"`
+	MaxContentBytes      *int64    `json:"max_content_bytes,omitempty" db:"max_content_bytes" jsonschema:"minimum=0" description:"Maximum total size of notebook content in bytes; 0 for unlimited, omitted for the configured default"`
+	MaxRequestsPerSecond *int64    `json:"max_requests_per_second,omitempty" db:"max_requests_per_second" jsonschema:"minimum=0" description:"Maximum requests per second; 0 for unlimited, omitted for the configured default"`
+	UpdatedAt            time.Time `json:"updated_at,omitempty" db:"updated_at" jsonschema:"readonly"`
+}
+
+// QuotaUsage is the usage of one limit. Limit is 0 when the limit is unlimited.
+type QuotaUsage struct {
+	Used  int64 `json:"used"`
+	Limit int64 `json:"limit"`
+}
+
+// TenantUsage is a tenant's usage of each of its limits
+type TenantUsage struct {
+	TenantID     string     `json:"tenantId"`
+	Notebooks    QuotaUsage `json:"notebooks"`
+	Features     QuotaUsage `json:"features"`
+	ContentBytes QuotaUsage `json:"content_bytes"`
+	// RequestsPerSecond reports only the limit; requests are counted per process
+	RequestsPerSecond QuotaUsage `json:"requests_per_second"`
+}
@@ -1,15 +1,67 @@
 package domain
 
+import (
+	"encoding/json"
+	"time"
+)
+
+// RedactedValue replaces sensitive values in responses to callers who may not reveal them
+const RedactedValue = "[REDACTED]"
+
 // ToolConfig represents configuration for external tools/integrations
 type ToolConfig struct {
 	TenantID    string                 `json:"tenantId" db:"tenant_id"`
 	ID          string                 `json:"toolId" db:"id"`
+	Kind        string                 `json:"kind" db:"kind"` // how the tool is executed, e.g. "slack-webhook"
 	DisplayName string                 `json:"display_name" db:"display_name"`
 	Description string                 `json:"description" db:"description"`
 	Config      map[string]interface{} `json:"config" db:"config_json"`
+	// SensitiveKeys names the config entries, such as webhook URLs and tokens,
+	// stored encrypted and redacted in responses
+	SensitiveKeys []string `json:"sensitive_keys,omitempty" db:"sensitive_keys"`
 }
 
 // URI returns the MCP URI for this tool configuration
 func (t *ToolConfig) URI() string {
 	return "synthesis://tenant/" + t.TenantID + "/tool/" + t.ID
 }
+
+// Redacted returns a copy of the tool configuration with its sensitive entries
+// replaced by RedactedValue
+func (t *ToolConfig) Redacted() *ToolConfig {
+	redacted := *t
+	redacted.Config = make(map[string]interface{}, len(t.Config))
+	for key, value := range t.Config {
+		redacted.Config[key] = value
+	}
+	for _, key := range t.SensitiveKeys {
+		if _, ok := redacted.Config[key]; ok {
+			redacted.Config[key] = RedactedValue
+		}
+	}
+	return &redacted
+}
+
+// Tool invocation statuses
+const (
+	ToolInvocationSucceeded = "succeeded"
+	ToolInvocationFailed    = "failed"
+)
+
+// ToolInvocation records one execution of a tool
+type ToolInvocation struct {
+	ID       string `json:"id" db:"id"`
+	TenantID string `json:"tenantId" db:"tenant_id"`
+	ToolID   string `json:"toolId" db:"tool_id"`
+	Kind     string `json:"kind" db:"kind"`
+	Actor    string `json:"actor" db:"actor"`
+	// SourceURI is the resource the tool was used for, if any; it is linked to
+	// the tool with a USES_TOOL edge
+	SourceURI  string          `json:"source_uri,omitempty" db:"source_uri"`
+	Status     string          `json:"status" db:"status"`
+	StatusCode int             `json:"status_code,omitempty" db:"status_code"`
+	Error      string          `json:"error,omitempty" db:"error"`
+	DurationMS int64           `json:"duration_ms" db:"duration_ms"`
+	Result     json.RawMessage `json:"result,omitempty" db:"result_json"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
@@ -1,5 +1,14 @@
 package domain
 
+import (
+	"encoding/json"
+	"time"
+)
+
+// ConstraintJSONSchema is the type of the constraint holding a type's JSON
+// Schema, as {"schema": {...}}
+const ConstraintJSONSchema = "json-schema"
+
 // TypeDef represents content type definitions, renderers, editors, and constraints
 type TypeDef struct {
 	Name        string            `json:"name" db:"name"`
@@ -8,6 +17,17 @@ type TypeDef struct {
 	Editors     []Editor          `json:"editors" db:"-"`
 	Constraints []Constraint      `json:"constraints" db:"-"`
 	Labels      map[string]string `json:"labels" db:"labels_json"`
+	// SchemaVersion is the version of the type's current JSON Schema; 0 when it has none
+	SchemaVersion int `json:"schemaVersion,omitempty" db:"schema_version"`
+}
+
+// TypeSchema is one version of a type's JSON Schema. A new version is recorded
+// each time the schema of the type's json-schema constraint changes.
+type TypeSchema struct {
+	TypeName  string          `json:"typeName" db:"type_name"`
+	Version   int             `json:"version" db:"version"`
+	Schema    json.RawMessage `json:"schema" db:"schema_json"`
+	CreatedAt time.Time       `json:"createdAt" db:"created_at"`
 }
 
 // Renderer defines how a type is rendered
@@ -32,3 +52,17 @@ type Constraint struct {
 func (t *TypeDef) URI() string {
 	return "synthesis://type/" + t.Name
 }
+
+// JSONSchema returns the schema of the type's json-schema constraint, if it has
+// one with a schema
+func (t *TypeDef) JSONSchema() (interface{}, bool) {
+	for _, constraint := range t.Constraints {
+		if constraint.Type != ConstraintJSONSchema {
+			continue
+		}
+		if schema, ok := constraint.Config["schema"]; ok && schema != nil {
+			return schema, true
+		}
+	}
+	return nil, false
+}
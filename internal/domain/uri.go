@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// URIScheme is the scheme of every Synthesis resource URI
+const URIScheme = "synthesis://"
+
+// ResourceRef identifies a resource addressed by a synthesis:// URI
+type ResourceRef struct {
+	// TenantID is empty for global resources such as types
+	TenantID string
+	// Type is the resource type: tenant, library, notebook, feature, product, tool or type
+	Type string
+	// ID is the resource ID (the tenant ID for tenants, the type name for types)
+	ID string
+}
+
+// ParseURI parses a resource URI such as synthesis://tenant/acme,
+// synthesis://tenant/acme/notebook/n1 or synthesis://type/markdown
+func ParseURI(uri string) (*ResourceRef, error) {
+	if !strings.HasPrefix(uri, URIScheme) {
+		return nil, fmt.Errorf("invalid resource URI %q: must start with %s", uri, URIScheme)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(uri, URIScheme), "/")
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("invalid resource URI %q: empty path segment", uri)
+		}
+	}
+
+	switch {
+	case len(parts) == 2 && parts[0] == "type":
+		return &ResourceRef{Type: "type", ID: parts[1]}, nil
+	case len(parts) == 2 && parts[0] == "tenant":
+		return &ResourceRef{TenantID: parts[1], Type: "tenant", ID: parts[1]}, nil
+	case len(parts) == 4 && parts[0] == "tenant":
+		switch parts[2] {
+		case "library", "notebook", "feature", "product", "tool":
+			return &ResourceRef{TenantID: parts[1], Type: parts[2], ID: parts[3]}, nil
+		}
+		return nil, fmt.Errorf("invalid resource URI %q: unknown resource type %q", uri, parts[2])
+	}
+
+	return nil, fmt.Errorf("invalid resource URI %q", uri)
+}
+
+// URI returns the canonical URI of the referenced resource
+func (r *ResourceRef) URI() string {
+	switch r.Type {
+	case "type":
+		return URIScheme + "type/" + r.ID
+	case "tenant":
+		return URIScheme + "tenant/" + r.TenantID
+	}
+	return URIScheme + "tenant/" + r.TenantID + "/" + r.Type + "/" + r.ID
+}
+
+// maxIDLength is the size of the ID columns
+const maxIDLength = 255
+
+// ValidateID checks that id can identify a resource within a URI: 1 to 255
+// characters, none of them '/', whitespace or control characters
+func ValidateID(id string) error {
+	if id == "" {
+		return fmt.Errorf("is required")
+	}
+	if len(id) > maxIDLength {
+		return fmt.Errorf("is %d bytes long; the maximum is %d", len(id), maxIDLength)
+	}
+	for _, r := range id {
+		if r == '/' || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return fmt.Errorf("must not contain %q", r)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusDelivered = "delivered"
+	WebhookStatusDead      = "dead"
+)
+
+// WebhookDelivery represents an event queued for delivery to a notification URL
+type WebhookDelivery struct {
+	ID            string           `json:"deliveryId" db:"id"`
+	TenantID      string           `json:"tenantId" db:"tenant_id"`
+	ResourceURI   string           `json:"resource_uri" db:"resource_uri"`
+	EventType     string           `json:"event_type" db:"event_type"`
+	URL           string           `json:"url" db:"url"`
+	Payload       json.RawMessage  `json:"payload" db:"payload"`
+	Status        string           `json:"status" db:"status"`
+	Attempts      int              `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time        `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     string           `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt   *time.Time       `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt     time.Time        `json:"created_at" db:"created_at"`
+	AttemptLog    []WebhookAttempt `json:"attempt_log,omitempty"`
+}
+
+// WebhookAttempt records a single attempt to deliver a webhook
+type WebhookAttempt struct {
+	DeliveryID  string    `json:"deliveryId" db:"delivery_id"`
+	Attempt     int       `json:"attempt" db:"attempt"`
+	StatusCode  int       `json:"status_code,omitempty" db:"status_code"`
+	Error       string    `json:"error,omitempty" db:"error"`
+	DurationMS  int64     `json:"duration_ms" db:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at" db:"attempted_at"`
+}
@@ -0,0 +1,222 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/prismon/synthesis/internal/config"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// claimBatchSize is the maximum number of outbox events dispatched per claim
+const claimBatchSize = 100
+
+// Handler processes a domain event
+type Handler func(ctx context.Context, event *domain.Event) error
+
+type subscriber struct {
+	name    string
+	types   map[string]bool
+	handler Handler
+}
+
+func (s *subscriber) wants(event *domain.Event) bool {
+	return len(s.types) == 0 || s.types[event.Type]
+}
+
+// Dispatcher fans domain events out to in-process subscribers.
+//
+// Durable subscribers (Subscribe) are fed from the outbox: each event is handled
+// once across all running processes, failures are retried with backoff, and a
+// subscriber that already succeeded is skipped on retry. Handlers must be
+// idempotent since delivery is at least once.
+//
+// Live subscribers (SubscribeLive) see every event committed while the process
+// is running, in every process, on a best-effort basis. They suit fan-out to
+// connected clients such as MCP sessions.
+type Dispatcher struct {
+	repo  *postgres.EventRepository
+	dbURL string
+	cfg   config.EventsConfig
+
+	mu      sync.RWMutex
+	durable []*subscriber
+	live    []*subscriber
+}
+
+// NewDispatcher creates a new event dispatcher. dbURL is used to LISTEN for committed events.
+func NewDispatcher(repo *postgres.EventRepository, dbURL string, cfg config.EventsConfig) *Dispatcher {
+	return &Dispatcher{
+		repo:  repo,
+		dbURL: dbURL,
+		cfg:   cfg,
+	}
+}
+
+// Subscribe registers a durable subscriber for the given event types, or for all
+// events if none are given. The name identifies the subscriber in the outbox and
+// must be stable across restarts.
+func (d *Dispatcher) Subscribe(name string, handler Handler, eventTypes ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.durable = append(d.durable, newSubscriber(name, handler, eventTypes))
+}
+
+// SubscribeLive registers a live subscriber for the given event types, or for all
+// events if none are given
+func (d *Dispatcher) SubscribeLive(name string, handler Handler, eventTypes ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.live = append(d.live, newSubscriber(name, handler, eventTypes))
+}
+
+func newSubscriber(name string, handler Handler, eventTypes []string) *subscriber {
+	types := make(map[string]bool, len(eventTypes))
+	for _, t := range eventTypes {
+		types[t] = true
+	}
+	return &subscriber{name: name, types: types, handler: handler}
+}
+
+// Run dispatches events until the context is cancelled. It wakes up whenever an
+// event is committed and also polls the outbox to pick up retries.
+func (d *Dispatcher) Run(ctx context.Context) {
+	listener := pq.NewListener(d.dbURL, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("event listener: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(postgres.EventChannel); err != nil {
+		log.Printf("failed to listen for events, falling back to polling: %v", err)
+	}
+
+	ticker := time.NewTicker(time.Duration(d.cfg.PollInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DispatchPending(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("event dispatch failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-listener.Notify:
+			// A nil notification means the connection was re-established
+			if n != nil {
+				d.publishLive(ctx, n.Extra)
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchPending hands every due outbox event to the durable subscribers
+func (d *Dispatcher) DispatchPending(ctx context.Context) error {
+	lease := time.Duration(d.cfg.PollInterval) * time.Second * 6
+
+	for {
+		pending, err := d.repo.ClaimPending(ctx, claimBatchSize, lease)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range pending {
+			if err := d.dispatch(ctx, p); err != nil {
+				return err
+			}
+		}
+
+		if len(pending) < claimBatchSize {
+			return nil
+		}
+	}
+}
+
+// dispatch runs the durable subscribers that have not yet handled an event and
+// records the outcome in the outbox
+func (d *Dispatcher) dispatch(ctx context.Context, p *postgres.PendingEvent) error {
+	d.mu.RLock()
+	subscribers := d.durable
+	d.mu.RUnlock()
+
+	handled := make(map[string]bool, len(p.HandledBy))
+	for _, name := range p.HandledBy {
+		handled[name] = true
+	}
+
+	handledBy := append([]string{}, p.HandledBy...)
+	var failures []error
+
+	for _, sub := range subscribers {
+		if handled[sub.name] {
+			continue
+		}
+		if sub.wants(p.Event) {
+			if err := sub.handler(ctx, p.Event); err != nil {
+				failures = append(failures, fmt.Errorf("%s: %w", sub.name, err))
+				continue
+			}
+		}
+		handledBy = append(handledBy, sub.name)
+	}
+
+	if len(failures) == 0 {
+		return d.repo.MarkPublished(ctx, p.Event.ID, handledBy)
+	}
+
+	attempts := p.Attempts + 1
+	giveUp := attempts >= d.cfg.MaxAttempts
+	dispatchErr := fmt.Errorf("%v", failures)
+
+	if giveUp {
+		log.Printf("event %s (%s) failed after %d attempts: %v", p.Event.ID, p.Event.Type, attempts, dispatchErr)
+	}
+
+	return d.repo.MarkFailed(ctx, p.Event.ID, handledBy, dispatchErr, time.Now().Add(backoff(attempts)), giveUp)
+}
+
+// publishLive loads a committed event and hands it to the live subscribers
+func (d *Dispatcher) publishLive(ctx context.Context, eventID string) {
+	d.mu.RLock()
+	subscribers := d.live
+	d.mu.RUnlock()
+
+	if len(subscribers) == 0 {
+		return
+	}
+
+	event, err := d.repo.Get(ctx, eventID)
+	if err != nil {
+		log.Printf("failed to load event %s: %v", eventID, err)
+		return
+	}
+
+	for _, sub := range subscribers {
+		if !sub.wants(event) {
+			continue
+		}
+		if err := sub.handler(ctx, event); err != nil {
+			log.Printf("live subscriber %s failed on event %s: %v", sub.name, event.ID, err)
+		}
+	}
+}
+
+// backoff returns the delay before retrying an event after the given number of failed attempts
+func backoff(attempts int) time.Duration {
+	delay := time.Second
+	for i := 1; i < attempts && delay < 10*time.Minute; i++ {
+		delay *= 2
+	}
+	if delay > 10*time.Minute {
+		delay = 10 * time.Minute
+	}
+	return delay
+}
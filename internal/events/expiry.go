@@ -0,0 +1,40 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// expiryBatchSize is the maximum number of features expired per transaction
+const expiryBatchSize = 100
+
+// RunFeatureExpiry periodically removes features whose TTL has elapsed until the
+// context is cancelled. Each expired feature produces a feature.expired event.
+func RunFeatureExpiry(ctx context.Context, repo *postgres.FeatureRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := repo.ExpireDue(ctx, expiryBatchSize)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("feature expiry failed: %v", err)
+				}
+				break
+			}
+			if n < expiryBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package events
+
+import (
+	"context"
+
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// Indexer keeps the resource index in step with domain events
+type Indexer struct {
+	repo *postgres.ResourceIndexRepository
+}
+
+// NewIndexer creates a new resource indexer
+func NewIndexer(repo *postgres.ResourceIndexRepository) *Indexer {
+	return &Indexer{repo: repo}
+}
+
+// HandleEvent is an event subscriber that upserts or removes the event's resource in the index
+func (i *Indexer) HandleEvent(ctx context.Context, event *domain.Event) error {
+	if !event.Removes() {
+		return i.repo.Upsert(ctx, event.URI, event.ResourceType, event.ResourceID, event.TenantID)
+	}
+
+	// Everything under a tenant goes with it
+	if event.Type == domain.EventTenantDeleted {
+		return i.repo.DeleteByTenant(ctx, event.ResourceID)
+	}
+
+	return i.repo.Delete(ctx, event.URI)
+}
@@ -0,0 +1,151 @@
+// Package integration runs the tools registered in Synthesis: external
+// integrations, such as webhooks, configured per tenant by a ToolConfig whose
+// kind names how the tool runs and the schema its config must meet.
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/prismon/synthesis/internal/jsonschema"
+)
+
+// InvokeFunc runs a tool of a kind with its opened config and an input meeting
+// the kind's input schema. It returns the result when the integration answered,
+// and an error when the invocation failed; both may be set, e.g. for an HTTP
+// error response. Errors must not reveal sensitive config entries.
+type InvokeFunc func(ctx context.Context, config map[string]interface{}, input map[string]interface{}) (*Result, error)
+
+// ConnectFunc opens an initialized client session with the MCP server a tool's
+// opened config describes. Closing the client ends the session.
+type ConnectFunc func(ctx context.Context, config map[string]interface{}) (*client.Client, error)
+
+// Result is what a tool returned
+type Result struct {
+	// StatusCode is the HTTP status of the integration's response, if any
+	StatusCode int `json:"status_code,omitempty"`
+	// Output is the response: parsed JSON, or text
+	Output interface{} `json:"output,omitempty"`
+}
+
+// Kind is a kind of tool
+type Kind struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// ConfigSchema and InputSchema are the JSON Schemas (draft 2020-12) of a
+	// tool's config and of an invocation's input
+	ConfigSchema map[string]interface{} `json:"config_schema"`
+	InputSchema  map[string]interface{} `json:"input_schema"`
+	// SensitiveKeys names the config entries always stored sealed, such as URLs
+	// holding tokens
+	SensitiveKeys []string   `json:"sensitive_keys,omitempty"`
+	Invoke        InvokeFunc `json:"-"`
+	// Check, if set, checks an opened config for what its schema cannot express
+	Check func(config map[string]interface{}) error `json:"-"`
+	// Connect is set for kinds whose tools are MCP servers; their tools are
+	// federated by the MCP server
+	Connect ConnectFunc `json:"-"`
+
+	config *jsonschema.Schema
+	input  *jsonschema.Schema
+}
+
+// ValidateConfig returns an error listing the ways config breaks the kind's
+// config schema, or else the error of its Check
+func (k *Kind) ValidateConfig(config map[string]interface{}) error {
+	if err := check("config", k.config, config); err != nil {
+		return err
+	}
+	if k.Check != nil {
+		if err := k.Check(config); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	return nil
+}
+
+// ValidateInput returns an error listing the ways input breaks the kind's input schema
+func (k *Kind) ValidateInput(input map[string]interface{}) error {
+	return check("input", k.input, input)
+}
+
+func check(what string, schema *jsonschema.Schema, value map[string]interface{}) error {
+	instance := map[string]interface{}{}
+	for key, entry := range value {
+		instance[key] = entry
+	}
+
+	errs := schema.ValidateValue(instance)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return fmt.Errorf("invalid %s: %s", what, strings.Join(messages, "; "))
+}
+
+// Registry holds the tool kinds by name
+type Registry struct {
+	mu    sync.RWMutex
+	kinds map[string]*Kind
+}
+
+// NewRegistry creates a registry holding the built-in kinds:
+//
+//   - http-webhook: sends the input's payload as JSON to a URL
+//   - slack-webhook: posts a message to a Slack-style incoming webhook
+func NewRegistry() *Registry {
+	r := &Registry{kinds: make(map[string]*Kind)}
+	for _, kind := range []*Kind{httpWebhookKind(), slackWebhookKind()} {
+		if err := r.Register(kind); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// Register adds or replaces a kind. Its schemas must compile.
+func (r *Registry) Register(kind *Kind) error {
+	config, err := jsonschema.CompileValue(kind.ConfigSchema)
+	if err != nil {
+		return fmt.Errorf("invalid config schema of tool kind %s: %w", kind.Name, err)
+	}
+	input, err := jsonschema.CompileValue(kind.InputSchema)
+	if err != nil {
+		return fmt.Errorf("invalid input schema of tool kind %s: %w", kind.Name, err)
+	}
+	kind.config = config
+	kind.input = input
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.kinds[kind.Name] = kind
+	return nil
+}
+
+// Kind returns the named kind
+func (r *Registry) Kind(name string) (*Kind, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	kind, ok := r.kinds[name]
+	return kind, ok
+}
+
+// Kinds returns the registered kinds, sorted by name
+func (r *Registry) Kinds() []*Kind {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	kinds := make([]*Kind, 0, len(r.kinds))
+	for _, kind := range r.kinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].Name < kinds[j].Name })
+	return kinds
+}
@@ -0,0 +1,338 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/egress"
+)
+
+// MCPServerKindName is the name of the kind of tool that is a downstream MCP server
+const MCPServerKindName = "mcp-server"
+
+// MCPServerKind returns the mcp-server kind: a downstream MCP server reached
+// over streamable HTTP at a URL, or over stdio by running a command. Only the
+// command lines in commands may be run: each is a command and its arguments
+// separated by spaces, and a config's command and args must match one exactly,
+// since arguments alone can make a command run anything (npx, uvx and
+// interpreters take the code to run as an argument). With none, only HTTP
+// servers can be configured. A config's env may only set the variables named
+// in env, and never PATH or a variable changing what code a program loads, such
+// as LD_PRELOAD or NODE_OPTIONS. Its tools are federated by the MCP server, and
+// invoking the tool calls one of them.
+func MCPServerKind(commands, env []string) *Kind {
+	m := &mcpServers{env: make(map[string]bool, len(env))}
+	for _, line := range commands {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			m.commands = append(m.commands, fields)
+		}
+	}
+	for _, name := range env {
+		if !reservedEnv(name) {
+			m.env[name] = true
+		}
+	}
+
+	return &Kind{
+		Name:        MCPServerKindName,
+		Description: "A downstream MCP server whose tools are re-exposed as <toolId>.<tool name>; invoking it calls one of its tools",
+		ConfigSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"url": map[string]interface{}{
+					"type":        "string",
+					"pattern":     "^https?://[^/]+",
+					"description": "Streamable HTTP endpoint of the server",
+				},
+				"headers": map[string]interface{}{
+					"type":                 "object",
+					"description":          "Headers sent with every HTTP request, such as Authorization",
+					"additionalProperties": map[string]interface{}{"type": "string"},
+				},
+				"command": map[string]interface{}{
+					"type":        "string",
+					"minLength":   1.0,
+					"description": "Command running the server over stdio; with args, it must be an allowed command line",
+				},
+				"args": map[string]interface{}{
+					"type":        "array",
+					"description": "Arguments of the command, as the allowed command line gives them",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"env": map[string]interface{}{
+					"type":                 "object",
+					"description":          "Environment of the command, besides PATH",
+					"additionalProperties": map[string]interface{}{"type": "string"},
+				},
+				"timeout_seconds": map[string]interface{}{
+					"type":        "integer",
+					"minimum":     1.0,
+					"maximum":     60.0,
+					"description": "Seconds to wait for the server to start or answer a call (default: 10)",
+				},
+			},
+			"oneOf": []interface{}{
+				map[string]interface{}{"required": []interface{}{"url"}},
+				map[string]interface{}{"required": []interface{}{"command"}},
+			},
+			"additionalProperties": false,
+		},
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"tool": map[string]interface{}{
+					"type":        "string",
+					"minLength":   1.0,
+					"description": "Name of the server's tool to call",
+				},
+				"arguments": map[string]interface{}{
+					"type":        "object",
+					"description": "Arguments of the call (default: {})",
+				},
+			},
+			"required":             []interface{}{"tool"},
+			"additionalProperties": false,
+		},
+		SensitiveKeys: []string{"url", "headers", "env"},
+		Invoke:        m.invoke,
+		Check:         m.check,
+		Connect:       m.connect,
+	}
+}
+
+// mcpServers connects to downstream MCP servers
+type mcpServers struct {
+	// commands are the allowed command lines, each a command and its arguments
+	commands [][]string
+	// env names the variables a config's env may set
+	env map[string]bool
+}
+
+// loaderEnv names variables that change what code a program loads or runs;
+// a config's env never sets them, nor any variable with a prefix in loaderEnvPrefixes
+var loaderEnv = map[string]bool{
+	"PATH":              true,
+	"NODE_OPTIONS":      true,
+	"NODE_PATH":         true,
+	"PYTHONPATH":        true,
+	"PYTHONHOME":        true,
+	"PYTHONSTARTUP":     true,
+	"PYTHONUSERBASE":    true,
+	"PERL5LIB":          true,
+	"PERL5OPT":          true,
+	"PERLLIB":           true,
+	"RUBYLIB":           true,
+	"RUBYOPT":           true,
+	"BASH_ENV":          true,
+	"ENV":               true,
+	"JAVA_TOOL_OPTIONS": true,
+	"_JAVA_OPTIONS":     true,
+	"JDK_JAVA_OPTIONS":  true,
+	"CLASSPATH":         true,
+	"GCONV_PATH":        true,
+	"HOME":              true,
+}
+
+var loaderEnvPrefixes = []string{"LD_", "DYLD_", "NPM_CONFIG_", "UV_", "PIP_"}
+
+// reservedEnv reports whether a config's env may never set name
+func reservedEnv(name string) bool {
+	upper := strings.ToUpper(name)
+	if loaderEnv[upper] {
+		return true
+	}
+	for _, prefix := range loaderEnvPrefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mcpServers) check(config map[string]interface{}) error {
+	command, _ := config["command"].(string)
+	if command == "" {
+		target, _ := config["url"].(string)
+		if err := egress.CheckURL(target); err != nil {
+			return fmt.Errorf("url: %w", err)
+		}
+		return nil
+	}
+
+	if !m.allowed(command, commandArgs(config)) {
+		if len(m.commands) == 0 {
+			return fmt.Errorf("command %q is not allowed: no MCP server commands are allowed", command)
+		}
+		allowed := make([]string, 0, len(m.commands))
+		for _, line := range m.commands {
+			allowed = append(allowed, strings.Join(line, " "))
+		}
+		sort.Strings(allowed)
+		return fmt.Errorf("command %q with these args is not allowed; allowed command lines are %q", command, allowed)
+	}
+
+	for name := range stringMap(config["env"]) {
+		if reservedEnv(name) {
+			return fmt.Errorf("env may not set %s", name)
+		}
+		if !m.env[name] {
+			return fmt.Errorf("env may not set %s; allowed variables are %v", name, m.allowedEnv())
+		}
+	}
+	return nil
+}
+
+// allowed reports whether command run with args is an allowed command line
+func (m *mcpServers) allowed(command string, args []string) bool {
+	for _, line := range m.commands {
+		if line[0] != command || len(line)-1 != len(args) {
+			continue
+		}
+		match := true
+		for i, arg := range args {
+			if line[i+1] != arg {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mcpServers) allowedEnv() []string {
+	names := make([]string, 0, len(m.env))
+	for name := range m.env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// commandArgs returns the args of a config
+func commandArgs(config map[string]interface{}) []string {
+	var args []string
+	if values, ok := config["args"].([]interface{}); ok {
+		for _, value := range values {
+			if arg, ok := value.(string); ok {
+				args = append(args, arg)
+			}
+		}
+	}
+	return args
+}
+
+// connect starts a session with the server a config describes that hears the
+// server's notifications
+func (m *mcpServers) connect(ctx context.Context, config map[string]interface{}) (*client.Client, error) {
+	return m.open(ctx, config, true)
+}
+
+// open starts a session with the server a config describes; over HTTP, it
+// only hears notifications if listen is set. Errors name neither the URL nor
+// the headers or environment, which may hold secrets.
+func (m *mcpServers) open(ctx context.Context, config map[string]interface{}, listen bool) (*client.Client, error) {
+	if err := m.check(config); err != nil {
+		return nil, err
+	}
+
+	var c *client.Client
+	if command, _ := config["command"].(string); command != "" {
+		var err error
+		c, err = client.NewStdioMCPClientWithOptions(command, m.commandEnv(config), commandArgs(config), transport.WithCommandFunc(isolatedCommand))
+		if err != nil {
+			return nil, fmt.Errorf("failed to start MCP server %s: %v", filepath.Base(command), err)
+		}
+	} else {
+		target, _ := config["url"].(string)
+
+		// The URL is tenant-supplied: connect only to public addresses
+		options := []transport.StreamableHTTPCOption{
+			transport.WithHTTPBasicClient(egress.NewClient(0)),
+			transport.WithHTTPHeaders(stringMap(config["headers"])),
+		}
+		if listen {
+			options = append(options, transport.WithContinuousListening())
+		}
+
+		var err error
+		c, err = client.NewStreamableHttpClient(target, options...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MCP server URL")
+		}
+		if err := c.Start(ctx); err != nil {
+			return nil, fmt.Errorf("failed to connect to MCP server: %v", withoutURL(err))
+		}
+	}
+
+	initCtx, cancel := context.WithTimeout(ctx, configTimeout(config))
+	defer cancel()
+
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	request.Params.ClientInfo = mcp.Implementation{Name: "synthesis", Version: "1.0.0"}
+	if _, err := c.Initialize(initCtx, request); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to initialize MCP session: %v", withoutURL(err))
+	}
+
+	return c, nil
+}
+
+// commandEnv returns the environment of a server command: the allowed
+// variables of the configured env, and PATH, so the command can be found.
+// Nothing else of the process's own environment, such as database credentials,
+// is passed on, and the configured env cannot override PATH.
+func (m *mcpServers) commandEnv(config map[string]interface{}) []string {
+	var env []string
+	for name, value := range stringMap(config["env"]) {
+		if m.env[name] && !reservedEnv(name) {
+			env = append(env, name+"="+value)
+		}
+	}
+	sort.Strings(env)
+	return append(env, "PATH="+os.Getenv("PATH"))
+}
+
+// isolatedCommand creates a server command with exactly the environment given
+func isolatedCommand(ctx context.Context, command string, env []string, args []string) (*exec.Cmd, error) {
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Env = env
+	return cmd, nil
+}
+
+func (m *mcpServers) invoke(ctx context.Context, config map[string]interface{}, input map[string]interface{}) (*Result, error) {
+	c, err := m.open(ctx, config, false)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, configTimeout(config))
+	defer cancel()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name, _ = input["tool"].(string)
+	request.Params.Arguments = input["arguments"]
+
+	result, err := c.CallTool(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("MCP tool call failed: %v", withoutURL(err))
+	}
+
+	output := &Result{Output: map[string]interface{}{"tool": request.Params.Name, "output": result}}
+	if result.IsError {
+		return output, fmt.Errorf("MCP tool %s returned an error", request.Params.Name)
+	}
+	return output, nil
+}
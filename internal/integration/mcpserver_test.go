@@ -0,0 +1,87 @@
+package integration
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prismon/synthesis/internal/egress"
+)
+
+// testMCPServerKind allows two command lines and, of the variables named, only
+// GITHUB_TOKEN: the others are reserved
+func testMCPServerKind() *Kind {
+	return MCPServerKind(
+		[]string{"npx -y @modelcontextprotocol/server-github", "/usr/local/bin/notes-mcp"},
+		[]string{"GITHUB_TOKEN", "LD_PRELOAD", "PATH"},
+	)
+}
+
+func TestMCPServerCommandLines(t *testing.T) {
+	kind := testMCPServerKind()
+
+	allowed := []map[string]interface{}{
+		{"command": "npx", "args": []interface{}{"-y", "@modelcontextprotocol/server-github"}},
+		{"command": "/usr/local/bin/notes-mcp"},
+		{"command": "/usr/local/bin/notes-mcp", "args": []interface{}{}},
+	}
+	for _, config := range allowed {
+		if err := kind.Check(config); err != nil {
+			t.Errorf("expected %v to be allowed, got %v", config, err)
+		}
+	}
+
+	refused := []map[string]interface{}{
+		{"command": "npx", "args": []interface{}{"-y", "evil-package"}},
+		{"command": "npx", "args": []interface{}{"-y", "@modelcontextprotocol/server-github", "--extra"}},
+		{"command": "npx"},
+		{"command": "/usr/local/bin/notes-mcp", "args": []interface{}{"--exec", "sh"}},
+		{"command": "python", "args": []interface{}{"-c", "import os"}},
+	}
+	for _, config := range refused {
+		if err := kind.Check(config); err == nil {
+			t.Errorf("expected %v to be refused", config)
+		}
+	}
+}
+
+func TestMCPServerEnv(t *testing.T) {
+	kind := testMCPServerKind()
+
+	config := map[string]interface{}{"command": "/usr/local/bin/notes-mcp", "env": map[string]interface{}{"GITHUB_TOKEN": "t"}}
+	if err := kind.Check(config); err != nil {
+		t.Errorf("expected env GITHUB_TOKEN to be allowed, got %v", err)
+	}
+	for _, name := range []string{"PATH", "LD_PRELOAD", "ld_library_path", "NODE_OPTIONS", "PYTHONPATH", "DYLD_INSERT_LIBRARIES", "BASH_ENV", "OTHER"} {
+		config := map[string]interface{}{"command": "/usr/local/bin/notes-mcp", "env": map[string]interface{}{name: "x"}}
+		if err := kind.Check(config); err == nil {
+			t.Errorf("expected env %s to be refused", name)
+		}
+	}
+
+	// A stored config that predates the check still cannot override PATH or
+	// set a variable that is not allowed, even one listed by mistake
+	m := &mcpServers{env: map[string]bool{"GITHUB_TOKEN": true, "PATH": true}}
+	env := m.commandEnv(map[string]interface{}{"env": map[string]interface{}{
+		"GITHUB_TOKEN": "t",
+		"PATH":         "/tmp/evil",
+		"LD_PRELOAD":   "/tmp/evil.so",
+		"OTHER":        "x",
+	}})
+	if len(env) != 2 || env[0] != "GITHUB_TOKEN=t" || !strings.HasPrefix(env[1], "PATH=") || env[1] == "PATH=/tmp/evil" {
+		t.Fatalf("expected GITHUB_TOKEN and the server's PATH, got %v", env)
+	}
+}
+
+func TestMCPServerRefusesInternalURLs(t *testing.T) {
+	kind := testMCPServerKind()
+
+	if err := kind.Check(map[string]interface{}{"url": "https://mcp.example.com/mcp"}); err != nil {
+		t.Errorf("expected a public URL to be allowed, got %v", err)
+	}
+	for _, target := range []string{"http://localhost:8081/mcp", "http://169.254.169.254/", "http://192.168.0.10/mcp"} {
+		if err := kind.Check(map[string]interface{}{"url": target}); !errors.Is(err, egress.ErrForbiddenAddress) {
+			t.Errorf("expected %s to be refused, got %v", target, err)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+	"github.com/prismon/synthesis/internal/secrets"
+)
+
+// Service checks, seals and runs tool configurations
+type Service struct {
+	tools  *postgres.ToolRepository
+	sealer *secrets.Sealer
+	kinds  *Registry
+}
+
+// NewService creates a service running the tools in tools with the kinds in
+// kinds. Sensitive config entries are sealed and opened with sealer.
+func NewService(tools *postgres.ToolRepository, sealer *secrets.Sealer, kinds *Registry) *Service {
+	return &Service{tools: tools, sealer: sealer, kinds: kinds}
+}
+
+// Kinds returns the service's tool kind registry
+func (s *Service) Kinds() *Registry {
+	return s.kinds
+}
+
+// Prepare checks a tool configuration about to be stored against its kind and
+// seals its sensitive entries, which always include the kind's. existing is
+// the stored configuration an update replaces, or nil for a new one; its
+// values are kept for sensitive entries given as domain.RedactedValue.
+func (s *Service) Prepare(ctx context.Context, tool *domain.ToolConfig, existing *domain.ToolConfig) error {
+	kind, ok := s.kinds.Kind(tool.Kind)
+	if !ok {
+		return fmt.Errorf("unknown tool kind %q; known kinds are %s", tool.Kind, s.kindNames())
+	}
+
+	if tool.Config == nil {
+		tool.Config = map[string]interface{}{}
+	}
+	tool.SensitiveKeys = union(tool.SensitiveKeys, kind.SensitiveKeys)
+
+	// Only stored values are sealed; input that looks sealed is not trusted
+	if err := secrets.CheckUnsealed(tool.Config, tool.SensitiveKeys); err != nil {
+		return err
+	}
+
+	if existing != nil {
+		for _, key := range tool.SensitiveKeys {
+			if tool.Config[key] != domain.RedactedValue {
+				continue
+			}
+			if value, ok := existing.Config[key]; ok {
+				tool.Config[key] = value
+			} else {
+				delete(tool.Config, key)
+			}
+		}
+	}
+
+	// Kept values are opened so the whole config is checked, then sealed anew
+	if err := s.sealer.OpenConfig(ctx, tool.URI(), tool.Config, tool.SensitiveKeys); err != nil {
+		return fmt.Errorf("failed to decrypt tool config: %w", err)
+	}
+	if err := kind.ValidateConfig(tool.Config); err != nil {
+		return err
+	}
+	if err := s.sealer.SealConfig(ctx, tool.URI(), tool.Config, tool.SensitiveKeys); err != nil {
+		return fmt.Errorf("failed to encrypt tool config: %w", err)
+	}
+
+	return nil
+}
+
+// Invoke runs a tool with input on behalf of actor and logs the invocation.
+// sourceURI, if set, names the library, notebook, feature or product of the
+// tool's tenant the tool is used for. An invocation the integration failed is
+// returned with status failed rather than as an error.
+func (s *Service) Invoke(ctx context.Context, toolID string, input map[string]interface{}, actor, sourceURI string) (*domain.ToolInvocation, error) {
+	tool, err := s.tools.Get(ctx, toolID)
+	if err != nil {
+		return nil, err
+	}
+
+	kind, ok := s.kinds.Kind(tool.Kind)
+	if !ok {
+		return nil, fmt.Errorf("tool %s has unknown kind %q", tool.ID, tool.Kind)
+	}
+
+	if input == nil {
+		input = map[string]interface{}{}
+	}
+	if err := kind.ValidateInput(input); err != nil {
+		return nil, err
+	}
+
+	if sourceURI != "" {
+		source, err := domain.ParseURI(sourceURI)
+		if err != nil {
+			return nil, err
+		}
+		if source.TenantID != tool.TenantID {
+			return nil, fmt.Errorf("%s is not in the tool's tenant %s", sourceURI, tool.TenantID)
+		}
+	}
+
+	if err := s.sealer.OpenConfig(ctx, tool.URI(), tool.Config, tool.SensitiveKeys); err != nil {
+		return nil, fmt.Errorf("failed to decrypt tool config: %w", err)
+	}
+
+	invocation := &domain.ToolInvocation{
+		TenantID:  tool.TenantID,
+		ToolID:    tool.ID,
+		Kind:      tool.Kind,
+		Actor:     actor,
+		SourceURI: sourceURI,
+		Status:    domain.ToolInvocationSucceeded,
+	}
+
+	started := time.Now()
+	result, err := kind.Invoke(ctx, tool.Config, input)
+	invocation.DurationMS = time.Since(started).Milliseconds()
+
+	if err != nil {
+		invocation.Status = domain.ToolInvocationFailed
+		invocation.Error = err.Error()
+	}
+	if result != nil {
+		invocation.StatusCode = result.StatusCode
+		if result.Output != nil {
+			if invocation.Result, err = json.Marshal(result.Output); err != nil {
+				return nil, fmt.Errorf("failed to marshal tool result: %w", err)
+			}
+		}
+	}
+
+	if err := s.tools.RecordInvocation(ctx, invocation); err != nil {
+		return nil, err
+	}
+
+	return invocation, nil
+}
+
+// Connect starts a session with the MCP server a tool of a kind with Connect
+// describes, using its opened config
+func (s *Service) Connect(ctx context.Context, tool *domain.ToolConfig) (*client.Client, error) {
+	kind, ok := s.kinds.Kind(tool.Kind)
+	if !ok || kind.Connect == nil {
+		return nil, fmt.Errorf("tool %s of kind %q is not an MCP server", tool.ID, tool.Kind)
+	}
+
+	config := make(map[string]interface{}, len(tool.Config))
+	for key, value := range tool.Config {
+		config[key] = value
+	}
+	if err := s.sealer.OpenConfig(ctx, tool.URI(), config, tool.SensitiveKeys); err != nil {
+		return nil, fmt.Errorf("failed to decrypt tool config: %w", err)
+	}
+
+	return kind.Connect(ctx, config)
+}
+
+// Call calls a tool of a connected MCP server, a session Connect started for
+// tool, on behalf of actor and logs the invocation. The server's result is
+// returned as it is, including tool errors; an error means the call failed.
+func (s *Service) Call(ctx context.Context, c *client.Client, tool *domain.ToolConfig, name string, arguments interface{}, actor string) (*mcp.CallToolResult, error) {
+	invocation := &domain.ToolInvocation{
+		TenantID: tool.TenantID,
+		ToolID:   tool.ID,
+		Kind:     tool.Kind,
+		Actor:    actor,
+		Status:   domain.ToolInvocationSucceeded,
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, configTimeout(tool.Config))
+	defer cancel()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = arguments
+
+	started := time.Now()
+	result, callErr := c.CallTool(callCtx, request)
+	invocation.DurationMS = time.Since(started).Milliseconds()
+
+	switch {
+	case callErr != nil:
+		callErr = fmt.Errorf("MCP tool call failed: %v", withoutURL(callErr))
+		invocation.Status = domain.ToolInvocationFailed
+		invocation.Error = callErr.Error()
+	case result.IsError:
+		invocation.Status = domain.ToolInvocationFailed
+		invocation.Error = fmt.Sprintf("MCP tool %s returned an error", name)
+	}
+	if result != nil {
+		var err error
+		if invocation.Result, err = json.Marshal(map[string]interface{}{"tool": name, "output": result}); err != nil {
+			return nil, fmt.Errorf("failed to marshal tool result: %w", err)
+		}
+	}
+
+	if err := s.tools.RecordInvocation(ctx, invocation); err != nil {
+		return nil, err
+	}
+
+	return result, callErr
+}
+
+func (s *Service) kindNames() string {
+	var names string
+	for i, kind := range s.kinds.Kinds() {
+		if i > 0 {
+			names += ", "
+		}
+		names += kind.Name
+	}
+	return names
+}
+
+// union returns the strings of a and b, each once, sorted
+func union(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var all []string
+	for _, key := range append(append([]string{}, a...), b...) {
+		if !seen[key] {
+			seen[key] = true
+			all = append(all, key)
+		}
+	}
+	sort.Strings(all)
+	return all
+}
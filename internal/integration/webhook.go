@@ -0,0 +1,268 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prismon/synthesis/internal/egress"
+)
+
+const (
+	// defaultTimeout bounds a webhook request whose config sets no timeout
+	defaultTimeout = 10 * time.Second
+	// maxResponseBytes is the most of a response kept as the result
+	maxResponseBytes = 64 * 1024
+)
+
+// httpClient sends webhook requests; each request is bounded by its own
+// timeout. Webhook URLs are tenant-supplied, so it connects only to public
+// addresses and does not follow redirects.
+var httpClient = egress.NewClient(0)
+
+// webhookURLSchema is the schema of the URL of a webhook
+var webhookURLSchema = map[string]interface{}{
+	"type":    "string",
+	"pattern": "^https?://[^/]+",
+}
+
+// timeoutSchema is the schema of a webhook's timeout_seconds
+var timeoutSchema = map[string]interface{}{
+	"type":        "integer",
+	"minimum":     1.0,
+	"maximum":     60.0,
+	"description": "Seconds to wait for a response (default: 10)",
+}
+
+func httpWebhookKind() *Kind {
+	return &Kind{
+		Name:        "http-webhook",
+		Description: "Sends the input's payload as JSON to a URL",
+		ConfigSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"url": webhookURLSchema,
+				"method": map[string]interface{}{
+					"enum":        []interface{}{"POST", "PUT", "PATCH"},
+					"description": "HTTP method (default: POST)",
+				},
+				"headers": map[string]interface{}{
+					"type":                 "object",
+					"description":          "Headers sent with every request, such as Authorization",
+					"additionalProperties": map[string]interface{}{"type": "string"},
+				},
+				"timeout_seconds": timeoutSchema,
+			},
+			"required":             []interface{}{"url"},
+			"additionalProperties": false,
+		},
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"payload": map[string]interface{}{
+					"description": "JSON value sent as the request body (default: {})",
+				},
+			},
+			"additionalProperties": false,
+		},
+		SensitiveKeys: []string{"url", "headers"},
+		Invoke:        invokeHTTPWebhook,
+		Check:         checkWebhookURL("url"),
+	}
+}
+
+func slackWebhookKind() *Kind {
+	return &Kind{
+		Name:        "slack-webhook",
+		Description: "Posts a message to a Slack-style incoming webhook",
+		ConfigSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"webhook_url":     webhookURLSchema,
+				"channel":         map[string]interface{}{"type": "string"},
+				"username":        map[string]interface{}{"type": "string"},
+				"icon_emoji":      map[string]interface{}{"type": "string"},
+				"timeout_seconds": timeoutSchema,
+			},
+			"required":             []interface{}{"webhook_url"},
+			"additionalProperties": false,
+		},
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"text": map[string]interface{}{
+					"type":        "string",
+					"minLength":   1.0,
+					"description": "Message text",
+				},
+				"blocks": map[string]interface{}{
+					"type":        "array",
+					"description": "Block Kit blocks laying out the message (optional)",
+				},
+				"thread_ts": map[string]interface{}{
+					"type":        "string",
+					"description": "Timestamp of the message to reply to in a thread (optional)",
+				},
+			},
+			"required":             []interface{}{"text"},
+			"additionalProperties": false,
+		},
+		SensitiveKeys: []string{"webhook_url"},
+		Invoke:        invokeSlackWebhook,
+		Check:         checkWebhookURL("webhook_url"),
+	}
+}
+
+// checkWebhookURL returns a config check refusing a URL at key that the egress
+// guard does not allow, such as one of a loopback or private address
+func checkWebhookURL(key string) func(config map[string]interface{}) error {
+	return func(config map[string]interface{}) error {
+		target, _ := config[key].(string)
+		if err := egress.CheckURL(target); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		return nil
+	}
+}
+
+func invokeHTTPWebhook(ctx context.Context, config map[string]interface{}, input map[string]interface{}) (*Result, error) {
+	payload, ok := input["payload"]
+	if !ok {
+		payload = map[string]interface{}{}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	method, _ := config["method"].(string)
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	target, _ := config["url"].(string)
+	return send(ctx, method, target, stringMap(config["headers"]), body, config)
+}
+
+func invokeSlackWebhook(ctx context.Context, config map[string]interface{}, input map[string]interface{}) (*Result, error) {
+	message := map[string]interface{}{}
+	for _, key := range []string{"channel", "username", "icon_emoji"} {
+		if value, _ := config[key].(string); value != "" {
+			message[key] = value
+		}
+	}
+	for _, key := range []string{"text", "blocks", "thread_ts"} {
+		if value, ok := input[key]; ok {
+			message[key] = value
+		}
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	target, _ := config["webhook_url"].(string)
+	return send(ctx, http.MethodPost, target, nil, body, config)
+}
+
+// send sends a JSON body to a webhook and reads its response. Errors name
+// neither the URL nor the headers, which may hold secrets. Only the body of a
+// successful response is returned: the body of an error or redirect response
+// could disclose what the request reached.
+func send(ctx context.Context, method, target string, headers map[string]string, body []byte, config map[string]interface{}) (*Result, error) {
+	// Configs stored before the URL was checked are checked again here
+	if err := egress.CheckURL(target); err != nil {
+		return nil, fmt.Errorf("refusing webhook request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, configTimeout(config))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Synthesis-Tools/1.0")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if errors.Is(err, egress.ErrForbiddenAddress) {
+		return nil, fmt.Errorf("refusing webhook request: %w", egress.ErrForbiddenAddress)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("webhook request failed: %v", withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		return &Result{StatusCode: resp.StatusCode}, fmt.Errorf("webhook returned status %d: redirects are not followed", resp.StatusCode)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return &Result{StatusCode: resp.StatusCode}, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook response: %v", err)
+	}
+
+	return &Result{StatusCode: resp.StatusCode, Output: responseOutput(resp.Header.Get("Content-Type"), data)}, nil
+}
+
+// configTimeout returns the timeout_seconds of a config, or the default timeout
+func configTimeout(config map[string]interface{}) time.Duration {
+	if seconds, ok := config["timeout_seconds"].(float64); ok && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultTimeout
+}
+
+// withoutURL returns the cause of a *url.Error, whose message names the URL
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// stringMap returns the string entries of a config object such as headers
+func stringMap(value interface{}) map[string]string {
+	entries := map[string]string{}
+	if object, ok := value.(map[string]interface{}); ok {
+		for name, entry := range object {
+			if entry, ok := entry.(string); ok {
+				entries[name] = entry
+			}
+		}
+	}
+	return entries
+}
+
+// responseOutput returns a response body as parsed JSON when it is JSON, else as text
+func responseOutput(contentType string, data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		var output interface{}
+		if err := json.Unmarshal(data, &output); err == nil {
+			return output
+		}
+	}
+	return string(bytes.ToValidUTF8(data, []byte("�")))
+}
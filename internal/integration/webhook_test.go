@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prismon/synthesis/internal/egress"
+)
+
+func TestWebhookKindsRefuseInternalURLs(t *testing.T) {
+	kinds := map[string]*Kind{"url": httpWebhookKind(), "webhook_url": slackWebhookKind()}
+
+	for key, kind := range kinds {
+		if err := kind.Check(map[string]interface{}{key: "https://hooks.example.com/T000/B000"}); err != nil {
+			t.Errorf("%s: expected a public URL to be allowed, got %v", kind.Name, err)
+		}
+		for _, target := range []string{"http://localhost:8181/v1/data", "http://169.254.169.254/latest/meta-data/", "http://10.0.0.5/", "http://[::1]/"} {
+			err := kind.Check(map[string]interface{}{key: target})
+			if !errors.Is(err, egress.ErrForbiddenAddress) {
+				t.Errorf("%s: expected %s to be refused, got %v", kind.Name, target, err)
+			}
+		}
+	}
+}
+
+func TestSendRefusesInternalAddresses(t *testing.T) {
+	reached := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.Write([]byte("internal secret"))
+	}))
+	defer server.Close()
+
+	// The test server listens on loopback, by address and by a name resolving to it
+	for _, target := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+		result, err := send(context.Background(), http.MethodPost, target, nil, []byte("{}"), nil)
+		if !errors.Is(err, egress.ErrForbiddenAddress) {
+			t.Errorf("expected %s to be refused, got %v", target, err)
+		}
+		if result != nil {
+			t.Errorf("expected no result for %s, got %+v", target, result)
+		}
+	}
+	if reached {
+		t.Error("expected the internal server never to be reached")
+	}
+}
+
+func TestSendWithholdsUnsuccessfulResponses(t *testing.T) {
+	client := egress.NewClient(0)
+	saved := httpClient
+	httpClient = client
+	defer func() { httpClient = saved }()
+
+	responses := map[string]http.HandlerFunc{
+		"error": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "internal secret", http.StatusForbidden)
+		},
+		"redirect": func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+		},
+	}
+
+	for name, handler := range responses {
+		server := httptest.NewServer(handler)
+		// send checks the URL itself, so the request names a public host the
+		// test transport connects to the loopback server instead; only the
+		// handling of the response is under test
+		client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+			},
+		}
+
+		result, err := send(context.Background(), http.MethodPost, "http://hooks.example.com/notify", nil, []byte("{}"), nil)
+		server.Close()
+
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if result == nil || result.Output != nil {
+			t.Errorf("%s: expected the status without the body, got %+v", name, result)
+		}
+		if err != nil && strings.Contains(err.Error(), "internal secret") {
+			t.Errorf("%s: expected the error not to carry the body, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Reflector generates the JSON Schemas of Go types from their encoding/json
+// shape. Field schemas are refined with two struct tags: description, whose
+// value is the field's description, and jsonschema, a comma-separated list of
+//
+//	required       the field must be present (input schemas)
+//	enum=a|b|c     the field is one of the values
+//	minimum=n      the field is at least n
+//	maximum=n      the field is at most n
+//	default=v      the field is v when omitted
+//	format=name    the field has the string format, e.g. uri
+//	readonly       the field is set by the server, and ignored in requests
+//
+// e.g.
+//
+//	TenantID string `json:"tenantId" jsonschema:"required" description:"ID of the tenant"`
+type Reflector struct {
+	// Output describes values as encoding/json writes them: fields without
+	// omitempty are required, and nil maps, slices and pointers may be null.
+	// Otherwise values are described as a decoder accepts them, and only
+	// fields tagged required are required.
+	Output bool
+	// DisallowUnknownFields closes objects to properties their struct does
+	// not have, as json.Decoder.DisallowUnknownFields does
+	DisallowUnknownFields bool
+	// RefPrefix, when set, makes named struct types references to RefPrefix
+	// followed by the type's name, e.g. "#/components/schemas/", whose schemas
+	// are collected in Definitions. Otherwise named struct types are inlined,
+	// and only those that recur are referenced, from the $defs of the schema
+	// Reflect returns.
+	RefPrefix string
+	// Suffix is appended to the names of the types in Definitions, so that the
+	// input and output schemas of a type can be told apart
+	Suffix string
+	// Definitions holds the schemas of the types referenced by name
+	Definitions map[string]interface{}
+
+	names    map[reflect.Type]string
+	visiting map[reflect.Type]bool
+}
+
+// Reflect returns the input schema of the type of v
+func Reflect(v interface{}) map[string]interface{} {
+	return (&Reflector{}).Reflect(v)
+}
+
+// ReflectOutput returns the output schema of the type of v
+func ReflectOutput(v interface{}) map[string]interface{} {
+	return (&Reflector{Output: true}).Reflect(v)
+}
+
+// Reflect returns the schema of the type of v. Without a RefPrefix, types that
+// recur are defined under the $defs of the schema.
+func (r *Reflector) Reflect(v interface{}) map[string]interface{} {
+	return r.ReflectType(reflect.TypeOf(v))
+}
+
+// ReflectType returns the schema of t, as Reflect does
+func (r *Reflector) ReflectType(t reflect.Type) map[string]interface{} {
+	if r.Definitions == nil {
+		r.Definitions = make(map[string]interface{})
+	}
+	if r.names == nil {
+		r.names = make(map[reflect.Type]string)
+		r.visiting = make(map[reflect.Type]bool)
+	}
+
+	schema := r.reflect(t)
+	if r.RefPrefix == "" && len(r.Definitions) > 0 {
+		schema["$defs"] = r.Definitions
+		r.Definitions = nil
+	}
+	return schema
+}
+
+func (r *Reflector) reflect(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": r.reflect(t.Elem())}
+	case reflect.Map:
+		schema := map[string]interface{}{"type": "object"}
+		if t.Elem().Kind() != reflect.Interface {
+			schema["additionalProperties"] = r.reflect(t.Elem())
+		}
+		return schema
+	case reflect.Struct:
+		return r.reflectStruct(t)
+	}
+
+	// Interfaces, and anything encoding/json cannot encode, may be any value
+	return map[string]interface{}{}
+}
+
+// reflectStruct returns the schema of a struct type, or a reference to it
+func (r *Reflector) reflectStruct(t reflect.Type) map[string]interface{} {
+	if t.Name() == "" {
+		return r.structSchema(t)
+	}
+
+	name, named := r.names[t]
+	if !named {
+		name = r.defineName(t)
+	}
+	ref := map[string]interface{}{"$ref": r.refPrefix() + name}
+
+	switch {
+	case r.RefPrefix != "":
+		if !named {
+			r.Definitions[name] = r.structSchema(t)
+		}
+		return ref
+	case r.visiting[t]:
+		// A type within itself is referenced, and defined once it is complete
+		r.Definitions[name] = nil
+		return ref
+	}
+
+	r.visiting[t] = true
+	schema := r.structSchema(t)
+	delete(r.visiting, t)
+	if definition, recurs := r.Definitions[name]; recurs && definition == nil {
+		r.Definitions[name] = schema
+	}
+	return schema
+}
+
+func (r *Reflector) refPrefix() string {
+	if r.RefPrefix != "" {
+		return r.RefPrefix
+	}
+	return "#/$defs/"
+}
+
+// defineName names a struct type for its definition, capitalized and qualified
+// with its package when another type has its name. Generic types are named
+// after their type arguments too, e.g. Page[*domain.Tenant] is PageTenant.
+func (r *Reflector) defineName(t reflect.Type) string {
+	name := capitalize(t.Name())
+	if open := strings.Index(name, "["); open >= 0 {
+		base := name[:open]
+		for _, arg := range strings.Split(strings.TrimSuffix(name[open+1:], "]"), ",") {
+			arg = arg[strings.LastIndexAny(arg, "./")+1:]
+			base += strings.Map(func(c rune) rune {
+				if unicode.IsLetter(c) || unicode.IsDigit(c) {
+					return c
+				}
+				return -1
+			}, arg)
+		}
+		name = base
+	}
+	name += r.Suffix
+
+	for other, taken := range r.names {
+		if taken == name && other != t {
+			pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+			name = capitalize(pkg) + name
+			break
+		}
+	}
+
+	r.names[t] = name
+	return name
+}
+
+// structSchema returns the object schema of a struct type's fields
+func (r *Reflector) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	r.addFields(t, properties, &required)
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	if r.DisallowUnknownFields {
+		schema["additionalProperties"] = false
+	}
+	return schema
+}
+
+// addFields adds the properties of a struct's fields, and of the fields of the
+// structs it embeds, which a field of the struct itself takes precedence over
+func (r *Reflector) addFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	var embedded []reflect.Type
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := properties[name]; ok {
+			continue
+		}
+
+		omitempty := false
+		asString := false
+		for _, option := range strings.Split(options, ",") {
+			switch option {
+			case "omitempty", "omitzero":
+				omitempty = true
+			case "string":
+				asString = true
+			}
+		}
+
+		schema := r.reflect(field.Type)
+		if asString {
+			schema = map[string]interface{}{"type": "string"}
+		}
+		if description := field.Tag.Get("description"); description != "" {
+			schema = describe(schema, description)
+		}
+
+		isRequired := false
+		for _, keyword := range strings.Split(field.Tag.Get("jsonschema"), ",") {
+			key, value, _ := strings.Cut(keyword, "=")
+			switch key {
+			case "required":
+				isRequired = true
+			case "enum":
+				// The enum of a slice constrains its items
+				target := schema
+				if items, ok := schema["items"].(map[string]interface{}); ok {
+					target = items
+				}
+				values := strings.Split(value, "|")
+				enum := make([]interface{}, len(values))
+				for i, v := range values {
+					enum[i] = literal(v, target["type"])
+				}
+				target["enum"] = enum
+			case "minimum", "maximum":
+				schema[key] = literal(value, "number")
+			case "default":
+				schema["default"] = literal(value, schema["type"])
+			case "format":
+				schema["format"] = value
+			case "readonly":
+				schema["readOnly"] = true
+			}
+		}
+
+		if r.Output {
+			isRequired = !omitempty
+			switch field.Type.Kind() {
+			case reflect.Map, reflect.Slice, reflect.Ptr:
+				if !omitempty && field.Type != rawMessageType {
+					schema = nullable(schema)
+				}
+			}
+		}
+
+		properties[name] = schema
+		if isRequired {
+			*required = append(*required, name)
+		}
+	}
+
+	for _, et := range embedded {
+		r.addFields(et, properties, required)
+	}
+}
+
+// capitalize returns s with its first letter upper case
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// describe returns schema with a description. A reference is wrapped, so the
+// description does not change the definition it refers to.
+func describe(schema map[string]interface{}, description string) map[string]interface{} {
+	if _, ok := schema["$ref"]; ok {
+		return map[string]interface{}{"allOf": []interface{}{schema}, "description": description}
+	}
+	schema["description"] = description
+	return schema
+}
+
+// nullable returns schema allowing null too
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []string{typ, "null"}
+		return schema
+	}
+	if len(schema) == 0 {
+		return schema
+	}
+	return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+}
+
+// literal parses a tag value as a value of a schema type
+func literal(value string, typ interface{}) interface{} {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
@@ -0,0 +1,103 @@
+package jsonschema
+
+import "strings"
+
+// Sample returns a value of a schema generated by a Reflector, with every
+// property of every object set, so that decoding it exercises each field the
+// schema describes. References are looked up in definitions by the name after
+// their last slash, as a Reflector with or without a RefPrefix writes them.
+// Recursive types are sampled once: a type within itself is left out.
+func Sample(schema map[string]interface{}, definitions map[string]interface{}) interface{} {
+	return sample(schema, definitions, map[string]bool{})
+}
+
+func sample(schema map[string]interface{}, definitions map[string]interface{}, visiting map[string]bool) interface{} {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := ref[strings.LastIndex(ref, "/")+1:]
+		definition, _ := definitions[name].(map[string]interface{})
+		if definition == nil || visiting[name] {
+			return nil
+		}
+		visiting[name] = true
+		defer delete(visiting, name)
+		return sample(definition, definitions, visiting)
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	if value, ok := schema["default"]; ok {
+		return value
+	}
+
+	switch SampleType(schema) {
+	case "boolean":
+		return true
+	case "integer":
+		if minimum, ok := schema["minimum"].(float64); ok {
+			return minimum + 1
+		}
+		return 1.0
+	case "number":
+		return 1.5
+	case "string":
+		switch {
+		case schema["format"] == "date-time":
+			return "2026-01-02T15:04:05Z"
+		case schema["contentEncoding"] == "base64":
+			return "c2FtcGxl"
+		}
+		return "sample"
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		if items == nil {
+			return []interface{}{}
+		}
+		if item := sample(items, definitions, visiting); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "object":
+		object := map[string]interface{}{}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, property := range properties {
+			if property, ok := property.(map[string]interface{}); ok {
+				if value := sample(property, definitions, visiting); value != nil {
+					object[name] = value
+				}
+			}
+		}
+		if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+			if value := sample(additional, definitions, visiting); value != nil {
+				object["key"] = value
+			}
+		}
+		return object
+	}
+
+	// A schema of any value
+	return "sample"
+}
+
+// SampleType returns the type of values of a schema generated by a Reflector:
+// its type, or the first of its types other than null. It returns "" for a
+// schema of any value.
+func SampleType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, name := range t {
+			if name, ok := name.(string); ok && name != "null" {
+				return name
+			}
+		}
+	case []string:
+		for _, name := range t {
+			if name != "null" {
+				return name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,527 @@
+// Package jsonschema validates JSON documents against JSON Schema draft 2020-12.
+//
+// It implements the core and applicator vocabularies with local references
+// ("#", "#/$defs/name" and other JSON pointers into the same document) and the
+// validation vocabulary. format is treated as an annotation, as the draft does
+// by default. Remote references, anchors, $dynamicRef and the unevaluated*
+// keywords are not supported; schemas using them fail to compile, as do schemas
+// whose references loop without descending into the instance.
+//
+// It also generates the schemas of Go types, so that the schemas published for
+// the arguments and results of the API are those of the types they decode into
+// and encode from; see Reflector.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Draft is the $schema URI of the supported draft
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// unsupported are keywords the package does not implement
+var unsupported = []string{"$dynamicRef", "$dynamicAnchor", "$recursiveRef", "$recursiveAnchor", "$anchor", "unevaluatedProperties", "unevaluatedItems"}
+
+// Schema is a compiled JSON Schema
+type Schema struct {
+	// always is set for the boolean schemas true and false
+	always *bool
+
+	ref *Schema
+
+	types    []string
+	enum     []interface{}
+	hasConst bool
+	constant interface{}
+
+	allOf, anyOf, oneOf []*Schema
+	not                 *Schema
+	ifSchema            *Schema
+	thenSchema          *Schema
+	elseSchema          *Schema
+
+	properties           map[string]*Schema
+	patternProperties    []patternSchema
+	additionalProperties *Schema
+	propertyNames        *Schema
+	required             []string
+	dependentRequired    map[string][]string
+	dependentSchemas     map[string]*Schema
+	minProperties        *int
+	maxProperties        *int
+
+	prefixItems []*Schema
+	items       *Schema
+	contains    *Schema
+	minContains *int
+	maxContains *int
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+}
+
+type patternSchema struct {
+	pattern *regexp.Regexp
+	schema  *Schema
+}
+
+// compiler compiles the subschemas of one document, resolving references
+// within it. Subschemas are kept by location so recursive references terminate.
+type compiler struct {
+	root      interface{}
+	locations map[string]*Schema
+}
+
+// Compile compiles the JSON Schema in data
+func Compile(data []byte) (*Schema, error) {
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return CompileValue(root)
+}
+
+// CompileValue compiles a JSON Schema already decoded from JSON
+func CompileValue(root interface{}) (*Schema, error) {
+	if doc, ok := root.(map[string]interface{}); ok {
+		if draft, ok := doc["$schema"]; ok && strings.TrimSuffix(fmt.Sprint(draft), "#") != Draft {
+			return nil, fmt.Errorf("unsupported $schema %v: only %s is supported", draft, Draft)
+		}
+	}
+
+	c := &compiler{root: root, locations: make(map[string]*Schema)}
+	schema, err := c.compile(root, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkCycles(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// checkCycles rejects a schema that can reach itself through keywords applying
+// to the same instance ($ref, allOf, anyOf, oneOf, not, if, then, else and
+// dependentSchemas). Validating against it would recurse without consuming any
+// of the instance and never end. Cycles through properties, items and the other
+// keywords that descend into the instance are fine: they end with the instance.
+func (c *compiler) checkCycles() error {
+	locations := sortedSchemaKeys(c.locations)
+	locationOf := make(map[*Schema]string, len(locations))
+	for _, location := range locations {
+		locationOf[c.locations[location]] = location
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[*Schema]int, len(c.locations))
+
+	var visit func(s *Schema) error
+	visit = func(s *Schema) error {
+		switch state[s] {
+		case visiting:
+			return fmt.Errorf("%s: reference cycle that never descends into the instance", pointerOrRoot(locationOf[s]))
+		case done:
+			return nil
+		}
+
+		state[s] = visiting
+		for _, next := range s.inPlace() {
+			if err := visit(next); err != nil {
+				return err
+			}
+		}
+		state[s] = done
+		return nil
+	}
+
+	for _, location := range locations {
+		if err := visit(c.locations[location]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inPlace returns the subschemas s applies to the instance it validates itself
+func (s *Schema) inPlace() []*Schema {
+	var subs []*Schema
+	for _, sub := range []*Schema{s.ref, s.not, s.ifSchema, s.thenSchema, s.elseSchema} {
+		if sub != nil {
+			subs = append(subs, sub)
+		}
+	}
+	subs = append(subs, s.allOf...)
+	subs = append(subs, s.anyOf...)
+	subs = append(subs, s.oneOf...)
+	for _, name := range sortedSchemaKeys(s.dependentSchemas) {
+		subs = append(subs, s.dependentSchemas[name])
+	}
+	return subs
+}
+
+func (c *compiler) compile(value interface{}, location string) (*Schema, error) {
+	if schema, ok := c.locations[location]; ok {
+		return schema, nil
+	}
+
+	schema := &Schema{}
+	c.locations[location] = schema
+
+	switch v := value.(type) {
+	case bool:
+		schema.always = &v
+		return schema, nil
+	case map[string]interface{}:
+		if err := c.compileObject(schema, v, location); err != nil {
+			return nil, err
+		}
+		return schema, nil
+	}
+
+	return nil, fmt.Errorf("%s: a schema must be an object or a boolean", pointerOrRoot(location))
+}
+
+func (c *compiler) compileObject(s *Schema, doc map[string]interface{}, location string) error {
+	for _, keyword := range unsupported {
+		if _, ok := doc[keyword]; ok {
+			return fmt.Errorf("%s: unsupported keyword %s", pointerOrRoot(location), keyword)
+		}
+	}
+	if id, ok := doc["$id"]; ok && location != "" {
+		return fmt.Errorf("%s: $id is only supported on the root schema, not %v", location, id)
+	}
+
+	var err error
+	at := func(keyword string) string { return location + "/" + escape(keyword) }
+
+	if ref, ok := doc["$ref"].(string); ok {
+		if s.ref, err = c.resolve(ref, location); err != nil {
+			return err
+		}
+	}
+
+	if raw, ok := doc["type"]; ok {
+		switch t := raw.(type) {
+		case string:
+			s.types = []string{t}
+		case []interface{}:
+			for _, item := range t {
+				name, ok := item.(string)
+				if !ok {
+					return fmt.Errorf("%s: must be a string or an array of strings", at("type"))
+				}
+				s.types = append(s.types, name)
+			}
+		default:
+			return fmt.Errorf("%s: must be a string or an array of strings", at("type"))
+		}
+		for _, name := range s.types {
+			switch name {
+			case "null", "boolean", "object", "array", "number", "integer", "string":
+			default:
+				return fmt.Errorf("%s: unknown type %q", at("type"), name)
+			}
+		}
+	}
+
+	if raw, ok := doc["enum"]; ok {
+		values, ok := raw.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: must be an array", at("enum"))
+		}
+		s.enum = values
+	}
+	if raw, ok := doc["const"]; ok {
+		s.hasConst = true
+		s.constant = raw
+	}
+
+	for keyword, target := range map[string]*[]*Schema{"allOf": &s.allOf, "anyOf": &s.anyOf, "oneOf": &s.oneOf, "prefixItems": &s.prefixItems} {
+		if *target, err = c.compileArray(doc, keyword, location); err != nil {
+			return err
+		}
+	}
+
+	for keyword, target := range map[string]**Schema{
+		"not":                  &s.not,
+		"if":                   &s.ifSchema,
+		"then":                 &s.thenSchema,
+		"else":                 &s.elseSchema,
+		"additionalProperties": &s.additionalProperties,
+		"propertyNames":        &s.propertyNames,
+		"items":                &s.items,
+		"contains":             &s.contains,
+	} {
+		if raw, ok := doc[keyword]; ok {
+			if *target, err = c.compile(raw, at(keyword)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if s.properties, err = c.compileMap(doc, "properties", location); err != nil {
+		return err
+	}
+	if s.dependentSchemas, err = c.compileMap(doc, "dependentSchemas", location); err != nil {
+		return err
+	}
+
+	if raw, ok := doc["patternProperties"]; ok {
+		patterns, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: must be an object", at("patternProperties"))
+		}
+		for _, expr := range sortedKeys(patterns) {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("%s: invalid pattern %q: %w", at("patternProperties"), expr, err)
+			}
+			schema, err := c.compile(patterns[expr], at("patternProperties")+"/"+escape(expr))
+			if err != nil {
+				return err
+			}
+			s.patternProperties = append(s.patternProperties, patternSchema{pattern: re, schema: schema})
+		}
+	}
+
+	if s.required, err = stringArray(doc, "required", location); err != nil {
+		return err
+	}
+
+	if raw, ok := doc["dependentRequired"]; ok {
+		deps, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: must be an object", at("dependentRequired"))
+		}
+		s.dependentRequired = make(map[string][]string, len(deps))
+		for name := range deps {
+			if s.dependentRequired[name], err = stringArray(deps, name, at("dependentRequired")); err != nil {
+				return err
+			}
+		}
+	}
+
+	for keyword, target := range map[string]**int{
+		"minProperties": &s.minProperties,
+		"maxProperties": &s.maxProperties,
+		"minContains":   &s.minContains,
+		"maxContains":   &s.maxContains,
+		"minItems":      &s.minItems,
+		"maxItems":      &s.maxItems,
+		"minLength":     &s.minLength,
+		"maxLength":     &s.maxLength,
+	} {
+		if *target, err = count(doc, keyword, location); err != nil {
+			return err
+		}
+	}
+
+	for keyword, target := range map[string]**float64{
+		"minimum":          &s.minimum,
+		"maximum":          &s.maximum,
+		"exclusiveMinimum": &s.exclusiveMinimum,
+		"exclusiveMaximum": &s.exclusiveMaximum,
+		"multipleOf":       &s.multipleOf,
+	} {
+		if raw, ok := doc[keyword]; ok {
+			n, ok := raw.(float64)
+			if !ok {
+				return fmt.Errorf("%s: must be a number", at(keyword))
+			}
+			*target = &n
+		}
+	}
+	if s.multipleOf != nil && *s.multipleOf <= 0 {
+		return fmt.Errorf("%s: must be greater than 0", at("multipleOf"))
+	}
+
+	if raw, ok := doc["uniqueItems"]; ok {
+		unique, ok := raw.(bool)
+		if !ok {
+			return fmt.Errorf("%s: must be a boolean", at("uniqueItems"))
+		}
+		s.uniqueItems = unique
+	}
+
+	if raw, ok := doc["pattern"]; ok {
+		expr, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("%s: must be a string", at("pattern"))
+		}
+		if s.pattern, err = regexp.Compile(expr); err != nil {
+			return fmt.Errorf("%s: invalid pattern %q: %w", at("pattern"), expr, err)
+		}
+	}
+
+	return nil
+}
+
+// resolve compiles the subschema a local reference points to
+func (c *compiler) resolve(ref, location string) (*Schema, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("%s/$ref: only references within the schema (starting with #) are supported, not %q", location, ref)
+	}
+
+	pointer, err := url.PathUnescape(strings.TrimPrefix(ref, "#"))
+	if err != nil {
+		return nil, fmt.Errorf("%s/$ref: invalid reference %q: %w", location, ref, err)
+	}
+	if pointer != "" && !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%s/$ref: anchors are not supported, not %q", location, ref)
+	}
+
+	value := c.root
+	if pointer != "" {
+		for _, token := range strings.Split(pointer[1:], "/") {
+			token = unescape(token)
+			switch v := value.(type) {
+			case map[string]interface{}:
+				value = v[token]
+			case []interface{}:
+				i, err := strconv.Atoi(token)
+				if err != nil || i < 0 || i >= len(v) {
+					value = nil
+				} else {
+					value = v[i]
+				}
+			default:
+				value = nil
+			}
+			if value == nil {
+				return nil, fmt.Errorf("%s/$ref: %q points to nothing", location, ref)
+			}
+		}
+	}
+
+	return c.compile(value, pointer)
+}
+
+func (c *compiler) compileArray(doc map[string]interface{}, keyword, location string) ([]*Schema, error) {
+	raw, ok := doc[keyword]
+	if !ok {
+		return nil, nil
+	}
+	items, ok := raw.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("%s/%s: must be a non-empty array", location, keyword)
+	}
+
+	schemas := make([]*Schema, len(items))
+	for i, item := range items {
+		schema, err := c.compile(item, location+"/"+keyword+"/"+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		schemas[i] = schema
+	}
+	return schemas, nil
+}
+
+func (c *compiler) compileMap(doc map[string]interface{}, keyword, location string) (map[string]*Schema, error) {
+	raw, ok := doc[keyword]
+	if !ok {
+		return nil, nil
+	}
+	entries, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s/%s: must be an object", location, keyword)
+	}
+
+	schemas := make(map[string]*Schema, len(entries))
+	for name, entry := range entries {
+		schema, err := c.compile(entry, location+"/"+keyword+"/"+escape(name))
+		if err != nil {
+			return nil, err
+		}
+		schemas[name] = schema
+	}
+	return schemas, nil
+}
+
+func stringArray(doc map[string]interface{}, keyword, location string) ([]string, error) {
+	raw, ok := doc[keyword]
+	if !ok {
+		return nil, nil
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s/%s: must be an array of strings", location, escape(keyword))
+	}
+
+	values := make([]string, len(items))
+	for i, item := range items {
+		value, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s/%s: must be an array of strings", location, escape(keyword))
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func count(doc map[string]interface{}, keyword, location string) (*int, error) {
+	raw, ok := doc[keyword]
+	if !ok {
+		return nil, nil
+	}
+	n, ok := raw.(float64)
+	if !ok || n < 0 || n != float64(int(n)) {
+		return nil, fmt.Errorf("%s/%s: must be a non-negative integer", location, keyword)
+	}
+	value := int(n)
+	return &value, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedSchemaKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escape escapes a JSON pointer reference token
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// unescape reverses escape
+func unescape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+func pointerOrRoot(location string) string {
+	if location == "" {
+		return "schema"
+	}
+	return location
+}
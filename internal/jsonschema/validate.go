@@ -0,0 +1,322 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error is a single validation failure
+type Error struct {
+	// InstanceLocation is the JSON pointer to the failing value in the document
+	InstanceLocation string `json:"instanceLocation"`
+	// Keyword is the schema keyword that failed
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	location := e.InstanceLocation
+	if location == "" {
+		location = "/"
+	}
+	return fmt.Sprintf("%s: %s", location, e.Message)
+}
+
+// Validate validates a JSON document and returns every failure found
+func (s *Schema) Validate(data []byte) ([]*Error, error) {
+	var instance interface{}
+	if err := json.Unmarshal(data, &instance); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return s.ValidateValue(instance), nil
+}
+
+// ValidateValue validates a document already decoded from JSON
+func (s *Schema) ValidateValue(instance interface{}) []*Error {
+	var errs []*Error
+	s.validate(instance, "", &errs)
+	return errs
+}
+
+func (s *Schema) valid(instance interface{}) bool {
+	return len(s.ValidateValue(instance)) == 0
+}
+
+func (s *Schema) validate(instance interface{}, location string, errs *[]*Error) {
+	fail := func(keyword, format string, args ...interface{}) {
+		*errs = append(*errs, &Error{InstanceLocation: location, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.always != nil {
+		if !*s.always {
+			fail("false", "no value is allowed here")
+		}
+		return
+	}
+
+	if s.ref != nil {
+		s.ref.validate(instance, location, errs)
+	}
+
+	if len(s.types) > 0 && !matchesType(instance, s.types) {
+		fail("type", "expected %s, got %s", strings.Join(s.types, " or "), typeOf(instance))
+	}
+	if s.enum != nil && !contains(s.enum, instance) {
+		fail("enum", "value must be one of the enumerated values")
+	}
+	if s.hasConst && !equal(s.constant, instance) {
+		fail("const", "value must equal the constant %s", compact(s.constant))
+	}
+
+	s.validateApplicators(instance, location, errs, fail)
+
+	switch v := instance.(type) {
+	case map[string]interface{}:
+		s.validateObject(v, location, errs, fail)
+	case []interface{}:
+		s.validateArray(v, location, errs, fail)
+	case string:
+		s.validateString(v, fail)
+	case float64:
+		s.validateNumber(v, fail)
+	}
+}
+
+func (s *Schema) validateApplicators(instance interface{}, location string, errs *[]*Error, fail func(string, string, ...interface{})) {
+	for _, sub := range s.allOf {
+		sub.validate(instance, location, errs)
+	}
+
+	if s.anyOf != nil {
+		matched := false
+		for _, sub := range s.anyOf {
+			if sub.valid(instance) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("anyOf", "value must match at least one schema in anyOf")
+		}
+	}
+
+	if s.oneOf != nil {
+		matched := 0
+		for _, sub := range s.oneOf {
+			if sub.valid(instance) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			fail("oneOf", "value must match exactly one schema in oneOf, matched %d", matched)
+		}
+	}
+
+	if s.not != nil && s.not.valid(instance) {
+		fail("not", "value must not match the schema in not")
+	}
+
+	if s.ifSchema != nil {
+		if s.ifSchema.valid(instance) {
+			if s.thenSchema != nil {
+				s.thenSchema.validate(instance, location, errs)
+			}
+		} else if s.elseSchema != nil {
+			s.elseSchema.validate(instance, location, errs)
+		}
+	}
+}
+
+func (s *Schema) validateObject(object map[string]interface{}, location string, errs *[]*Error, fail func(string, string, ...interface{})) {
+	for _, name := range s.required {
+		if _, ok := object[name]; !ok {
+			fail("required", "missing required property %q", name)
+		}
+	}
+	if s.minProperties != nil && len(object) < *s.minProperties {
+		fail("minProperties", "must have at least %d properties", *s.minProperties)
+	}
+	if s.maxProperties != nil && len(object) > *s.maxProperties {
+		fail("maxProperties", "must have at most %d properties", *s.maxProperties)
+	}
+
+	for _, name := range sortedKeys(object) {
+		value := object[name]
+		at := location + "/" + escape(name)
+
+		if deps, ok := s.dependentRequired[name]; ok {
+			for _, dep := range deps {
+				if _, ok := object[dep]; !ok {
+					fail("dependentRequired", "property %q requires property %q", name, dep)
+				}
+			}
+		}
+		if dep, ok := s.dependentSchemas[name]; ok {
+			dep.validate(object, location, errs)
+		}
+
+		if s.propertyNames != nil && !s.propertyNames.valid(name) {
+			fail("propertyNames", "invalid property name %q", name)
+		}
+
+		evaluated := false
+		if sub, ok := s.properties[name]; ok {
+			sub.validate(value, at, errs)
+			evaluated = true
+		}
+		for _, pp := range s.patternProperties {
+			if pp.pattern.MatchString(name) {
+				pp.schema.validate(value, at, errs)
+				evaluated = true
+			}
+		}
+		if !evaluated && s.additionalProperties != nil {
+			if s.additionalProperties.always != nil && !*s.additionalProperties.always {
+				fail("additionalProperties", "property %q is not allowed", name)
+			} else {
+				s.additionalProperties.validate(value, at, errs)
+			}
+		}
+	}
+}
+
+func (s *Schema) validateArray(array []interface{}, location string, errs *[]*Error, fail func(string, string, ...interface{})) {
+	if s.minItems != nil && len(array) < *s.minItems {
+		fail("minItems", "must have at least %d items", *s.minItems)
+	}
+	if s.maxItems != nil && len(array) > *s.maxItems {
+		fail("maxItems", "must have at most %d items", *s.maxItems)
+	}
+
+	if s.uniqueItems {
+	unique:
+		for i := range array {
+			for j := i + 1; j < len(array); j++ {
+				if equal(array[i], array[j]) {
+					fail("uniqueItems", "items %d and %d are equal", i, j)
+					break unique
+				}
+			}
+		}
+	}
+
+	for i, item := range array {
+		at := location + "/" + strconv.Itoa(i)
+		if i < len(s.prefixItems) {
+			s.prefixItems[i].validate(item, at, errs)
+		} else if s.items != nil {
+			s.items.validate(item, at, errs)
+		}
+	}
+
+	if s.contains != nil {
+		matched := 0
+		for _, item := range array {
+			if s.contains.valid(item) {
+				matched++
+			}
+		}
+		min := 1
+		if s.minContains != nil {
+			min = *s.minContains
+		}
+		if matched < min {
+			fail("contains", "must contain at least %d matching items, found %d", min, matched)
+		}
+		if s.maxContains != nil && matched > *s.maxContains {
+			fail("maxContains", "must contain at most %d matching items, found %d", *s.maxContains, matched)
+		}
+	}
+}
+
+func (s *Schema) validateString(value string, fail func(string, string, ...interface{})) {
+	length := utf8.RuneCountInString(value)
+	if s.minLength != nil && length < *s.minLength {
+		fail("minLength", "must be at least %d characters", *s.minLength)
+	}
+	if s.maxLength != nil && length > *s.maxLength {
+		fail("maxLength", "must be at most %d characters", *s.maxLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(value) {
+		fail("pattern", "must match pattern %q", s.pattern.String())
+	}
+}
+
+func (s *Schema) validateNumber(value float64, fail func(string, string, ...interface{})) {
+	if s.minimum != nil && value < *s.minimum {
+		fail("minimum", "must be >= %v", *s.minimum)
+	}
+	if s.maximum != nil && value > *s.maximum {
+		fail("maximum", "must be <= %v", *s.maximum)
+	}
+	if s.exclusiveMinimum != nil && value <= *s.exclusiveMinimum {
+		fail("exclusiveMinimum", "must be > %v", *s.exclusiveMinimum)
+	}
+	if s.exclusiveMaximum != nil && value >= *s.exclusiveMaximum {
+		fail("exclusiveMaximum", "must be < %v", *s.exclusiveMaximum)
+	}
+	if s.multipleOf != nil {
+		quotient := value / *s.multipleOf
+		if math.IsInf(quotient, 0) || math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			fail("multipleOf", "must be a multiple of %v", *s.multipleOf)
+		}
+	}
+}
+
+func typeOf(instance interface{}) string {
+	switch v := instance.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", instance)
+}
+
+func matchesType(instance interface{}, types []string) bool {
+	actual := typeOf(instance)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []interface{}, instance interface{}) bool {
+	for _, value := range values {
+		if equal(value, instance) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compares decoded JSON values; numbers are all float64 so 1 and 1.0 are equal
+func equal(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+func compact(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
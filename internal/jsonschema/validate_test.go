@@ -0,0 +1,238 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+// conformanceCase is a schema with instances it must accept and reject
+type conformanceCase struct {
+	name    string
+	schema  string
+	valid   []string
+	invalid []string
+}
+
+var conformanceCases = []conformanceCase{
+	{
+		name:    "boolean schemas",
+		schema:  `{"properties": {"any": true, "none": false}}`,
+		valid:   []string{`{"any": 1}`, `{}`},
+		invalid: []string{`{"none": 1}`},
+	},
+	{
+		name:    "type",
+		schema:  `{"type": ["string", "null"]}`,
+		valid:   []string{`"a"`, `null`},
+		invalid: []string{`1`, `{}`, `[]`, `true`},
+	},
+	{
+		name:    "integer",
+		schema:  `{"type": "integer"}`,
+		valid:   []string{`1`, `1.0`, `-3`},
+		invalid: []string{`1.5`, `"1"`},
+	},
+	{
+		name:    "enum",
+		schema:  `{"enum": ["a", 1, {"b": [2]}]}`,
+		valid:   []string{`"a"`, `1`, `{"b": [2]}`},
+		invalid: []string{`"b"`, `{"b": [3]}`},
+	},
+	{
+		name:    "const",
+		schema:  `{"const": {"a": 1}}`,
+		valid:   []string{`{"a": 1}`, `{"a": 1.0}`},
+		invalid: []string{`{"a": 2}`, `{"a": 1, "b": 1}`},
+	},
+	{
+		name:    "required and properties",
+		schema:  `{"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}`,
+		valid:   []string{`{"name": "x"}`, `{"name": "x", "other": 1}`},
+		invalid: []string{`{}`, `{"name": 1}`},
+	},
+	{
+		name:    "additionalProperties",
+		schema:  `{"properties": {"a": {}}, "patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": false}`,
+		valid:   []string{`{"a": 1}`, `{"x-b": "c"}`},
+		invalid: []string{`{"b": 1}`, `{"x-b": 1}`},
+	},
+	{
+		name:    "propertyNames and property counts",
+		schema:  `{"propertyNames": {"maxLength": 2}, "minProperties": 1, "maxProperties": 2}`,
+		valid:   []string{`{"a": 1}`, `{"ab": 1, "c": 2}`},
+		invalid: []string{`{}`, `{"abc": 1}`, `{"a": 1, "b": 2, "c": 3}`},
+	},
+	{
+		name:    "dependentRequired and dependentSchemas",
+		schema:  `{"dependentRequired": {"card": ["billing"]}, "dependentSchemas": {"vip": {"required": ["since"]}}}`,
+		valid:   []string{`{}`, `{"card": 1, "billing": 2}`, `{"vip": true, "since": 2020}`},
+		invalid: []string{`{"card": 1}`, `{"vip": true}`},
+	},
+	{
+		name:    "prefixItems and items",
+		schema:  `{"prefixItems": [{"type": "string"}], "items": {"type": "integer"}, "minItems": 1, "maxItems": 3}`,
+		valid:   []string{`["a"]`, `["a", 1, 2]`},
+		invalid: []string{`[]`, `[1]`, `["a", "b"]`, `["a", 1, 2, 3]`},
+	},
+	{
+		name:    "contains",
+		schema:  `{"contains": {"const": 1}, "minContains": 2, "maxContains": 3}`,
+		valid:   []string{`[1, 1]`, `[1, 2, 1, 1]`},
+		invalid: []string{`[]`, `[1, 2]`, `[1, 1, 1, 1]`},
+	},
+	{
+		name:    "uniqueItems",
+		schema:  `{"uniqueItems": true}`,
+		valid:   []string{`[1, 2]`, `[{"a": 1}, {"a": 2}]`},
+		invalid: []string{`[1, 1]`, `[{"a": 1}, {"a": 1}]`},
+	},
+	{
+		name:    "string bounds and pattern",
+		schema:  `{"minLength": 2, "maxLength": 3, "pattern": "^[a-z]+$"}`,
+		valid:   []string{`"ab"`, `"abc"`, `1`},
+		invalid: []string{`"a"`, `"abcd"`, `"AB"`},
+	},
+	{
+		name:    "string length counts code points",
+		schema:  `{"maxLength": 2}`,
+		valid:   []string{`"éé"`},
+		invalid: []string{`"ééé"`},
+	},
+	{
+		name:    "numeric bounds",
+		schema:  `{"minimum": 1, "exclusiveMaximum": 10, "multipleOf": 0.5}`,
+		valid:   []string{`1`, `9.5`, `"x"`},
+		invalid: []string{`0.5`, `10`, `1.25`},
+	},
+	{
+		name:    "allOf",
+		schema:  `{"allOf": [{"minimum": 1}, {"maximum": 3}]}`,
+		valid:   []string{`2`},
+		invalid: []string{`0`, `4`},
+	},
+	{
+		name:    "anyOf",
+		schema:  `{"anyOf": [{"type": "string"}, {"minimum": 5}]}`,
+		valid:   []string{`"a"`, `6`},
+		invalid: []string{`4`},
+	},
+	{
+		name:    "oneOf",
+		schema:  `{"oneOf": [{"type": "integer"}, {"minimum": 5}]}`,
+		valid:   []string{`1`, `5.5`},
+		invalid: []string{`6`, `1.5`},
+	},
+	{
+		name:    "not",
+		schema:  `{"not": {"type": "null"}}`,
+		valid:   []string{`1`},
+		invalid: []string{`null`},
+	},
+	{
+		name:    "if then else",
+		schema:  `{"if": {"type": "string"}, "then": {"minLength": 2}, "else": {"type": "integer"}}`,
+		valid:   []string{`"ab"`, `1`},
+		invalid: []string{`"a"`, `1.5`},
+	},
+	{
+		name:    "$ref to $defs",
+		schema:  `{"$defs": {"id": {"type": "string", "minLength": 1}}, "properties": {"id": {"$ref": "#/$defs/id"}}}`,
+		valid:   []string{`{"id": "a"}`},
+		invalid: []string{`{"id": ""}`, `{"id": 1}`},
+	},
+	{
+		name:    "$ref with sibling keywords",
+		schema:  `{"$defs": {"s": {"type": "string"}}, "$ref": "#/$defs/s", "maxLength": 1}`,
+		valid:   []string{`"a"`},
+		invalid: []string{`"ab"`, `1`},
+	},
+	{
+		name:    "recursive $ref through properties",
+		schema:  `{"type": "object", "properties": {"value": {"type": "integer"}, "children": {"type": "array", "items": {"$ref": "#"}}}}`,
+		valid:   []string{`{"value": 1, "children": [{"value": 2, "children": [{"value": 3}]}]}`},
+		invalid: []string{`{"children": [{"children": [{"value": "x"}]}]}`},
+	},
+	{
+		name:    "recursive $ref through a definition",
+		schema:  `{"$defs": {"list": {"anyOf": [{"type": "null"}, {"type": "object", "properties": {"next": {"$ref": "#/$defs/list"}}, "required": ["next"]}]}}, "$ref": "#/$defs/list"}`,
+		valid:   []string{`null`, `{"next": {"next": null}}`},
+		invalid: []string{`{"next": {}}`, `1`},
+	},
+}
+
+func TestConformance(t *testing.T) {
+	for _, tc := range conformanceCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schema, err := Compile([]byte(tc.schema))
+			if err != nil {
+				t.Fatalf("failed to compile: %v", err)
+			}
+
+			for _, instance := range tc.valid {
+				errs, err := schema.Validate([]byte(instance))
+				if err != nil {
+					t.Fatalf("invalid instance %s: %v", instance, err)
+				}
+				if len(errs) != 0 {
+					t.Errorf("expected %s to be valid, got %v", instance, errs)
+				}
+			}
+			for _, instance := range tc.invalid {
+				errs, err := schema.Validate([]byte(instance))
+				if err != nil {
+					t.Fatalf("invalid instance %s: %v", instance, err)
+				}
+				if len(errs) == 0 {
+					t.Errorf("expected %s to be invalid", instance)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorLocations(t *testing.T) {
+	schema, err := Compile([]byte(`{"properties": {"items": {"items": {"type": "string"}}}}`))
+	if err != nil {
+		t.Fatalf("failed to compile: %v", err)
+	}
+
+	errs, err := schema.Validate([]byte(`{"items": ["a", 1]}`))
+	if err != nil {
+		t.Fatalf("failed to validate: %v", err)
+	}
+	if len(errs) != 1 || errs[0].InstanceLocation != "/items/1" || errs[0].Keyword != "type" {
+		t.Fatalf("expected one type error at /items/1, got %v", errs)
+	}
+}
+
+func TestCompileRejects(t *testing.T) {
+	cases := map[string]struct {
+		schema string
+		err    string
+	}{
+		"self reference":       {`{"$ref": "#"}`, "reference cycle"},
+		"mutual references":    {`{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`, "reference cycle"},
+		"cycle through allOf":  {`{"allOf": [{"$ref": "#"}]}`, "reference cycle"},
+		"cycle through anyOf":  {`{"anyOf": [{"type": "string"}, {"$ref": "#"}]}`, "reference cycle"},
+		"cycle through not":    {`{"not": {"$ref": "#"}}`, "reference cycle"},
+		"cycle through if":     {`{"if": {"$ref": "#"}}`, "reference cycle"},
+		"dependentSchemas":     {`{"dependentSchemas": {"a": {"$ref": "#"}}}`, "reference cycle"},
+		"unresolved reference": {`{"$ref": "#/$defs/missing"}`, "missing"},
+		"remote reference":     {`{"$ref": "https://example.com/schema"}`, "https://example.com/schema"},
+		"unsupported keyword":  {`{"$dynamicRef": "#"}`, "$dynamicRef"},
+		"unsupported draft":    {`{"$schema": "http://json-schema.org/draft-07/schema#"}`, "unsupported $schema"},
+		"unknown type":         {`{"type": "float"}`, "unknown type"},
+		"not a schema":         {`{"properties": {"a": 1}}`, "must be an object or a boolean"},
+	}
+
+	for name, tc := range cases {
+		_, err := Compile([]byte(tc.schema))
+		if err == nil {
+			t.Errorf("%s: expected %s not to compile", name, tc.schema)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected an error mentioning %q, got %v", name, tc.err, err)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/prismon/synthesis/internal/auth"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// federationTimeout bounds listing the tools of a downstream MCP server
+const federationTimeout = 30 * time.Second
+
+// invokeToolAction is the action a call of a federated tool is authorized as,
+// on the registered tool behind it
+const invokeToolAction = "invoke_tool"
+
+// federation holds the sessions with the downstream MCP servers registered as
+// tools, whose tools are re-exposed named "<toolId>.<tool name>"
+type federation struct {
+	// connectMu serializes connecting and disconnecting servers
+	connectMu sync.Mutex
+	// ctx is the lifetime of the sessions; nil until Federate is called
+	ctx context.Context
+
+	mu      sync.RWMutex
+	servers map[string]*federatedServer // by registered tool ID
+	tools   map[string]federatedTool    // by exposed name
+}
+
+// federatedServer is a session with a downstream MCP server
+type federatedServer struct {
+	tool   *domain.ToolConfig
+	client *client.Client
+	names  []string // exposed names of its tools
+}
+
+// federatedTool is a tool of a downstream MCP server
+type federatedTool struct {
+	toolID string
+	uri    string // URI of the registered tool
+	name   string // name on the downstream server
+}
+
+func newFederation() *federation {
+	return &federation{
+		servers: make(map[string]*federatedServer),
+		tools:   make(map[string]federatedTool),
+	}
+}
+
+// tool returns the federated tool exposed as name
+func (f *federation) tool(name string) (federatedTool, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	tool, ok := f.tools[name]
+	return tool, ok
+}
+
+// server returns the session with the server registered as a tool
+func (f *federation) server(toolID string) *federatedServer {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.servers[toolID]
+}
+
+// Federate connects to each downstream MCP server registered as a tool and
+// re-exposes its tools until ctx is done, then ends the sessions. Servers
+// registered, changed or deleted meanwhile are followed through HandleEvent,
+// and a server's tools are listed again when it reports they changed.
+func (s *Server) Federate(ctx context.Context) {
+	s.federation.connectMu.Lock()
+	s.federation.ctx = ctx
+	s.federation.connectMu.Unlock()
+
+	for _, kind := range s.integrations.Kinds().Kinds() {
+		if kind.Connect == nil {
+			continue
+		}
+
+		tools, err := s.toolRepo.ListByKind(postgres.WithSystemScope(ctx), kind.Name)
+		if err != nil {
+			log.Printf("failed to list %s tools: %v", kind.Name, err)
+			continue
+		}
+		for _, tool := range tools {
+			s.connectFederated(tool.ID)
+		}
+	}
+
+	<-ctx.Done()
+
+	s.federation.connectMu.Lock()
+	defer s.federation.connectMu.Unlock()
+	s.federation.ctx = nil
+	for toolID := range s.federation.servers {
+		s.disconnectLocked(toolID)
+	}
+}
+
+// connectFederated (re)starts the session with the MCP server registered as a
+// tool and exposes its tools; a tool of another kind is only disconnected
+func (s *Server) connectFederated(toolID string) {
+	s.federation.connectMu.Lock()
+	defer s.federation.connectMu.Unlock()
+
+	ctx := s.federation.ctx
+	if ctx == nil {
+		return
+	}
+	s.disconnectLocked(toolID)
+
+	tool, err := s.toolRepo.Get(postgres.WithSystemScope(ctx), toolID)
+	if err != nil {
+		log.Printf("failed to get tool %s: %v", toolID, err)
+		return
+	}
+	if kind, ok := s.integrations.Kinds().Kind(tool.Kind); !ok || kind.Connect == nil {
+		return
+	}
+
+	c, err := s.integrations.Connect(ctx, tool)
+	if err != nil {
+		log.Printf("failed to connect to MCP server of tool %s: %v", toolID, err)
+		return
+	}
+	c.OnNotification(func(notification mcp.JSONRPCNotification) {
+		if notification.Method == mcp.MethodNotificationToolsListChanged {
+			go s.refreshFederated(toolID)
+		}
+	})
+
+	s.federation.mu.Lock()
+	s.federation.servers[toolID] = &federatedServer{tool: tool, client: c}
+	s.federation.mu.Unlock()
+
+	s.refreshFederated(toolID)
+}
+
+// disconnectFederated ends the session with the MCP server registered as a
+// tool and withdraws its tools
+func (s *Server) disconnectFederated(toolID string) {
+	s.federation.connectMu.Lock()
+	defer s.federation.connectMu.Unlock()
+	s.disconnectLocked(toolID)
+}
+
+// disconnectLocked is disconnectFederated with connectMu held
+func (s *Server) disconnectLocked(toolID string) {
+	s.federation.mu.Lock()
+	fs, ok := s.federation.servers[toolID]
+	if ok {
+		delete(s.federation.servers, toolID)
+		for _, name := range fs.names {
+			delete(s.federation.tools, name)
+		}
+	}
+	s.federation.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	if len(fs.names) > 0 {
+		s.mcpServer.DeleteTools(fs.names...)
+	}
+	if err := fs.client.Close(); err != nil {
+		log.Printf("failed to close MCP session of tool %s: %v", toolID, err)
+	}
+}
+
+// refreshFederated lists the tools of the MCP server registered as a tool and
+// exposes them in place of those exposed before
+func (s *Server) refreshFederated(toolID string) {
+	fs := s.federation.server(toolID)
+	if fs == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), federationTimeout)
+	defer cancel()
+
+	listed, err := fs.client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		log.Printf("failed to list tools of MCP server of tool %s: %v", toolID, err)
+		return
+	}
+
+	tools := make([]server.ServerTool, 0, len(listed.Tools))
+	federated := make(map[string]federatedTool, len(listed.Tools))
+	for _, tool := range listed.Tools {
+		name := tool.Name
+		tool.Name = toolID + "." + name
+		tool.Description = fmt.Sprintf("%s (via %s)", tool.Description, fs.tool.DisplayName)
+
+		tools = append(tools, server.ServerTool{Tool: tool, Handler: s.callFederated(toolID, name)})
+		federated[tool.Name] = federatedTool{toolID: toolID, uri: fs.tool.URI(), name: name}
+	}
+
+	s.federation.mu.Lock()
+	// The server may have been disconnected while its tools were listed
+	if s.federation.servers[toolID] != fs {
+		s.federation.mu.Unlock()
+		return
+	}
+	var withdrawn []string
+	for _, name := range fs.names {
+		delete(s.federation.tools, name)
+		if _, ok := federated[name]; !ok {
+			withdrawn = append(withdrawn, name)
+		}
+	}
+	fs.names = fs.names[:0]
+	for name, tool := range federated {
+		s.federation.tools[name] = tool
+		fs.names = append(fs.names, name)
+	}
+	sort.Strings(fs.names)
+	s.federation.mu.Unlock()
+
+	if len(withdrawn) > 0 {
+		s.mcpServer.DeleteTools(withdrawn...)
+	}
+	if len(tools) > 0 {
+		s.mcpServer.AddTools(tools...)
+	}
+}
+
+// callFederated returns the handler of a federated tool, which proxies calls
+// to the tool named name of the MCP server registered as a tool. Calls are
+// logged as invocations of the registered tool.
+func (s *Server) callFederated(toolID, name string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		fs := s.federation.server(toolID)
+		if fs == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("the MCP server of tool %s is not connected", toolID)), nil
+		}
+
+		result, err := s.integrations.Call(ctx, fs.client, fs.tool, name, request.Params.Arguments, auth.IdentityFromContext(ctx, "system"))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to call %s: %v", request.Params.Name, err)), nil
+		}
+
+		return result, nil
+	}
+}
+
+// filterFederatedTools hides from a tool listing the federated tools the caller
+// may not invoke or that lie outside the session's roots
+func (s *Server) filterFederatedTools(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	roots := s.sessionRoots(ctx)
+
+	filtered := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if federated, ok := s.federation.tool(tool.Name); ok {
+			if !roots.contains(federated.uri) {
+				continue
+			}
+			if s.authorizer != nil && s.authorizer.Check(ctx, invokeToolAction, federated.uri) != nil {
+				continue
+			}
+		}
+		filtered = append(filtered, tool)
+	}
+	return filtered
+}
+
+// handleToolEvent follows a change of a registered tool: a downstream MCP
+// server is connected again with its new config, or disconnected when deleted
+func (s *Server) handleToolEvent(event *domain.Event) {
+	switch event.Type {
+	case domain.EventToolCreated, domain.EventToolUpdated:
+		go s.connectFederated(event.ResourceID)
+	case domain.EventToolDeleted:
+		go s.disconnectFederated(event.ResourceID)
+	}
+}
@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// Filters a list tool may offer besides its sort and page
+const (
+	filterLabels = "label_selector"
+	filterOwner  = "owner"
+	filterStatus = "status"
+)
+
+// listArgs are the filter, sort and page arguments of a list tool
+type listArgs struct {
+	LabelSelector string `json:"label_selector" description:"Only list resources whose labels match this selector, e.g. \"environment=production,app in (nginx)\"; requirements are key=value, key!=value, key in (...), key notin (...), key and !key (optional)"`
+	Owner         string `json:"owner" description:"Only list resources with this owner (optional)"`
+	Status        string `json:"status" description:"Only list resources with this status (optional)"`
+	Sort          string `json:"sort" description:"Field to sort by, descending with a \"-\" prefix (optional)"`
+	Cursor        string `json:"cursor" description:"next_cursor of the previous page, to continue the listing with the same sort (optional)"`
+	Limit         int    `json:"limit" jsonschema:"minimum=0"`
+}
+
+// options returns the arguments as list options
+func (a listArgs) options() postgres.ListOptions {
+	return postgres.ListOptions{
+		Selector: a.LabelSelector,
+		Owner:    a.Owner,
+		Status:   a.Status,
+		Sort:     a.Sort,
+		Cursor:   a.Cursor,
+		Limit:    a.Limit,
+	}
+}
+
+// withListProperties returns an edit of a list tool's input schema that limits
+// its sort argument to sorting by any of sorts, and offers only the filters
+// named
+func withListProperties(sorts []string, filters ...string) func(map[string]interface{}) {
+	return func(schema map[string]interface{}) {
+		props := properties(schema)
+
+		offered := make(map[string]bool, len(filters))
+		for _, filter := range filters {
+			offered[filter] = true
+		}
+		for _, filter := range []string{filterLabels, filterOwner, filterStatus} {
+			if !offered[filter] {
+				delete(props, filter)
+			}
+		}
+
+		enum := make([]string, 0, 2*len(sorts))
+		for _, field := range sorts {
+			enum = append(enum, field, "-"+field)
+		}
+		if sort, ok := props["sort"].(map[string]interface{}); ok {
+			sort["enum"] = enum
+		}
+		if limit, ok := props["limit"].(map[string]interface{}); ok {
+			limit["description"] = fmt.Sprintf("Maximum number of results (default: %d, max: %d)", postgres.DefaultListLimit, postgres.MaxListLimit)
+		}
+	}
+}
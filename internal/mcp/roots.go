@@ -0,0 +1,331 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// rootsTimeout bounds how long the server waits for a client to list its roots
+const rootsTimeout = 5 * time.Second
+
+// rootsAwareTools address no single resource and read nothing outside the
+// session's roots: they limit their results to the roots themselves, or read
+// only the global types and tool kinds
+var rootsAwareTools = map[string]bool{
+	"semantic_search_notebooks": true,
+	"query_audit_log":           true,
+	"list_types":                true,
+	"validate_resource":         true,
+	"list_tool_kinds":           true,
+}
+
+// rootSet limits a session to the resources at or beneath a set of synthesis://
+// URIs. A nil *rootSet does not limit the session; an empty one admits nothing.
+type rootSet struct {
+	uris []string
+}
+
+// parseRoots returns the roots at uris in canonical form
+func parseRoots(uris []string) (*rootSet, error) {
+	roots := &rootSet{}
+	for _, uri := range uris {
+		ref, err := domain.ParseURI(uri)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root: %w", err)
+		}
+		roots.add(ref.URI())
+	}
+	return roots, nil
+}
+
+func (r *rootSet) add(uri string) {
+	for _, existing := range r.uris {
+		if existing == uri {
+			return
+		}
+	}
+	r.uris = append(r.uris, uri)
+}
+
+// list returns the root URIs
+func (r *rootSet) list() []string {
+	if r == nil {
+		return nil
+	}
+	return r.uris
+}
+
+// contains reports whether uri is one of the roots or a resource beneath one.
+// Types are global, so they lie within every root.
+func (r *rootSet) contains(uri string) bool {
+	if r == nil || strings.HasPrefix(uri, domain.URIScheme+"type/") {
+		return true
+	}
+	for _, root := range r.uris {
+		if uri == root || strings.HasPrefix(uri, root+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// intersect returns the roots admitting only what both r and other admit
+func (r *rootSet) intersect(other *rootSet) *rootSet {
+	if r == nil {
+		return other
+	}
+	if other == nil {
+		return r
+	}
+
+	result := &rootSet{}
+	for _, uri := range r.uris {
+		if other.contains(uri) {
+			result.add(uri)
+		}
+	}
+	for _, uri := range other.uris {
+		if r.contains(uri) {
+			result.add(uri)
+		}
+	}
+	return result
+}
+
+// tenant returns the tenant every root lies in, or "" if they span several
+func (r *rootSet) tenant() string {
+	var tenantID string
+	for _, uri := range r.list() {
+		ref, err := domain.ParseURI(uri)
+		if err != nil || ref.TenantID == "" || (tenantID != "" && ref.TenantID != tenantID) {
+			return ""
+		}
+		tenantID = ref.TenantID
+	}
+	return tenantID
+}
+
+func (r *rootSet) String() string {
+	if r == nil {
+		return "(none)"
+	}
+	if len(r.uris) == 0 {
+		return "(empty)"
+	}
+	return strings.Join(r.uris, ", ")
+}
+
+// sessionRegistry tracks the connected sessions and the roots their clients declared
+type sessionRegistry struct {
+	mu       sync.Mutex
+	sessions map[string]server.ClientSession
+	// declared holds each session's declared roots once listed; nil when the
+	// client declared no synthesis:// roots
+	declared map[string]*rootSet
+}
+
+func newSessionRegistry() *sessionRegistry {
+	return &sessionRegistry{
+		sessions: make(map[string]server.ClientSession),
+		declared: make(map[string]*rootSet),
+	}
+}
+
+// registerSessionHooks keeps the session registry current
+func (s *Server) registerSessionHooks(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		s.sessions.mu.Lock()
+		defer s.sessions.mu.Unlock()
+		s.sessions.sessions[session.SessionID()] = session
+	})
+
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		s.sessions.mu.Lock()
+		defer s.sessions.mu.Unlock()
+		delete(s.sessions.sessions, session.SessionID())
+		delete(s.sessions.declared, session.SessionID())
+	})
+
+	hooks.AddAfterListResources(func(ctx context.Context, id any, message *mcp.ListResourcesRequest, result *mcp.ListResourcesResult) {
+		roots := s.sessionRoots(ctx)
+		if roots == nil {
+			return
+		}
+
+		resources := result.Resources[:0]
+		for _, resource := range result.Resources {
+			if roots.contains(resource.URI) {
+				resources = append(resources, resource)
+			}
+		}
+		result.Resources = resources
+	})
+}
+
+// handleRootsListChanged forgets a session's declared roots so they are listed
+// again before its next call
+func (s *Server) handleRootsListChanged(ctx context.Context, notification mcp.JSONRPCNotification) {
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return
+	}
+
+	s.sessions.mu.Lock()
+	defer s.sessions.mu.Unlock()
+	delete(s.sessions.declared, session.SessionID())
+}
+
+// sessionRoots returns the roots of the session on ctx: the configured roots,
+// narrowed to those its client declared
+func (s *Server) sessionRoots(ctx context.Context) *rootSet {
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return s.roots
+	}
+	return s.rootsFor(ctx, session)
+}
+
+// rootsFor returns session's roots, asking its client for them the first time
+func (s *Server) rootsFor(ctx context.Context, session server.ClientSession) *rootSet {
+	s.sessions.mu.Lock()
+	declared, ok := s.sessions.declared[session.SessionID()]
+	s.sessions.mu.Unlock()
+
+	if !ok {
+		declared = s.listRoots(ctx, session)
+
+		// Only registered sessions are forgotten when they end, so only their
+		// roots are kept
+		s.sessions.mu.Lock()
+		if _, registered := s.sessions.sessions[session.SessionID()]; registered {
+			s.sessions.declared[session.SessionID()] = declared
+		}
+		s.sessions.mu.Unlock()
+	}
+
+	return s.roots.intersect(declared)
+}
+
+// listRoots asks session's client for its roots. Roots other than synthesis://
+// URIs, such as file:// roots, do not limit the session.
+func (s *Server) listRoots(ctx context.Context, session server.ClientSession) *rootSet {
+	withInfo, ok := session.(server.SessionWithClientInfo)
+	if !ok || withInfo.GetClientCapabilities().Roots == nil {
+		return nil
+	}
+
+	withRoots, ok := session.(server.SessionWithRoots)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, rootsTimeout)
+	defer cancel()
+
+	result, err := withRoots.ListRoots(ctx, mcp.ListRootsRequest{})
+	if err != nil {
+		log.Printf("failed to list roots of MCP session %s: %v", session.SessionID(), err)
+		return nil
+	}
+
+	var uris []string
+	for _, root := range result.Roots {
+		if strings.HasPrefix(root.URI, domain.URIScheme) {
+			uris = append(uris, root.URI)
+		}
+	}
+	if len(uris) == 0 {
+		return nil
+	}
+
+	roots, err := parseRoots(uris)
+	if err != nil {
+		log.Printf("MCP session %s declared %v; admitting nothing", session.SessionID(), err)
+		return &rootSet{}
+	}
+	return roots
+}
+
+// rootsTool is tool handler middleware that rejects calls naming any resource
+// outside the session's roots, by an ID or a URI argument. Calls addressing no
+// resource are rejected too, unless the tool limits its results to the roots itself.
+func (s *Server) rootsTool(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		roots := s.sessionRoots(ctx)
+		if roots == nil {
+			return next(ctx, request)
+		}
+
+		// Every resource the arguments name must lie within the roots
+		_, targets, err := s.callTargets(ctx, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		addressed := false
+		for _, target := range targets {
+			if target == "" {
+				continue
+			}
+			addressed = true
+
+			ref, err := domain.ParseURI(target)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if !roots.contains(ref.URI()) {
+				return mcp.NewToolResultError(fmt.Sprintf("%s is outside the session roots: %s", ref.URI(), roots)), nil
+			}
+		}
+
+		if !addressed && !rootsAwareTools[request.Params.Name] {
+			return mcp.NewToolResultError(fmt.Sprintf("%s must address a resource within the session roots: %s", request.Params.Name, roots)), nil
+		}
+
+		return next(ctx, request)
+	}
+}
+
+// rootsResource is resource handler middleware that rejects reads outside the session's roots
+func (s *Server) rootsResource(next server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		if roots := s.sessionRoots(ctx); !roots.contains(request.Params.URI) {
+			return nil, fmt.Errorf("%s is outside the session roots: %s", request.Params.URI, roots)
+		}
+		return next(ctx, request)
+	}
+}
+
+// notifyResourceUpdated tells each session whose roots contain uri that the resource changed
+func (s *Server) notifyResourceUpdated(ctx context.Context, uri string) {
+	s.sessions.mu.Lock()
+	sessions := make([]server.ClientSession, 0, len(s.sessions.sessions))
+	for _, session := range s.sessions.sessions {
+		sessions = append(sessions, session)
+	}
+	s.sessions.mu.Unlock()
+
+	for _, session := range sessions {
+		if !session.Initialized() {
+			continue
+		}
+		if !s.rootsFor(s.mcpServer.WithContext(ctx, session), session).contains(uri) {
+			continue
+		}
+
+		err := s.mcpServer.SendNotificationToSpecificClient(session.SessionID(), mcp.MethodNotificationResourceUpdated, map[string]any{
+			"uri": uri,
+		})
+		if err != nil {
+			log.Printf("failed to notify MCP session %s: %v", session.SessionID(), err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+const (
+	acme       = "synthesis://tenant/acme"
+	acmeN1     = "synthesis://tenant/acme/notebook/n1"
+	acmeN2     = "synthesis://tenant/acme/notebook/n2"
+	acmeLib    = "synthesis://tenant/acme/library/l1"
+	acmeCorp   = "synthesis://tenant/acmecorp"
+	acmeCorpN1 = "synthesis://tenant/acmecorp/notebook/n1"
+	globex     = "synthesis://tenant/globex"
+	globexN1   = "synthesis://tenant/globex/notebook/n1"
+	markdown   = "synthesis://type/markdown"
+)
+
+func roots(t *testing.T, uris ...string) *rootSet {
+	t.Helper()
+	r, err := parseRoots(uris)
+	if err != nil {
+		t.Fatalf("failed to parse roots %v: %v", uris, err)
+	}
+	return r
+}
+
+func TestParseRoots(t *testing.T) {
+	r := roots(t, acmeN1, acme, acmeN1)
+	if want := []string{acmeN1, acme}; !reflect.DeepEqual(r.list(), want) {
+		t.Errorf("expected %v, got %v", want, r.list())
+	}
+
+	for _, uri := range []string{"file:///home", "synthesis://tenant/acme/", "synthesis://tenant/acme/page/p1", "synthesis://tenant"} {
+		if _, err := parseRoots([]string{uri}); err == nil {
+			t.Errorf("%s: expected an error", uri)
+		}
+	}
+}
+
+func TestRootSetContains(t *testing.T) {
+	tests := []struct {
+		name  string
+		roots *rootSet
+		uri   string
+		want  bool
+	}{
+		{"no roots admit anything", nil, globexN1, true},
+		{"empty roots admit nothing", &rootSet{}, acmeN1, false},
+		{"empty roots admit types", &rootSet{}, markdown, true},
+		{"the root itself", roots(t, acmeN1), acmeN1, true},
+		{"beneath a tenant root", roots(t, acme), acmeN1, true},
+		{"a sibling", roots(t, acmeN1), acmeN2, false},
+		{"a tenant sharing the prefix", roots(t, acme), acmeCorpN1, false},
+		{"a tenant sharing the prefix itself", roots(t, acme), acmeCorp, false},
+		{"a notebook ID sharing the prefix", roots(t, acmeN1), acmeN1 + "0", false},
+		{"the tenant above a notebook root", roots(t, acmeN1), acme, false},
+		{"another tenant", roots(t, acme), globexN1, false},
+		{"any of several roots", roots(t, acmeN1, globex), globexN1, true},
+		{"types lie within every root", roots(t, acmeN1), markdown, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.roots.contains(tc.uri); got != tc.want {
+				t.Errorf("%s contains %s = %v, want %v", tc.roots, tc.uri, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRootSetIntersect(t *testing.T) {
+	tests := []struct {
+		name  string
+		r     *rootSet
+		other *rootSet
+		// want is nil for a set that does not limit the session
+		want []string
+	}{
+		{"neither limits", nil, nil, nil},
+		{"only the configured roots", roots(t, acme), nil, []string{acme}},
+		{"only the declared roots", nil, roots(t, acmeN1), []string{acmeN1}},
+		{"declared beneath configured", roots(t, acme), roots(t, acmeN1), []string{acmeN1}},
+		{"configured beneath declared", roots(t, acmeN1), roots(t, acme), []string{acmeN1}},
+		{"the same roots", roots(t, acmeN1), roots(t, acmeN1), []string{acmeN1}},
+		{"disjoint roots", roots(t, acme), roots(t, globexN1), []string{}},
+		{"a tenant sharing the prefix", roots(t, acme), roots(t, acmeCorpN1), []string{}},
+		{"against empty roots", roots(t, acme), &rootSet{}, []string{}},
+		{"several roots", roots(t, acme, globexN1), roots(t, acmeN1, acmeLib, globex), []string{globexN1, acmeN1, acmeLib}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.r.intersect(tc.other)
+			if tc.want == nil {
+				if got != nil {
+					t.Errorf("expected no roots, got %s", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("expected %v, got no roots", tc.want)
+			}
+			if uris := append([]string{}, got.list()...); !reflect.DeepEqual(uris, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, uris)
+			}
+
+			// Whatever the intersection admits, both sets admit
+			for _, uri := range []string{acme, acmeN1, acmeN2, acmeLib, acmeCorpN1, globex, globexN1} {
+				if got.contains(uri) && !(tc.r.contains(uri) && tc.other.contains(uri)) {
+					t.Errorf("intersection admits %s, which %s or %s does not", uri, tc.r, tc.other)
+				}
+			}
+		})
+	}
+}
+
+func TestRootSetTenant(t *testing.T) {
+	tests := []struct {
+		name  string
+		roots *rootSet
+		want  string
+	}{
+		{"no roots", nil, ""},
+		{"empty roots", &rootSet{}, ""},
+		{"a tenant", roots(t, acme), "acme"},
+		{"a notebook", roots(t, acmeN1), "acme"},
+		{"several resources of a tenant", roots(t, acmeN1, acmeLib, acme), "acme"},
+		{"several tenants", roots(t, acmeN1, globexN1), ""},
+		{"tenants sharing a prefix", roots(t, acme, acmeCorp), ""},
+		{"a type", roots(t, markdown), ""},
+		{"a type and a tenant", roots(t, acmeN1, markdown), ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.roots.tenant(); got != tc.want {
+				t.Errorf("tenant of %s = %q, want %q", tc.roots, got, tc.want)
+			}
+		})
+	}
+}
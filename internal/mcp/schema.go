@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/jsonschema"
+)
+
+// inputSchema returns the input schema of a tool whose arguments are decoded
+// into the type of args, changed by each of edits. Descriptions, required
+// arguments and enums are taken from the type's struct tags, so the schema a
+// client sees is the one its arguments are parsed with. Handlers ignore
+// arguments they do not know, so the schema does not close to them.
+func inputSchema(args interface{}, edits ...func(schema map[string]interface{})) json.RawMessage {
+	schema := jsonschema.Reflect(args)
+	for _, edit := range edits {
+		edit(schema)
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		panic("mcp: input schema: " + err.Error())
+	}
+	return data
+}
+
+// outputSchema returns the output schema of a tool whose structured result is
+// encoded from the type of result, which must encode as a JSON object
+func outputSchema(result interface{}) json.RawMessage {
+	schema := jsonschema.ReflectOutput(result)
+	if schema["type"] != "object" {
+		panic("mcp: output schema: a tool result must be an object")
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		panic("mcp: output schema: " + err.Error())
+	}
+	return data
+}
+
+// properties returns the properties of an object schema
+func properties(schema map[string]interface{}) map[string]interface{} {
+	properties, _ := schema["properties"].(map[string]interface{})
+	return properties
+}
+
+// withKeyword returns an edit of an input schema that sets a keyword of the
+// argument name, for values defined in code rather than in struct tags, e.g.
+// withKeyword("role", "enum", domain.ProductRoles)
+func withKeyword(name, keyword string, value interface{}) func(map[string]interface{}) {
+	return func(schema map[string]interface{}) {
+		if property, ok := properties(schema)[name].(map[string]interface{}); ok {
+			property[keyword] = value
+		}
+	}
+}
+
+// withRequired returns an edit of an input schema that requires the arguments
+// named, for types shared with APIs that take them elsewhere, e.g. a REST path
+func withRequired(names ...string) func(map[string]interface{}) {
+	return func(schema map[string]interface{}) {
+		required, _ := schema["required"].([]string)
+		schema["required"] = append(required, names...)
+	}
+}
+
+// structuredResult returns result as a tool's structured content, with its
+// JSON as the text content for clients that do not read structured content
+func structuredResult(result interface{}) *mcp.CallToolResult {
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err))
+	}
+	return mcp.NewToolResultStructured(result, string(resultBytes))
+}
@@ -0,0 +1,241 @@
+package mcp
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/prismon/synthesis/internal/blob"
+	"github.com/prismon/synthesis/internal/config"
+	"github.com/prismon/synthesis/internal/integration"
+	"github.com/prismon/synthesis/internal/jsonschema"
+	"github.com/prismon/synthesis/internal/postgres"
+	"github.com/prismon/synthesis/internal/quota"
+	"github.com/prismon/synthesis/internal/render"
+	"github.com/prismon/synthesis/internal/validation"
+)
+
+// unreachableDatabase is a database URL nothing listens on
+const unreachableDatabase = "postgres://synthesis@127.0.0.1:1/synthesis?sslmode=disable&connect_timeout=1"
+
+// newSchemaTestServer returns a server built as the MCP command builds it, with
+// every tool registered. Its database is SYNTHESIS_TEST_DATABASE_URL, which the
+// tools may write to, if set; otherwise it refuses connections, so handlers run
+// until their first query and report its failure.
+func newSchemaTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	var db *postgres.DB
+	if url := os.Getenv("SYNTHESIS_TEST_DATABASE_URL"); url != "" {
+		var err error
+		db, err = postgres.New(config.DatabaseConfig{URL: url, MaxOpenConns: 2, MaxIdleConns: 1, ConnMaxLifetime: 60})
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+	} else {
+		connector, err := pq.NewConnector(unreachableDatabase)
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		db = &postgres.DB{DB: sql.OpenDB(connector)}
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store, err := blob.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create blob store: %v", err)
+	}
+
+	types, blobs := postgres.NewTypeRepository(db), postgres.NewBlobRepository(db)
+	s, err := NewServer(db, config.MCPConfig{}, nil, nil,
+		quota.NewEnforcer(postgres.NewQuotaRepository(db), config.QuotaConfig{}),
+		nil,
+		validation.NewValidator(types, blobs, validation.NewRegistry()),
+		render.NewRenderer(types, render.NewRegistry()),
+		blob.NewService(store, blobs, 1<<20),
+		integration.NewService(postgres.NewToolRepository(db), nil, integration.NewRegistry()))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	return s
+}
+
+// call calls a tool's handler with arguments, as the system so that a test
+// database's rows are visible. A panic fails the test.
+func call(t *testing.T, tool *server.ServerTool, arguments map[string]interface{}) *mcp.CallToolResult {
+	t.Helper()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = tool.Tool.Name
+	request.Params.Arguments = arguments
+
+	result, err := tool.Handler(postgres.WithSystemScope(context.Background()), request)
+	if err != nil {
+		t.Fatalf("handler returned an error instead of an error result: %v", err)
+	}
+	if result == nil {
+		t.Fatal("handler returned no result")
+	}
+	return result
+}
+
+// parseError returns the error a result reports decoding its arguments failed
+// with, if any
+func parseError(result *mcp.CallToolResult) string {
+	if !result.IsError {
+		return ""
+	}
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok && strings.HasPrefix(text.Text, "failed to parse arguments") {
+			return text.Text
+		}
+	}
+	return ""
+}
+
+// checkOutput reports a successful result whose structured content is missing
+// or does not meet the tool's output schema
+func checkOutput(t *testing.T, output *jsonschema.Schema, result *mcp.CallToolResult) {
+	t.Helper()
+	if result.IsError {
+		return
+	}
+	if result.StructuredContent == nil {
+		t.Error("result has no structured content")
+		return
+	}
+
+	data, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatalf("failed to marshal structured content: %v", err)
+	}
+	var content interface{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		t.Fatalf("failed to unmarshal structured content: %v", err)
+	}
+	if errs := output.ValidateValue(content); len(errs) > 0 {
+		t.Errorf("structured content %s does not meet the output schema: %v", data, errs)
+	}
+}
+
+// decodedLater lists the arguments handlers decode in a second step, as raw
+// JSON first, so a value of the wrong type does not fail argument parsing
+var decodedLater = map[string]bool{
+	// Decoded as the resource kind names by decodeCandidate
+	"validate_resource.resource": true,
+}
+
+// mismatched returns a value of another type than the schema's, which decoding
+// into the field the schema describes must reject. Schemas of several types
+// describe fields taking any value, which have no such value.
+func mismatched(schema map[string]interface{}) (interface{}, bool) {
+	if types, ok := schema["type"].([]interface{}); ok && len(types) > 1 {
+		return nil, false
+	}
+	switch jsonschema.SampleType(schema) {
+	case "string":
+		return 12345, true
+	case "integer", "number", "boolean", "array", "object":
+		return "mismatched", true
+	}
+	return nil, false
+}
+
+func TestToolSchemasMatchHandlers(t *testing.T) {
+	tools := newSchemaTestServer(t).mcpServer.ListTools()
+	if len(tools) == 0 {
+		t.Fatal("expected tools to be registered")
+	}
+
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		tool := tools[name]
+		t.Run(name, func(t *testing.T) {
+			var schema map[string]interface{}
+			if err := json.Unmarshal(tool.Tool.RawInputSchema, &schema); err != nil {
+				t.Fatalf("invalid input schema: %v", err)
+			}
+			compiled, err := jsonschema.CompileValue(schema)
+			if err != nil {
+				t.Fatalf("input schema does not compile: %v", err)
+			}
+
+			// Results are structured, so each tool declares their schema
+			if len(tool.Tool.RawOutputSchema) == 0 {
+				t.Fatal("no output schema")
+			}
+			var outputSchema map[string]interface{}
+			if err := json.Unmarshal(tool.Tool.RawOutputSchema, &outputSchema); err != nil {
+				t.Fatalf("invalid output schema: %v", err)
+			}
+			output, err := jsonschema.CompileValue(outputSchema)
+			if err != nil {
+				t.Fatalf("output schema does not compile: %v", err)
+			}
+
+			// Handlers ignore unknown arguments, so schemas must not close to them
+			if schema["additionalProperties"] == false {
+				t.Error("input schema sets additionalProperties false, but unknown arguments are ignored")
+			}
+
+			properties, _ := schema["properties"].(map[string]interface{})
+			for _, required := range requiredNames(schema) {
+				if _, ok := properties[required]; !ok {
+					t.Errorf("required argument %s is not a property", required)
+				}
+			}
+
+			definitions, _ := schema["$defs"].(map[string]interface{})
+			sample, _ := jsonschema.Sample(schema, definitions).(map[string]interface{})
+			if errs := compiled.ValidateValue(sample); len(errs) > 0 {
+				t.Fatalf("sample %v does not meet the schema: %v", sample, errs)
+			}
+			result := call(t, tool, sample)
+			if parseErr := parseError(result); parseErr != "" {
+				t.Fatalf("handler does not decode a sample of its schema %v: %s", sample, parseErr)
+			}
+			checkOutput(t, output, result)
+
+			// Each argument the schema describes must be decoded by the handler:
+			// a value of the wrong type fails only if a field receives it
+			for property, value := range properties {
+				propertySchema, _ := value.(map[string]interface{})
+				wrong, ok := mismatched(propertySchema)
+				if !ok || decodedLater[name+"."+property] {
+					continue
+				}
+
+				arguments := map[string]interface{}{}
+				for k, v := range sample {
+					arguments[k] = v
+				}
+				arguments[property] = wrong
+				if parseError(call(t, tool, arguments)) == "" {
+					t.Errorf("argument %s is described but not decoded by the handler", property)
+				}
+			}
+		})
+	}
+}
+
+func requiredNames(schema map[string]interface{}) []string {
+	var names []string
+	required, _ := schema["required"].([]interface{})
+	for _, name := range required {
+		names = append(names, fmt.Sprint(name))
+	}
+	return names
+}
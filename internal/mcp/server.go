@@ -3,40 +3,124 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/prismon/synthesis/internal/audit"
+	"github.com/prismon/synthesis/internal/auth"
+	"github.com/prismon/synthesis/internal/authz"
+	"github.com/prismon/synthesis/internal/blob"
+	"github.com/prismon/synthesis/internal/config"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/integration"
 	"github.com/prismon/synthesis/internal/postgres"
+	"github.com/prismon/synthesis/internal/quota"
+	"github.com/prismon/synthesis/internal/render"
+	"github.com/prismon/synthesis/internal/secrets"
+	"github.com/prismon/synthesis/internal/validation"
 )
 
 // Server represents the Synthesis MCP server
 type Server struct {
 	mcpServer    *server.MCPServer
 	tenantRepo   *postgres.TenantRepository
+	libraryRepo  *postgres.LibraryRepository
 	notebookRepo *postgres.NotebookRepository
 	vectorRepo   *postgres.VectorRepository
 	graphRepo    *postgres.GraphRepository
+	webhookRepo  *postgres.WebhookRepository
+	apiKeyRepo   *postgres.APIKeyRepository
+	auditRepo    *postgres.AuditRepository
+	productRepo  *postgres.ProductRepository
+	quotaRepo    *postgres.QuotaRepository
+	featureRepo  *postgres.FeatureRepository
+	toolRepo     *postgres.ToolRepository
+	typeRepo     *postgres.TypeRepository
+	attrs        *postgres.AuthzRepository
+	auditor      *audit.Recorder
+	authn        *auth.Authenticator
+	authorizer   *authz.Authorizer
+	quotas       *quota.Enforcer
+	sealer       *secrets.Sealer
+	validator    *validation.Validator
+	renderer     *render.Renderer
+	blobs        *blob.Service
+	integrations *integration.Service
+	// roots limits every session to cfg.Roots; nil when it is not configured
+	roots      *rootSet
+	sessions   *sessionRegistry
+	federation *federation
 }
 
-// NewServer creates a new MCP server
-func NewServer(db *postgres.DB) (*Server, error) {
+// NewServer creates a new MCP server. When authn is nil, HTTP requests are not
+// authenticated; when authorizer is nil, tool calls are not checked against policy.
+// Tool calls count against the tenant limits quotas enforces. Sensitive values
+// are sealed with sealer; when it is nil, they cannot be stored. Content is
+// checked against the constraints of its types with validator, notebooks are
+// rendered with renderer, binary block content is stored in blobs, and
+// registered tools are configured and invoked through integrations.
+func NewServer(db *postgres.DB, cfg config.MCPConfig, authn *auth.Authenticator, authorizer *authz.Authorizer, quotas *quota.Enforcer, sealer *secrets.Sealer, validator *validation.Validator, renderer *render.Renderer, blobs *blob.Service, integrations *integration.Service) (*Server, error) {
 	s := &Server{
+		authn:        authn,
+		authorizer:   authorizer,
+		quotas:       quotas,
+		sealer:       sealer,
+		validator:    validator,
+		renderer:     renderer,
+		blobs:        blobs,
+		integrations: integrations,
 		tenantRepo:   postgres.NewTenantRepository(db),
+		libraryRepo:  postgres.NewLibraryRepository(db),
 		notebookRepo: postgres.NewNotebookRepository(db),
 		vectorRepo:   postgres.NewVectorRepository(db),
 		graphRepo:    postgres.NewGraphRepository(db),
+		webhookRepo:  postgres.NewWebhookRepository(db),
+		apiKeyRepo:   postgres.NewAPIKeyRepository(db),
+		auditRepo:    postgres.NewAuditRepository(db),
+		productRepo:  postgres.NewProductRepository(db),
+		quotaRepo:    postgres.NewQuotaRepository(db),
+		featureRepo:  postgres.NewFeatureRepository(db),
+		toolRepo:     postgres.NewToolRepository(db),
+		typeRepo:     postgres.NewTypeRepository(db),
+		attrs:        postgres.NewAuthzRepository(db),
+		sessions:     newSessionRegistry(),
+		federation:   newFederation(),
 	}
+	s.auditor = audit.NewRecorder(s.auditRepo)
 
-	// Create MCP server with capabilities
-	mcpServer := server.NewMCPServer(
-		"Synthesis MCP Server",
-		"1.0.0",
+	if len(cfg.Roots) > 0 {
+		roots, err := parseRoots(cfg.Roots)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MCP_ROOTS: %w", err)
+		}
+		s.roots = roots
+	}
+
+	hooks := &server.Hooks{}
+	s.registerSessionHooks(hooks)
+
+	// Create MCP server with capabilities. Audit runs outermost so that calls
+	// denied by policy or outside the session roots are recorded too.
+	opts := []server.ServerOption{
 		server.WithResourceCapabilities(true, true), // supports list and read
 		server.WithToolCapabilities(true),           // supports tools
-	)
+		server.WithHooks(hooks),
+		server.WithToolFilter(s.filterFederatedTools),
+		server.WithToolHandlerMiddleware(s.auditTool),
+		server.WithToolHandlerMiddleware(s.rootsTool),
+		server.WithResourceHandlerMiddleware(s.rootsResource),
+	}
+	if authorizer != nil {
+		opts = append(opts, server.WithToolHandlerMiddleware(s.authorizeTool))
+	}
+	opts = append(opts, server.WithToolHandlerMiddleware(s.limitTool))
+
+	mcpServer := server.NewMCPServer("Synthesis MCP Server", "1.0.0", opts...)
 
 	s.mcpServer = mcpServer
+	s.mcpServer.AddNotificationHandler(mcp.MethodNotificationRootsListChanged, s.handleRootsListChanged)
 
 	// Register tools
 	s.registerTools()
@@ -50,12 +134,84 @@ func (s *Server) registerTools() {
 	s.mcpServer.AddTool(createTenantTool(), s.handleCreateTenant)
 	s.mcpServer.AddTool(getTenantTool(), s.handleGetTenant)
 
+	// Quota tools
+	s.mcpServer.AddTool(getTenantUsageTool(), s.handleGetTenantUsage)
+	s.mcpServer.AddTool(setTenantQuotaTool(), s.handleSetTenantQuota)
+
 	// Library tools
 	s.mcpServer.AddTool(createLibraryTool(), s.handleCreateLibrary)
+	s.mcpServer.AddTool(listLibrariesTool(), s.handleListLibraries)
 
 	// Notebook tools
 	s.mcpServer.AddTool(createNotebookTool(), s.handleCreateNotebook)
+	s.mcpServer.AddTool(listNotebooksTool(), s.handleListNotebooks)
 	s.mcpServer.AddTool(appendContentBlockTool(), s.handleAppendContentBlock)
+	s.mcpServer.AddTool(renderNotebookTool(), s.handleRenderNotebook)
+
+	// Notebook workflow tools
+	s.mcpServer.AddTool(submitNotebookTool(), s.handleSubmitNotebook)
+	s.mcpServer.AddTool(approveNotebookTool(), s.handleApproveNotebook)
+	s.mcpServer.AddTool(rejectNotebookTool(), s.handleRejectNotebook)
+	s.mcpServer.AddTool(archiveNotebookTool(), s.handleArchiveNotebook)
+	s.mcpServer.AddTool(listNotebookTransitionsTool(), s.handleListNotebookTransitions)
+
+	// Product tools
+	s.mcpServer.AddTool(createProductTool(), s.handleCreateProduct)
+	s.mcpServer.AddTool(getProductTool(), s.handleGetProduct)
+	s.mcpServer.AddTool(listProductsTool(), s.handleListProducts)
+	s.mcpServer.AddTool(updateProductTool(), s.handleUpdateProduct)
+	s.mcpServer.AddTool(deleteProductTool(), s.handleDeleteProduct)
+	s.mcpServer.AddTool(setProductMemberTool(), s.handleSetProductMember)
+	s.mcpServer.AddTool(removeProductMemberTool(), s.handleRemoveProductMember)
+	s.mcpServer.AddTool(attachToProductTool(), s.handleAttachToProduct)
+	s.mcpServer.AddTool(detachFromProductTool(), s.handleDetachFromProduct)
+
+	// Type tools
+	s.mcpServer.AddTool(createTypeTool(), s.handleCreateType)
+	s.mcpServer.AddTool(getTypeTool(), s.handleGetType)
+	s.mcpServer.AddTool(listTypesTool(), s.handleListTypes)
+	s.mcpServer.AddTool(updateTypeTool(), s.handleUpdateType)
+	s.mcpServer.AddTool(deleteTypeTool(), s.handleDeleteType)
+	s.mcpServer.AddTool(listTypeSchemasTool(), s.handleListTypeSchemas)
+	s.mcpServer.AddTool(findInvalidBlocksTool(), s.handleFindInvalidBlocks)
+	s.mcpServer.AddResourceTemplate(typeResourceTemplate(), s.handleReadType)
+
+	// Validation tools
+	s.mcpServer.AddTool(validateResourceTool(), s.handleValidateResource)
+
+	// Feature tools
+	s.mcpServer.AddTool(createFeatureTool(), s.handleCreateFeature)
+	s.mcpServer.AddTool(getFeatureTool(), s.handleGetFeature)
+
+	// Tool registry tools
+	s.mcpServer.AddTool(listToolKindsTool(), s.handleListToolKinds)
+	s.mcpServer.AddTool(createToolTool(), s.handleCreateTool)
+	s.mcpServer.AddTool(getToolTool(), s.handleGetTool)
+	s.mcpServer.AddTool(listToolsTool(), s.handleListTools)
+	s.mcpServer.AddTool(updateToolTool(), s.handleUpdateTool)
+	s.mcpServer.AddTool(deleteToolTool(), s.handleDeleteTool)
+	s.mcpServer.AddTool(invokeToolTool(), s.handleInvokeTool)
+	s.mcpServer.AddTool(listToolInvocationsTool(), s.handleListToolInvocations)
+
+	// Encryption tools
+	s.mcpServer.AddTool(rotateEncryptionKeyTool(), s.handleRotateEncryptionKey)
+
+	// Webhook tools
+	s.mcpServer.AddTool(listWebhookDeliveriesTool(), s.handleListWebhookDeliveries)
+	s.mcpServer.AddTool(retryWebhookDeliveryTool(), s.handleRetryWebhookDelivery)
+	s.mcpServer.AddTool(getWebhookSecretTool(), s.handleGetWebhookSecret)
+	s.mcpServer.AddTool(rotateWebhookSecretTool(), s.handleRotateWebhookSecret)
+
+	// API key tools
+	s.mcpServer.AddTool(createAPIKeyTool(), s.handleCreateAPIKey)
+	s.mcpServer.AddTool(listAPIKeysTool(), s.handleListAPIKeys)
+	s.mcpServer.AddTool(revokeAPIKeyTool(), s.handleRevokeAPIKey)
+
+	// Policy tools
+	s.mcpServer.AddTool(checkPolicyTool(), s.handleCheckPolicy)
+
+	// Audit tools
+	s.mcpServer.AddTool(queryAuditLogTool(), s.handleQueryAuditLog)
 
 	// Search tools
 	s.mcpServer.AddTool(semanticSearchNotebooksTool(), s.handleSemanticSearchNotebooks)
@@ -67,22 +223,129 @@ func (s *Server) Start() error {
 	// Create stdio server
 	stdioServer := server.NewStdioServer(s.mcpServer)
 
-	// Start listening on stdio
-	ctx := context.Background()
+	// Start listening on stdio; whoever runs the process is the local operator
+	ctx := authz.CallerScope(auth.WithLocalOperator(context.Background()))
 	return stdioServer.Listen(ctx, os.Stdin, os.Stdout)
 }
 
+// callTargets returns the action a tool call is authorized as and the URIs of
+// the resources its arguments address; see authz.ResolveTargets. A call of a
+// federated tool is an invoke_tool call of the registered tool behind it.
+func (s *Server) callTargets(ctx context.Context, request mcp.CallToolRequest) (string, []string, error) {
+	if federated, ok := s.federation.tool(request.Params.Name); ok {
+		return invokeToolAction, []string{federated.uri}, nil
+	}
+	targets, err := authz.ResolveTargets(ctx, s.attrs, request.GetArguments())
+	return request.Params.Name, targets, err
+}
+
+// authorizeTool is tool handler middleware that checks each call against policy.
+// The action is the tool name, checked on every resource its arguments address.
+func (s *Server) authorizeTool(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		action, targets, err := s.callTargets(ctx, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		if err := s.authorizer.CheckAll(ctx, action, targets); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return next(ctx, request)
+	}
+}
+
+// limitTool is tool handler middleware that counts each call against the request
+// rate of the tenant it addresses
+func (s *Server) limitTool(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		_, targets, err := s.callTargets(ctx, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		if tenantID := quota.RequestTenant(ctx, authz.PrimaryTarget(targets)); tenantID != "" {
+			if err := s.quotas.Allow(ctx, tenantID); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		return next(ctx, request)
+	}
+}
+
+// auditTool is tool handler middleware that records each call of a tool that is
+// not annotated read-only in the audit log
+func (s *Server) auditTool(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tool := s.mcpServer.GetTool(request.Params.Name)
+		if tool != nil && tool.Tool.Annotations.ReadOnlyHint != nil && *tool.Tool.Annotations.ReadOnlyHint {
+			return next(ctx, request)
+		}
+
+		// A call whose arguments disagree on its target is rejected before it runs
+		_, targets, _ := s.callTargets(ctx, request)
+		action := s.auditor.Begin(ctx, domain.AuditSourceMCP, request.Params.Name, authz.PrimaryTarget(targets))
+
+		result, err := next(ctx, request)
+
+		var failure string
+		switch {
+		case err != nil:
+			failure = err.Error()
+		case result != nil && result.IsError:
+			failure = "tool returned an error"
+			for _, content := range result.Content {
+				if text, ok := content.(mcp.TextContent); ok {
+					failure = text.Text
+					break
+				}
+			}
+		}
+		action.End(ctx, failure)
+
+		return result, err
+	}
+}
+
+// StartHTTP starts the MCP server with the streamable HTTP transport on /mcp.
+// Tool handlers see the authenticated principal, and queries run in its tenant scope.
+func (s *Server) StartHTTP(port int) error {
+	var handler http.Handler = authz.ScopeMiddleware(server.NewStreamableHTTPServer(s.mcpServer))
+	if s.authn != nil {
+		handler = s.authn.Middleware(handler)
+	}
+	handler = audit.RequestIDMiddleware(handler)
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", handler)
+
+	addr := fmt.Sprintf(":%d", port)
+	fmt.Fprintf(os.Stderr, "Starting MCP HTTP server on %s\n", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+// HandleEvent is a live event subscriber that tells connected MCP clients a
+// resource within their roots changed, and follows changes of the downstream
+// MCP servers registered as tools
+func (s *Server) HandleEvent(ctx context.Context, event *domain.Event) error {
+	s.notifyResourceUpdated(ctx, event.URI)
+	s.handleToolEvent(event)
+	return nil
+}
+
 // Example resource handler (not used but shows pattern)
 func (s *Server) handleListResources(ctx context.Context) ([]mcp.Resource, error) {
 	resources := []mcp.Resource{}
 
 	// List all tenants and create resource entries
-	tenants, err := s.tenantRepo.List(ctx)
+	tenants, err := s.tenantRepo.List(ctx, postgres.ListOptions{Limit: postgres.MaxListLimit})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tenants: %w", err)
 	}
 
-	for _, tenant := range tenants {
+	for _, tenant := range tenants.Items {
 		resources = append(resources, mcp.Resource{
 			URI:         tenant.URI(),
 			Name:        tenant.ID,
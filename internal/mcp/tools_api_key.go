@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/auth"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// createAPIKeyArgs are the arguments of the create_api_key tool
+type createAPIKeyArgs struct {
+	TenantID  string     `json:"tenantId" jsonschema:"required" description:"ID of the tenant the key acts in"`
+	Name      string     `json:"name" jsonschema:"required" description:"Name of the key, unique within the tenant, e.g. nightly-feature-import"`
+	Scopes    []string   `json:"scopes" description:"Roles granted to the key within its tenant, e.g. editor (optional; default read-only)"`
+	ExpiresAt *time.Time `json:"expires_at" description:"When the key stops working, RFC 3339 (optional; default never)"`
+}
+
+// createAPIKeyResult is the result of the create_api_key tool
+type createAPIKeyResult struct {
+	APIKey *domain.APIKey `json:"apiKey"`
+	// Key is the secret key, returned only when it is created
+	Key  string `json:"key"`
+	Note string `json:"note"`
+}
+
+// createAPIKeyTool defines the create_api_key tool
+func createAPIKeyTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "create_api_key",
+		Description:     "Create an API key for a machine client of a tenant. The key is returned once; only its hash is stored. Requests made with it act as a service account holding the key's scopes as roles.",
+		RawInputSchema:  inputSchema(createAPIKeyArgs{}),
+		RawOutputSchema: outputSchema(createAPIKeyResult{}),
+	}
+}
+
+// handleCreateAPIKey handles the create_api_key tool invocation
+func (s *Server) handleCreateAPIKey(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args createAPIKeyArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	key, secret, err := auth.NewAPIKey(args.TenantID, args.Name, args.Scopes, args.ExpiresAt, auth.IdentityFromContext(ctx, ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if _, err := s.tenantRepo.Get(ctx, args.TenantID); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get tenant: %v", err)), nil
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create API key: %v", err)), nil
+	}
+
+	result := createAPIKeyResult{
+		APIKey: key,
+		Key:    secret,
+		Note:   "Store the key now; it cannot be shown again",
+	}
+
+	return structuredResult(result), nil
+}
+
+// listAPIKeysResult is the result of the list_api_keys tool
+type listAPIKeysResult struct {
+	APIKeys []*domain.APIKey `json:"apiKeys"`
+}
+
+// listAPIKeysTool defines the list_api_keys tool
+func listAPIKeysTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "list_api_keys",
+		Description:     "List a tenant's API keys with their prefixes, scopes, expiry and last use",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(tenantArgs{}),
+		RawOutputSchema: outputSchema(listAPIKeysResult{}),
+	}
+}
+
+// handleListAPIKeys handles the list_api_keys tool invocation
+func (s *Server) handleListAPIKeys(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args tenantArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if args.TenantID == "" {
+		return mcp.NewToolResultError("tenantId is required"), nil
+	}
+
+	keys, err := s.apiKeyRepo.List(ctx, args.TenantID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list API keys: %v", err)), nil
+	}
+
+	if keys == nil {
+		keys = []*domain.APIKey{}
+	}
+
+	return structuredResult(listAPIKeysResult{APIKeys: keys}), nil
+}
+
+// revokeAPIKeyArgs are the arguments of the revoke_api_key tool
+type revokeAPIKeyArgs struct {
+	TenantID string `json:"tenantId" jsonschema:"required" description:"ID of the tenant"`
+	KeyID    string `json:"keyId" jsonschema:"required" description:"ID of the API key"`
+}
+
+// revokeAPIKeyResult is the result of the revoke_api_key tool
+type revokeAPIKeyResult struct {
+	KeyID   string `json:"keyId"`
+	Message string `json:"message"`
+}
+
+// revokeAPIKeyTool defines the revoke_api_key tool
+func revokeAPIKeyTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "revoke_api_key",
+		Description:     "Revoke a tenant's API key. Requests made with it are rejected from then on.",
+		RawInputSchema:  inputSchema(revokeAPIKeyArgs{}),
+		RawOutputSchema: outputSchema(revokeAPIKeyResult{}),
+	}
+}
+
+// handleRevokeAPIKey handles the revoke_api_key tool invocation
+func (s *Server) handleRevokeAPIKey(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args revokeAPIKeyArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if err := s.apiKeyRepo.Revoke(ctx, args.TenantID, args.KeyID); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to revoke API key: %v", err)), nil
+	}
+
+	return structuredResult(revokeAPIKeyResult{KeyID: args.KeyID, Message: "API key revoked"}), nil
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// queryAuditLogArgs are the arguments of the query_audit_log tool
+type queryAuditLogArgs struct {
+	TenantID  string     `json:"tenantId" description:"Only show entries for this tenant (optional)"`
+	Principal string     `json:"principal" description:"Only show entries by this principal (optional)"`
+	Action    string     `json:"action" description:"Only show this action, a tool name or REST method and route template (optional)"`
+	TargetURI string     `json:"target_uri" description:"Only show entries on this resource URI or the resources beneath it (optional)"`
+	Outcome   string     `json:"outcome" description:"Only show entries with this outcome (optional)"`
+	Since     *time.Time `json:"since" description:"Only show entries at or after this time, RFC 3339 (optional)"`
+	Until     *time.Time `json:"until" description:"Only show entries before this time, RFC 3339 (optional)"`
+	BeforeSeq int64      `json:"before_seq" description:"Only show entries older than this sequence number, to page back (optional)"`
+	Limit     int        `json:"limit" jsonschema:"default=100" description:"Maximum number of entries (default 100)"`
+}
+
+// queryAuditLogResult is the result of the query_audit_log tool
+type queryAuditLogResult struct {
+	Entries []*domain.AuditEntry `json:"entries"`
+}
+
+// queryAuditLogTool defines the query_audit_log tool
+func queryAuditLogTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "query_audit_log",
+		Description: "Query the audit log of mutating tool calls and API requests, newest first",
+		Annotations: mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema: inputSchema(queryAuditLogArgs{},
+			withKeyword("outcome", "enum", []string{domain.AuditOutcomeSucceeded, domain.AuditOutcomeFailed})),
+		RawOutputSchema: outputSchema(queryAuditLogResult{}),
+	}
+}
+
+// handleQueryAuditLog handles the query_audit_log tool invocation
+func (s *Server) handleQueryAuditLog(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args queryAuditLogArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	// A session limited by roots only sees entries on resources within them
+	roots := s.sessionRoots(ctx)
+	if roots != nil {
+		if args.TargetURI != "" && !roots.contains(args.TargetURI) {
+			return mcp.NewToolResultError(fmt.Sprintf("%s is outside the session roots: %s", args.TargetURI, roots)), nil
+		}
+		if args.TargetURI == "" && len(roots.list()) == 1 {
+			args.TargetURI = roots.list()[0]
+		}
+	}
+
+	entries, err := s.auditRepo.List(ctx, postgres.AuditFilter{
+		TenantID:       args.TenantID,
+		Principal:      args.Principal,
+		Action:         args.Action,
+		URIPrefix:      args.TargetURI,
+		Outcome:        args.Outcome,
+		Since:          args.Since,
+		Until:          args.Until,
+		BeforeSequence: args.BeforeSeq,
+		Limit:          args.Limit,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to query audit log: %v", err)), nil
+	}
+
+	visible := []*domain.AuditEntry{}
+	for _, entry := range entries {
+		if roots.contains(entry.TargetURI) {
+			visible = append(visible, entry)
+		}
+	}
+
+	return structuredResult(queryAuditLogResult{Entries: visible}), nil
+}
@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/postgres"
+	"github.com/prismon/synthesis/internal/secrets"
+)
+
+// revealAction is the action policies must allow for a caller to see sensitive values decrypted
+const revealAction = "reveal_secrets"
+
+// checkReveal returns an error unless the caller on ctx may see the sensitive
+// values of the resource at uri. Without an authorizer, anyone may.
+func (s *Server) checkReveal(ctx context.Context, uri string) error {
+	if s.authorizer == nil {
+		return nil
+	}
+	return s.authorizer.Check(ctx, revealAction, uri)
+}
+
+// rotateEncryptionKeyResult is the result of the rotate_encryption_key tool
+type rotateEncryptionKeyResult struct {
+	FeaturesRewrapped int    `json:"features_rewrapped"`
+	ToolsRewrapped    int    `json:"tools_rewrapped"`
+	Message           string `json:"message"`
+}
+
+// rotateEncryptionKeyTool defines the rotate_encryption_key tool
+func rotateEncryptionKeyTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "rotate_encryption_key",
+		Description:     "Rewrap the data keys of every sensitive feature value and tool secret with the current master key. Run after making a new key current in the key file; the old key can be removed once this succeeds.",
+		RawInputSchema:  inputSchema(struct{}{}),
+		RawOutputSchema: outputSchema(rotateEncryptionKeyResult{}),
+	}
+}
+
+// handleRotateEncryptionKey handles the rotate_encryption_key tool invocation
+func (s *Server) handleRotateEncryptionKey(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.sealer == nil {
+		return mcp.NewToolResultError(secrets.ErrNotConfigured.Error()), nil
+	}
+
+	// Rotation spans every tenant
+	ctx = postgres.WithSystemScope(ctx)
+
+	features, err := s.featureRepo.RewrapSensitive(ctx, s.sealer.Rewrap)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to rewrap feature values: %v", err)), nil
+	}
+
+	tools, err := s.toolRepo.RewrapSensitive(ctx, s.sealer.Rewrap)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to rewrap tool secrets: %v", err)), nil
+	}
+
+	result := rotateEncryptionKeyResult{
+		FeaturesRewrapped: features,
+		ToolsRewrapped:    tools,
+		Message:           "Sensitive values rewrapped with the current key",
+	}
+
+	return structuredResult(result), nil
+}
@@ -0,0 +1,156 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// createFeatureArgs are the arguments of the create_feature tool
+type createFeatureArgs struct {
+	TenantID      string              `json:"tenantId" jsonschema:"required" description:"ID of the tenant"`
+	FeatureID     string              `json:"featureId" jsonschema:"required" description:"Unique identifier for the feature"`
+	DisplayName   string              `json:"display_name" jsonschema:"required" description:"Display name for the feature"`
+	Description   string              `json:"description" description:"Description of the feature"`
+	Values        map[string]string   `json:"values" description:"The feature's values"`
+	SensitiveKeys []string            `json:"sensitive_keys" description:"Keys of the values to store encrypted, such as personal data (optional)"`
+	ValueTypes    map[string][]string `json:"value_types" description:"For each value key, the types whose constraints the value must meet (optional)"`
+	Resources     []string            `json:"resources" description:"URLs of the external resources the feature describes (optional)"`
+	Notifications []string            `json:"notifications" description:"URLs notified of changes to the feature (optional)"`
+	TTLSeconds    int64               `json:"ttl_seconds" jsonschema:"minimum=0" description:"Seconds until the feature expires; 0 for never (optional)"`
+}
+
+// createFeatureResult is the result of the create_feature tool
+type createFeatureResult struct {
+	FeatureURI string `json:"featureUri"`
+	FeatureID  string `json:"featureId"`
+	Message    string `json:"message"`
+}
+
+// createFeatureTool defines the create_feature tool
+func createFeatureTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "create_feature",
+		Description:     "Create a feature: derived data about external resources. Values named in sensitive_keys are stored encrypted and redacted when read.",
+		RawInputSchema:  inputSchema(createFeatureArgs{}),
+		RawOutputSchema: outputSchema(createFeatureResult{}),
+	}
+}
+
+// handleCreateFeature handles the create_feature tool invocation
+func (s *Server) handleCreateFeature(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args createFeatureArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if args.TTLSeconds < 0 {
+		return mcp.NewToolResultError("ttl_seconds must not be negative"), nil
+	}
+
+	if err := s.quotas.CheckFeatures(ctx, args.TenantID, 1); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	feature := &domain.Feature{
+		TenantID:      args.TenantID,
+		ID:            args.FeatureID,
+		DisplayName:   args.DisplayName,
+		Description:   args.Description,
+		TTL:           time.Duration(args.TTLSeconds) * time.Second,
+		Values:        args.Values,
+		SensitiveKeys: args.SensitiveKeys,
+		ValueTypes:    args.ValueTypes,
+	}
+	if feature.Values == nil {
+		feature.Values = map[string]string{}
+	}
+	for _, url := range args.Resources {
+		feature.Resources = append(feature.Resources, domain.ExternalResource{URL: url})
+	}
+	for _, url := range args.Notifications {
+		feature.Notifications = append(feature.Notifications, domain.Notification{URL: url})
+	}
+
+	if err := s.validator.ValidateFeature(ctx, feature); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid feature: %v", err)), nil
+	}
+
+	// Values are validated before they are sealed
+	if err := s.sealer.SealValues(ctx, feature.URI(), feature.Values, feature.SensitiveKeys); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encrypt feature values: %v", err)), nil
+	}
+
+	if err := s.featureRepo.Create(ctx, feature); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create feature: %v", err)), nil
+	}
+
+	result := createFeatureResult{
+		FeatureURI: feature.URI(),
+		FeatureID:  feature.ID,
+		Message:    "Feature created successfully",
+	}
+
+	return structuredResult(result), nil
+}
+
+// getFeatureArgs are the arguments of the get_feature tool
+type getFeatureArgs struct {
+	FeatureID string `json:"featureId" jsonschema:"required" description:"ID of the feature"`
+	Reveal    bool   `json:"reveal" description:"Decrypt sensitive values instead of redacting them; requires the reveal_secrets permission (optional)"`
+}
+
+// getFeatureTool defines the get_feature tool
+func getFeatureTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "get_feature",
+		Description:     "Get a feature with its resources and notifications. Sensitive values are redacted unless reveal is set and the caller may reveal them.",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(getFeatureArgs{}),
+		RawOutputSchema: outputSchema(domain.Feature{}),
+	}
+}
+
+// handleGetFeature handles the get_feature tool invocation
+func (s *Server) handleGetFeature(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args getFeatureArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	feature, err := s.featureRepo.Get(ctx, args.FeatureID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get feature: %v", err)), nil
+	}
+
+	if args.Reveal {
+		if err := s.checkReveal(ctx, feature.URI()); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if err := s.sealer.OpenValues(ctx, feature.URI(), feature.Values, feature.SensitiveKeys); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to decrypt feature values: %v", err)), nil
+		}
+	} else {
+		feature = feature.Redacted()
+	}
+
+	return structuredResult(feature), nil
+}
@@ -5,99 +5,157 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/google/uuid"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/auth"
 	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
 )
 
+// createLibraryArgs are the arguments of the create_library tool
+type createLibraryArgs struct {
+	TenantID    string `json:"tenantId" jsonschema:"required" description:"ID of the tenant"`
+	LibraryID   string `json:"libraryId" jsonschema:"required" description:"Unique identifier for the library"`
+	Owner       string `json:"owner" jsonschema:"required" description:"Email address of the library owner"`
+	DisplayName string `json:"display_name" jsonschema:"required" description:"Display name for the library"`
+	Description string `json:"description" description:"Description of the library"`
+}
+
+// createLibraryResult is the result of the create_library tool
+type createLibraryResult struct {
+	LibraryURI string `json:"libraryUri"`
+	LibraryID  string `json:"libraryId"`
+	Message    string `json:"message"`
+}
+
 // createLibraryTool defines the create_library tool
 func createLibraryTool() mcp.Tool {
 	return mcp.Tool{
-		Name:        "create_library",
-		Description: "Create a new library in a tenant",
-		InputSchema: mcp.ToolInputSchema{
-			Type: "object",
-			Properties: map[string]interface{}{
-				"tenantId": map[string]interface{}{
-					"type":        "string",
-					"description": "ID of the tenant",
-				},
-				"libraryId": map[string]interface{}{
-					"type":        "string",
-					"description": "Unique identifier for the library",
-				},
-				"owner": map[string]interface{}{
-					"type":        "string",
-					"description": "Email address of the library owner",
-				},
-				"display_name": map[string]interface{}{
-					"type":        "string",
-					"description": "Display name for the library",
-				},
-				"description": map[string]interface{}{
-					"type":        "string",
-					"description": "Description of the library",
-				},
-			},
-			Required: []string{"tenantId", "libraryId", "owner", "display_name"},
-		},
+		Name:            "create_library",
+		Description:     "Create a new library in a tenant",
+		RawInputSchema:  inputSchema(createLibraryArgs{}),
+		RawOutputSchema: outputSchema(createLibraryResult{}),
 	}
 }
 
-// handleCreateLibrary handles the create_library tool invocation
+// handleCreateLibrary handles the create_library tool invocation. An
+// authenticated caller owns the library, as over the REST API.
 func (s *Server) handleCreateLibrary(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Placeholder implementation
-	return mcp.NewToolResultText(`{"message": "Library creation not yet implemented"}`), nil
+	// Parse arguments
+	var args createLibraryArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	library := &domain.Library{
+		TenantID:    args.TenantID,
+		ID:          args.LibraryID,
+		Owner:       auth.IdentityFromContext(ctx, args.Owner),
+		DisplayName: args.DisplayName,
+		Description: args.Description,
+	}
+
+	if err := s.validator.ValidateLibrary(library); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if _, err := s.tenantRepo.Get(ctx, library.TenantID); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get tenant: %v", err)), nil
+	}
+
+	if err := s.libraryRepo.Create(ctx, library); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create library: %v", err)), nil
+	}
+
+	result := createLibraryResult{
+		LibraryURI: library.URI(),
+		LibraryID:  library.ID,
+		Message:    "Library created successfully",
+	}
+
+	return structuredResult(result), nil
+}
+
+// listLibrariesArgs are the arguments of the list_libraries tool
+type listLibrariesArgs struct {
+	TenantID string `json:"tenantId" jsonschema:"required" description:"ID of the tenant"`
+	listArgs
+}
+
+// listLibrariesTool defines the list_libraries tool
+func listLibrariesTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "list_libraries",
+		Description:     "List a page of the libraries of a tenant, newest first unless sorted otherwise",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(listLibrariesArgs{}, withListProperties(postgres.LibrarySorts(), filterLabels, filterOwner)),
+		RawOutputSchema: outputSchema(domain.Page[*domain.Library]{}),
+	}
+}
+
+// handleListLibraries handles the list_libraries tool invocation
+func (s *Server) handleListLibraries(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args listLibrariesArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if args.TenantID == "" {
+		return mcp.NewToolResultError("tenantId is required"), nil
+	}
+
+	libraries, err := s.libraryRepo.ListByTenant(ctx, args.TenantID, args.options())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list libraries: %v", err)), nil
+	}
+
+	return structuredResult(libraries), nil
+}
+
+// createNotebookArgs are the arguments of the create_notebook tool
+type createNotebookArgs struct {
+	TenantID        string `json:"tenantId" jsonschema:"required" description:"ID of the tenant"`
+	LibraryID       string `json:"libraryId" jsonschema:"required" description:"ID of the library"`
+	NotebookID      string `json:"notebookId" jsonschema:"required" description:"Unique identifier for the notebook"`
+	DisplayName     string `json:"display_name" jsonschema:"required" description:"Display name for the notebook"`
+	Description     string `json:"description" description:"Description of the notebook"`
+	InitialMarkdown string `json:"initial_markdown" description:"Initial markdown content"`
+}
+
+// createNotebookResult is the result of the create_notebook tool
+type createNotebookResult struct {
+	NotebookURI string `json:"notebookUri"`
+	NotebookID  string `json:"notebookId"`
+	Message     string `json:"message"`
 }
 
 // createNotebookTool defines the create_notebook tool
 func createNotebookTool() mcp.Tool {
 	return mcp.Tool{
-		Name:        "create_notebook",
-		Description: "Create a new notebook in a library",
-		InputSchema: mcp.ToolInputSchema{
-			Type: "object",
-			Properties: map[string]interface{}{
-				"tenantId": map[string]interface{}{
-					"type":        "string",
-					"description": "ID of the tenant",
-				},
-				"libraryId": map[string]interface{}{
-					"type":        "string",
-					"description": "ID of the library",
-				},
-				"notebookId": map[string]interface{}{
-					"type":        "string",
-					"description": "Unique identifier for the notebook",
-				},
-				"display_name": map[string]interface{}{
-					"type":        "string",
-					"description": "Display name for the notebook",
-				},
-				"description": map[string]interface{}{
-					"type":        "string",
-					"description": "Description of the notebook",
-				},
-				"initial_markdown": map[string]interface{}{
-					"type":        "string",
-					"description": "Initial markdown content",
-				},
-			},
-			Required: []string{"tenantId", "libraryId", "notebookId", "display_name"},
-		},
+		Name:            "create_notebook",
+		Description:     "Create a new notebook in a library",
+		RawInputSchema:  inputSchema(createNotebookArgs{}),
+		RawOutputSchema: outputSchema(createNotebookResult{}),
 	}
 }
 
 // handleCreateNotebook handles the create_notebook tool invocation
 func (s *Server) handleCreateNotebook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Parse arguments
-	var args struct {
-		TenantID        string `json:"tenantId"`
-		LibraryID       string `json:"libraryId"`
-		NotebookID      string `json:"notebookId"`
-		DisplayName     string `json:"display_name"`
-		Description     string `json:"description"`
-		InitialMarkdown string `json:"initial_markdown"`
-	}
+	var args createNotebookArgs
 
 	argsBytes, err := json.Marshal(request.Params.Arguments)
 	if err != nil {
@@ -108,13 +166,20 @@ func (s *Server) handleCreateNotebook(ctx context.Context, request mcp.CallToolR
 		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
 	}
 
+	if err := s.quotas.CheckNotebooks(ctx, args.TenantID, 1); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := s.quotas.CheckContentBytes(ctx, args.TenantID, int64(len(args.InitialMarkdown))); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	// Create notebook
 	notebook := &domain.Notebook{
 		TenantID:    args.TenantID,
 		ID:          args.NotebookID,
 		LibraryID:   args.LibraryID,
-		Status:      "draft",
-		Owner:       "system", // TODO: Get from auth context
+		Status:      domain.NotebookStatusDraft,
+		Owner:       auth.IdentityFromContext(ctx, "system"),
 		DisplayName: args.DisplayName,
 		Description: args.Description,
 		Contents: domain.NotebookContents{
@@ -131,52 +196,153 @@ func (s *Server) handleCreateNotebook(ctx context.Context, request mcp.CallToolR
 	}
 
 	// Return success response
-	result := map[string]interface{}{
-		"notebookUri": notebook.URI(),
-		"notebookId":  notebook.ID,
-		"message":     "Notebook created successfully",
+	result := createNotebookResult{
+		NotebookURI: notebook.URI(),
+		NotebookID:  notebook.ID,
+		Message:     "Notebook created successfully",
+	}
+
+	return structuredResult(result), nil
+}
+
+// listNotebooksArgs are the arguments of the list_notebooks tool
+type listNotebooksArgs struct {
+	LibraryID string `json:"libraryId" jsonschema:"required" description:"ID of the library"`
+	listArgs
+}
+
+// listNotebooksTool defines the list_notebooks tool
+func listNotebooksTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "list_notebooks",
+		Description:     "List a page of the notebooks in a library without their contents, newest first unless sorted otherwise",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(listNotebooksArgs{}, withListProperties(postgres.NotebookSorts(), filterOwner, filterStatus)),
+		RawOutputSchema: outputSchema(domain.Page[*domain.Notebook]{}),
 	}
+}
+
+// handleListNotebooks handles the list_notebooks tool invocation
+func (s *Server) handleListNotebooks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args listNotebooksArgs
 
-	resultBytes, err := json.Marshal(result)
+	argsBytes, err := json.Marshal(request.Params.Arguments)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if args.LibraryID == "" {
+		return mcp.NewToolResultError("libraryId is required"), nil
+	}
+
+	notebooks, err := s.notebookRepo.ListByLibrary(ctx, args.LibraryID, args.options())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list notebooks: %v", err)), nil
+	}
+
+	return structuredResult(notebooks), nil
+}
+
+// appendContentBlockArgs are the arguments of the append_content_block tool
+type appendContentBlockArgs struct {
+	NotebookID  string   `json:"notebookId" jsonschema:"required" description:"ID of the notebook"`
+	ContentType string   `json:"content_type" jsonschema:"required" description:"Content type (e.g., text/markdown, binary/image)"`
+	Data        string   `json:"data" jsonschema:"required" description:"Content data"`
+	ParentUID   *string  `json:"parent_uid" description:"UID of parent block (optional)"`
+	UID         string   `json:"uid" description:"UID of the new block (optional, generated if omitted)"`
+	Types       []string `json:"types" description:"Type names of the block (optional)"`
+}
+
+// appendContentBlockResult is the result of the append_content_block tool
+type appendContentBlockResult struct {
+	UID      string `json:"uid"`
+	Revision int64  `json:"revision"`
+	Message  string `json:"message"`
 }
 
 // appendContentBlockTool defines the append_content_block tool
 func appendContentBlockTool() mcp.Tool {
 	return mcp.Tool{
-		Name:        "append_content_block",
-		Description: "Append a content block to a notebook",
-		InputSchema: mcp.ToolInputSchema{
-			Type: "object",
-			Properties: map[string]interface{}{
-				"notebookId": map[string]interface{}{
-					"type":        "string",
-					"description": "ID of the notebook",
-				},
-				"content_type": map[string]interface{}{
-					"type":        "string",
-					"description": "Content type (e.g., text/markdown, binary/image)",
-				},
-				"data": map[string]interface{}{
-					"type":        "string",
-					"description": "Content data",
-				},
-				"parent_uid": map[string]interface{}{
-					"type":        "string",
-					"description": "UID of parent block (optional)",
-				},
-			},
-			Required: []string{"notebookId", "content_type", "data"},
-		},
+		Name:            "append_content_block",
+		Description:     "Append a content block to a notebook. The edit is sequenced with concurrent collaborative edits and broadcast to everyone editing the notebook.",
+		RawInputSchema:  inputSchema(appendContentBlockArgs{}),
+		RawOutputSchema: outputSchema(appendContentBlockResult{}),
 	}
 }
 
 // handleAppendContentBlock handles the append_content_block tool invocation
 func (s *Server) handleAppendContentBlock(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Placeholder implementation
-	return mcp.NewToolResultText(`{"message": "Append content block not yet implemented"}`), nil
+	var args appendContentBlockArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if args.UID == "" {
+		args.UID = uuid.New().String()
+	}
+
+	op := &domain.BlockOperation{
+		ID:          uuid.New().String(),
+		NotebookID:  args.NotebookID,
+		Type:        domain.BlockOpInsert,
+		BlockUID:    args.UID,
+		ParentUID:   args.ParentUID,
+		ContentType: args.ContentType,
+		Data:        args.Data,
+		Types:       args.Types,
+		Actor:       auth.IdentityFromContext(ctx, "system"),
+	}
+
+	if err := op.Validate(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid content block: %v", err)), nil
+	}
+
+	tenantID, err := s.attrs.TenantOf(postgres.WithSystemScope(ctx), "notebook", args.NotebookID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to append content block: %v", err)), nil
+	}
+	if err := s.quotas.CheckContentBytes(ctx, tenantID, int64(len(args.Data))); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Binary content is stored as a blob the block references, once the block
+	// is known to be valid
+	pending, err := s.blobs.Prepare(ctx, tenantID, op.ContentType, op.Data)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to store content block data: %v", err)), nil
+	}
+	op.Data = pending.Data
+	if err := s.validator.ValidateOperation(ctx, op, pending.Blob); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid content block: %v", err)), nil
+	}
+	if err := s.blobs.Offload(ctx, pending); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to store content block data: %v", err)), nil
+	}
+
+	if err := s.notebookRepo.ApplyBlockOperation(ctx, op); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to append content block: %v", err)), nil
+	}
+
+	if op.Status == domain.BlockOpDiscarded {
+		return mcp.NewToolResultError(fmt.Sprintf("content block not appended: %s", op.Reason)), nil
+	}
+
+	result := appendContentBlockResult{
+		UID:      op.BlockUID,
+		Revision: op.Revision,
+		Message:  "Content block appended successfully",
+	}
+
+	return structuredResult(result), nil
 }
@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// checkPolicyArgs are the arguments of the check_policy tool
+type checkPolicyArgs struct {
+	Action string `json:"action" jsonschema:"required" description:"Action to check: a tool name (e.g. approve_notebook), a REST method and route template (e.g. DELETE /api/v1/libraries/{id}), or subscribe/edit"`
+	URI    string `json:"uri" jsonschema:"required" description:"synthesis:// URI of the target resource, e.g. synthesis://tenant/acme/notebook/n1"`
+}
+
+// checkPolicyResult is the result of the check_policy tool
+type checkPolicyResult struct {
+	Action  string   `json:"action"`
+	URI     string   `json:"uri"`
+	Allow   bool     `json:"allow"`
+	Reasons []string `json:"reasons"`
+}
+
+// checkPolicyTool defines the check_policy tool
+func checkPolicyTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "check_policy",
+		Description:     "Check whether the caller may perform an action on a resource, without performing it. Returns allow or deny with the policy reasons.",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(checkPolicyArgs{}),
+		RawOutputSchema: outputSchema(checkPolicyResult{}),
+	}
+}
+
+// handleCheckPolicy handles the check_policy tool invocation
+func (s *Server) handleCheckPolicy(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args checkPolicyArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if args.Action == "" || args.URI == "" {
+		return mcp.NewToolResultError("action and uri are required"), nil
+	}
+
+	if _, err := domain.ParseURI(args.URI); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := checkPolicyResult{
+		Action: args.Action,
+		URI:    args.URI,
+	}
+
+	if s.authorizer == nil {
+		result.Allow = true
+		result.Reasons = []string{"authorization is disabled (AUTHZ_DISABLED is set)"}
+	} else {
+		decision, err := s.authorizer.Authorize(ctx, args.Action, args.URI)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to check policy: %v", err)), nil
+		}
+		result.Allow = decision.Allow
+		result.Reasons = decision.Reasons
+	}
+
+	return structuredResult(result), nil
+}
@@ -0,0 +1,414 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/auth"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// createProductArgs are the arguments of the create_product tool
+type createProductArgs struct {
+	TenantID    string `json:"tenantId" jsonschema:"required" description:"ID of the tenant"`
+	ProductID   string `json:"productId" jsonschema:"required" description:"Unique identifier for the product"`
+	DisplayName string `json:"display_name" jsonschema:"required" description:"Display name for the product"`
+	Description string `json:"description" description:"Description of the product"`
+	Owner       string `json:"owner" description:"First owner of the product; ignored for authenticated callers, who become the owner (optional)"`
+}
+
+// createProductResult is the result of the create_product tool
+type createProductResult struct {
+	ProductURI string               `json:"productUri"`
+	ProductID  string               `json:"productId"`
+	Members    []domain.ProductUser `json:"members"`
+	Message    string               `json:"message"`
+}
+
+// createProductTool defines the create_product tool
+func createProductTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "create_product",
+		Description:     "Create a product in a tenant. The caller becomes its first owner.",
+		RawInputSchema:  inputSchema(createProductArgs{}),
+		RawOutputSchema: outputSchema(createProductResult{}),
+	}
+}
+
+// handleCreateProduct handles the create_product tool invocation
+func (s *Server) handleCreateProduct(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args createProductArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if args.TenantID == "" || args.ProductID == "" || args.DisplayName == "" {
+		return mcp.NewToolResultError("tenantId, productId and display_name are required"), nil
+	}
+
+	product := &domain.Product{
+		TenantID:    args.TenantID,
+		ID:          args.ProductID,
+		DisplayName: args.DisplayName,
+		Description: args.Description,
+	}
+
+	if err := s.productRepo.Create(ctx, product, auth.IdentityFromContext(ctx, args.Owner)); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create product: %v", err)), nil
+	}
+
+	result := createProductResult{
+		ProductURI: product.URI(),
+		ProductID:  product.ID,
+		Members:    product.Members,
+		Message:    "Product created successfully",
+	}
+
+	return structuredResult(result), nil
+}
+
+// productArgs are the arguments of a tool that addresses a product
+type productArgs struct {
+	ProductID string `json:"productId" jsonschema:"required" description:"ID of the product"`
+}
+
+// productResult is the result of a tool that changed a product
+type productResult struct {
+	ProductID string `json:"productId"`
+	Message   string `json:"message"`
+}
+
+// getProductTool defines the get_product tool
+func getProductTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "get_product",
+		Description:     "Get a product with its members and the notebooks and features attached to it",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(productArgs{}),
+		RawOutputSchema: outputSchema(domain.Product{}),
+	}
+}
+
+// handleGetProduct handles the get_product tool invocation
+func (s *Server) handleGetProduct(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args productArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	product, err := s.productRepo.Get(ctx, args.ProductID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get product: %v", err)), nil
+	}
+
+	return structuredResult(product), nil
+}
+
+// listProductsArgs are the arguments of the list_products tool
+type listProductsArgs struct {
+	TenantID string `json:"tenantId" jsonschema:"required" description:"ID of the tenant"`
+	listArgs
+}
+
+// listProductsTool defines the list_products tool
+func listProductsTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "list_products",
+		Description:     "List a page of the products of a tenant, by display name unless sorted otherwise",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(listProductsArgs{}, withListProperties(postgres.ProductSorts())),
+		RawOutputSchema: outputSchema(domain.Page[*domain.Product]{}),
+	}
+}
+
+// handleListProducts handles the list_products tool invocation
+func (s *Server) handleListProducts(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args listProductsArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if args.TenantID == "" {
+		return mcp.NewToolResultError("tenantId is required"), nil
+	}
+
+	products, err := s.productRepo.ListByTenant(ctx, args.TenantID, args.options())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list products: %v", err)), nil
+	}
+
+	return structuredResult(products), nil
+}
+
+// updateProductArgs are the arguments of the update_product tool
+type updateProductArgs struct {
+	ProductID   string  `json:"productId" jsonschema:"required" description:"ID of the product"`
+	DisplayName *string `json:"display_name" description:"New display name (optional)"`
+	Description *string `json:"description" description:"New description (optional)"`
+}
+
+// updateProductTool defines the update_product tool
+func updateProductTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "update_product",
+		Description:     "Change a product's display name or description",
+		RawInputSchema:  inputSchema(updateProductArgs{}),
+		RawOutputSchema: outputSchema(domain.Product{}),
+	}
+}
+
+// handleUpdateProduct handles the update_product tool invocation
+func (s *Server) handleUpdateProduct(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args updateProductArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	product, err := s.productRepo.Get(ctx, args.ProductID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get product: %v", err)), nil
+	}
+
+	if args.DisplayName != nil {
+		if *args.DisplayName == "" {
+			return mcp.NewToolResultError("display_name cannot be empty"), nil
+		}
+		product.DisplayName = *args.DisplayName
+	}
+	if args.Description != nil {
+		product.Description = *args.Description
+	}
+
+	if err := s.productRepo.Update(ctx, product); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to update product: %v", err)), nil
+	}
+
+	return structuredResult(product), nil
+}
+
+// deleteProductTool defines the delete_product tool
+func deleteProductTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "delete_product",
+		Description:     "Delete a product with its memberships. Attached notebooks and features are kept.",
+		RawInputSchema:  inputSchema(productArgs{}),
+		RawOutputSchema: outputSchema(productResult{}),
+	}
+}
+
+// handleDeleteProduct handles the delete_product tool invocation
+func (s *Server) handleDeleteProduct(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args productArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if err := s.productRepo.Delete(ctx, args.ProductID); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete product: %v", err)), nil
+	}
+
+	return structuredResult(productResult{ProductID: args.ProductID, Message: "Product deleted"}), nil
+}
+
+// setProductMemberArgs are the arguments of the set_product_member tool
+type setProductMemberArgs struct {
+	ProductID string `json:"productId" jsonschema:"required" description:"ID of the product"`
+	UserID    string `json:"userId" jsonschema:"required" description:"User to grant the role to: a verified email address, or an issuer and token subject as <issuer>#<subject>"`
+	Role      string `json:"role" jsonschema:"required" description:"Role on the product"`
+}
+
+// setProductMemberTool defines the set_product_member tool
+func setProductMemberTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "set_product_member",
+		Description:     "Grant a user a role on a product, replacing any role they held. Owners manage the product, contributors edit its notebooks and features, viewers read them.",
+		RawInputSchema:  inputSchema(setProductMemberArgs{}, withKeyword("role", "enum", domain.ProductRoles)),
+		RawOutputSchema: outputSchema(domain.ProductUser{}),
+	}
+}
+
+// handleSetProductMember handles the set_product_member tool invocation
+func (s *Server) handleSetProductMember(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args setProductMemberArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if args.ProductID == "" || args.UserID == "" {
+		return mcp.NewToolResultError("productId and userId are required"), nil
+	}
+
+	member := &domain.ProductUser{ProductID: args.ProductID, UserID: args.UserID, Role: args.Role}
+	if err := s.productRepo.SetMember(ctx, member); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to set product member: %v", err)), nil
+	}
+
+	return structuredResult(member), nil
+}
+
+// removeProductMemberArgs are the arguments of the remove_product_member tool
+type removeProductMemberArgs struct {
+	ProductID string `json:"productId" jsonschema:"required" description:"ID of the product"`
+	UserID    string `json:"userId" jsonschema:"required" description:"User to remove"`
+}
+
+// removeProductMemberResult is the result of the remove_product_member tool
+type removeProductMemberResult struct {
+	ProductID string `json:"productId"`
+	UserID    string `json:"userId"`
+	Message   string `json:"message"`
+}
+
+// removeProductMemberTool defines the remove_product_member tool
+func removeProductMemberTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "remove_product_member",
+		Description:     "Revoke a user's role on a product. A product always keeps at least one owner.",
+		RawInputSchema:  inputSchema(removeProductMemberArgs{}),
+		RawOutputSchema: outputSchema(removeProductMemberResult{}),
+	}
+}
+
+// handleRemoveProductMember handles the remove_product_member tool invocation
+func (s *Server) handleRemoveProductMember(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args removeProductMemberArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if err := s.productRepo.RemoveMember(ctx, args.ProductID, args.UserID); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to remove product member: %v", err)), nil
+	}
+
+	return structuredResult(removeProductMemberResult{ProductID: args.ProductID, UserID: args.UserID, Message: "Product member removed"}), nil
+}
+
+// productResourceArgs are the arguments of the attach_to_product and
+// detach_from_product tools
+type productResourceArgs struct {
+	ProductID   string `json:"productId" jsonschema:"required" description:"ID of the product"`
+	ResourceURI string `json:"resource_uri" jsonschema:"required" description:"URI of a notebook or feature in the product's tenant, e.g. synthesis://tenant/acme/notebook/n1"`
+}
+
+// productResourceResult is the result of the attach_to_product and
+// detach_from_product tools
+type productResourceResult struct {
+	ProductID   string `json:"productId"`
+	ResourceURI string `json:"resource_uri"`
+	Message     string `json:"message"`
+}
+
+// attachToProductTool defines the attach_to_product tool
+func attachToProductTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "attach_to_product",
+		Description:     "Make a notebook or feature part of a product (a PART_OF_PRODUCT edge). Product members' roles then extend to it. Requires managing the product and changing the resource.",
+		RawInputSchema:  inputSchema(productResourceArgs{}),
+		RawOutputSchema: outputSchema(productResourceResult{}),
+	}
+}
+
+// handleAttachToProduct handles the attach_to_product tool invocation
+func (s *Server) handleAttachToProduct(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.productResource(ctx, request, true)
+}
+
+// detachFromProductTool defines the detach_from_product tool
+func detachFromProductTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "detach_from_product",
+		Description:     "Remove a notebook or feature from a product",
+		RawInputSchema:  inputSchema(productResourceArgs{}),
+		RawOutputSchema: outputSchema(productResourceResult{}),
+	}
+}
+
+// handleDetachFromProduct handles the detach_from_product tool invocation
+func (s *Server) handleDetachFromProduct(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.productResource(ctx, request, false)
+}
+
+// productResource attaches a resource to or detaches it from the requested product
+func (s *Server) productResource(ctx context.Context, request mcp.CallToolRequest, attach bool) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args productResourceArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	ref, err := domain.ParseURI(args.ResourceURI)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if !attach {
+		if err := s.productRepo.Detach(ctx, args.ProductID, ref); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to detach from product: %v", err)), nil
+		}
+		return structuredResult(productResourceResult{ProductID: args.ProductID, ResourceURI: ref.URI(), Message: "Detached from product"}), nil
+	}
+
+	if err := s.productRepo.Attach(ctx, args.ProductID, ref); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to attach to product: %v", err)), nil
+	}
+
+	return structuredResult(productResourceResult{ProductID: args.ProductID, ResourceURI: ref.URI(), Message: "Attached to product"}), nil
+}
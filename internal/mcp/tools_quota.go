@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// getTenantUsageTool defines the get_tenant_usage tool
+func getTenantUsageTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "get_tenant_usage",
+		Description:     "Get a tenant's usage of each of its limits: notebooks, features, content bytes and requests per second. A limit of 0 is unlimited.",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(tenantArgs{}),
+		RawOutputSchema: outputSchema(domain.TenantUsage{}),
+	}
+}
+
+// handleGetTenantUsage handles the get_tenant_usage tool invocation
+func (s *Server) handleGetTenantUsage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args tenantArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if args.TenantID == "" {
+		return mcp.NewToolResultError("tenantId is required"), nil
+	}
+
+	usage, err := s.quotas.Usage(ctx, args.TenantID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get tenant usage: %v", err)), nil
+	}
+
+	return structuredResult(usage), nil
+}
+
+// setTenantQuotaTool defines the set_tenant_quota tool
+func setTenantQuotaTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "set_tenant_quota",
+		Description:     "Override the configured limits for a tenant, replacing its previous overrides",
+		RawInputSchema:  inputSchema(domain.TenantQuota{}, withRequired("tenantId")),
+		RawOutputSchema: outputSchema(domain.TenantQuota{}),
+	}
+}
+
+// handleSetTenantQuota handles the set_tenant_quota tool invocation
+func (s *Server) handleSetTenantQuota(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var quota domain.TenantQuota
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &quota); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if quota.TenantID == "" {
+		return mcp.NewToolResultError("tenantId is required"), nil
+	}
+
+	if err := s.quotaRepo.Set(ctx, &quota); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to set tenant quota: %v", err)), nil
+	}
+	s.quotas.Forget(quota.TenantID)
+
+	return structuredResult(quota), nil
+}
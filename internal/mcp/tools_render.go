@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/render"
+)
+
+// renderNotebookArgs are the arguments of the render_notebook tool
+type renderNotebookArgs struct {
+	NotebookID string `json:"notebookId" jsonschema:"required" description:"ID of the notebook"`
+	Format     string `json:"format" description:"Output format (default html)"`
+}
+
+// renderNotebookResult is the result of the render_notebook tool
+type renderNotebookResult struct {
+	NotebookURI string        `json:"notebookUri"`
+	Revision    int64         `json:"revision"`
+	Format      render.Format `json:"format"`
+	Content     string        `json:"content"`
+}
+
+// renderNotebookTool defines the render_notebook tool
+func renderNotebookTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "render_notebook",
+		Description: "Render a notebook's markdown and content blocks as HTML or plain text. Each block is rendered with a renderer its types name, such as markdown-renderer, image-renderer or json-viewer, or by its content type.",
+		Annotations: mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema: inputSchema(renderNotebookArgs{},
+			withKeyword("format", "enum", []string{string(render.FormatHTML), string(render.FormatText)}),
+			withKeyword("format", "default", string(render.FormatHTML))),
+		RawOutputSchema: outputSchema(renderNotebookResult{}),
+	}
+}
+
+// handleRenderNotebook handles the render_notebook tool invocation
+func (s *Server) handleRenderNotebook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args renderNotebookArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	format, err := render.ParseFormat(args.Format)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	notebook, err := s.notebookRepo.Get(ctx, args.NotebookID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get notebook: %v", err)), nil
+	}
+
+	rendered, err := s.renderer.RenderNotebook(ctx, notebook, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to render notebook: %v", err)), nil
+	}
+
+	result := renderNotebookResult{
+		NotebookURI: notebook.URI(),
+		Revision:    notebook.Revision,
+		Format:      format,
+		Content:     rendered,
+	}
+
+	return structuredResult(result), nil
+}
@@ -0,0 +1,191 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/validation"
+)
+
+// blockScanPageSize is how many blocks find_invalid_blocks reads at a time
+const blockScanPageSize = 500
+
+// listTypeSchemasResult is the result of the list_type_schemas tool
+type listTypeSchemasResult struct {
+	Schemas []*domain.TypeSchema `json:"schemas"`
+}
+
+// listTypeSchemasTool defines the list_type_schemas tool
+func listTypeSchemasTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "list_type_schemas",
+		Description:     "List every version of a type's JSON Schema, newest first. A version is recorded each time the schema of the type's json-schema constraint changes.",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(typeArgs{}),
+		RawOutputSchema: outputSchema(listTypeSchemasResult{}),
+	}
+}
+
+// handleListTypeSchemas handles the list_type_schemas tool invocation
+func (s *Server) handleListTypeSchemas(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args typeArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	schemas, err := s.typeRepo.ListSchemas(ctx, args.TypeName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list type schemas: %v", err)), nil
+	}
+	if schemas == nil {
+		schemas = []*domain.TypeSchema{}
+	}
+
+	return structuredResult(listTypeSchemasResult{Schemas: schemas}), nil
+}
+
+// findInvalidBlocksArgs are the arguments of the find_invalid_blocks tool
+type findInvalidBlocksArgs struct {
+	TypeName  string      `json:"typeName" jsonschema:"required" description:"Name of the type"`
+	StaleOnly bool        `json:"staleOnly" description:"Only check blocks last validated against an older version of the type's schema"`
+	Schema    interface{} `json:"schema" description:"Candidate JSON Schema (draft 2020-12) to check the blocks against, instead of the type's constraints"`
+	Cursor    string      `json:"cursor" description:"nextCursor of a previous result, to continue the scan"`
+	Limit     int         `json:"limit" jsonschema:"default=100" description:"Maximum number of invalid blocks to return (default 100, max 1000)"`
+}
+
+// findInvalidBlocksResult is the result of the find_invalid_blocks tool
+type findInvalidBlocksResult struct {
+	TypeName      string `json:"typeName"`
+	SchemaVersion int    `json:"schemaVersion"`
+	// Candidate is set when the blocks were checked against a candidate schema
+	Candidate  bool            `json:"candidate"`
+	Checked    int             `json:"checked"`
+	Invalid    []*invalidBlock `json:"invalid"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+}
+
+// findInvalidBlocksTool defines the find_invalid_blocks tool
+func findInvalidBlocksTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "find_invalid_blocks",
+		Description:     "Find the content blocks tagged with a type that break its constraints, such as blocks written before its JSON Schema changed. Pass a candidate schema to see which blocks a schema change would break before making it.",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(findInvalidBlocksArgs{}, withKeyword("schema", "type", []string{"object", "boolean"})),
+		RawOutputSchema: outputSchema(findInvalidBlocksResult{}),
+	}
+}
+
+// invalidBlock is a content block reported by find_invalid_blocks
+type invalidBlock struct {
+	NotebookURI string `json:"notebookUri"`
+	UID         string `json:"uid"`
+	// ValidatedVersion is the schema version the block was last validated against
+	ValidatedVersion int                     `json:"validatedVersion"`
+	Violations       []*validation.Violation `json:"violations"`
+}
+
+// handleFindInvalidBlocks handles the find_invalid_blocks tool invocation
+func (s *Server) handleFindInvalidBlocks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args findInvalidBlocksArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	// Set default limit
+	if args.Limit <= 0 {
+		args.Limit = 100
+	}
+	if args.Limit > 1000 {
+		args.Limit = 1000
+	}
+
+	typeDef, err := s.typeRepo.Get(ctx, args.TypeName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get type: %v", err)), nil
+	}
+
+	check := func(block *domain.ContentBlock) ([]*validation.Violation, error) {
+		if args.Schema != nil {
+			return s.validator.CheckSchema("/data", args.Schema, block.Data)
+		}
+		return s.validator.Check(ctx, "/data", block.Types, block.ContentType, block.Data)
+	}
+
+	// Blocks are read in the caller's tenant scope, and a session limited by
+	// roots only checks the notebooks within them
+	roots := s.sessionRoots(ctx)
+
+	invalid := []*invalidBlock{}
+	checked := 0
+	cursor := args.Cursor
+	nextCursor := ""
+
+scan:
+	for {
+		blocks, err := s.notebookRepo.ListBlocksOfType(ctx, args.TypeName, args.StaleOnly, cursor, blockScanPageSize)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list blocks: %v", err)), nil
+		}
+
+		for _, typed := range blocks {
+			cursor = typed.ID
+
+			notebook := &domain.ResourceRef{TenantID: typed.TenantID, Type: "notebook", ID: typed.NotebookID}
+			if !roots.contains(notebook.URI()) {
+				continue
+			}
+
+			violations, err := check(&typed.Block)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to check block %s of %s: %v", typed.Block.UID, notebook.URI(), err)), nil
+			}
+			checked++
+
+			if len(violations) == 0 {
+				continue
+			}
+			invalid = append(invalid, &invalidBlock{
+				NotebookURI:      notebook.URI(),
+				UID:              typed.Block.UID,
+				ValidatedVersion: typed.SchemaVersion,
+				Violations:       violations,
+			})
+			if len(invalid) == args.Limit {
+				nextCursor = cursor
+				break scan
+			}
+		}
+
+		if len(blocks) < blockScanPageSize {
+			break
+		}
+	}
+
+	result := findInvalidBlocksResult{
+		TypeName:      typeDef.Name,
+		SchemaVersion: typeDef.SchemaVersion,
+		Candidate:     args.Schema != nil,
+		Checked:       checked,
+		Invalid:       invalid,
+		NextCursor:    nextCursor,
+	}
+
+	return structuredResult(result), nil
+}
@@ -6,43 +6,43 @@ import (
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/domain"
 )
 
+// semanticSearchNotebooksArgs are the arguments of the semantic_search_notebooks tool
+type semanticSearchNotebooksArgs struct {
+	Query    string `json:"query" jsonschema:"required" description:"Search query text"`
+	TenantID string `json:"tenantId" description:"Optional tenant ID to scope search"`
+	Limit    int    `json:"limit" jsonschema:"default=10" description:"Maximum number of results (default 10)"`
+}
+
+// semanticSearchMatch is a notebook found by the semantic_search_notebooks tool
+type semanticSearchMatch struct {
+	Notebook *domain.Notebook `json:"notebook"`
+	// Score is the similarity of the notebook to the query, from 0 to 1
+	Score float64 `json:"score"`
+}
+
+// semanticSearchNotebooksResult is the result of the semantic_search_notebooks tool
+type semanticSearchNotebooksResult struct {
+	Matches []semanticSearchMatch `json:"matches"`
+}
+
 // semanticSearchNotebooksTool defines the semantic_search_notebooks tool
 func semanticSearchNotebooksTool() mcp.Tool {
 	return mcp.Tool{
-		Name:        "semantic_search_notebooks",
-		Description: "Perform semantic search on notebooks using vector similarity",
-		InputSchema: mcp.ToolInputSchema{
-			Type: "object",
-			Properties: map[string]interface{}{
-				"query": map[string]interface{}{
-					"type":        "string",
-					"description": "Search query text",
-				},
-				"tenantId": map[string]interface{}{
-					"type":        "string",
-					"description": "Optional tenant ID to scope search",
-				},
-				"limit": map[string]interface{}{
-					"type":        "integer",
-					"description": "Maximum number of results (default 10)",
-					"default":     10,
-				},
-			},
-			Required: []string{"query"},
-		},
+		Name:            "semantic_search_notebooks",
+		Description:     "Perform semantic search on notebooks using vector similarity (not implemented yet: requires an embedding service)",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(semanticSearchNotebooksArgs{}),
+		RawOutputSchema: outputSchema(semanticSearchNotebooksResult{}),
 	}
 }
 
 // handleSemanticSearchNotebooks handles the semantic_search_notebooks tool invocation
 func (s *Server) handleSemanticSearchNotebooks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Parse arguments
-	var args struct {
-		Query    string `json:"query"`
-		TenantID string `json:"tenantId"`
-		Limit    int    `json:"limit"`
-	}
+	var args semanticSearchNotebooksArgs
 
 	argsBytes, err := json.Marshal(request.Params.Arguments)
 	if err != nil {
@@ -53,62 +53,44 @@ func (s *Server) handleSemanticSearchNotebooks(ctx context.Context, request mcp.
 		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
 	}
 
-	// Set default limit
-	if args.Limit == 0 {
-		args.Limit = 10
+	if args.Query == "" {
+		return mcp.NewToolResultError("query is required"), nil
 	}
 
-	// TODO: Generate embedding for the query using an embedding service
-	// For now, return a placeholder response
-	result := map[string]interface{}{
-		"query":   args.Query,
-		"results": []interface{}{},
-		"message": "Semantic search requires embedding service integration",
-	}
+	// No embedding service is integrated yet, so no query can be embedded
+	return mcp.NewToolResultError("semantic search is not implemented: no embedding service is configured"), nil
+}
 
-	resultBytes, err := json.Marshal(result)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
-	}
+// graphQueryResourcesArgs are the arguments of the graph_query_resources tool
+type graphQueryResourcesArgs struct {
+	Query      string `json:"query" description:"Cypher query or query description"`
+	ResourceID string `json:"resource_id" description:"Starting resource ID"`
+	URI        string `json:"uri" description:"Starting resource URI, instead of resource_id; required in sessions limited by roots"`
+	MaxHops    int    `json:"max_hops" jsonschema:"default=2" description:"Maximum number of hops (default 2)"`
+}
 
-	return mcp.NewToolResultText(string(resultBytes)), nil
+// graphQueryResourcesResult is the result of the graph_query_resources tool
+type graphQueryResourcesResult struct {
+	ResourceID string        `json:"resource_id"`
+	Results    []interface{} `json:"results"`
+	Message    string        `json:"message"`
 }
 
 // graphQueryResourcesTool defines the graph_query_resources tool
 func graphQueryResourcesTool() mcp.Tool {
 	return mcp.Tool{
-		Name:        "graph_query_resources",
-		Description: "Query resource relationships using graph traversal",
-		InputSchema: mcp.ToolInputSchema{
-			Type: "object",
-			Properties: map[string]interface{}{
-				"query": map[string]interface{}{
-					"type":        "string",
-					"description": "Cypher query or query description",
-				},
-				"resource_id": map[string]interface{}{
-					"type":        "string",
-					"description": "Starting resource ID",
-				},
-				"max_hops": map[string]interface{}{
-					"type":        "integer",
-					"description": "Maximum number of hops (default 2)",
-					"default":     2,
-				},
-			},
-			Required: []string{"resource_id"},
-		},
+		Name:            "graph_query_resources",
+		Description:     "Query resource relationships using graph traversal",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(graphQueryResourcesArgs{}),
+		RawOutputSchema: outputSchema(graphQueryResourcesResult{}),
 	}
 }
 
 // handleGraphQueryResources handles the graph_query_resources tool invocation
 func (s *Server) handleGraphQueryResources(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Parse arguments
-	var args struct {
-		Query      string `json:"query"`
-		ResourceID string `json:"resource_id"`
-		MaxHops    int    `json:"max_hops"`
-	}
+	var args graphQueryResourcesArgs
 
 	argsBytes, err := json.Marshal(request.Params.Arguments)
 	if err != nil {
@@ -119,6 +101,22 @@ func (s *Server) handleGraphQueryResources(ctx context.Context, request mcp.Call
 		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
 	}
 
+	// A bare resource_id names no tenant, so it cannot be checked against roots
+	if args.URI == "" && s.sessionRoots(ctx) != nil {
+		return mcp.NewToolResultError("uri is required in sessions limited by roots"), nil
+	}
+
+	if args.URI != "" {
+		ref, err := domain.ParseURI(args.URI)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		args.ResourceID = ref.ID
+	}
+	if args.ResourceID == "" {
+		return mcp.NewToolResultError("resource_id or uri is required"), nil
+	}
+
 	// Set default max hops
 	if args.MaxHops == 0 {
 		args.MaxHops = 2
@@ -126,16 +124,11 @@ func (s *Server) handleGraphQueryResources(ctx context.Context, request mcp.Call
 
 	// TODO: Execute graph query using Apache AGE
 	// For now, return a placeholder response
-	result := map[string]interface{}{
-		"resource_id": args.ResourceID,
-		"results":     []interface{}{},
-		"message":     "Graph queries use Apache AGE integration",
-	}
-
-	resultBytes, err := json.Marshal(result)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
+	result := graphQueryResourcesResult{
+		ResourceID: args.ResourceID,
+		Results:    []interface{}{},
+		Message:    "Graph queries use Apache AGE integration",
 	}
 
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return structuredResult(result), nil
 }
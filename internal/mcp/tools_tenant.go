@@ -7,53 +7,40 @@ import (
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/auth"
 	"github.com/prismon/synthesis/internal/domain"
 )
 
+// createTenantArgs are the arguments of the create_tenant tool
+type createTenantArgs struct {
+	TenantID    string            `json:"tenantId" jsonschema:"required" description:"Unique identifier for the tenant"`
+	Owner       string            `json:"owner" description:"Email address of the tenant owner (ignored when authenticated; the caller owns the tenant)"`
+	DisplayName string            `json:"display_name" jsonschema:"required" description:"Display name for the tenant"`
+	Description string            `json:"description" description:"Description of the tenant"`
+	Labels      map[string]string `json:"labels" description:"Key-value labels for the tenant"`
+}
+
+// createTenantResult is the result of the create_tenant tool
+type createTenantResult struct {
+	TenantURI string `json:"tenantUri"`
+	TenantID  string `json:"tenantId"`
+	Message   string `json:"message"`
+}
+
 // createTenantTool defines the create_tenant tool
 func createTenantTool() mcp.Tool {
 	return mcp.Tool{
-		Name:        "create_tenant",
-		Description: "Create a new tenant in Synthesis",
-		InputSchema: mcp.ToolInputSchema{
-			Type: "object",
-			Properties: map[string]interface{}{
-				"tenantId": map[string]interface{}{
-					"type":        "string",
-					"description": "Unique identifier for the tenant",
-				},
-				"owner": map[string]interface{}{
-					"type":        "string",
-					"description": "Email address of the tenant owner",
-				},
-				"display_name": map[string]interface{}{
-					"type":        "string",
-					"description": "Display name for the tenant",
-				},
-				"description": map[string]interface{}{
-					"type":        "string",
-					"description": "Description of the tenant",
-				},
-				"labels": map[string]interface{}{
-					"type":        "object",
-					"description": "Key-value labels for the tenant",
-				},
-			},
-			Required: []string{"tenantId", "owner", "display_name"},
-		},
+		Name:            "create_tenant",
+		Description:     "Create a new tenant in Synthesis",
+		RawInputSchema:  inputSchema(createTenantArgs{}),
+		RawOutputSchema: outputSchema(createTenantResult{}),
 	}
 }
 
 // handleCreateTenant handles the create_tenant tool invocation
 func (s *Server) handleCreateTenant(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Parse arguments
-	var args struct {
-		TenantID    string            `json:"tenantId"`
-		Owner       string            `json:"owner"`
-		DisplayName string            `json:"display_name"`
-		Description string            `json:"description"`
-		Labels      map[string]string `json:"labels"`
-	}
+	var args createTenantArgs
 
 	argsBytes, err := json.Marshal(request.Params.Arguments)
 	if err != nil {
@@ -64,6 +51,8 @@ func (s *Server) handleCreateTenant(ctx context.Context, request mcp.CallToolReq
 		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
 	}
 
+	args.Owner = auth.IdentityFromContext(ctx, args.Owner)
+
 	// Validate required fields
 	if args.TenantID == "" || args.Owner == "" || args.DisplayName == "" {
 		return mcp.NewToolResultError("tenantId, owner, and display_name are required"), nil
@@ -85,44 +74,35 @@ func (s *Server) handleCreateTenant(ctx context.Context, request mcp.CallToolReq
 	}
 
 	// Return success response
-	result := map[string]interface{}{
-		"tenantUri": tenant.URI(),
-		"tenantId":  tenant.ID,
-		"message":   "Tenant created successfully",
+	result := createTenantResult{
+		TenantURI: tenant.URI(),
+		TenantID:  tenant.ID,
+		Message:   "Tenant created successfully",
 	}
 
-	resultBytes, err := json.Marshal(result)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal result: %v", err)), nil
-	}
+	return structuredResult(result), nil
+}
 
-	return mcp.NewToolResultText(string(resultBytes)), nil
+// tenantArgs are the arguments of a tool that addresses a tenant
+type tenantArgs struct {
+	TenantID string `json:"tenantId" jsonschema:"required" description:"ID of the tenant"`
 }
 
 // getTenantTool defines the get_tenant tool
 func getTenantTool() mcp.Tool {
 	return mcp.Tool{
-		Name:        "get_tenant",
-		Description: "Get tenant information by ID",
-		InputSchema: mcp.ToolInputSchema{
-			Type: "object",
-			Properties: map[string]interface{}{
-				"tenantId": map[string]interface{}{
-					"type":        "string",
-					"description": "Unique identifier for the tenant",
-				},
-			},
-			Required: []string{"tenantId"},
-		},
+		Name:            "get_tenant",
+		Description:     "Get tenant information by ID",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(tenantArgs{}),
+		RawOutputSchema: outputSchema(domain.Tenant{}),
 	}
 }
 
 // handleGetTenant handles the get_tenant tool invocation
 func (s *Server) handleGetTenant(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Parse arguments
-	var args struct {
-		TenantID string `json:"tenantId"`
-	}
+	var args tenantArgs
 
 	argsBytes, err := json.Marshal(request.Params.Arguments)
 	if err != nil {
@@ -140,10 +120,5 @@ func (s *Server) handleGetTenant(ctx context.Context, request mcp.CallToolReques
 	}
 
 	// Marshal tenant to JSON
-	resultBytes, err := json.Marshal(tenant)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal tenant: %v", err)), nil
-	}
-
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	return structuredResult(tenant), nil
 }
@@ -0,0 +1,424 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/auth"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/integration"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// maxToolInvocations is the most invocations list_tool_invocations returns
+const maxToolInvocations = 200
+
+// listToolKindsResult is the result of the list_tool_kinds tool
+type listToolKindsResult struct {
+	Kinds []*integration.Kind `json:"kinds"`
+}
+
+// listToolKindsTool defines the list_tool_kinds tool
+func listToolKindsTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "list_tool_kinds",
+		Description:     "List the kinds of tool that can be registered, with the JSON Schemas of their config and invocation input and the config entries always stored encrypted",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(struct{}{}),
+		RawOutputSchema: outputSchema(listToolKindsResult{}),
+	}
+}
+
+// handleListToolKinds handles the list_tool_kinds tool invocation
+func (s *Server) handleListToolKinds(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return structuredResult(listToolKindsResult{Kinds: s.integrations.Kinds().Kinds()}), nil
+}
+
+// createToolArgs are the arguments of the create_tool tool
+type createToolArgs struct {
+	TenantID      string                 `json:"tenantId" jsonschema:"required" description:"ID of the tenant"`
+	ToolID        string                 `json:"toolId" jsonschema:"required" description:"Unique identifier for the tool"`
+	Kind          string                 `json:"kind" jsonschema:"required" description:"Kind of tool, e.g. http-webhook or slack-webhook"`
+	DisplayName   string                 `json:"display_name" jsonschema:"required" description:"Display name for the tool"`
+	Description   string                 `json:"description" description:"Description of the tool (optional)"`
+	Config        map[string]interface{} `json:"config" jsonschema:"required" description:"The tool's config, as its kind's config schema describes"`
+	SensitiveKeys []string               `json:"sensitive_keys" description:"Further config entries to store encrypted (optional)"`
+}
+
+// createToolResult is the result of the create_tool tool
+type createToolResult struct {
+	ToolURI string `json:"toolUri"`
+	ToolID  string `json:"toolId"`
+	Message string `json:"message"`
+}
+
+// createToolTool defines the create_tool tool
+func createToolTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "create_tool",
+		Description:     "Register an external tool for a tenant, such as a Slack-style incoming webhook. Its config must meet its kind's config schema (see list_tool_kinds); entries named in sensitive_keys, and the kind's secret entries, are stored encrypted and redacted when read.",
+		RawInputSchema:  inputSchema(createToolArgs{}),
+		RawOutputSchema: outputSchema(createToolResult{}),
+	}
+}
+
+// handleCreateTool handles the create_tool tool invocation
+func (s *Server) handleCreateTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args createToolArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if err := domain.ValidateID(args.ToolID); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid toolId: %v", err)), nil
+	}
+	if args.DisplayName == "" {
+		return mcp.NewToolResultError("display_name is required"), nil
+	}
+
+	tool := &domain.ToolConfig{
+		TenantID:      args.TenantID,
+		ID:            args.ToolID,
+		Kind:          args.Kind,
+		DisplayName:   args.DisplayName,
+		Description:   args.Description,
+		Config:        args.Config,
+		SensitiveKeys: args.SensitiveKeys,
+	}
+
+	if err := s.integrations.Prepare(ctx, tool, nil); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid tool: %v", err)), nil
+	}
+
+	if err := s.toolRepo.Create(ctx, tool); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create tool: %v", err)), nil
+	}
+
+	result := createToolResult{
+		ToolURI: tool.URI(),
+		ToolID:  tool.ID,
+		Message: "Tool created successfully",
+	}
+
+	return structuredResult(result), nil
+}
+
+// getToolArgs are the arguments of the get_tool tool
+type getToolArgs struct {
+	ToolID string `json:"toolId" jsonschema:"required" description:"ID of the tool"`
+	Reveal bool   `json:"reveal" description:"Decrypt sensitive config entries instead of redacting them; requires the reveal_secrets permission (optional)"`
+}
+
+// getToolTool defines the get_tool tool
+func getToolTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "get_tool",
+		Description:     "Get a registered tool. Sensitive config entries are redacted unless reveal is set and the caller may reveal them.",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(getToolArgs{}),
+		RawOutputSchema: outputSchema(domain.ToolConfig{}),
+	}
+}
+
+// handleGetTool handles the get_tool tool invocation
+func (s *Server) handleGetTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args getToolArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	tool, err := s.toolRepo.Get(ctx, args.ToolID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get tool: %v", err)), nil
+	}
+
+	if args.Reveal {
+		if err := s.checkReveal(ctx, tool.URI()); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if err := s.sealer.OpenConfig(ctx, tool.URI(), tool.Config, tool.SensitiveKeys); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to decrypt tool config: %v", err)), nil
+		}
+	} else {
+		tool = tool.Redacted()
+	}
+
+	return structuredResult(tool), nil
+}
+
+// listToolsArgs are the arguments of the list_tools tool
+type listToolsArgs struct {
+	TenantID string `json:"tenantId" jsonschema:"required" description:"ID of the tenant"`
+	listArgs
+}
+
+// listToolsTool defines the list_tools tool
+func listToolsTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "list_tools",
+		Description:     "List a page of the tools registered for a tenant, by display name unless sorted otherwise, with sensitive config entries redacted",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(listToolsArgs{}, withListProperties(postgres.ToolSorts())),
+		RawOutputSchema: outputSchema(domain.Page[*domain.ToolConfig]{}),
+	}
+}
+
+// handleListTools handles the list_tools tool invocation
+func (s *Server) handleListTools(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args listToolsArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if args.TenantID == "" {
+		return mcp.NewToolResultError("tenantId is required"), nil
+	}
+
+	tools, err := s.toolRepo.ListByTenant(ctx, args.TenantID, args.options())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list tools: %v", err)), nil
+	}
+
+	for i, tool := range tools.Items {
+		tools.Items[i] = tool.Redacted()
+	}
+
+	return structuredResult(tools), nil
+}
+
+// updateToolArgs are the arguments of the update_tool tool
+type updateToolArgs struct {
+	ToolID        string                 `json:"toolId" jsonschema:"required" description:"ID of the tool"`
+	DisplayName   *string                `json:"display_name" description:"New display name (optional)"`
+	Description   *string                `json:"description" description:"New description (optional)"`
+	Config        map[string]interface{} `json:"config" description:"New config (optional)"`
+	SensitiveKeys []string               `json:"sensitive_keys" description:"New further config entries to store encrypted (optional)"`
+}
+
+// updateToolTool defines the update_tool tool
+func updateToolTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "update_tool",
+		Description:     "Change a registered tool's display name, description, config or sensitive keys. A new config replaces the old one; sensitive entries given as \"[REDACTED]\" keep their stored values.",
+		RawInputSchema:  inputSchema(updateToolArgs{}),
+		RawOutputSchema: outputSchema(domain.ToolConfig{}),
+	}
+}
+
+// handleUpdateTool handles the update_tool tool invocation
+func (s *Server) handleUpdateTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args updateToolArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	existing, err := s.toolRepo.Get(ctx, args.ToolID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get tool: %v", err)), nil
+	}
+
+	tool := *existing
+	if args.DisplayName != nil {
+		if *args.DisplayName == "" {
+			return mcp.NewToolResultError("display_name cannot be empty"), nil
+		}
+		tool.DisplayName = *args.DisplayName
+	}
+	if args.Description != nil {
+		tool.Description = *args.Description
+	}
+	if args.SensitiveKeys != nil {
+		tool.SensitiveKeys = args.SensitiveKeys
+	}
+	// Without a new config, the stored one is kept as it is
+	tool.Config = args.Config
+	if tool.Config == nil {
+		tool.Config = existing.Redacted().Config
+	}
+
+	if err := s.integrations.Prepare(ctx, &tool, existing); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid tool: %v", err)), nil
+	}
+
+	if err := s.toolRepo.Update(ctx, &tool); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to update tool: %v", err)), nil
+	}
+
+	redacted := tool.Redacted()
+	return structuredResult(redacted), nil
+}
+
+// toolArgs are the arguments of a tool that addresses a registered tool
+type toolArgs struct {
+	ToolID string `json:"toolId" jsonschema:"required" description:"ID of the tool"`
+}
+
+// toolResult is the result of a tool that changed a registered tool
+type toolResult struct {
+	ToolID  string `json:"toolId"`
+	Message string `json:"message"`
+}
+
+// deleteToolTool defines the delete_tool tool
+func deleteToolTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "delete_tool",
+		Description:     "Delete a registered tool with its invocation log",
+		RawInputSchema:  inputSchema(toolArgs{}),
+		RawOutputSchema: outputSchema(toolResult{}),
+	}
+}
+
+// handleDeleteTool handles the delete_tool tool invocation
+func (s *Server) handleDeleteTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args toolArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if err := s.toolRepo.Delete(ctx, args.ToolID); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete tool: %v", err)), nil
+	}
+
+	return structuredResult(toolResult{ToolID: args.ToolID, Message: "Tool deleted"}), nil
+}
+
+// invokeToolArgs are the arguments of the invoke_tool tool
+type invokeToolArgs struct {
+	ToolID    string                 `json:"toolId" jsonschema:"required" description:"ID of the tool"`
+	Input     map[string]interface{} `json:"input" description:"Input of the invocation, e.g. {\"text\": \"...\"} for a slack-webhook"`
+	SourceURI string                 `json:"sourceUri" description:"synthesis:// URI of the library, notebook, feature or product of the tool's tenant the tool is used for (optional)"`
+}
+
+// invokeToolTool defines the invoke_tool tool
+func invokeToolTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "invoke_tool",
+		Description:     "Run a registered tool with its stored config, e.g. post a message through a Slack webhook. The input must meet the tool kind's input schema (see list_tool_kinds). Every invocation is logged; when sourceUri is given, the resource is linked to the tool with a USES_TOOL edge.",
+		RawInputSchema:  inputSchema(invokeToolArgs{}),
+		RawOutputSchema: outputSchema(domain.ToolInvocation{}),
+	}
+}
+
+// handleInvokeTool handles the invoke_tool tool invocation
+func (s *Server) handleInvokeTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args invokeToolArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	invocation, err := s.integrations.Invoke(ctx, args.ToolID, args.Input, auth.IdentityFromContext(ctx, "system"), args.SourceURI)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to invoke tool: %v", err)), nil
+	}
+
+	resultBytes, err := json.Marshal(invocation)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal invocation: %v", err)), nil
+	}
+
+	if invocation.Status == domain.ToolInvocationFailed {
+		return mcp.NewToolResultError(string(resultBytes)), nil
+	}
+	return mcp.NewToolResultStructured(invocation, string(resultBytes)), nil
+}
+
+// listToolInvocationsArgs are the arguments of the list_tool_invocations tool
+type listToolInvocationsArgs struct {
+	ToolID string `json:"toolId" jsonschema:"required" description:"ID of the tool"`
+	Limit  int    `json:"limit"`
+}
+
+// listToolInvocationsResult is the result of the list_tool_invocations tool
+type listToolInvocationsResult struct {
+	Invocations []*domain.ToolInvocation `json:"invocations"`
+}
+
+// listToolInvocationsTool defines the list_tool_invocations tool
+func listToolInvocationsTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "list_tool_invocations",
+		Description: "List the logged invocations of a tool, newest first, with their outcomes",
+		Annotations: mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema: inputSchema(listToolInvocationsArgs{},
+			withKeyword("limit", "description", fmt.Sprintf("Maximum number of invocations to return (default: 50, max: %d)", maxToolInvocations))),
+		RawOutputSchema: outputSchema(listToolInvocationsResult{}),
+	}
+}
+
+// handleListToolInvocations handles the list_tool_invocations tool invocation
+func (s *Server) handleListToolInvocations(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args listToolInvocationsArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if args.Limit <= 0 {
+		args.Limit = 50
+	}
+	if args.Limit > maxToolInvocations {
+		args.Limit = maxToolInvocations
+	}
+
+	invocations, err := s.toolRepo.ListInvocations(ctx, args.ToolID, args.Limit)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list tool invocations: %v", err)), nil
+	}
+
+	if invocations == nil {
+		invocations = []*domain.ToolInvocation{}
+	}
+
+	return structuredResult(listToolInvocationsResult{Invocations: invocations}), nil
+}
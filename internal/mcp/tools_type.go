@@ -0,0 +1,273 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// typeDefArgs are the arguments of create_type and update_type
+type typeDefArgs struct {
+	TypeName    string               `json:"typeName" jsonschema:"required" description:"Name of the type; it is addressed as synthesis://type/{name}"`
+	Description *string              `json:"description" description:"Description of the type"`
+	Renderers   *[]domain.Renderer   `json:"renderers" description:"Renderers of the type: objects with a name and config"`
+	Editors     *[]domain.Editor     `json:"editors" description:"Editors of the type: objects with a name and config"`
+	Constraints *[]domain.Constraint `json:"constraints" description:"Constraints content of the type must meet: objects with a type, such as max-length, min-length, pattern, file-size or json-schema, and a config. A json-schema constraint's config holds the type's JSON Schema (draft 2020-12) as {\"schema\": {...}}"`
+	Labels      *map[string]string   `json:"labels" description:"Labels of the type"`
+}
+
+// apply sets the fields of typeDef given in args
+func (args *typeDefArgs) apply(typeDef *domain.TypeDef) {
+	if args.Description != nil {
+		typeDef.Description = *args.Description
+	}
+	if args.Renderers != nil {
+		typeDef.Renderers = *args.Renderers
+	}
+	if args.Editors != nil {
+		typeDef.Editors = *args.Editors
+	}
+	if args.Constraints != nil {
+		typeDef.Constraints = *args.Constraints
+	}
+	if args.Labels != nil {
+		typeDef.Labels = *args.Labels
+	}
+}
+
+// createTypeResult is the result of the create_type tool
+type createTypeResult struct {
+	TypeURI  string `json:"typeUri"`
+	TypeName string `json:"typeName"`
+	Message  string `json:"message"`
+}
+
+// createTypeTool defines the create_type tool
+func createTypeTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "create_type",
+		Description:     "Create a type definition. Content blocks and feature values tagged with the type are validated against its constraints when written.",
+		RawInputSchema:  inputSchema(typeDefArgs{}),
+		RawOutputSchema: outputSchema(createTypeResult{}),
+	}
+}
+
+// handleCreateType handles the create_type tool invocation
+func (s *Server) handleCreateType(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args typeDefArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	typeDef := &domain.TypeDef{Name: args.TypeName}
+	args.apply(typeDef)
+
+	if err := s.validator.ValidateType(typeDef); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid type: %v", err)), nil
+	}
+
+	if err := s.typeRepo.Create(ctx, typeDef); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create type: %v", err)), nil
+	}
+
+	result := createTypeResult{
+		TypeURI:  typeDef.URI(),
+		TypeName: typeDef.Name,
+		Message:  "Type created successfully",
+	}
+
+	return structuredResult(result), nil
+}
+
+// typeArgs are the arguments of a tool that addresses a type definition
+type typeArgs struct {
+	TypeName string `json:"typeName" jsonschema:"required" description:"Name of the type"`
+}
+
+// typeResult is the result of a tool that changed a type definition
+type typeResult struct {
+	TypeURI string `json:"typeUri"`
+	Message string `json:"message"`
+}
+
+// getTypeTool defines the get_type tool
+func getTypeTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "get_type",
+		Description:     "Get a type definition with its renderers, editors and constraints",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(typeArgs{}),
+		RawOutputSchema: outputSchema(domain.TypeDef{}),
+	}
+}
+
+// handleGetType handles the get_type tool invocation
+func (s *Server) handleGetType(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args typeArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	typeDef, err := s.typeRepo.Get(ctx, args.TypeName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get type: %v", err)), nil
+	}
+
+	return structuredResult(typeDef), nil
+}
+
+// listTypesResult is the result of the list_types tool
+type listTypesResult struct {
+	Types []*domain.TypeDef `json:"types"`
+}
+
+// listTypesTool defines the list_types tool
+func listTypesTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "list_types",
+		Description:     "List the type definitions",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(struct{}{}),
+		RawOutputSchema: outputSchema(listTypesResult{}),
+	}
+}
+
+// handleListTypes handles the list_types tool invocation
+func (s *Server) handleListTypes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	types, err := s.typeRepo.List(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list types: %v", err)), nil
+	}
+
+	if types == nil {
+		types = []*domain.TypeDef{}
+	}
+
+	return structuredResult(listTypesResult{Types: types}), nil
+}
+
+// updateTypeTool defines the update_type tool
+func updateTypeTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "update_type",
+		Description:     "Change a type definition. Only the fields given are changed; each replaces the current value. A changed JSON Schema is recorded as a new schema version; content already written is not revalidated, but find_invalid_blocks finds blocks that no longer validate.",
+		RawInputSchema:  inputSchema(typeDefArgs{}),
+		RawOutputSchema: outputSchema(domain.TypeDef{}),
+	}
+}
+
+// handleUpdateType handles the update_type tool invocation
+func (s *Server) handleUpdateType(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args typeDefArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	typeDef, err := s.typeRepo.Get(ctx, args.TypeName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get type: %v", err)), nil
+	}
+
+	args.apply(typeDef)
+
+	if err := s.validator.ValidateType(typeDef); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid type: %v", err)), nil
+	}
+
+	if err := s.typeRepo.Update(ctx, typeDef); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to update type: %v", err)), nil
+	}
+
+	return structuredResult(typeDef), nil
+}
+
+// deleteTypeTool defines the delete_type tool
+func deleteTypeTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "delete_type",
+		Description:     "Delete a type definition. Content tagged with it keeps the tag, but can no longer be written until the tag is removed.",
+		RawInputSchema:  inputSchema(typeArgs{}),
+		RawOutputSchema: outputSchema(typeResult{}),
+	}
+}
+
+// handleDeleteType handles the delete_type tool invocation
+func (s *Server) handleDeleteType(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args typeArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if err := s.typeRepo.Delete(ctx, args.TypeName); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete type: %v", err)), nil
+	}
+
+	ref := &domain.ResourceRef{Type: "type", ID: args.TypeName}
+	return structuredResult(typeResult{TypeURI: ref.URI(), Message: "Type deleted"}), nil
+}
+
+// typeResourceTemplate defines the synthesis://type/{name} resource template
+func typeResourceTemplate() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		"synthesis://type/{name}",
+		"Type definition",
+		mcp.WithTemplateDescription("A type definition with its renderers, editors and constraints"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
+// handleReadType reads a synthesis://type/{name} resource
+func (s *Server) handleReadType(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	ref, err := domain.ParseURI(request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Type != "type" {
+		return nil, fmt.Errorf("%s is not a type", request.Params.URI)
+	}
+
+	typeDef, err := s.typeRepo.Get(ctx, ref.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(typeDef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal type: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: typeDef.URI(), MIMEType: "application/json", Text: string(data)},
+	}, nil
+}
@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/validation"
+)
+
+// validateResourceArgs are the arguments of the validate_resource tool
+type validateResourceArgs struct {
+	Kind       string          `json:"kind" jsonschema:"required" description:"Kind of resource"`
+	Resource   json.RawMessage `json:"resource" jsonschema:"required" description:"The candidate resource, as it would be returned by the get tools"`
+	NotebookID string          `json:"notebookId" description:"Notebook a content block would be appended to; required for content_block"`
+}
+
+// validateResourceResult is the result of the validate_resource tool
+type validateResourceResult struct {
+	Kind   string                  `json:"kind"`
+	Valid  bool                    `json:"valid"`
+	Errors []*validation.Violation `json:"errors"`
+}
+
+// validateResourceTool defines the validate_resource tool
+func validateResourceTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "validate_resource",
+		Description: "Validate a candidate notebook, content block or feature against the domain rules without writing anything: required fields, ID formats, status values, parent blocks, URLs and the constraints of its types. Returns each error with a JSON pointer into the resource.",
+		Annotations: mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema: inputSchema(validateResourceArgs{},
+			withKeyword("kind", "enum", validation.Kinds),
+			withKeyword("resource", "type", "object")),
+		RawOutputSchema: outputSchema(validateResourceResult{}),
+	}
+}
+
+// handleValidateResource handles the validate_resource tool invocation
+func (s *Server) handleValidateResource(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args validateResourceArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	var violations []*validation.Violation
+
+	switch args.Kind {
+	case validation.KindNotebook:
+		var notebook domain.Notebook
+		if violation := decodeCandidate(args.Resource, &notebook); violation != nil {
+			violations = append(violations, violation)
+			break
+		}
+		violations, err = s.validator.CheckNotebook(ctx, &notebook)
+
+	case validation.KindContentBlock:
+		if args.NotebookID == "" {
+			return mcp.NewToolResultError("notebookId is required to validate a content block"), nil
+		}
+
+		notebook, getErr := s.notebookRepo.Get(ctx, args.NotebookID)
+		if getErr != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get notebook: %v", getErr)), nil
+		}
+
+		var block domain.ContentBlock
+		if violation := decodeCandidate(args.Resource, &block); violation != nil {
+			violations = append(violations, violation)
+			break
+		}
+		violations, err = s.validator.CheckContentBlock(ctx, &block, notebook.Contents.ContentBlocks)
+
+	case validation.KindFeature:
+		var feature domain.Feature
+		if violation := decodeCandidate(args.Resource, &feature); violation != nil {
+			violations = append(violations, violation)
+			break
+		}
+		violations, err = s.validator.CheckFeature(ctx, &feature)
+
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("kind must be one of %s", strings.Join(validation.Kinds, ", "))), nil
+	}
+
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to validate %s: %v", args.Kind, err)), nil
+	}
+
+	if violations == nil {
+		violations = []*validation.Violation{}
+	}
+
+	result := validateResourceResult{
+		Kind:   args.Kind,
+		Valid:  len(violations) == 0,
+		Errors: violations,
+	}
+
+	return structuredResult(result), nil
+}
+
+// decodeCandidate decodes a candidate resource into v, returning a violation
+// for malformed JSON, unknown fields and values of the wrong type
+func decodeCandidate(data json.RawMessage, v interface{}) *validation.Violation {
+	if len(data) == 0 {
+		return &validation.Violation{Message: "resource is required"}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		path := ""
+		if typeErr.Field != "" {
+			path = "/" + strings.ReplaceAll(typeErr.Field, ".", "/")
+		}
+		return &validation.Violation{Path: path, Message: fmt.Sprintf("must be %s, not %s", typeErr.Type, typeErr.Value)}
+	}
+
+	return &validation.Violation{Message: strings.TrimPrefix(err.Error(), "json: ")}
+}
@@ -0,0 +1,188 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+	"github.com/prismon/synthesis/internal/webhook"
+)
+
+// listWebhookDeliveriesArgs are the arguments of the list_webhook_deliveries tool
+type listWebhookDeliveriesArgs struct {
+	TenantID    string `json:"tenantId" jsonschema:"required" description:"ID of the tenant"`
+	ResourceURI string `json:"resource_uri" description:"Only show deliveries for this resource URI (optional)"`
+	Status      string `json:"status" description:"Only show deliveries in this status (optional)"`
+	Limit       int    `json:"limit" jsonschema:"default=50" description:"Maximum number of deliveries (default 50)"`
+}
+
+// listWebhookDeliveriesResult is the result of the list_webhook_deliveries tool
+type listWebhookDeliveriesResult struct {
+	Deliveries []*domain.WebhookDelivery `json:"deliveries"`
+}
+
+// listWebhookDeliveriesTool defines the list_webhook_deliveries tool
+func listWebhookDeliveriesTool() mcp.Tool {
+	return mcp.Tool{
+		Name:        "list_webhook_deliveries",
+		Description: "List webhook deliveries for a tenant along with each delivery attempt",
+		Annotations: mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema: inputSchema(listWebhookDeliveriesArgs{},
+			withKeyword("status", "enum", []string{domain.WebhookStatusPending, domain.WebhookStatusDelivered, domain.WebhookStatusDead})),
+		RawOutputSchema: outputSchema(listWebhookDeliveriesResult{}),
+	}
+}
+
+// handleListWebhookDeliveries handles the list_webhook_deliveries tool invocation
+func (s *Server) handleListWebhookDeliveries(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args listWebhookDeliveriesArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if args.TenantID == "" {
+		return mcp.NewToolResultError("tenantId is required"), nil
+	}
+
+	deliveries, err := s.webhookRepo.ListDeliveries(ctx, postgres.WebhookDeliveryFilter{
+		TenantID:    args.TenantID,
+		ResourceURI: args.ResourceURI,
+		Status:      args.Status,
+		Limit:       args.Limit,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list webhook deliveries: %v", err)), nil
+	}
+
+	if deliveries == nil {
+		deliveries = []*domain.WebhookDelivery{}
+	}
+
+	return structuredResult(listWebhookDeliveriesResult{Deliveries: deliveries}), nil
+}
+
+// retryWebhookDeliveryArgs are the arguments of the retry_webhook_delivery tool
+type retryWebhookDeliveryArgs struct {
+	TenantID   string `json:"tenantId" jsonschema:"required" description:"ID of the tenant"`
+	DeliveryID string `json:"deliveryId" jsonschema:"required" description:"ID of the dead-lettered delivery"`
+}
+
+// retryWebhookDeliveryResult is the result of the retry_webhook_delivery tool
+type retryWebhookDeliveryResult struct {
+	DeliveryID string `json:"deliveryId"`
+	Message    string `json:"message"`
+}
+
+// retryWebhookDeliveryTool defines the retry_webhook_delivery tool
+func retryWebhookDeliveryTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "retry_webhook_delivery",
+		Description:     "Requeue a dead-lettered webhook delivery for another attempt",
+		RawInputSchema:  inputSchema(retryWebhookDeliveryArgs{}),
+		RawOutputSchema: outputSchema(retryWebhookDeliveryResult{}),
+	}
+}
+
+// handleRetryWebhookDelivery handles the retry_webhook_delivery tool invocation
+func (s *Server) handleRetryWebhookDelivery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args retryWebhookDeliveryArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	if err := s.webhookRepo.Retry(ctx, args.TenantID, args.DeliveryID); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to retry webhook delivery: %v", err)), nil
+	}
+
+	return structuredResult(retryWebhookDeliveryResult{DeliveryID: args.DeliveryID, Message: "Webhook delivery requeued"}), nil
+}
+
+// webhookSecretResult is the result of the get_webhook_secret and
+// rotate_webhook_secret tools
+type webhookSecretResult struct {
+	TenantID  string `json:"tenantId"`
+	Secret    string `json:"secret"`
+	Algorithm string `json:"algorithm"`
+	Header    string `json:"header"`
+}
+
+// getWebhookSecretTool defines the get_webhook_secret tool
+func getWebhookSecretTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "get_webhook_secret",
+		Description:     "Get the secret a tenant's webhook receivers use to verify the X-Synthesis-Signature header",
+		RawInputSchema:  inputSchema(tenantArgs{}),
+		RawOutputSchema: outputSchema(webhookSecretResult{}),
+	}
+}
+
+// handleGetWebhookSecret handles the get_webhook_secret tool invocation
+func (s *Server) handleGetWebhookSecret(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.webhookSecret(ctx, request, s.webhookRepo.GetSecret)
+}
+
+// rotateWebhookSecretTool defines the rotate_webhook_secret tool
+func rotateWebhookSecretTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "rotate_webhook_secret",
+		Description:     "Replace a tenant's webhook signing secret. Pending deliveries are signed with the new secret.",
+		RawInputSchema:  inputSchema(tenantArgs{}),
+		RawOutputSchema: outputSchema(webhookSecretResult{}),
+	}
+}
+
+// handleRotateWebhookSecret handles the rotate_webhook_secret tool invocation
+func (s *Server) handleRotateWebhookSecret(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.webhookSecret(ctx, request, s.webhookRepo.RotateSecret)
+}
+
+// webhookSecret returns the secret produced by fetch for the requested tenant
+func (s *Server) webhookSecret(ctx context.Context, request mcp.CallToolRequest, fetch func(context.Context, string) (string, error)) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args tenantArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	// Make sure the tenant exists before handing out a secret for it
+	if _, err := s.tenantRepo.Get(ctx, args.TenantID); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get tenant: %v", err)), nil
+	}
+
+	secret, err := fetch(ctx, args.TenantID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get webhook secret: %v", err)), nil
+	}
+
+	result := webhookSecretResult{
+		TenantID:  args.TenantID,
+		Secret:    secret,
+		Algorithm: "HMAC-SHA256",
+		Header:    webhook.HeaderSignature,
+	}
+
+	return structuredResult(result), nil
+}
@@ -0,0 +1,235 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prismon/synthesis/internal/auth"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// notebookTransitionArgs are the arguments of the notebook workflow tools
+type notebookTransitionArgs struct {
+	NotebookID string `json:"notebookId" jsonschema:"required" description:"ID of the notebook"`
+	Reason     string `json:"reason" description:"Reason for the transition"`
+}
+
+// notebookTransitionResult is the result of the notebook workflow tools
+type notebookTransitionResult struct {
+	NotebookURI string `json:"notebookUri"`
+	NotebookID  string `json:"notebookId"`
+	FromStatus  string `json:"fromStatus"`
+	Status      string `json:"status"`
+	Message     string `json:"message"`
+}
+
+// submitNotebookTool defines the submit_notebook tool
+func submitNotebookTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "submit_notebook",
+		Description:     "Submit a draft notebook for review (draft → in_review)",
+		RawInputSchema:  inputSchema(notebookTransitionArgs{}),
+		RawOutputSchema: outputSchema(notebookTransitionResult{}),
+	}
+}
+
+// approveNotebookTool defines the approve_notebook tool
+func approveNotebookTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "approve_notebook",
+		Description:     "Approve a notebook under review (in_review → approved). Requires the approver role.",
+		RawInputSchema:  inputSchema(notebookTransitionArgs{}),
+		RawOutputSchema: outputSchema(notebookTransitionResult{}),
+	}
+}
+
+// rejectNotebookTool defines the reject_notebook tool
+func rejectNotebookTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "reject_notebook",
+		Description:     "Reject a notebook under review and return it to draft (in_review → draft). Requires the approver role.",
+		RawInputSchema:  inputSchema(notebookTransitionArgs{}),
+		RawOutputSchema: outputSchema(notebookTransitionResult{}),
+	}
+}
+
+// archiveNotebookTool defines the archive_notebook tool
+func archiveNotebookTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "archive_notebook",
+		Description:     "Archive an approved notebook (approved → archived)",
+		RawInputSchema:  inputSchema(notebookTransitionArgs{}),
+		RawOutputSchema: outputSchema(notebookTransitionResult{}),
+	}
+}
+
+// handleSubmitNotebook handles the submit_notebook tool invocation
+func (s *Server) handleSubmitNotebook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.transitionNotebook(ctx, request, "submit")
+}
+
+// handleApproveNotebook handles the approve_notebook tool invocation
+func (s *Server) handleApproveNotebook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.transitionNotebook(ctx, request, "approve")
+}
+
+// handleRejectNotebook handles the reject_notebook tool invocation
+func (s *Server) handleRejectNotebook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.transitionNotebook(ctx, request, "reject")
+}
+
+// handleArchiveNotebook handles the archive_notebook tool invocation
+func (s *Server) handleArchiveNotebook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return s.transitionNotebook(ctx, request, "archive")
+}
+
+// transitionNotebook applies a workflow action to a notebook after checking the caller's role
+func (s *Server) transitionNotebook(ctx context.Context, request mcp.CallToolRequest, action string) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args notebookTransitionArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	// Validate required fields
+	if args.NotebookID == "" {
+		return mcp.NewToolResultError("notebookId is required"), nil
+	}
+
+	// The actor is always the authenticated caller; the local operator acts as system
+	actor := auth.IdentityFromContext(ctx, "")
+	if actor == "" && auth.IsLocalOperator(ctx) {
+		actor = "system"
+	}
+	if actor == "" {
+		return mcp.NewToolResultError("notebook transitions require an authenticated caller"), nil
+	}
+
+	notebook, err := s.notebookRepo.Get(ctx, args.NotebookID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get notebook: %v", err)), nil
+	}
+
+	transition, err := domain.FindNotebookTransition(action, notebook.Status)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	roles, err := s.notebookRoles(ctx, notebook, actor)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to resolve roles: %v", err)), nil
+	}
+
+	if !transition.Allows(roles) {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is not allowed to %s notebook %s (requires one of %v)",
+			actor, action, notebook.ID, transition.Roles)), nil
+	}
+
+	change := &domain.NotebookStatusChange{
+		NotebookID: notebook.ID,
+		FromStatus: transition.From,
+		ToStatus:   transition.To,
+		Actor:      actor,
+		Reason:     args.Reason,
+	}
+
+	// The notebook's notifications fire from the resulting notebook.status_changed event
+	if err := s.notebookRepo.TransitionStatus(ctx, change); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to %s notebook: %v", action, err)), nil
+	}
+
+	// Return success response
+	result := notebookTransitionResult{
+		NotebookURI: notebook.URI(),
+		NotebookID:  notebook.ID,
+		FromStatus:  change.FromStatus,
+		Status:      change.ToStatus,
+		Message:     fmt.Sprintf("Notebook moved from %s to %s", change.FromStatus, change.ToStatus),
+	}
+
+	return structuredResult(result), nil
+}
+
+// notebookRoles resolves the workflow roles the actor holds on a notebook
+func (s *Server) notebookRoles(ctx context.Context, notebook *domain.Notebook, actor string) ([]string, error) {
+	var roles []string
+
+	// The operator running the stdio transport administers every notebook
+	if auth.IsLocalOperator(ctx) {
+		roles = append(roles, domain.NotebookRoleAuthor, domain.NotebookRoleApprover)
+	}
+
+	if notebook.Owner == actor {
+		roles = append(roles, domain.NotebookRoleAuthor)
+	}
+
+	tenant, err := s.tenantRepo.Get(ctx, notebook.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.Owner == actor {
+		roles = append(roles, domain.NotebookRoleApprover)
+	}
+
+	// Roles granted by the identity provider apply within the caller's own tenant
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && principal.TenantID == notebook.TenantID {
+		roles = append(roles, principal.Roles...)
+	}
+
+	return roles, nil
+}
+
+// notebookArgs are the arguments of a tool that addresses a notebook
+type notebookArgs struct {
+	NotebookID string `json:"notebookId" jsonschema:"required" description:"ID of the notebook"`
+}
+
+// listNotebookTransitionsResult is the result of the list_notebook_transitions tool
+type listNotebookTransitionsResult struct {
+	Transitions []*domain.NotebookStatusChange `json:"transitions"`
+}
+
+// listNotebookTransitionsTool defines the list_notebook_transitions tool
+func listNotebookTransitionsTool() mcp.Tool {
+	return mcp.Tool{
+		Name:            "list_notebook_transitions",
+		Description:     "List the status transition history of a notebook",
+		Annotations:     mcp.ToolAnnotation{ReadOnlyHint: mcp.ToBoolPtr(true)},
+		RawInputSchema:  inputSchema(notebookArgs{}),
+		RawOutputSchema: outputSchema(listNotebookTransitionsResult{}),
+	}
+}
+
+// handleListNotebookTransitions handles the list_notebook_transitions tool invocation
+func (s *Server) handleListNotebookTransitions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse arguments
+	var args notebookArgs
+
+	argsBytes, err := json.Marshal(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to marshal arguments: %v", err)), nil
+	}
+
+	if err := json.Unmarshal(argsBytes, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to parse arguments: %v", err)), nil
+	}
+
+	changes, err := s.notebookRepo.ListStatusChanges(ctx, args.NotebookID)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list transitions: %v", err)), nil
+	}
+
+	if changes == nil {
+		changes = []*domain.NotebookStatusChange{}
+	}
+
+	return structuredResult(listNotebookTransitionsResult{Transitions: changes}), nil
+}
@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// APIKeyRepository handles API key persistence
+type APIKeyRepository struct {
+	db *DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create stores a new API key. key.KeyHash must already hold the hash of the key.
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+
+	query := `
+		INSERT INTO api_key (tenant_id, name, prefix, key_hash, scopes, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		key.TenantID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		pq.Array(key.Scopes),
+		key.ExpiresAt,
+		nullString(key.CreatedBy),
+	).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", asConflict(err))
+	}
+
+	return nil
+}
+
+// GetByPrefix retrieves the API key with the given prefix, revoked and expired keys included
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	query := `
+		SELECT id, tenant_id, name, prefix, key_hash, scopes, expires_at, last_used_at,
+			revoked_at, COALESCE(created_by, ''), created_at
+		FROM api_key
+		WHERE prefix = $1
+	`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, prefix))
+	if err == sql.ErrNoRows {
+		return nil, notFound("API key not found: %s", prefix)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// List retrieves a tenant's API keys, newest first
+func (r *APIKeyRepository) List(ctx context.Context, tenantID string) ([]*domain.APIKey, error) {
+	query := `
+		SELECT id, tenant_id, name, prefix, key_hash, scopes, expires_at, last_used_at,
+			revoked_at, COALESCE(created_by, ''), created_at
+		FROM api_key
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Revoke disables a tenant's API key. Revoked keys are kept for reference.
+func (r *APIKeyRepository) Revoke(ctx context.Context, tenantID, id string) error {
+	query := `
+		UPDATE api_key
+		SET revoked_at = NOW()
+		WHERE tenant_id = $1 AND id = $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return notFound("active API key not found: %s", id)
+	}
+
+	return nil
+}
+
+// TouchLastUsed records that a key was used. The timestamp is kept to the minute
+// so that busy clients do not write on every request.
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string) error {
+	query := `
+		UPDATE api_key
+		SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to update API key last use: %w", err)
+	}
+
+	return nil
+}
+
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.TenantID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		pq.Array(&key.Scopes),
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedBy,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// auditSummaries selects the fields an audit entry records about each resource type
+var auditSummaries = map[string]string{
+	"tenant":   `SELECT owner, display_name, labels_json AS labels, version FROM tenant WHERE id = $1`,
+	"library":  `SELECT tenant_id, owner, display_name, labels_json AS labels FROM library WHERE id = $1`,
+	"notebook": `SELECT tenant_id, library_id, status, owner, display_name, revision, updated_at FROM notebook WHERE id = $1`,
+	"feature":  `SELECT tenant_id, display_name, ttl::text AS ttl, expires_at, updated_at FROM feature WHERE id = $1`,
+	"product":  `SELECT tenant_id, display_name, updated_at FROM product WHERE id = $1`,
+	"tool":     `SELECT tenant_id, display_name, updated_at FROM tool WHERE id = $1`,
+}
+
+// AuditRepository handles the append-only audit log
+type AuditRepository struct {
+	db *DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// AuditFilter narrows the entries returned by List. Entries are listed newest
+// first; BeforeSequence pages back from a previous result.
+type AuditFilter struct {
+	TenantID       string
+	Principal      string
+	Action         string
+	URIPrefix      string
+	Outcome        string
+	Since          *time.Time
+	Until          *time.Time
+	BeforeSequence int64
+	Limit          int
+}
+
+// Record appends an entry to the audit log
+func (r *AuditRepository) Record(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (tenant_id, principal, source, action, target_uri, request_id,
+			outcome, error, before_summary, after_summary)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, seq, occurred_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		nullString(entry.TenantID),
+		entry.Principal,
+		entry.Source,
+		entry.Action,
+		nullString(entry.TargetURI),
+		nullString(entry.RequestID),
+		entry.Outcome,
+		nullString(entry.Error),
+		nullJSON(entry.Before),
+		nullJSON(entry.After),
+	).Scan(&entry.ID, &entry.Sequence, &entry.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves audit entries matching the filter, newest first
+func (r *AuditRepository) List(ctx context.Context, filter AuditFilter) ([]*domain.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+
+	query := `
+		SELECT id, seq, COALESCE(tenant_id, ''), principal, source, action, COALESCE(target_uri, ''),
+			COALESCE(request_id, ''), outcome, COALESCE(error, ''), before_summary, after_summary, occurred_at
+		FROM audit_log
+		WHERE ($1 = '' OR tenant_id = $1)
+			AND ($2 = '' OR principal = $2)
+			AND ($3 = '' OR action = $3)
+			AND ($4 = '' OR target_uri = $4 OR starts_with(target_uri, $4 || '/'))
+			AND ($5 = '' OR outcome = $5)
+			AND ($6::timestamptz IS NULL OR occurred_at >= $6)
+			AND ($7::timestamptz IS NULL OR occurred_at < $7)
+			AND ($8 = 0 OR seq < $8)
+		ORDER BY seq DESC
+		LIMIT $9
+	`
+
+	rows, err := r.db.QueryContext(ctx, query,
+		filter.TenantID,
+		filter.Principal,
+		filter.Action,
+		filter.URIPrefix,
+		filter.Outcome,
+		filter.Since,
+		filter.Until,
+		filter.BeforeSequence,
+		filter.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domain.AuditEntry
+
+	for rows.Next() {
+		entry := &domain.AuditEntry{}
+		var before, after []byte
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Sequence,
+			&entry.TenantID,
+			&entry.Principal,
+			&entry.Source,
+			&entry.Action,
+			&entry.TargetURI,
+			&entry.RequestID,
+			&entry.Outcome,
+			&entry.Error,
+			&before,
+			&after,
+			&entry.OccurredAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+
+		entry.Before = before
+		entry.After = after
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// Summary returns the audited fields of the resource at uri, or nil if the
+// resource does not exist or its type is not summarised
+func (r *AuditRepository) Summary(ctx context.Context, uri string) (json.RawMessage, error) {
+	ref, err := domain.ParseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	query, ok := auditSummaries[ref.Type]
+	if !ok {
+		return nil, nil
+	}
+
+	var summary []byte
+	err = r.db.QueryRowContext(ctx, `SELECT to_jsonb(s) FROM (`+query+`) s`, ref.ID).Scan(&summary)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarise %s: %w", uri, err)
+	}
+
+	return summary, nil
+}
+
+func nullJSON(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return []byte(data)
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// resourceTables maps tenant-scoped resource types to their tables
+var resourceTables = map[string]string{
+	"library":  "library",
+	"notebook": "notebook",
+	"feature":  "feature",
+	"product":  "product",
+	"tool":     "tool",
+}
+
+// ownedResourceTables maps the resource types that record an owner to their tables
+var ownedResourceTables = map[string]string{
+	"tenant":   "tenant",
+	"library":  "library",
+	"notebook": "notebook",
+}
+
+// AuthzRepository reads the resource attributes that authorization policies decide on
+type AuthzRepository struct {
+	db *DB
+}
+
+// NewAuthzRepository creates a new authorization attribute repository
+func NewAuthzRepository(db *DB) *AuthzRepository {
+	return &AuthzRepository{db: db}
+}
+
+// TenantOf returns the tenant a resource belongs to
+func (r *AuthzRepository) TenantOf(ctx context.Context, resourceType, id string) (string, error) {
+	table, ok := resourceTables[resourceType]
+	if !ok {
+		return "", fmt.Errorf("unknown resource type: %s", resourceType)
+	}
+
+	var tenantID string
+	err := r.db.QueryRowContext(ctx, `SELECT tenant_id FROM `+table+` WHERE id = $1`, id).Scan(&tenantID)
+	if err == sql.ErrNoRows {
+		return "", notFound("%s not found: %s", resourceType, id)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s tenant: %w", resourceType, err)
+	}
+
+	return tenantID, nil
+}
+
+// Owner returns the owner of a tenant, library or notebook, or "" for other
+// resource types and resources that do not exist
+func (r *AuthzRepository) Owner(ctx context.Context, resourceType, id string) (string, error) {
+	table, ok := ownedResourceTables[resourceType]
+	if !ok {
+		return "", nil
+	}
+
+	var owner string
+	err := r.db.QueryRowContext(ctx, `SELECT owner FROM `+table+` WHERE id = $1`, id).Scan(&owner)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s owner: %w", resourceType, err)
+	}
+
+	return owner, nil
+}
+
+// productRoleRank orders a column of product roles from most to least privileged
+func productRoleRank(column string) string {
+	return `CASE ` + column + ` WHEN 'owner' THEN 1 WHEN 'contributor' THEN 2 ELSE 3 END`
+}
+
+// ProductRole returns the most privileged role any of the given user IDs holds on a product, or ""
+func (r *AuthzRepository) ProductRole(ctx context.Context, productID string, userIDs ...string) (string, error) {
+	query := `
+		SELECT role
+		FROM product_user
+		WHERE product_id = $1 AND user_id = ANY($2)
+		ORDER BY ` + productRoleRank("role") + `
+		LIMIT 1
+	`
+
+	var role string
+	err := r.db.QueryRowContext(ctx, query, productID, pq.Array(userIDs)).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get product role: %w", err)
+	}
+
+	return role, nil
+}
+
+// AttachedProductRole returns the most privileged role any of the given user IDs
+// holds on the products a notebook or feature is part of, or ""
+func (r *AuthzRepository) AttachedProductRole(ctx context.Context, resourceType, id string, userIDs ...string) (string, error) {
+	query := `
+		SELECT pu.role
+		FROM product_resource pr
+		JOIN product_user pu ON pu.product_id = pr.product_id
+		WHERE pr.resource_type = $1 AND pr.resource_id = $2 AND pu.user_id = ANY($3)
+		ORDER BY ` + productRoleRank("pu.role") + `
+		LIMIT 1
+	`
+
+	var role string
+	err := r.db.QueryRowContext(ctx, query, resourceType, id, pq.Array(userIDs)).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get product role: %w", err)
+	}
+
+	return role, nil
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// BlobRepository handles blob metadata persistence; the bytes live in a blob store
+type BlobRepository struct {
+	db *DB
+}
+
+// NewBlobRepository creates a new blob repository
+func NewBlobRepository(db *DB) *BlobRepository {
+	return &BlobRepository{db: db}
+}
+
+// Create records a blob. Blobs are content-addressed, so recording one that
+// exists already changes nothing; blob then holds the existing record.
+func (r *BlobRepository) Create(ctx context.Context, blob *domain.Blob) error {
+	query := `
+		INSERT INTO blob (tenant_id, digest, media_type, size)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, digest) DO UPDATE SET digest = EXCLUDED.digest
+		RETURNING media_type, size, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, blob.TenantID, blob.Digest, blob.MediaType, blob.Size).Scan(
+		&blob.MediaType,
+		&blob.Size,
+		&blob.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create blob: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves a blob's metadata
+func (r *BlobRepository) Get(ctx context.Context, tenantID, digest string) (*domain.Blob, error) {
+	query := `
+		SELECT tenant_id, digest, media_type, size, created_at
+		FROM blob
+		WHERE tenant_id = $1 AND digest = $2
+	`
+
+	blob := &domain.Blob{}
+	err := r.db.QueryRowContext(ctx, query, tenantID, digest).Scan(
+		&blob.TenantID,
+		&blob.Digest,
+		&blob.MediaType,
+		&blob.Size,
+		&blob.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, notFound("blob not found: %s", digest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob: %w", err)
+	}
+
+	return blob, nil
+}
+
+// Find retrieves the metadata of a blob with the given digest in any tenant
+// the caller may see. Blobs are content-addressed, so every such blob has the
+// same media type and size.
+func (r *BlobRepository) Find(ctx context.Context, digest string) (*domain.Blob, error) {
+	query := `
+		SELECT tenant_id, digest, media_type, size, created_at
+		FROM blob
+		WHERE digest = $1
+		ORDER BY created_at
+		LIMIT 1
+	`
+
+	blob := &domain.Blob{}
+	err := r.db.QueryRowContext(ctx, query, digest).Scan(
+		&blob.TenantID,
+		&blob.Digest,
+		&blob.MediaType,
+		&blob.Size,
+		&blob.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, notFound("blob not found: %s", digest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob: %w", err)
+	}
+
+	return blob, nil
+}
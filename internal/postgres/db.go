@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/prismon/synthesis/internal/config"
 )
 
@@ -15,13 +15,16 @@ type DB struct {
 	*sql.DB
 }
 
-// New creates a new database connection
+// New creates a new database connection. Each query runs in the tenant scope of
+// its context; see WithTenantScope and WithSystemScope.
 func New(cfg config.DatabaseConfig) (*DB, error) {
-	db, err := sql.Open("postgres", cfg.URL)
+	connector, err := pq.NewConnector(cfg.URL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	db := sql.OpenDB(&scopedConnector{Connector: connector})
+
 	// Set connection pool settings
 	db.SetMaxOpenConns(cfg.MaxOpenConns)
 	db.SetMaxIdleConns(cfg.MaxIdleConns)
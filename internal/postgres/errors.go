@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrNotFound is matched by the errors of reads and writes of resources
+	// that do not exist, or are not visible in the caller's tenant scope
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is matched by the errors of writes that clash with an
+	// existing resource, such as creating one whose ID is taken
+	ErrConflict = errors.New("conflict")
+	// ErrInvalidArgument is matched by the errors of malformed list options,
+	// such as a cursor or label selector that does not parse
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// notFoundError reports a missing resource; it matches ErrNotFound
+type notFoundError struct {
+	message string
+}
+
+func (e *notFoundError) Error() string { return e.message }
+
+func (e *notFoundError) Is(target error) bool { return target == ErrNotFound }
+
+// notFound returns an error matching ErrNotFound with a formatted message,
+// e.g. notFound("tenant not found: %s", id)
+func notFound(format string, args ...interface{}) error {
+	return &notFoundError{message: fmt.Sprintf(format, args...)}
+}
+
+// invalidError reports a malformed argument; it matches ErrInvalidArgument
+type invalidError struct {
+	message string
+}
+
+func (e *invalidError) Error() string { return e.message }
+
+func (e *invalidError) Is(target error) bool { return target == ErrInvalidArgument }
+
+// invalid returns an error matching ErrInvalidArgument with a formatted message
+func invalid(format string, args ...interface{}) error {
+	return &invalidError{message: fmt.Sprintf(format, args...)}
+}
+
+// conflictError is a unique violation; it matches ErrConflict
+type conflictError struct {
+	err error
+}
+
+func (e *conflictError) Error() string { return e.err.Error() }
+
+func (e *conflictError) Unwrap() error { return e.err }
+
+func (e *conflictError) Is(target error) bool { return target == ErrConflict }
+
+// uniqueViolation is the SQLSTATE of a unique constraint violation
+const uniqueViolation = "23505"
+
+// asConflict returns err as an error matching ErrConflict when it is a unique
+// constraint violation, and unchanged otherwise
+func asConflict(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return &conflictError{err: err}
+	}
+	return err
+}
@@ -0,0 +1,253 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// EventChannel is the NOTIFY channel signalled with the event ID whenever an event is committed
+const EventChannel = "domain_event"
+
+// EventRepository handles the domain event outbox
+type EventRepository struct {
+	db *DB
+}
+
+// NewEventRepository creates a new event repository
+func NewEventRepository(db *DB) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+// PendingEvent is an outbox event claimed for dispatch
+type PendingEvent struct {
+	Event     *domain.Event
+	HandledBy []string
+	Attempts  int
+}
+
+// EventFilter narrows the events returned by ListSince
+type EventFilter struct {
+	AfterSequence int64
+	TenantID      string
+	URIPrefix     string
+	Limit         int
+}
+
+// insertEvent writes an event to the outbox as part of tx. Listeners on
+// EventChannel are notified when (and only if) the transaction commits.
+func insertEvent(ctx context.Context, tx *sql.Tx, event *domain.Event) error {
+	query := `
+		INSERT INTO domain_event (event_type, tenant_id, resource_type, resource_id, resource_uri, payload)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, seq, occurred_at
+	`
+
+	err := tx.QueryRowContext(ctx, query,
+		event.Type,
+		nullString(event.TenantID),
+		event.ResourceType,
+		event.ResourceID,
+		event.URI,
+		[]byte(event.Data),
+	).Scan(&event.ID, &event.Sequence, &event.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record %s event: %w", event.Type, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_notify($1, $2)`, EventChannel, event.ID); err != nil {
+		return fmt.Errorf("failed to notify event: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves an event by ID
+func (r *EventRepository) Get(ctx context.Context, id string) (*domain.Event, error) {
+	query := `
+		SELECT id, seq, event_type, COALESCE(tenant_id, ''), resource_type, resource_id, resource_uri, payload, occurred_at
+		FROM domain_event
+		WHERE id = $1
+	`
+
+	event := &domain.Event{}
+	var payload []byte
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&event.ID,
+		&event.Sequence,
+		&event.Type,
+		&event.TenantID,
+		&event.ResourceType,
+		&event.ResourceID,
+		&event.URI,
+		&payload,
+		&event.OccurredAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, notFound("event not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+
+	event.Data = payload
+	return event, nil
+}
+
+// ClaimPending returns up to limit pending events that are due for dispatch, oldest first.
+// Claimed events are leased for the given duration so that other dispatchers skip them.
+func (r *EventRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*PendingEvent, error) {
+	query := `
+		UPDATE domain_event
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM domain_event
+			WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY seq
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, seq, event_type, COALESCE(tenant_id, ''), resource_type, resource_id, resource_uri,
+			payload, occurred_at, handled_by, attempts
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim events: %w", err)
+	}
+	defer rows.Close()
+
+	var pending []*PendingEvent
+
+	for rows.Next() {
+		event := &domain.Event{}
+		p := &PendingEvent{Event: event}
+		var payload []byte
+
+		err := rows.Scan(
+			&event.ID,
+			&event.Sequence,
+			&event.Type,
+			&event.TenantID,
+			&event.ResourceType,
+			&event.ResourceID,
+			&event.URI,
+			&payload,
+			&event.OccurredAt,
+			pq.Array(&p.HandledBy),
+			&p.Attempts,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+
+		event.Data = payload
+		pending = append(pending, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating events: %w", err)
+	}
+
+	// Rows come back from UPDATE in no particular order
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Event.Sequence < pending[j].Event.Sequence
+	})
+	return pending, nil
+}
+
+// MarkPublished records that every subscriber has handled an event
+func (r *EventRepository) MarkPublished(ctx context.Context, id string, handledBy []string) error {
+	query := `
+		UPDATE domain_event
+		SET status = 'published', handled_by = $2, published_at = CURRENT_TIMESTAMP, last_error = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, pq.Array(handledBy)); err != nil {
+		return fmt.Errorf("failed to mark event published: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed dispatch. The event is retried at nextAttempt, or
+// marked failed for good when giveUp is set. handledBy lists the subscribers
+// that succeeded and are skipped on retry.
+func (r *EventRepository) MarkFailed(ctx context.Context, id string, handledBy []string, dispatchErr error, nextAttempt time.Time, giveUp bool) error {
+	status := "pending"
+	if giveUp {
+		status = "failed"
+	}
+
+	query := `
+		UPDATE domain_event
+		SET status = $2, handled_by = $3, attempts = attempts + 1, next_attempt_at = $4, last_error = $5
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, status, pq.Array(handledBy), nextAttempt, dispatchErr.Error())
+	if err != nil {
+		return fmt.Errorf("failed to mark event failed: %w", err)
+	}
+
+	return nil
+}
+
+// ListSince retrieves events after a sequence number in sequence order.
+// URIPrefix matches the URI itself and every resource below it.
+func (r *EventRepository) ListSince(ctx context.Context, filter EventFilter) ([]*domain.Event, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+
+	query := `
+		SELECT id, seq, event_type, COALESCE(tenant_id, ''), resource_type, resource_id, resource_uri, payload, occurred_at
+		FROM domain_event
+		WHERE seq > $1
+			AND ($2 = '' OR tenant_id = $2)
+			AND ($3 = '' OR resource_uri = $3 OR starts_with(resource_uri, $3 || '/'))
+		ORDER BY seq
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.AfterSequence, filter.TenantID, filter.URIPrefix, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.Event
+
+	for rows.Next() {
+		event := &domain.Event{}
+		var payload []byte
+
+		err := rows.Scan(
+			&event.ID,
+			&event.Sequence,
+			&event.Type,
+			&event.TenantID,
+			&event.ResourceType,
+			&event.ResourceID,
+			&event.URI,
+			&payload,
+			&event.OccurredAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+
+		event.Data = payload
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,337 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// FeatureRepository handles feature persistence
+type FeatureRepository struct {
+	db *DB
+}
+
+// NewFeatureRepository creates a new feature repository
+func NewFeatureRepository(db *DB) *FeatureRepository {
+	return &FeatureRepository{db: db}
+}
+
+// featureColumns is the column list read by scanFeature
+const featureColumns = `id, tenant_id, display_name, COALESCE(description, ''),
+	COALESCE(EXTRACT(EPOCH FROM ttl), 0), values_json, sensitive_keys,
+	COALESCE(value_types_json, '{}')`
+
+// Create inserts a new feature with its resources and notifications. Its
+// sensitive values must already be sealed; the feature.created event carries
+// them redacted.
+func (r *FeatureRepository) Create(ctx context.Context, feature *domain.Feature) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	valuesJSON, err := json.Marshal(feature.Values)
+	if err != nil {
+		return fmt.Errorf("failed to marshal values: %w", err)
+	}
+
+	valueTypesJSON, err := json.Marshal(feature.ValueTypes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value types: %w", err)
+	}
+	if feature.ValueTypes == nil {
+		valueTypesJSON = []byte(`{}`)
+	}
+
+	query := `
+		INSERT INTO feature (id, tenant_id, display_name, description, ttl, expires_at, values_json, sensitive_keys, value_types_json)
+		VALUES ($1, $2, $3, $4,
+			CASE WHEN $5::float8 > 0 THEN make_interval(secs => $5::float8) END,
+			CASE WHEN $5::float8 > 0 THEN CURRENT_TIMESTAMP + make_interval(secs => $5::float8) END,
+			$6, $7, $8)
+	`
+
+	_, err = tx.ExecContext(ctx, query,
+		feature.ID,
+		feature.TenantID,
+		feature.DisplayName,
+		feature.Description,
+		feature.TTL.Seconds(),
+		valuesJSON,
+		pq.Array(feature.SensitiveKeys),
+		valueTypesJSON,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create feature: %w", asConflict(err))
+	}
+
+	for _, resource := range feature.Resources {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO feature_resource (feature_id, url) VALUES ($1, $2)`, feature.ID, resource.URL); err != nil {
+			return fmt.Errorf("failed to add feature resource: %w", err)
+		}
+	}
+
+	for _, notif := range feature.Notifications {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO feature_notification (feature_id, nurl) VALUES ($1, $2)`, feature.ID, notif.URL); err != nil {
+			return fmt.Errorf("failed to add feature notification: %w", err)
+		}
+	}
+
+	redacted := feature.Redacted()
+	event, err := domain.NewEvent(domain.EventFeatureCreated, feature.TenantID, feature.ID, feature.URI(), redacted)
+	if err != nil {
+		return err
+	}
+
+	if err := insertEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Get retrieves a feature by ID
+func (r *FeatureRepository) Get(ctx context.Context, id string) (*domain.Feature, error) {
+	query := `SELECT ` + featureColumns + ` FROM feature WHERE id = $1`
+
+	feature, err := scanFeature(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, notFound("feature not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feature: %w", err)
+	}
+
+	if err := r.loadRelations(ctx, r.db.DB, feature); err != nil {
+		return nil, err
+	}
+
+	return feature, nil
+}
+
+// ExpireDue deletes up to limit features whose TTL has elapsed, recording a
+// feature.expired event for each in the same transaction. It returns the
+// number of features expired.
+func (r *FeatureRepository) ExpireDue(ctx context.Context, limit int) (int, error) {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + featureColumns + `
+		FROM feature
+		WHERE expires_at IS NOT NULL AND expires_at <= CURRENT_TIMESTAMP
+		ORDER BY expires_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired features: %w", err)
+	}
+
+	var expired []*domain.Feature
+	for rows.Next() {
+		feature, err := scanFeature(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan feature: %w", err)
+		}
+		expired = append(expired, feature)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating features: %w", err)
+	}
+
+	for _, feature := range expired {
+		// Capture notifications and resources before they are deleted with the feature
+		if err := r.loadRelations(ctx, tx, feature); err != nil {
+			return 0, err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM feature WHERE id = $1`, feature.ID); err != nil {
+			return 0, fmt.Errorf("failed to delete expired feature: %w", err)
+		}
+
+		event, err := domain.NewEvent(domain.EventFeatureExpired, feature.TenantID, feature.ID, feature.URI(), feature.Redacted())
+		if err != nil {
+			return 0, err
+		}
+
+		if err := insertEvent(ctx, tx, event); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit expired features: %w", err)
+	}
+
+	return len(expired), nil
+}
+
+// RewrapSensitive passes each sensitive value of every feature to rewrap,
+// storing those it changes. It returns the number of features changed.
+func (r *FeatureRepository) RewrapSensitive(ctx context.Context, rewrap func(context.Context, string) (string, bool, error)) (int, error) {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + featureColumns + `
+		FROM feature
+		WHERE cardinality(sensitive_keys) > 0
+		FOR UPDATE
+	`
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find sensitive features: %w", err)
+	}
+
+	var features []*domain.Feature
+	for rows.Next() {
+		feature, err := scanFeature(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan feature: %w", err)
+		}
+		features = append(features, feature)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating features: %w", err)
+	}
+
+	changed := 0
+	for _, feature := range features {
+		updated := false
+		for _, key := range feature.SensitiveKeys {
+			value, ok := feature.Values[key]
+			if !ok {
+				continue
+			}
+
+			rewrapped, ok, err := rewrap(ctx, value)
+			if err != nil {
+				return 0, fmt.Errorf("failed to rewrap %s of feature %s: %w", key, feature.ID, err)
+			}
+			if ok {
+				feature.Values[key] = rewrapped
+				updated = true
+			}
+		}
+		if !updated {
+			continue
+		}
+
+		valuesJSON, err := json.Marshal(feature.Values)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal values: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE feature SET values_json = $2 WHERE id = $1`, feature.ID, valuesJSON); err != nil {
+			return 0, fmt.Errorf("failed to update feature: %w", err)
+		}
+		changed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit rewrapped features: %w", err)
+	}
+
+	return changed, nil
+}
+
+// Helper methods
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanFeature(row rowScanner) (*domain.Feature, error) {
+	feature := &domain.Feature{}
+	var ttlSeconds float64
+	var valuesJSON, valueTypesJSON []byte
+
+	err := row.Scan(
+		&feature.ID,
+		&feature.TenantID,
+		&feature.DisplayName,
+		&feature.Description,
+		&ttlSeconds,
+		&valuesJSON,
+		pq.Array(&feature.SensitiveKeys),
+		&valueTypesJSON,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	feature.TTL = time.Duration(ttlSeconds * float64(time.Second))
+
+	if err := json.Unmarshal(valuesJSON, &feature.Values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal values: %w", err)
+	}
+
+	if err := json.Unmarshal(valueTypesJSON, &feature.ValueTypes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal value types: %w", err)
+	}
+	if len(feature.ValueTypes) == 0 {
+		feature.ValueTypes = nil
+	}
+
+	return feature, nil
+}
+
+func (r *FeatureRepository) loadRelations(ctx context.Context, q queryer, feature *domain.Feature) error {
+	resourceRows, err := q.QueryContext(ctx, `SELECT url FROM feature_resource WHERE feature_id = $1`, feature.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get feature resources: %w", err)
+	}
+	defer resourceRows.Close()
+
+	for resourceRows.Next() {
+		var resource domain.ExternalResource
+		if err := resourceRows.Scan(&resource.URL); err != nil {
+			return fmt.Errorf("failed to scan feature resource: %w", err)
+		}
+		feature.Resources = append(feature.Resources, resource)
+	}
+	if err := resourceRows.Err(); err != nil {
+		return err
+	}
+
+	notifRows, err := q.QueryContext(ctx, `SELECT nurl FROM feature_notification WHERE feature_id = $1`, feature.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get feature notifications: %w", err)
+	}
+	defer notifRows.Close()
+
+	for notifRows.Next() {
+		var notif domain.Notification
+		if err := notifRows.Scan(&notif.URL); err != nil {
+			return fmt.Errorf("failed to scan feature notification: %w", err)
+		}
+		feature.Notifications = append(feature.Notifications, notif)
+	}
+
+	return notifRows.Err()
+}
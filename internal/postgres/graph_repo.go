@@ -2,9 +2,16 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 )
 
+// loadAGE loads Apache AGE into a session. The servers connect as a role
+// without superuser rights (migration 019), which may only load libraries from
+// $libdir/plugins, where the postgres image links AGE.
+const loadAGE = `LOAD '$libdir/plugins/age'`
+
 // GraphRepository handles graph operations using Apache AGE
 type GraphRepository struct {
 	db *DB
@@ -58,7 +65,7 @@ func (r *GraphRepository) ExecuteCypherQuery(ctx context.Context, query string,
 // FindNotebooksForProduct finds all notebooks associated with a product within N hops
 func (r *GraphRepository) FindNotebooksForProduct(ctx context.Context, productID string, maxHops int) ([]string, error) {
 	// Set the search path
-	_, err := r.db.ExecContext(ctx, "LOAD 'age'")
+	_, err := r.db.ExecContext(ctx, loadAGE)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load age: %w", err)
 	}
@@ -76,7 +83,7 @@ func (r *GraphRepository) FindNotebooksForProduct(ctx context.Context, productID
 // FindFeatureLineage traces the lineage of a feature back to its source notebooks
 func (r *GraphRepository) FindFeatureLineage(ctx context.Context, featureID string) ([]GraphNode, error) {
 	// Set the search path
-	_, err := r.db.ExecContext(ctx, "LOAD 'age'")
+	_, err := r.db.ExecContext(ctx, loadAGE)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load age: %w", err)
 	}
@@ -93,7 +100,7 @@ func (r *GraphRepository) FindFeatureLineage(ctx context.Context, featureID stri
 // CreateRelationship creates a relationship between two entities
 func (r *GraphRepository) CreateRelationship(ctx context.Context, fromType, fromID, relType, toType, toID string) error {
 	// Set the search path
-	_, err := r.db.ExecContext(ctx, "LOAD 'age'")
+	_, err := r.db.ExecContext(ctx, loadAGE)
 	if err != nil {
 		return fmt.Errorf("failed to load age: %w", err)
 	}
@@ -123,7 +130,7 @@ func (r *GraphRepository) CreateRelationship(ctx context.Context, fromType, from
 // DeleteRelationship deletes a relationship between two entities
 func (r *GraphRepository) DeleteRelationship(ctx context.Context, fromID, relType, toID string) error {
 	// Set the search path
-	_, err := r.db.ExecContext(ctx, "LOAD 'age'")
+	_, err := r.db.ExecContext(ctx, loadAGE)
 	if err != nil {
 		return fmt.Errorf("failed to load age: %w", err)
 	}
@@ -152,7 +159,7 @@ func (r *GraphRepository) DeleteRelationship(ctx context.Context, fromID, relTyp
 // GetNeighbors gets all neighbors of a node within a certain distance
 func (r *GraphRepository) GetNeighbors(ctx context.Context, nodeID string, distance int) ([]GraphNode, error) {
 	// Set the search path
-	_, err := r.db.ExecContext(ctx, "LOAD 'age'")
+	_, err := r.db.ExecContext(ctx, loadAGE)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load age: %w", err)
 	}
@@ -165,3 +172,103 @@ func (r *GraphRepository) GetNeighbors(ctx context.Context, nodeID string, dista
 	// This is a placeholder implementation
 	return []GraphNode{}, nil
 }
+
+// useGraph loads Apache AGE for the rest of tx
+func useGraph(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, loadAGE); err != nil {
+		return fmt.Errorf("failed to load age: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "SET LOCAL search_path = ag_catalog, \"$user\", public"); err != nil {
+		return fmt.Errorf("failed to set search path: %w", err)
+	}
+
+	return nil
+}
+
+// cypherParams encodes the parameters of a Cypher query as agtype
+func cypherParams(params map[string]string) (string, error) {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal graph parameters: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// mergeGraphEdge links two vertices with an edge as part of tx, creating the
+// vertices if they are missing. Labels and the relationship type are fixed by the
+// caller; only the IDs are passed as parameters.
+func mergeGraphEdge(ctx context.Context, tx *sql.Tx, fromLabel, fromID, relType, toLabel, toID string) error {
+	if err := useGraph(ctx, tx); err != nil {
+		return err
+	}
+
+	params, err := cypherParams(map[string]string{"from_id": fromID, "to_id": toID})
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT * FROM cypher('synthesis_graph', $$
+			MERGE (a:%s {id: $from_id})
+			MERGE (b:%s {id: $to_id})
+			MERGE (a)-[:%s]->(b)
+		$$, $1) AS (result agtype)
+	`, fromLabel, toLabel, relType)
+
+	if _, err := tx.ExecContext(ctx, query, params); err != nil {
+		return fmt.Errorf("failed to create %s relationship: %w", relType, err)
+	}
+
+	return nil
+}
+
+// deleteGraphEdge removes the edge between two vertices as part of tx
+func deleteGraphEdge(ctx context.Context, tx *sql.Tx, fromLabel, fromID, relType, toLabel, toID string) error {
+	if err := useGraph(ctx, tx); err != nil {
+		return err
+	}
+
+	params, err := cypherParams(map[string]string{"from_id": fromID, "to_id": toID})
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT * FROM cypher('synthesis_graph', $$
+			MATCH (a:%s {id: $from_id})-[r:%s]->(b:%s {id: $to_id})
+			DELETE r
+		$$, $1) AS (result agtype)
+	`, fromLabel, relType, toLabel)
+
+	if _, err := tx.ExecContext(ctx, query, params); err != nil {
+		return fmt.Errorf("failed to delete %s relationship: %w", relType, err)
+	}
+
+	return nil
+}
+
+// deleteGraphVertex removes a vertex and its edges as part of tx
+func deleteGraphVertex(ctx context.Context, tx *sql.Tx, label, id string) error {
+	if err := useGraph(ctx, tx); err != nil {
+		return err
+	}
+
+	params, err := cypherParams(map[string]string{"id": id})
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT * FROM cypher('synthesis_graph', $$
+			MATCH (v:%s {id: $id})
+			DETACH DELETE v
+		$$, $1) AS (result agtype)
+	`, label)
+
+	if _, err := tx.ExecContext(ctx, query, params); err != nil {
+		return fmt.Errorf("failed to delete %s vertex: %w", label, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// LibraryRepository handles library persistence
+type LibraryRepository struct {
+	db *DB
+}
+
+// libraryColumns are the columns scanLibrary reads
+const libraryColumns = `id, tenant_id, owner, display_name, COALESCE(description, ''), labels_json`
+
+// NewLibraryRepository creates a new library repository
+func NewLibraryRepository(db *DB) *LibraryRepository {
+	return &LibraryRepository{db: db}
+}
+
+// Create creates a new library
+func (r *LibraryRepository) Create(ctx context.Context, library *domain.Library) error {
+	labelsJSON, err := json.Marshal(library.Labels)
+	if err != nil {
+		return fmt.Errorf("failed to marshal labels: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO library (id, tenant_id, owner, display_name, description, labels_json)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = tx.ExecContext(ctx, query,
+		library.ID,
+		library.TenantID,
+		library.Owner,
+		library.DisplayName,
+		library.Description,
+		labelsJSON,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create library: %w", asConflict(err))
+	}
+
+	if err := r.recordEvent(ctx, tx, domain.EventLibraryCreated, library); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Get retrieves a library by ID
+func (r *LibraryRepository) Get(ctx context.Context, id string) (*domain.Library, error) {
+	query := `SELECT ` + libraryColumns + ` FROM library WHERE id = $1`
+
+	library, err := scanLibrary(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, notFound("library not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get library: %w", err)
+	}
+
+	return library, nil
+}
+
+// libraryListing lists libraries, newest first unless sorted otherwise
+var libraryListing = &listing{
+	sorts: map[string]sortColumn{
+		"id":           {"id", "text"},
+		"display_name": {"display_name", "text"},
+		"created_at":   {"created_at", "timestamptz"},
+	},
+	defaultSort: "-created_at",
+	labels:      "labels_json",
+	owner:       "owner",
+}
+
+// LibrarySorts lists the fields libraries can be sorted by
+func LibrarySorts() []string { return libraryListing.Sorts() }
+
+// ListByTenant retrieves a page of the libraries of a tenant that match opts'
+// label selector and owner
+func (r *LibraryRepository) ListByTenant(ctx context.Context, tenantID string, opts ListOptions) (*domain.Page[*domain.Library], error) {
+	query, args, sortName, limit, err := libraryListing.query(libraryColumns, "library",
+		[]string{"tenant_id = $1"}, []interface{}{tenantID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list libraries: %w", err)
+	}
+	defer rows.Close()
+
+	page, err := scanPage(rows, sortName, limit, func(row rowScanner) (*domain.Library, string, error) {
+		library, err := scanLibrary(row)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to scan library: %w", err)
+		}
+		return library, library.ID, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error iterating libraries: %w", err)
+	}
+
+	return page, nil
+}
+
+// Update replaces a library's owner, display name, description and labels.
+// Its tenant is kept.
+func (r *LibraryRepository) Update(ctx context.Context, library *domain.Library) error {
+	labelsJSON, err := json.Marshal(library.Labels)
+	if err != nil {
+		return fmt.Errorf("failed to marshal labels: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE library
+		SET owner = $2, display_name = $3, description = $4, labels_json = $5
+		WHERE id = $1
+		RETURNING tenant_id
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		library.ID,
+		library.Owner,
+		library.DisplayName,
+		library.Description,
+		labelsJSON,
+	).Scan(&library.TenantID)
+	if err == sql.ErrNoRows {
+		return notFound("library not found: %s", library.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update library: %w", err)
+	}
+
+	if err := r.recordEvent(ctx, tx, domain.EventLibraryUpdated, library); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a library with its notebooks, recording the deletion of each
+func (r *LibraryRepository) Delete(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Capture the notebooks that go with the library for their events
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, tenant_id, library_id, status, owner, display_name, COALESCE(description, ''), revision
+		FROM notebook
+		WHERE library_id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to list notebooks of library: %w", err)
+	}
+	var notebooks []*domain.Notebook
+	for rows.Next() {
+		notebook := &domain.Notebook{}
+		if err := rows.Scan(&notebook.ID, &notebook.TenantID, &notebook.LibraryID, &notebook.Status,
+			&notebook.Owner, &notebook.DisplayName, &notebook.Description, &notebook.Revision); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan notebook: %w", err)
+		}
+		notebooks = append(notebooks, notebook)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating notebooks: %w", err)
+	}
+
+	library, err := scanLibrary(tx.QueryRowContext(ctx, `
+		DELETE FROM library
+		WHERE id = $1
+		RETURNING `+libraryColumns, id))
+	if err == sql.ErrNoRows {
+		return notFound("library not found: %s", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete library: %w", err)
+	}
+
+	for _, notebook := range notebooks {
+		event, err := domain.NewEvent(domain.EventNotebookDeleted, notebook.TenantID, notebook.ID, notebook.URI(), notebook)
+		if err != nil {
+			return err
+		}
+		if err := insertEvent(ctx, tx, event); err != nil {
+			return err
+		}
+	}
+
+	if err := r.recordEvent(ctx, tx, domain.EventLibraryDeleted, library); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func scanLibrary(row rowScanner) (*domain.Library, error) {
+	library := &domain.Library{}
+	var labelsJSON []byte
+
+	if err := row.Scan(
+		&library.ID,
+		&library.TenantID,
+		&library.Owner,
+		&library.DisplayName,
+		&library.Description,
+		&labelsJSON,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(labelsJSON) > 0 {
+		if err := json.Unmarshal(labelsJSON, &library.Labels); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal labels: %w", err)
+		}
+	}
+
+	return library, nil
+}
+
+// recordEvent writes a library event to the outbox as part of tx
+func (r *LibraryRepository) recordEvent(ctx context.Context, tx *sql.Tx, eventType string, library *domain.Library) error {
+	event, err := domain.NewEvent(eventType, library.TenantID, library.ID, library.URI(), library)
+	if err != nil {
+		return err
+	}
+
+	return insertEvent(ctx, tx, event)
+}
@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+const (
+	// DefaultListLimit is the page size of a listing that sets no limit
+	DefaultListLimit = 50
+	// MaxListLimit bounds the page size of a listing
+	MaxListLimit = 200
+)
+
+// ListOptions narrows, orders and pages a listing. Filters a listing does not
+// support are rejected rather than ignored.
+type ListOptions struct {
+	// Selector is a label selector, e.g. "environment=production,app in (nginx)";
+	// see domain.ParseLabelSelector
+	Selector string
+	Owner    string
+	Status   string
+	// Sort names the field rows are ordered by, descending with a "-" prefix,
+	// e.g. "-created_at"; ties are ordered by ID
+	Sort string
+	// Cursor continues the listing after the page that returned it. It is only
+	// valid with the same Sort.
+	Cursor string
+	// Limit is the page size (default: DefaultListLimit, max: MaxListLimit)
+	Limit int
+}
+
+// sortColumn is a column a listing can be sorted by
+type sortColumn struct {
+	name string
+	// sqlType is the type a cursor's key, kept as text, is cast back to
+	sqlType string
+}
+
+// listing describes how a kind of resource is listed
+type listing struct {
+	sorts       map[string]sortColumn
+	defaultSort string
+	// labels, owner and status name the columns filters apply to; empty when
+	// the resource has no such field
+	labels string
+	owner  string
+	status string
+}
+
+// Sorts lists the fields the listing can be sorted by
+func (l *listing) Sorts() []string {
+	fields := make([]string, 0, len(l.sorts))
+	for field := range l.sorts {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// query builds the query of a page of the listing: columns of from, with the
+// sort key as a last column, where every condition holds and opts' filters and
+// cursor allow. It returns the query, its arguments, the sort and the page
+// size; one row more than the page size is selected to tell whether another
+// page follows.
+func (l *listing) query(columns, from string, where []string, args []interface{}, opts ListOptions) (string, []interface{}, string, int, error) {
+	limit := opts.Limit
+	switch {
+	case limit < 0:
+		return "", nil, "", 0, invalid("limit must not be negative")
+	case limit == 0:
+		limit = DefaultListLimit
+	case limit > MaxListLimit:
+		limit = MaxListLimit
+	}
+
+	sortName := opts.Sort
+	if sortName == "" {
+		sortName = l.defaultSort
+	}
+	column, ok := l.sorts[strings.TrimPrefix(sortName, "-")]
+	if !ok {
+		return "", nil, "", 0, invalid("cannot sort by %q; sortable fields are %s", strings.TrimPrefix(sortName, "-"), strings.Join(l.Sorts(), ", "))
+	}
+	direction, after := "ASC", ">"
+	if strings.HasPrefix(sortName, "-") {
+		direction, after = "DESC", "<"
+	}
+
+	where = append([]string(nil), where...)
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if opts.Selector != "" {
+		if l.labels == "" {
+			return "", nil, "", 0, invalid("cannot select by labels")
+		}
+		selector, err := domain.ParseLabelSelector(opts.Selector)
+		if err != nil {
+			return "", nil, "", 0, invalid("%v", err)
+		}
+		for _, requirement := range selector {
+			where = append(where, labelCondition(l.labels, requirement, arg))
+		}
+	}
+	if opts.Owner != "" {
+		if l.owner == "" {
+			return "", nil, "", 0, invalid("cannot filter by owner")
+		}
+		where = append(where, l.owner+" = "+arg(opts.Owner))
+	}
+	if opts.Status != "" {
+		if l.status == "" {
+			return "", nil, "", 0, invalid("cannot filter by status")
+		}
+		where = append(where, l.status+" = "+arg(opts.Status))
+	}
+
+	if opts.Cursor != "" {
+		cursor, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return "", nil, "", 0, err
+		}
+		if cursor.Sort != sortName {
+			return "", nil, "", 0, invalid("the cursor continues a listing sorted by %q, not %q", cursor.Sort, sortName)
+		}
+		where = append(where, fmt.Sprintf("(%s, id) %s (%s::%s, %s)", column.name, after, arg(cursor.Key), column.sqlType, arg(cursor.ID)))
+	}
+
+	query := fmt.Sprintf("SELECT %s, %s::text FROM %s", columns, column.name, from)
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT %s", column.name, direction, direction, arg(limit+1))
+
+	return query, args, sortName, limit, nil
+}
+
+// labelCondition returns the SQL condition of a label requirement on a JSONB
+// column of labels. Equality and existence use the containment and key
+// operators a GIN index serves; a row without labels meets negations.
+func labelCondition(column string, requirement domain.LabelRequirement, arg func(interface{}) string) string {
+	contains := func(value string) string {
+		label, _ := json.Marshal(map[string]string{requirement.Key: value})
+		return column + " @> " + arg(string(label)) + "::jsonb"
+	}
+	anyOf := func() string {
+		conditions := make([]string, len(requirement.Values))
+		for i, value := range requirement.Values {
+			conditions[i] = contains(value)
+		}
+		return "(" + strings.Join(conditions, " OR ") + ")"
+	}
+
+	switch requirement.Operator {
+	case domain.LabelEquals:
+		return contains(requirement.Values[0])
+	case domain.LabelNotEquals:
+		return "NOT COALESCE(" + contains(requirement.Values[0]) + ", false)"
+	case domain.LabelIn:
+		return anyOf()
+	case domain.LabelNotIn:
+		return "NOT COALESCE(" + anyOf() + ", false)"
+	case domain.LabelDoesNotExist:
+		return "NOT COALESCE(" + column + " ? " + arg(requirement.Key) + ", false)"
+	default:
+		return column + " ? " + arg(requirement.Key)
+	}
+}
+
+// listCursor is the position a page ends at: the sort key and ID of its last row
+type listCursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   string `json:"i"`
+}
+
+func encodeCursor(cursor listCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (*listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, invalid("malformed cursor")
+	}
+	var cursor listCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, invalid("malformed cursor")
+	}
+	return &cursor, nil
+}
+
+// keyedRow scans a row whose last column is the sort key of its listing
+type keyedRow struct {
+	rows *sql.Rows
+	key  *string
+}
+
+func (r keyedRow) Scan(dest ...interface{}) error {
+	return r.rows.Scan(append(dest, r.key)...)
+}
+
+// scanPage scans up to limit rows of a query built by listing.query with scan,
+// which returns the item of a row and its ID. When a further row follows, the
+// page's NextCursor continues after its last item.
+func scanPage[T any](rows *sql.Rows, sortName string, limit int, scan func(rowScanner) (T, string, error)) (*domain.Page[T], error) {
+	page := &domain.Page[T]{Items: []T{}}
+
+	var last listCursor
+	for rows.Next() {
+		if len(page.Items) == limit {
+			page.NextCursor = encodeCursor(last)
+			break
+		}
+
+		item, id, err := scan(keyedRow{rows: rows, key: &last.Key})
+		if err != nil {
+			return nil, err
+		}
+		page.Items = append(page.Items, item)
+		last.Sort, last.ID = sortName, id
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	cursor := listCursor{Sort: "-created_at", Key: "2025-01-02 03:04:05+00", ID: "n1"}
+	got, err := decodeCursor(encodeCursor(cursor))
+	if err != nil {
+		t.Fatalf("failed to decode an encoded cursor: %v", err)
+	}
+	if *got != cursor {
+		t.Errorf("expected %+v, got %+v", cursor, *got)
+	}
+
+	malformed := map[string]string{
+		"empty":              "",
+		"not base64":         "not a cursor!",
+		"padded base64":      base64.URLEncoding.EncodeToString([]byte(`{"s":"id","k":"a","i":"a"}`)),
+		"standard alphabet":  "+/+/",
+		"not JSON":           base64.RawURLEncoding.EncodeToString([]byte("n1")),
+		"JSON array":         base64.RawURLEncoding.EncodeToString([]byte(`["id","a","a"]`)),
+		"wrongly typed keys": base64.RawURLEncoding.EncodeToString([]byte(`{"s":1,"k":"a","i":"a"}`)),
+		"no ID":              base64.RawURLEncoding.EncodeToString([]byte(`{"s":"id","k":"a"}`)),
+		"empty object":       base64.RawURLEncoding.EncodeToString([]byte(`{}`)),
+	}
+	for name, value := range malformed {
+		if _, err := decodeCursor(value); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: expected an invalid argument error, got %v", name, err)
+		}
+	}
+}
+
+func TestListingCursorSort(t *testing.T) {
+	cursor := func(sort string) string {
+		return encodeCursor(listCursor{Sort: sort, Key: "k", ID: "t1"})
+	}
+
+	tests := []struct {
+		name   string
+		sort   string
+		cursor string
+		// condition is the cursor's condition, or "" when the cursor is refused
+		condition string
+	}{
+		{"default sort", "", cursor("-created_at"), "(created_at, id) < ($1::timestamptz, $2)"},
+		{"default sort named", "-created_at", cursor("-created_at"), "(created_at, id) < ($1::timestamptz, $2)"},
+		{"ascending", "display_name", cursor("display_name"), "(display_name, id) > ($1::text, $2)"},
+		{"other direction", "created_at", cursor("-created_at"), ""},
+		{"other field", "id", cursor("display_name"), ""},
+		{"other field than the default", "", cursor("display_name"), ""},
+		{"cursor without a sort", "", cursor(""), ""},
+		{"field the listing cannot sort by", "", cursor("-owner"), ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			query, args, sortName, _, err := tenantListing.query("id", "tenant", nil, nil, ListOptions{Sort: tc.sort, Cursor: tc.cursor})
+			if tc.condition == "" {
+				if !errors.Is(err, ErrInvalidArgument) || !strings.Contains(err.Error(), "the cursor continues a listing sorted by") {
+					t.Errorf("expected the cursor to be refused, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to build the query: %v", err)
+			}
+			if !strings.Contains(query, " WHERE "+tc.condition+" ORDER BY ") {
+				t.Errorf("expected the query to hold %q, got %q", tc.condition, query)
+			}
+			if want := []interface{}{"k", "t1", DefaultListLimit + 1}; !reflect.DeepEqual(args, want) {
+				t.Errorf("expected arguments %v, got %v", want, args)
+			}
+			if tc.sort != "" && sortName != tc.sort {
+				t.Errorf("expected sort %q, got %q", tc.sort, sortName)
+			}
+		})
+	}
+}
+
+func TestListingQueryWithoutCursor(t *testing.T) {
+	query, args, sortName, limit, err := tenantListing.query("id", "tenant", []string{"id = $1"}, []interface{}{"acme"}, ListOptions{Sort: "display_name", Limit: 1000})
+	if err != nil {
+		t.Fatalf("failed to build the query: %v", err)
+	}
+
+	want := "SELECT id, display_name::text FROM tenant WHERE id = $1 ORDER BY display_name ASC, id ASC LIMIT $2"
+	if query != want {
+		t.Errorf("expected %q, got %q", want, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"acme", MaxListLimit + 1}) {
+		t.Errorf("unexpected arguments %v", args)
+	}
+	if sortName != "display_name" || limit != MaxListLimit {
+		t.Errorf("expected display_name and %d, got %s and %d", MaxListLimit, sortName, limit)
+	}
+}
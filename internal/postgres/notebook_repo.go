@@ -3,8 +3,11 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/prismon/synthesis/internal/domain"
 )
 
@@ -42,7 +45,7 @@ func (r *NotebookRepository) Create(ctx context.Context, notebook *domain.Notebo
 		notebook.Description,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create notebook: %w", err)
+		return fmt.Errorf("failed to create notebook: %w", asConflict(err))
 	}
 
 	// Insert notebook content
@@ -72,9 +75,8 @@ func (r *NotebookRepository) Create(ctx context.Context, notebook *domain.Notebo
 		}
 	}
 
-	// Update resource index
-	if err := r.updateResourceIndex(ctx, tx, notebook); err != nil {
-		return fmt.Errorf("failed to update resource index: %w", err)
+	if err := r.recordEvent(ctx, tx, domain.EventNotebookCreated, notebook.ID, nil); err != nil {
+		return err
 	}
 
 	return tx.Commit()
@@ -83,7 +85,7 @@ func (r *NotebookRepository) Create(ctx context.Context, notebook *domain.Notebo
 // Get retrieves a notebook by ID
 func (r *NotebookRepository) Get(ctx context.Context, id string) (*domain.Notebook, error) {
 	query := `
-		SELECT id, tenant_id, library_id, status, owner, display_name, description
+		SELECT id, tenant_id, library_id, status, owner, display_name, description, revision
 		FROM notebook
 		WHERE id = $1
 	`
@@ -98,10 +100,11 @@ func (r *NotebookRepository) Get(ctx context.Context, id string) (*domain.Notebo
 		&notebook.Owner,
 		&notebook.DisplayName,
 		&notebook.Description,
+		&notebook.Revision,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("notebook not found: %s", id)
+		return nil, notFound("notebook not found: %s", id)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get notebook: %w", err)
@@ -115,7 +118,7 @@ func (r *NotebookRepository) Get(ctx context.Context, id string) (*domain.Notebo
 	}
 
 	// Get content blocks
-	blocks, err := r.getContentBlocks(ctx, id)
+	blocks, err := r.getContentBlocks(ctx, r.db, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get content blocks: %w", err)
 	}
@@ -131,26 +134,41 @@ func (r *NotebookRepository) Get(ctx context.Context, id string) (*domain.Notebo
 	return notebook, nil
 }
 
-// ListByLibrary retrieves all notebooks in a library
-func (r *NotebookRepository) ListByLibrary(ctx context.Context, libraryID string) ([]*domain.Notebook, error) {
-	query := `
-		SELECT id, tenant_id, library_id, status, owner, display_name, description
-		FROM notebook
-		WHERE library_id = $1
-		ORDER BY created_at DESC
-	`
+// notebookListing lists notebooks, newest first unless sorted otherwise
+var notebookListing = &listing{
+	sorts: map[string]sortColumn{
+		"id":           {"id", "text"},
+		"display_name": {"display_name", "text"},
+		"created_at":   {"created_at", "timestamptz"},
+		"updated_at":   {"updated_at", "timestamptz"},
+	},
+	defaultSort: "-created_at",
+	owner:       "owner",
+	status:      "status",
+}
+
+// NotebookSorts lists the fields notebooks can be sorted by
+func NotebookSorts() []string { return notebookListing.Sorts() }
+
+// ListByLibrary retrieves a page of the notebooks in a library that match
+// opts' owner and status. Their contents are not read.
+func (r *NotebookRepository) ListByLibrary(ctx context.Context, libraryID string, opts ListOptions) (*domain.Page[*domain.Notebook], error) {
+	query, args, sortName, limit, err := notebookListing.query(
+		"id, tenant_id, library_id, status, owner, display_name, COALESCE(description, ''), revision",
+		"notebook", []string{"library_id = $1"}, []interface{}{libraryID}, opts)
+	if err != nil {
+		return nil, err
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, libraryID)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list notebooks: %w", err)
 	}
 	defer rows.Close()
 
-	var notebooks []*domain.Notebook
-
-	for rows.Next() {
+	return scanPage(rows, sortName, limit, func(row rowScanner) (*domain.Notebook, string, error) {
 		notebook := &domain.Notebook{}
-		err := rows.Scan(
+		err := row.Scan(
 			&notebook.ID,
 			&notebook.TenantID,
 			&notebook.LibraryID,
@@ -158,15 +176,13 @@ func (r *NotebookRepository) ListByLibrary(ctx context.Context, libraryID string
 			&notebook.Owner,
 			&notebook.DisplayName,
 			&notebook.Description,
+			&notebook.Revision,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan notebook: %w", err)
+			return nil, "", fmt.Errorf("failed to scan notebook: %w", err)
 		}
-
-		notebooks = append(notebooks, notebook)
-	}
-
-	return notebooks, rows.Err()
+		return notebook, notebook.ID, nil
+	})
 }
 
 // Update updates an existing notebook
@@ -201,7 +217,7 @@ func (r *NotebookRepository) Update(ctx context.Context, notebook *domain.Notebo
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("notebook not found: %s", notebook.ID)
+		return notFound("notebook not found: %s", notebook.ID)
 	}
 
 	// Update content
@@ -216,16 +232,31 @@ func (r *NotebookRepository) Update(ctx context.Context, notebook *domain.Notebo
 		return fmt.Errorf("failed to update notebook content: %w", err)
 	}
 
+	if err := r.recordEvent(ctx, tx, domain.EventNotebookUpdated, notebook.ID, nil); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
-// Delete deletes a notebook
-func (r *NotebookRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM notebook WHERE id = $1`
+// TransitionStatus moves a notebook from one status to another and records the change.
+// The update only applies if the notebook is still in the expected from status.
+func (r *NotebookRepository) TransitionStatus(ctx context.Context, change *domain.NotebookStatusChange) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	query := `
+		UPDATE notebook
+		SET status = $3
+		WHERE id = $1 AND status = $2
+	`
+
+	result, err := tx.ExecContext(ctx, query, change.NotebookID, change.FromStatus, change.ToStatus)
 	if err != nil {
-		return fmt.Errorf("failed to delete notebook: %w", err)
+		return fmt.Errorf("failed to update notebook status: %w", err)
 	}
 
 	rows, err := result.RowsAffected()
@@ -234,14 +265,331 @@ func (r *NotebookRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("notebook not found: %s", id)
+		return fmt.Errorf("notebook %s is no longer in status %q", change.NotebookID, change.FromStatus)
 	}
 
-	return nil
+	historyQuery := `
+		INSERT INTO notebook_status_transition (notebook_id, from_status, to_status, actor, reason)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+
+	err = tx.QueryRowContext(ctx, historyQuery,
+		change.NotebookID,
+		change.FromStatus,
+		change.ToStatus,
+		change.Actor,
+		change.Reason,
+	).Scan(&change.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record status transition: %w", err)
+	}
+
+	if err := r.recordEvent(ctx, tx, domain.EventNotebookStatusChanged, change.NotebookID, change); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListStatusChanges retrieves the status transition history of a notebook, oldest first
+func (r *NotebookRepository) ListStatusChanges(ctx context.Context, notebookID string) ([]*domain.NotebookStatusChange, error) {
+	query := `
+		SELECT notebook_id, from_status, to_status, actor, COALESCE(reason, ''), created_at
+		FROM notebook_status_transition
+		WHERE notebook_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list status changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []*domain.NotebookStatusChange
+
+	for rows.Next() {
+		change := &domain.NotebookStatusChange{}
+		err := rows.Scan(
+			&change.NotebookID,
+			&change.FromStatus,
+			&change.ToStatus,
+			&change.Actor,
+			&change.Reason,
+			&change.ChangedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan status change: %w", err)
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+// Delete deletes a notebook
+func (r *NotebookRepository) Delete(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Capture the notebook for the event before it is gone
+	snapshot, err := r.snapshot(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM notebook WHERE id = $1`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete notebook: %w", err)
+	}
+
+	event, err := domain.NewEvent(domain.EventNotebookDeleted, snapshot.TenantID, snapshot.ID, snapshot.URI(), snapshot)
+	if err != nil {
+		return err
+	}
+
+	if err := insertEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetBlocks reads a notebook's content blocks together with the revision they reflect
+func (r *NotebookRepository) GetBlocks(ctx context.Context, notebookID string) (int64, []domain.ContentBlock, error) {
+	// Repeatable read keeps the revision and blocks from the same snapshot
+	tx, err := r.db.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var revision int64
+	err = tx.QueryRowContext(ctx, `SELECT revision FROM notebook WHERE id = $1`, notebookID).Scan(&revision)
+	if err == sql.ErrNoRows {
+		return 0, nil, notFound("notebook not found: %s", notebookID)
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get notebook revision: %w", err)
+	}
+
+	blocks, err := r.getContentBlocks(ctx, tx, notebookID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get content blocks: %w", err)
+	}
+
+	return revision, blocks, nil
+}
+
+// ApplyBlockOperation sequences and applies a block operation. Applied operations
+// get the notebook's next revision and are recorded in the operation log; operations
+// that conflict with earlier ones are marked discarded and leave the notebook as is.
+// See domain.BlockOperation for the conflict rules.
+func (r *NotebookRepository) ApplyBlockOperation(ctx context.Context, op *domain.BlockOperation) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Locking the notebook row serializes operations on it across processes
+	var revision int64
+	err = tx.QueryRowContext(ctx, `SELECT revision FROM notebook WHERE id = $1 FOR UPDATE`, op.NotebookID).Scan(&revision)
+	if err == sql.ErrNoRows {
+		return notFound("notebook not found: %s", op.NotebookID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock notebook: %w", err)
+	}
+
+	// A retried operation reports its original outcome
+	var stored []byte
+	err = tx.QueryRowContext(ctx,
+		`SELECT operation FROM notebook_operation WHERE notebook_id = $1 AND op_id = $2`,
+		op.NotebookID, op.ID,
+	).Scan(&stored)
+	if err == nil {
+		return json.Unmarshal(stored, op)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check operation: %w", err)
+	}
+
+	if err := r.applyBlockOperation(ctx, tx, op); err != nil {
+		return fmt.Errorf("failed to apply %s of block %s: %w", op.Type, op.BlockUID, err)
+	}
+
+	if op.Status == domain.BlockOpDiscarded {
+		op.Revision = revision
+		return nil
+	}
+
+	op.Revision = revision + 1
+	op.Status = domain.BlockOpApplied
+	op.AppliedAt = time.Now().UTC()
+
+	payload, err := json.Marshal(op)
+	if err != nil {
+		return fmt.Errorf("failed to marshal operation: %w", err)
+	}
+
+	logQuery := `
+		INSERT INTO notebook_operation (notebook_id, revision, op_id, op_type, block_uid, actor, operation, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = tx.ExecContext(ctx, logQuery,
+		op.NotebookID,
+		op.Revision,
+		op.ID,
+		op.Type,
+		op.BlockUID,
+		op.Actor,
+		payload,
+		op.AppliedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record operation: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE notebook SET revision = $2 WHERE id = $1`, op.NotebookID, op.Revision)
+	if err != nil {
+		return fmt.Errorf("failed to update notebook revision: %w", err)
+	}
+
+	snapshot, err := r.snapshot(ctx, tx, op.NotebookID)
+	if err != nil {
+		return err
+	}
+
+	data := domain.NotebookBlocksChangedData{Notebook: snapshot, Operation: op}
+	event, err := domain.NewEvent(domain.EventNotebookBlocksChanged, snapshot.TenantID, snapshot.ID, snapshot.URI(), data)
+	if err != nil {
+		return err
+	}
+
+	if err := insertEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListBlockOperations retrieves the operations applied to a notebook after a revision, in revision order
+func (r *NotebookRepository) ListBlockOperations(ctx context.Context, notebookID string, afterRevision int64, limit int) ([]*domain.BlockOperation, error) {
+	query := `
+		SELECT operation
+		FROM notebook_operation
+		WHERE notebook_id = $1 AND revision > $2
+		ORDER BY revision
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, notebookID, afterRevision, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list block operations: %w", err)
+	}
+	defer rows.Close()
+
+	var ops []*domain.BlockOperation
+
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload); err != nil {
+			return nil, fmt.Errorf("failed to scan block operation: %w", err)
+		}
+
+		op := &domain.BlockOperation{}
+		if err := json.Unmarshal(payload, op); err != nil {
+			return nil, fmt.Errorf("failed to decode block operation: %w", err)
+		}
+
+		ops = append(ops, op)
+	}
+
+	return ops, rows.Err()
+}
+
+// TypedBlock is a content block tagged with a type, with the notebook and tenant
+// it belongs to
+type TypedBlock struct {
+	// ID is the block's row ID, the cursor of ListBlocksOfType
+	ID         string
+	NotebookID string
+	TenantID   string
+	Block      domain.ContentBlock
+	// SchemaVersion is the version of the type's JSON Schema the block was
+	// last validated against; 0 when it was written before the type had one
+	SchemaVersion int
+}
+
+// ListBlocksOfType retrieves up to limit content blocks tagged with typeName,
+// after the block with row ID after ("" for the first page). With staleOnly,
+// only blocks last validated against a schema version other than the type's
+// current one are listed.
+func (r *NotebookRepository) ListBlocksOfType(ctx context.Context, typeName string, staleOnly bool, after string, limit int) ([]*TypedBlock, error) {
+	query := `
+		SELECT cb.id, cb.notebook_id, n.tenant_id, cb.uid, cb.parent_uid, cb.content_type, COALESCE(cb.data, ''), cb."order",
+			COALESCE(MIN(cbt.schema_version), 0)
+		FROM content_block cb
+		JOIN content_block_type cbt ON cbt.content_block_id = cb.id AND cbt.type_name = $1
+		JOIN notebook n ON n.id = cb.notebook_id
+		WHERE ($3 = '' OR cb.id > $3::uuid)
+		GROUP BY cb.id, n.tenant_id
+		HAVING NOT $2 OR COALESCE(MIN(cbt.schema_version), 0) <>
+			(SELECT COALESCE(schema_version, 0) FROM type_def WHERE name = $1)
+		ORDER BY cb.id
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, typeName, staleOnly, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blocks of type: %w", err)
+	}
+	defer rows.Close()
+
+	var blocks []*TypedBlock
+
+	for rows.Next() {
+		typed := &TypedBlock{}
+
+		err := rows.Scan(
+			&typed.ID,
+			&typed.NotebookID,
+			&typed.TenantID,
+			&typed.Block.UID,
+			&typed.Block.ParentUID,
+			&typed.Block.ContentType,
+			&typed.Block.Data,
+			&typed.Block.Order,
+			&typed.SchemaVersion,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan block: %w", err)
+		}
+
+		typed.Block.Types = []string{typeName}
+		blocks = append(blocks, typed)
+	}
+
+	return blocks, rows.Err()
 }
 
 // Helper methods
 
+// blockTypeQuery tags a block with a type, recording the type's current schema
+// version: blocks are validated against their types before they are written
+const blockTypeQuery = `
+	INSERT INTO content_block_type (content_block_id, type_name, schema_version)
+	VALUES ($1, $2, (SELECT schema_version FROM type_def WHERE name = $2))
+`
+
 func (r *NotebookRepository) createContentBlock(ctx context.Context, tx *sql.Tx, notebookID string, block *domain.ContentBlock) error {
 	query := `
 		INSERT INTO content_block (notebook_id, uid, parent_uid, content_type, data, "order")
@@ -265,8 +613,7 @@ func (r *NotebookRepository) createContentBlock(ctx context.Context, tx *sql.Tx,
 
 	// Insert block types
 	for _, typeName := range block.Types {
-		typeQuery := `INSERT INTO content_block_type (content_block_id, type_name) VALUES ($1, $2)`
-		_, err = tx.ExecContext(ctx, typeQuery, blockID, typeName)
+		_, err = tx.ExecContext(ctx, blockTypeQuery, blockID, typeName)
 		if err != nil {
 			return err
 		}
@@ -275,15 +622,26 @@ func (r *NotebookRepository) createContentBlock(ctx context.Context, tx *sql.Tx,
 	return nil
 }
 
-func (r *NotebookRepository) getContentBlocks(ctx context.Context, notebookID string) ([]domain.ContentBlock, error) {
+// getContentBlocks reads a notebook's blocks with their types in a single query,
+// so it can run inside a transaction. Blocks referencing a blob get its
+// metadata; its bytes are only read when the blob is downloaded.
+func (r *NotebookRepository) getContentBlocks(ctx context.Context, q queryer, notebookID string) ([]domain.ContentBlock, error) {
 	query := `
-		SELECT id, uid, parent_uid, content_type, data, "order"
-		FROM content_block
-		WHERE notebook_id = $1
-		ORDER BY "order"
+		SELECT cb.uid, cb.parent_uid, cb.content_type, cb.data, cb."order",
+			COALESCE(array_agg(cbt.type_name) FILTER (WHERE cbt.type_name IS NOT NULL), '{}'),
+			b.tenant_id, b.digest, b.media_type, b.size, b.created_at
+		FROM content_block cb
+		LEFT JOIN content_block_type cbt ON cbt.content_block_id = cb.id
+		LEFT JOIN notebook n ON n.id = cb.notebook_id
+		LEFT JOIN blob b ON b.tenant_id = n.tenant_id
+			AND cb.data LIKE '` + domain.BlobRefPrefix + `%'
+			AND b.digest = substr(cb.data, ` + fmt.Sprint(len(domain.BlobRefPrefix)+1) + `)
+		WHERE cb.notebook_id = $1
+		GROUP BY cb.id, b.tenant_id, b.digest
+		ORDER BY cb."order", cb.uid
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, notebookID)
+	rows, err := q.QueryContext(ctx, query, notebookID)
 	if err != nil {
 		return nil, err
 	}
@@ -293,39 +651,40 @@ func (r *NotebookRepository) getContentBlocks(ctx context.Context, notebookID st
 
 	for rows.Next() {
 		var block domain.ContentBlock
-		var blockID string
+		var types []string
+		var blobTenant, blobDigest, blobMediaType sql.NullString
+		var blobSize sql.NullInt64
+		var blobCreated sql.NullTime
 
 		err := rows.Scan(
-			&blockID,
 			&block.UID,
 			&block.ParentUID,
 			&block.ContentType,
 			&block.Data,
 			&block.Order,
+			pq.Array(&types),
+			&blobTenant,
+			&blobDigest,
+			&blobMediaType,
+			&blobSize,
+			&blobCreated,
 		)
 		if err != nil {
 			return nil, err
 		}
 
-		// Get types for this block
-		typeQuery := `SELECT type_name FROM content_block_type WHERE content_block_id = $1`
-		typeRows, err := r.db.QueryContext(ctx, typeQuery, blockID)
-		if err != nil {
-			return nil, err
+		if len(types) > 0 {
+			block.Types = types
 		}
-
-		var types []string
-		for typeRows.Next() {
-			var typeName string
-			if err := typeRows.Scan(&typeName); err != nil {
-				typeRows.Close()
-				return nil, err
+		if blobDigest.Valid {
+			block.Blob = &domain.Blob{
+				TenantID:  blobTenant.String,
+				Digest:    blobDigest.String,
+				MediaType: blobMediaType.String,
+				Size:      blobSize.Int64,
+				CreatedAt: blobCreated.Time,
 			}
-			types = append(types, typeName)
 		}
-		typeRows.Close()
-
-		block.Types = types
 		blocks = append(blocks, block)
 	}
 
@@ -354,16 +713,233 @@ func (r *NotebookRepository) getNotifications(ctx context.Context, notebookID st
 	return notifications, rows.Err()
 }
 
-func (r *NotebookRepository) updateResourceIndex(ctx context.Context, tx *sql.Tx, notebook *domain.Notebook) error {
+// recordEvent writes a notebook event to the outbox as part of tx. The payload is
+// the notebook as it stands in tx (without contents), plus the status change if any.
+func (r *NotebookRepository) recordEvent(ctx context.Context, tx *sql.Tx, eventType, id string, change *domain.NotebookStatusChange) error {
+	snapshot, err := r.snapshot(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	var data interface{} = snapshot
+	if change != nil {
+		data = domain.NotebookStatusChangedData{Notebook: snapshot, Change: change}
+	}
+
+	event, err := domain.NewEvent(eventType, snapshot.TenantID, snapshot.ID, snapshot.URI(), data)
+	if err != nil {
+		return err
+	}
+
+	return insertEvent(ctx, tx, event)
+}
+
+// snapshot reads a notebook and its notifications, without contents, within tx
+func (r *NotebookRepository) snapshot(ctx context.Context, tx *sql.Tx, id string) (*domain.Notebook, error) {
 	query := `
-		INSERT INTO resource_index (uri, entity_type, entity_id, tenant_id)
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (uri) DO UPDATE
-		SET entity_type = EXCLUDED.entity_type,
-			entity_id = EXCLUDED.entity_id,
-			tenant_id = EXCLUDED.tenant_id
+		SELECT id, tenant_id, library_id, status, owner, display_name, description, revision
+		FROM notebook
+		WHERE id = $1
+	`
+
+	notebook := &domain.Notebook{}
+
+	err := tx.QueryRowContext(ctx, query, id).Scan(
+		&notebook.ID,
+		&notebook.TenantID,
+		&notebook.LibraryID,
+		&notebook.Status,
+		&notebook.Owner,
+		&notebook.DisplayName,
+		&notebook.Description,
+		&notebook.Revision,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, notFound("notebook not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notebook: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT nurl FROM notebook_notification WHERE notebook_id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var notif domain.Notification
+		if err := rows.Scan(&notif.URL); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notebook.Notifications = append(notebook.Notifications, notif)
+	}
+
+	return notebook, rows.Err()
+}
+
+// blockPosition is where a content block sits in a notebook
+type blockPosition struct {
+	id        string
+	parentUID *string
+	order     int
+}
+
+// applyBlockOperation applies op within tx, or marks it discarded if it conflicts
+func (r *NotebookRepository) applyBlockOperation(ctx context.Context, tx *sql.Tx, op *domain.BlockOperation) error {
+	if err := op.Resolve(ctx, &txBlockTree{r: r, tx: tx, notebookID: op.NotebookID}); err != nil {
+		return err
+	}
+	if op.Status == domain.BlockOpDiscarded {
+		return nil
+	}
+
+	block, err := r.blockPosition(ctx, tx, op.NotebookID, op.BlockUID)
+	if err != nil {
+		return err
+	}
+
+	switch op.Type {
+	case domain.BlockOpInsert:
+		order, err := r.placeBlock(ctx, tx, op.NotebookID, op.ParentUID, op.AfterUID, op.BlockUID)
+		if err != nil {
+			return err
+		}
+
+		return r.createContentBlock(ctx, tx, op.NotebookID, &domain.ContentBlock{
+			UID:         op.BlockUID,
+			ParentUID:   op.ParentUID,
+			ContentType: op.ContentType,
+			Data:        op.Data,
+			Order:       order,
+			Types:       op.Types,
+		})
+
+	case domain.BlockOpUpdate:
+		_, err := tx.ExecContext(ctx,
+			`UPDATE content_block SET content_type = $2, data = $3 WHERE id = $1`,
+			block.id, op.ContentType, op.Data,
+		)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM content_block_type WHERE content_block_id = $1`, block.id); err != nil {
+			return err
+		}
+		for _, typeName := range op.Types {
+			if _, err := tx.ExecContext(ctx, blockTypeQuery, block.id, typeName); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case domain.BlockOpMove:
+		order, err := r.placeBlock(ctx, tx, op.NotebookID, op.ParentUID, op.AfterUID, op.BlockUID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`UPDATE content_block SET parent_uid = $2, "order" = $3 WHERE id = $1`,
+			block.id, op.ParentUID, order,
+		)
+		return err
+
+	case domain.BlockOpDelete:
+		query := `
+			WITH RECURSIVE subtree AS (
+				SELECT uid FROM content_block WHERE notebook_id = $1 AND uid = $2
+				UNION
+				SELECT cb.uid FROM content_block cb
+				JOIN subtree s ON cb.parent_uid = s.uid
+				WHERE cb.notebook_id = $1
+			)
+			DELETE FROM content_block
+			WHERE notebook_id = $1 AND uid IN (SELECT uid FROM subtree)
+		`
+		_, err := tx.ExecContext(ctx, query, op.NotebookID, op.BlockUID)
+		return err
+	}
+
+	return fmt.Errorf("unknown block operation %q", op.Type)
+}
+
+// blockPosition returns where a block sits, or nil if the notebook has no such block
+func (r *NotebookRepository) blockPosition(ctx context.Context, tx *sql.Tx, notebookID, uid string) (*blockPosition, error) {
+	query := `SELECT id, parent_uid, "order" FROM content_block WHERE notebook_id = $1 AND uid = $2`
+
+	block := &blockPosition{}
+	err := tx.QueryRowContext(ctx, query, notebookID, uid).Scan(&block.id, &block.parentUID, &block.order)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
+// placeBlock makes room for block uid among the children of parentUID and returns its order.
+// The block goes directly after afterUID, first when afterUID is empty, and last when
+// afterUID is nil or not a child of parentUID.
+func (r *NotebookRepository) placeBlock(ctx context.Context, tx *sql.Tx, notebookID string, parentUID, afterUID *string, uid string) (int, error) {
+	siblings := `notebook_id = $1 AND parent_uid IS NOT DISTINCT FROM $2 AND uid <> $3`
+
+	order := 0
+	if afterUID == nil || *afterUID != "" {
+		err := sql.ErrNoRows
+		if afterUID != nil {
+			query := `SELECT "order" + 1 FROM content_block WHERE ` + siblings + ` AND uid = $4`
+			err = tx.QueryRowContext(ctx, query, notebookID, parentUID, uid, *afterUID).Scan(&order)
+		}
+		if err == sql.ErrNoRows {
+			query := `SELECT COALESCE(MAX("order") + 1, 0) FROM content_block WHERE ` + siblings
+			err := tx.QueryRowContext(ctx, query, notebookID, parentUID, uid).Scan(&order)
+			return order, err
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	query := `UPDATE content_block SET "order" = "order" + 1 WHERE ` + siblings + ` AND "order" >= $4`
+	_, err := tx.ExecContext(ctx, query, notebookID, parentUID, uid, order)
+	return order, err
+}
+
+// txBlockTree is a notebook's blocks as a transaction sees them
+type txBlockTree struct {
+	r          *NotebookRepository
+	tx         *sql.Tx
+	notebookID string
+}
+
+func (t *txBlockTree) Exists(ctx context.Context, uid string) (bool, error) {
+	block, err := t.r.blockPosition(ctx, t.tx, t.notebookID, uid)
+	return block != nil, err
+}
+
+func (t *txBlockTree) IsDescendant(ctx context.Context, uid, ancestorUID string) (bool, error) {
+	return t.r.isDescendant(ctx, t.tx, t.notebookID, uid, ancestorUID)
+}
+
+// isDescendant reports whether block uid lies beneath block ancestorUID
+func (r *NotebookRepository) isDescendant(ctx context.Context, tx *sql.Tx, notebookID, uid, ancestorUID string) (bool, error) {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT parent_uid FROM content_block WHERE notebook_id = $1 AND uid = $2
+			UNION
+			SELECT cb.parent_uid FROM content_block cb
+			JOIN ancestors a ON cb.uid = a.parent_uid
+			WHERE cb.notebook_id = $1
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE parent_uid = $3)
 	`
 
-	_, err := tx.ExecContext(ctx, query, notebook.URI(), "notebook", notebook.ID, notebook.TenantID)
-	return err
+	var descendant bool
+	err := tx.QueryRowContext(ctx, query, notebookID, uid, ancestorUID).Scan(&descendant)
+	return descendant, err
 }
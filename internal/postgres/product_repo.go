@@ -0,0 +1,470 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// productResourceLabels maps the resource types that can be attached to a product to their graph labels
+var productResourceLabels = map[string]string{
+	"notebook": "Notebook",
+	"feature":  "Feature",
+}
+
+// ProductRepository handles product, membership and attachment persistence
+type ProductRepository struct {
+	db *DB
+}
+
+// NewProductRepository creates a new product repository
+func NewProductRepository(db *DB) *ProductRepository {
+	return &ProductRepository{db: db}
+}
+
+// Create inserts a new product. owner, if set, becomes its first owner member.
+func (r *ProductRepository) Create(ctx context.Context, product *domain.Product, owner string) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO product (id, tenant_id, display_name, description)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err = tx.ExecContext(ctx, query, product.ID, product.TenantID, product.DisplayName, product.Description)
+	if err != nil {
+		return fmt.Errorf("failed to create product: %w", asConflict(err))
+	}
+
+	product.Members = nil
+	if owner != "" {
+		member := domain.ProductUser{ProductID: product.ID, UserID: owner, Role: domain.ProductRoleOwner}
+		if err := setProductMember(ctx, tx, &member); err != nil {
+			return err
+		}
+		product.Members = []domain.ProductUser{member}
+	}
+
+	if err := r.recordEvent(ctx, tx, domain.EventProductCreated, product); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Get retrieves a product with its members and attached resources
+func (r *ProductRepository) Get(ctx context.Context, id string) (*domain.Product, error) {
+	product, err := r.get(ctx, r.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadRelations(ctx, r.db, product); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// productListing lists products by display name unless sorted otherwise
+var productListing = &listing{
+	sorts: map[string]sortColumn{
+		"id":           {"id", "text"},
+		"display_name": {"display_name", "text"},
+		"created_at":   {"created_at", "timestamptz"},
+	},
+	defaultSort: "display_name",
+}
+
+// ProductSorts lists the fields products can be sorted by
+func ProductSorts() []string { return productListing.Sorts() }
+
+// ListByTenant retrieves a page of the products of a tenant
+func (r *ProductRepository) ListByTenant(ctx context.Context, tenantID string, opts ListOptions) (*domain.Page[*domain.Product], error) {
+	query, args, sortName, limit, err := productListing.query(
+		"id, tenant_id, display_name, COALESCE(description, '')",
+		"product", []string{"tenant_id = $1"}, []interface{}{tenantID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPage(rows, sortName, limit, func(row rowScanner) (*domain.Product, string, error) {
+		product := &domain.Product{}
+		if err := row.Scan(&product.ID, &product.TenantID, &product.DisplayName, &product.Description); err != nil {
+			return nil, "", fmt.Errorf("failed to scan product: %w", err)
+		}
+		return product, product.ID, nil
+	})
+}
+
+// Update updates a product's display name and description
+func (r *ProductRepository) Update(ctx context.Context, product *domain.Product) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE product
+		SET display_name = $2, description = $3
+		WHERE id = $1
+		RETURNING tenant_id
+	`
+
+	err = tx.QueryRowContext(ctx, query, product.ID, product.DisplayName, product.Description).Scan(&product.TenantID)
+	if err == sql.ErrNoRows {
+		return notFound("product not found: %s", product.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update product: %w", err)
+	}
+
+	if err := r.recordEvent(ctx, tx, domain.EventProductUpdated, product); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a product, its memberships and its attachments. The attached
+// resources themselves are kept.
+func (r *ProductRepository) Delete(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var tenantID string
+	err = tx.QueryRowContext(ctx, `DELETE FROM product WHERE id = $1 RETURNING tenant_id`, id).Scan(&tenantID)
+	if err == sql.ErrNoRows {
+		return notFound("product not found: %s", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete product: %w", err)
+	}
+
+	if err := r.recordEvent(ctx, tx, domain.EventProductDeleted, &domain.Product{ID: id, TenantID: tenantID}); err != nil {
+		return err
+	}
+
+	if err := deleteGraphVertex(ctx, tx, "Product", id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetMember grants a user a role on a product, replacing any role they held
+func (r *ProductRepository) SetMember(ctx context.Context, member *domain.ProductUser) error {
+	if !domain.ValidProductRole(member.Role) {
+		return fmt.Errorf("invalid product role %q: must be one of %v", member.Role, domain.ProductRoles)
+	}
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	product, err := r.lock(ctx, tx, member.ProductID)
+	if err != nil {
+		return err
+	}
+
+	if member.Role != domain.ProductRoleOwner {
+		if err := r.keepOwner(ctx, tx, member.ProductID, member.UserID); err != nil {
+			return err
+		}
+	}
+
+	if err := setProductMember(ctx, tx, member); err != nil {
+		return err
+	}
+
+	if err := r.recordEvent(ctx, tx, domain.EventProductUpdated, product); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RemoveMember revokes a user's role on a product. The last owner cannot be removed.
+func (r *ProductRepository) RemoveMember(ctx context.Context, productID, userID string) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	product, err := r.lock(ctx, tx, productID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.keepOwner(ctx, tx, productID, userID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM product_user WHERE product_id = $1 AND user_id = $2`, productID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove product member: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return notFound("product member not found: %s", userID)
+	}
+
+	if err := r.recordEvent(ctx, tx, domain.EventProductUpdated, product); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Attach makes a notebook or feature of the product's tenant part of the product
+func (r *ProductRepository) Attach(ctx context.Context, productID string, ref *domain.ResourceRef) error {
+	label, ok := productResourceLabels[ref.Type]
+	if !ok {
+		return fmt.Errorf("cannot attach %s resources to a product; use a notebook or feature", ref.Type)
+	}
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	product, err := r.lock(ctx, tx, productID)
+	if err != nil {
+		return err
+	}
+
+	var tenantID string
+	err = tx.QueryRowContext(ctx, `SELECT tenant_id FROM `+resourceTables[ref.Type]+` WHERE id = $1`, ref.ID).Scan(&tenantID)
+	if err == sql.ErrNoRows || (err == nil && tenantID != ref.TenantID) {
+		return notFound("%s not found: %s", ref.Type, ref.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", ref.Type, err)
+	}
+
+	if tenantID != product.TenantID {
+		return fmt.Errorf("%s %s belongs to tenant %s, not the product's tenant %s", ref.Type, ref.ID, tenantID, product.TenantID)
+	}
+
+	query := `
+		INSERT INTO product_resource (product_id, tenant_id, resource_type, resource_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := tx.ExecContext(ctx, query, productID, tenantID, ref.Type, ref.ID); err != nil {
+		return fmt.Errorf("failed to attach %s to product: %w", ref.Type, err)
+	}
+
+	if err := r.recordEvent(ctx, tx, domain.EventProductUpdated, product); err != nil {
+		return err
+	}
+
+	if err := mergeGraphEdge(ctx, tx, label, ref.ID, "PART_OF_PRODUCT", "Product", productID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Detach removes a notebook or feature from a product
+func (r *ProductRepository) Detach(ctx context.Context, productID string, ref *domain.ResourceRef) error {
+	label, ok := productResourceLabels[ref.Type]
+	if !ok {
+		return fmt.Errorf("cannot detach %s resources from a product; use a notebook or feature", ref.Type)
+	}
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	product, err := r.lock(ctx, tx, productID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		DELETE FROM product_resource
+		WHERE product_id = $1 AND resource_type = $2 AND resource_id = $3
+	`
+
+	result, err := tx.ExecContext(ctx, query, productID, ref.Type, ref.ID)
+	if err != nil {
+		return fmt.Errorf("failed to detach %s from product: %w", ref.Type, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("%s %s is not part of product %s", ref.Type, ref.ID, productID)
+	}
+
+	if err := r.recordEvent(ctx, tx, domain.EventProductUpdated, product); err != nil {
+		return err
+	}
+
+	if err := deleteGraphEdge(ctx, tx, label, ref.ID, "PART_OF_PRODUCT", "Product", productID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Helper methods
+
+// productQueryer is satisfied by both *sql.DB and *sql.Tx
+type productQueryer interface {
+	queryer
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (r *ProductRepository) get(ctx context.Context, q productQueryer, id string) (*domain.Product, error) {
+	query := `
+		SELECT id, tenant_id, display_name, COALESCE(description, '')
+		FROM product
+		WHERE id = $1
+	`
+
+	product := &domain.Product{}
+	err := q.QueryRowContext(ctx, query, id).Scan(&product.ID, &product.TenantID, &product.DisplayName, &product.Description)
+	if err == sql.ErrNoRows {
+		return nil, notFound("product not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	return product, nil
+}
+
+// lock reads a product and locks it against concurrent membership changes
+func (r *ProductRepository) lock(ctx context.Context, tx *sql.Tx, id string) (*domain.Product, error) {
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM product WHERE id = $1 FOR UPDATE`, id); err != nil {
+		return nil, fmt.Errorf("failed to lock product: %w", err)
+	}
+	return r.get(ctx, tx, id)
+}
+
+// keepOwner fails if userID is the product's only owner
+func (r *ProductRepository) keepOwner(ctx context.Context, tx *sql.Tx, productID, userID string) error {
+	query := `
+		SELECT COUNT(*) FILTER (WHERE user_id <> $2), COUNT(*) FILTER (WHERE user_id = $2)
+		FROM product_user
+		WHERE product_id = $1 AND role = $3
+	`
+
+	var others, self int
+	if err := tx.QueryRowContext(ctx, query, productID, userID, domain.ProductRoleOwner).Scan(&others, &self); err != nil {
+		return fmt.Errorf("failed to count product owners: %w", err)
+	}
+
+	if self > 0 && others == 0 {
+		return fmt.Errorf("%s is the last owner of product %s", userID, productID)
+	}
+
+	return nil
+}
+
+// loadRelations fills in a product's members and attached resources
+func (r *ProductRepository) loadRelations(ctx context.Context, q queryer, product *domain.Product) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT user_id, role
+		FROM product_user
+		WHERE product_id = $1
+		ORDER BY user_id
+	`, product.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list product members: %w", err)
+	}
+	defer rows.Close()
+
+	product.Members = []domain.ProductUser{}
+	for rows.Next() {
+		member := domain.ProductUser{ProductID: product.ID}
+		if err := rows.Scan(&member.UserID, &member.Role); err != nil {
+			return fmt.Errorf("failed to scan product member: %w", err)
+		}
+		product.Members = append(product.Members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating product members: %w", err)
+	}
+
+	resources, err := q.QueryContext(ctx, `
+		SELECT resource_type, resource_id
+		FROM product_resource
+		WHERE product_id = $1
+		ORDER BY resource_type, resource_id
+	`, product.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list product resources: %w", err)
+	}
+	defer resources.Close()
+
+	product.Resources = []string{}
+	for resources.Next() {
+		ref := &domain.ResourceRef{TenantID: product.TenantID}
+		if err := resources.Scan(&ref.Type, &ref.ID); err != nil {
+			return fmt.Errorf("failed to scan product resource: %w", err)
+		}
+		product.Resources = append(product.Resources, ref.URI())
+	}
+
+	return resources.Err()
+}
+
+// setProductMember upserts a membership as part of tx
+func setProductMember(ctx context.Context, tx *sql.Tx, member *domain.ProductUser) error {
+	query := `
+		INSERT INTO product_user (product_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (product_id, user_id) DO UPDATE
+		SET role = EXCLUDED.role
+	`
+
+	if _, err := tx.ExecContext(ctx, query, member.ProductID, member.UserID, member.Role); err != nil {
+		return fmt.Errorf("failed to set product member: %w", err)
+	}
+
+	return nil
+}
+
+// recordEvent writes a product event to the outbox as part of tx
+func (r *ProductRepository) recordEvent(ctx context.Context, tx *sql.Tx, eventType string, product *domain.Product) error {
+	event, err := domain.NewEvent(eventType, product.TenantID, product.ID, product.URI(), product)
+	if err != nil {
+		return err
+	}
+
+	return insertEvent(ctx, tx, event)
+}
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// QuotaRepository handles tenant quota overrides and usage counters
+type QuotaRepository struct {
+	db *DB
+}
+
+// NewQuotaRepository creates a new quota repository
+func NewQuotaRepository(db *DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+// Get retrieves a tenant's quota overrides; a tenant without any has no limits set
+func (r *QuotaRepository) Get(ctx context.Context, tenantID string) (*domain.TenantQuota, error) {
+	query := `
+		SELECT tenant_id, max_notebooks, max_features, max_content_bytes,
+			max_requests_per_second, updated_at
+		FROM tenant_quota
+		WHERE tenant_id = $1
+	`
+
+	var quota domain.TenantQuota
+	var maxNotebooks, maxFeatures, maxContentBytes, maxRequests sql.NullInt64
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&quota.TenantID,
+		&maxNotebooks,
+		&maxFeatures,
+		&maxContentBytes,
+		&maxRequests,
+		&quota.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return &domain.TenantQuota{TenantID: tenantID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant quota: %w", err)
+	}
+
+	quota.MaxNotebooks = int64Ptr(maxNotebooks)
+	quota.MaxFeatures = int64Ptr(maxFeatures)
+	quota.MaxContentBytes = int64Ptr(maxContentBytes)
+	quota.MaxRequestsPerSecond = int64Ptr(maxRequests)
+
+	return &quota, nil
+}
+
+// Set stores a tenant's quota overrides, replacing any it had
+func (r *QuotaRepository) Set(ctx context.Context, quota *domain.TenantQuota) error {
+	query := `
+		INSERT INTO tenant_quota (tenant_id, max_notebooks, max_features, max_content_bytes,
+			max_requests_per_second)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			max_notebooks = EXCLUDED.max_notebooks,
+			max_features = EXCLUDED.max_features,
+			max_content_bytes = EXCLUDED.max_content_bytes,
+			max_requests_per_second = EXCLUDED.max_requests_per_second,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		quota.TenantID,
+		quota.MaxNotebooks,
+		quota.MaxFeatures,
+		quota.MaxContentBytes,
+		quota.MaxRequestsPerSecond,
+	).Scan(&quota.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set tenant quota: %w", err)
+	}
+
+	return nil
+}
+
+// CountNotebooks returns the number of notebooks in a tenant
+func (r *QuotaRepository) CountNotebooks(ctx context.Context, tenantID string) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notebook WHERE tenant_id = $1`, tenantID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count notebooks: %w", err)
+	}
+	return count, nil
+}
+
+// CountFeatures returns the number of features in a tenant
+func (r *QuotaRepository) CountFeatures(ctx context.Context, tenantID string) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM feature WHERE tenant_id = $1`, tenantID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count features: %w", err)
+	}
+	return count, nil
+}
+
+// ContentBytes returns the size of a tenant's notebook content: markdown,
+// content blocks and blobs
+func (r *QuotaRepository) ContentBytes(ctx context.Context, tenantID string) (int64, error) {
+	query := `
+		SELECT
+			COALESCE((SELECT SUM(octet_length(nc.markdown))
+				FROM notebook_content nc JOIN notebook n ON n.id = nc.notebook_id
+				WHERE n.tenant_id = $1), 0)
+			+ COALESCE((SELECT SUM(octet_length(cb.data))
+				FROM content_block cb JOIN notebook n ON n.id = cb.notebook_id
+				WHERE n.tenant_id = $1), 0)
+			+ COALESCE((SELECT SUM(size) FROM blob WHERE tenant_id = $1), 0)
+	`
+
+	var bytes int64
+	if err := r.db.QueryRowContext(ctx, query, tenantID).Scan(&bytes); err != nil {
+		return 0, fmt.Errorf("failed to measure content: %w", err)
+	}
+	return bytes, nil
+}
+
+func int64Ptr(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int64
+}
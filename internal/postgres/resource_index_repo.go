@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+)
+
+// ResourceIndexRepository maintains the URI → entity index used for fast resource resolution
+type ResourceIndexRepository struct {
+	db *DB
+}
+
+// NewResourceIndexRepository creates a new resource index repository
+func NewResourceIndexRepository(db *DB) *ResourceIndexRepository {
+	return &ResourceIndexRepository{db: db}
+}
+
+// Upsert inserts or updates the index entry for a URI
+func (r *ResourceIndexRepository) Upsert(ctx context.Context, uri, entityType, entityID, tenantID string) error {
+	query := `
+		INSERT INTO resource_index (uri, entity_type, entity_id, tenant_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (uri) DO UPDATE
+		SET entity_type = EXCLUDED.entity_type,
+			entity_id = EXCLUDED.entity_id,
+			tenant_id = EXCLUDED.tenant_id
+	`
+
+	_, err := r.db.ExecContext(ctx, query, uri, entityType, entityID, nullString(tenantID))
+	if err != nil {
+		return fmt.Errorf("failed to update resource index: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the index entry for a URI
+func (r *ResourceIndexRepository) Delete(ctx context.Context, uri string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM resource_index WHERE uri = $1`, uri)
+	if err != nil {
+		return fmt.Errorf("failed to delete from resource index: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteByTenant removes every index entry belonging to a tenant
+func (r *ResourceIndexRepository) DeleteByTenant(ctx context.Context, tenantID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM resource_index WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant from resource index: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,281 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+)
+
+// Scope is the set of tenants a database session may see. Row-level security
+// policies (migration 008) compare each row's tenant against the scope held in
+// the transaction-local synthesis.tenant_id and synthesis.system settings.
+type Scope struct {
+	// TenantID limits the session to one tenant's rows
+	TenantID string
+	// System lifts the limit, for background workers and administrators
+	System bool
+}
+
+type scopeKey struct{}
+
+// WithTenantScope returns a context whose queries see only tenantID's rows
+func WithTenantScope(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, scopeKey{}, Scope{TenantID: tenantID})
+}
+
+// WithSystemScope returns a context whose queries see every tenant's rows
+func WithSystemScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, Scope{System: true})
+}
+
+// ScopeFromContext returns the scope of queries made with ctx. A context without
+// one is unscoped and sees no tenant-owned rows.
+func ScopeFromContext(ctx context.Context) Scope {
+	scope, _ := ctx.Value(scopeKey{}).(Scope)
+	return scope
+}
+
+// setScopeQuery stores a scope in the settings row-level security reads. The
+// settings are transaction-local (SET LOCAL), so they end with the transaction
+// and never carry over to the next user of a pooled connection.
+const setScopeQuery = `SELECT set_config('synthesis.tenant_id', $1, true), set_config('synthesis.system', $2, true)`
+
+// scopedConnector opens connections that run every statement in a transaction
+// scoped to its context
+type scopedConnector struct {
+	driver.Connector
+}
+
+func (c *scopedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	inner, ok := conn.(scopedDriverConn)
+	if !ok {
+		conn.Close()
+		return nil, errors.New("database driver does not support scoped connections")
+	}
+
+	return &scopedConn{conn: inner}, nil
+}
+
+// scopedDriverConn is the part of the pq connection scopedConn forwards to
+type scopedDriverConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.QueryerContext
+	driver.ExecerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+// scopedConn is a connection that sets the scope of each transaction when it
+// begins, and runs autocommit statements in a transaction of their own so they
+// are scoped the same way
+type scopedConn struct {
+	conn scopedDriverConn
+	// inTx is true while a transaction begun with BeginTx is open; its
+	// statements run in the scope it began with
+	inTx bool
+}
+
+// setScope writes ctx's scope to the settings of the open transaction
+func (c *scopedConn) setScope(ctx context.Context) error {
+	scope := ScopeFromContext(ctx)
+
+	system := "off"
+	if scope.System {
+		system = "on"
+	}
+
+	rows, err := c.conn.QueryContext(ctx, setScopeQuery, []driver.NamedValue{
+		{Ordinal: 1, Value: scope.TenantID},
+		{Ordinal: 2, Value: system},
+	})
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// beginScoped begins a transaction on the underlying connection and scopes it to ctx
+func (c *scopedConn) beginScoped(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	tx, err := c.conn.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.setScope(ctx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// exec runs an autocommit statement in a scoped transaction of its own
+func (c *scopedConn) exec(ctx context.Context, run func() (driver.Result, error)) (driver.Result, error) {
+	if c.inTx {
+		return run()
+	}
+
+	tx, err := c.beginScoped(ctx, driver.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := run()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// query runs an autocommit query in a scoped transaction of its own, which
+// commits once its rows are closed
+func (c *scopedConn) query(ctx context.Context, run func() (driver.Rows, error)) (driver.Rows, error) {
+	if c.inTx {
+		return run()
+	}
+
+	tx, err := c.beginScoped(ctx, driver.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := run()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return &scopedRows{Rows: rows, tx: tx}, nil
+}
+
+func (c *scopedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *scopedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.conn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &scopedStmt{Stmt: stmt, conn: c}, nil
+}
+
+func (c *scopedConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *scopedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *scopedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	tx, err := c.beginScoped(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.inTx = true
+	return &scopedTx{Tx: tx, conn: c}, nil
+}
+
+func (c *scopedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.query(ctx, func() (driver.Rows, error) {
+		return c.conn.QueryContext(ctx, query, args)
+	})
+}
+
+func (c *scopedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.exec(ctx, func() (driver.Result, error) {
+		return c.conn.ExecContext(ctx, query, args)
+	})
+}
+
+func (c *scopedConn) Ping(ctx context.Context) error {
+	return c.conn.Ping(ctx)
+}
+
+func (c *scopedConn) ResetSession(ctx context.Context) error {
+	return c.conn.ResetSession(ctx)
+}
+
+func (c *scopedConn) IsValid() bool {
+	return c.conn.IsValid()
+}
+
+// scopedTx marks its connection as outside a transaction once it ends; the
+// scope settings end with it
+type scopedTx struct {
+	driver.Tx
+	conn *scopedConn
+}
+
+func (t *scopedTx) Commit() error {
+	t.conn.inTx = false
+	return t.Tx.Commit()
+}
+
+func (t *scopedTx) Rollback() error {
+	t.conn.inTx = false
+	return t.Tx.Rollback()
+}
+
+// scopedRows commits the transaction of an autocommit query once its rows are closed
+type scopedRows struct {
+	driver.Rows
+	tx driver.Tx
+}
+
+func (r *scopedRows) Close() error {
+	if err := r.Rows.Close(); err != nil {
+		r.tx.Rollback()
+		return err
+	}
+	return r.tx.Commit()
+}
+
+// scopedStmt runs a prepared statement executed outside a transaction in a
+// scoped transaction of its own
+type scopedStmt struct {
+	driver.Stmt
+	conn *scopedConn
+}
+
+func (s *scopedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.exec(ctx, func() (driver.Result, error) {
+		if stmt, ok := s.Stmt.(driver.StmtExecContext); ok {
+			return stmt.ExecContext(ctx, args)
+		}
+		return s.Stmt.Exec(namedValues(args))
+	})
+}
+
+func (s *scopedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.query(ctx, func() (driver.Rows, error) {
+		if stmt, ok := s.Stmt.(driver.StmtQueryContext); ok {
+			return stmt.QueryContext(ctx, args)
+		}
+		return s.Stmt.Query(namedValues(args))
+	})
+}
+
+// namedValues returns the values of args, for statements without context support
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
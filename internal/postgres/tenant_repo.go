@@ -26,12 +26,18 @@ func (r *TenantRepository) Create(ctx context.Context, tenant *domain.Tenant) er
 		return fmt.Errorf("failed to marshal labels: %w", err)
 	}
 
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO tenant (id, owner, display_name, description, labels_json, version, last_modified)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query,
 		tenant.ID,
 		tenant.Owner,
 		tenant.DisplayName,
@@ -42,15 +48,14 @@ func (r *TenantRepository) Create(ctx context.Context, tenant *domain.Tenant) er
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to create tenant: %w", err)
+		return fmt.Errorf("failed to create tenant: %w", asConflict(err))
 	}
 
-	// Update resource index
-	if err := r.updateResourceIndex(ctx, tenant); err != nil {
-		return fmt.Errorf("failed to update resource index: %w", err)
+	if err := r.recordEvent(ctx, tx, domain.EventTenantCreated, tenant); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 // Get retrieves a tenant by ID
@@ -75,7 +80,7 @@ func (r *TenantRepository) Get(ctx context.Context, id string) (*domain.Tenant,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("tenant not found: %s", id)
+		return nil, notFound("tenant not found: %s", id)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tenant: %w", err)
@@ -88,27 +93,42 @@ func (r *TenantRepository) Get(ctx context.Context, id string) (*domain.Tenant,
 	return tenant, nil
 }
 
-// List retrieves all tenants
-func (r *TenantRepository) List(ctx context.Context) ([]*domain.Tenant, error) {
-	query := `
-		SELECT id, owner, display_name, description, labels_json, version, last_modified
-		FROM tenant
-		ORDER BY created_at DESC
-	`
+// tenantListing lists tenants, newest first unless sorted otherwise
+var tenantListing = &listing{
+	sorts: map[string]sortColumn{
+		"id":            {"id", "text"},
+		"display_name":  {"display_name", "text"},
+		"created_at":    {"created_at", "timestamptz"},
+		"last_modified": {"last_modified", "timestamptz"},
+	},
+	defaultSort: "-created_at",
+	labels:      "labels_json",
+	owner:       "owner",
+}
+
+// TenantSorts lists the fields tenants can be sorted by
+func TenantSorts() []string { return tenantListing.Sorts() }
 
-	rows, err := r.db.QueryContext(ctx, query)
+// List retrieves a page of the tenants that match opts' label selector and owner
+func (r *TenantRepository) List(ctx context.Context, opts ListOptions) (*domain.Page[*domain.Tenant], error) {
+	query, args, sortName, limit, err := tenantListing.query(
+		"id, owner, display_name, COALESCE(description, ''), labels_json, COALESCE(version, ''), last_modified",
+		"tenant", nil, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tenants: %w", err)
 	}
 	defer rows.Close()
 
-	var tenants []*domain.Tenant
-
-	for rows.Next() {
+	page, err := scanPage(rows, sortName, limit, func(row rowScanner) (*domain.Tenant, string, error) {
 		tenant := &domain.Tenant{}
 		var labelsJSON []byte
 
-		err := rows.Scan(
+		err := row.Scan(
 			&tenant.ID,
 			&tenant.Owner,
 			&tenant.DisplayName,
@@ -118,21 +138,22 @@ func (r *TenantRepository) List(ctx context.Context) ([]*domain.Tenant, error) {
 			&tenant.LastModified,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+			return nil, "", fmt.Errorf("failed to scan tenant: %w", err)
 		}
 
-		if err := json.Unmarshal(labelsJSON, &tenant.Labels); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal labels: %w", err)
+		if len(labelsJSON) > 0 {
+			if err := json.Unmarshal(labelsJSON, &tenant.Labels); err != nil {
+				return nil, "", fmt.Errorf("failed to unmarshal labels: %w", err)
+			}
 		}
 
-		tenants = append(tenants, tenant)
-	}
-
-	if err := rows.Err(); err != nil {
+		return tenant, tenant.ID, nil
+	})
+	if err != nil {
 		return nil, fmt.Errorf("error iterating tenants: %w", err)
 	}
 
-	return tenants, nil
+	return page, nil
 }
 
 // Update updates an existing tenant
@@ -142,13 +163,19 @@ func (r *TenantRepository) Update(ctx context.Context, tenant *domain.Tenant) er
 		return fmt.Errorf("failed to marshal labels: %w", err)
 	}
 
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE tenant
 		SET owner = $2, display_name = $3, description = $4, labels_json = $5, version = $6
 		WHERE id = $1
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := tx.ExecContext(ctx, query,
 		tenant.ID,
 		tenant.Owner,
 		tenant.DisplayName,
@@ -167,22 +194,27 @@ func (r *TenantRepository) Update(ctx context.Context, tenant *domain.Tenant) er
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("tenant not found: %s", tenant.ID)
+		return notFound("tenant not found: %s", tenant.ID)
 	}
 
-	// Update resource index
-	if err := r.updateResourceIndex(ctx, tenant); err != nil {
-		return fmt.Errorf("failed to update resource index: %w", err)
+	if err := r.recordEvent(ctx, tx, domain.EventTenantUpdated, tenant); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 // Delete deletes a tenant
 func (r *TenantRepository) Delete(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `DELETE FROM tenant WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := tx.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete tenant: %w", err)
 	}
@@ -193,29 +225,22 @@ func (r *TenantRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("tenant not found: %s", id)
+		return notFound("tenant not found: %s", id)
 	}
 
-	// Delete from resource index
-	_, err = r.db.ExecContext(ctx, `DELETE FROM resource_index WHERE entity_type = 'tenant' AND entity_id = $1`, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete from resource index: %w", err)
+	if err := r.recordEvent(ctx, tx, domain.EventTenantDeleted, &domain.Tenant{ID: id}); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
 
-// updateResourceIndex updates the resource index for a tenant
-func (r *TenantRepository) updateResourceIndex(ctx context.Context, tenant *domain.Tenant) error {
-	query := `
-		INSERT INTO resource_index (uri, entity_type, entity_id, tenant_id)
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (uri) DO UPDATE
-		SET entity_type = EXCLUDED.entity_type,
-			entity_id = EXCLUDED.entity_id,
-			tenant_id = EXCLUDED.tenant_id
-	`
+// recordEvent writes a tenant event to the outbox as part of tx
+func (r *TenantRepository) recordEvent(ctx context.Context, tx *sql.Tx, eventType string, tenant *domain.Tenant) error {
+	event, err := domain.NewEvent(eventType, tenant.ID, tenant.ID, tenant.URI(), tenant)
+	if err != nil {
+		return err
+	}
 
-	_, err := r.db.ExecContext(ctx, query, tenant.URI(), "tenant", tenant.ID, tenant.ID)
-	return err
+	return insertEvent(ctx, tx, event)
 }
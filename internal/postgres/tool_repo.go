@@ -0,0 +1,441 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// ToolRepository handles tool configuration persistence
+type ToolRepository struct {
+	db *DB
+}
+
+// NewToolRepository creates a new tool repository
+func NewToolRepository(db *DB) *ToolRepository {
+	return &ToolRepository{db: db}
+}
+
+// toolSourceLabels maps the resource types a tool can be used for to their graph labels
+var toolSourceLabels = map[string]string{
+	"library":  "Library",
+	"notebook": "Notebook",
+	"feature":  "Feature",
+	"product":  "Product",
+}
+
+// toolColumns is the column list read by scanTool
+const toolColumns = `id, tenant_id, kind, display_name, COALESCE(description, ''), COALESCE(config_json, '{}'), sensitive_keys`
+
+// Create inserts a new tool configuration. Its sensitive entries must already
+// be sealed; the tool.created event carries them redacted.
+func (r *ToolRepository) Create(ctx context.Context, tool *domain.ToolConfig) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	configJSON, err := json.Marshal(tool.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	query := `
+		INSERT INTO tool (id, tenant_id, kind, display_name, description, config_json, sensitive_keys)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err = tx.ExecContext(ctx, query,
+		tool.ID,
+		tool.TenantID,
+		tool.Kind,
+		tool.DisplayName,
+		tool.Description,
+		configJSON,
+		pq.Array(tool.SensitiveKeys),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create tool: %w", asConflict(err))
+	}
+
+	if err := r.recordEvent(ctx, tx, domain.EventToolCreated, tool); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Get retrieves a tool configuration by ID. Its sensitive entries are returned sealed.
+func (r *ToolRepository) Get(ctx context.Context, id string) (*domain.ToolConfig, error) {
+	query := `SELECT ` + toolColumns + ` FROM tool WHERE id = $1`
+
+	tool, err := scanTool(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, notFound("tool not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tool: %w", err)
+	}
+
+	return tool, nil
+}
+
+// toolListing lists tool configurations by display name unless sorted otherwise
+var toolListing = &listing{
+	sorts: map[string]sortColumn{
+		"id":           {"id", "text"},
+		"display_name": {"display_name", "text"},
+		"created_at":   {"created_at", "timestamptz"},
+	},
+	defaultSort: "display_name",
+}
+
+// ToolSorts lists the fields tool configurations can be sorted by
+func ToolSorts() []string { return toolListing.Sorts() }
+
+// ListByTenant retrieves a page of the tool configurations of a tenant. Their
+// sensitive entries are returned sealed.
+func (r *ToolRepository) ListByTenant(ctx context.Context, tenantID string, opts ListOptions) (*domain.Page[*domain.ToolConfig], error) {
+	query, args, sortName, limit, err := toolListing.query(toolColumns, "tool",
+		[]string{"tenant_id = $1"}, []interface{}{tenantID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPage(rows, sortName, limit, func(row rowScanner) (*domain.ToolConfig, string, error) {
+		tool, err := scanTool(row)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to scan tool: %w", err)
+		}
+		return tool, tool.ID, nil
+	})
+}
+
+// ListByKind retrieves the tool configurations of a kind visible on ctx; with
+// WithSystemScope, those of every tenant. Their sensitive entries are returned sealed.
+func (r *ToolRepository) ListByKind(ctx context.Context, kind string) ([]*domain.ToolConfig, error) {
+	query := `SELECT ` + toolColumns + ` FROM tool WHERE kind = $1 ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, kind)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	defer rows.Close()
+
+	var tools []*domain.ToolConfig
+	for rows.Next() {
+		tool, err := scanTool(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tool: %w", err)
+		}
+		tools = append(tools, tool)
+	}
+
+	return tools, rows.Err()
+}
+
+// Update replaces a tool configuration's display name, description, config and
+// sensitive keys. Its kind and tenant are kept; its sensitive entries must
+// already be sealed.
+func (r *ToolRepository) Update(ctx context.Context, tool *domain.ToolConfig) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	configJSON, err := json.Marshal(tool.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	query := `
+		UPDATE tool
+		SET display_name = $2, description = $3, config_json = $4, sensitive_keys = $5
+		WHERE id = $1
+		RETURNING tenant_id, kind
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		tool.ID,
+		tool.DisplayName,
+		tool.Description,
+		configJSON,
+		pq.Array(tool.SensitiveKeys),
+	).Scan(&tool.TenantID, &tool.Kind)
+	if err == sql.ErrNoRows {
+		return notFound("tool not found: %s", tool.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update tool: %w", err)
+	}
+
+	if err := r.recordEvent(ctx, tx, domain.EventToolUpdated, tool); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a tool configuration with its invocation log and USES_TOOL edges
+func (r *ToolRepository) Delete(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var tenantID string
+	err = tx.QueryRowContext(ctx, `DELETE FROM tool WHERE id = $1 RETURNING tenant_id`, id).Scan(&tenantID)
+	if err == sql.ErrNoRows {
+		return notFound("tool not found: %s", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete tool: %w", err)
+	}
+
+	if err := r.recordEvent(ctx, tx, domain.EventToolDeleted, &domain.ToolConfig{ID: id, TenantID: tenantID}); err != nil {
+		return err
+	}
+
+	if err := deleteGraphVertex(ctx, tx, "Tool", id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RecordInvocation logs an invocation of a tool. When it names a source
+// resource, a USES_TOOL edge from that resource to the tool is recorded too;
+// the source must belong to the tool's tenant.
+func (r *ToolRepository) RecordInvocation(ctx context.Context, invocation *domain.ToolInvocation) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var source *domain.ResourceRef
+	if invocation.SourceURI != "" {
+		source, err = domain.ParseURI(invocation.SourceURI)
+		if err != nil {
+			return err
+		}
+		if _, ok := toolSourceLabels[source.Type]; !ok {
+			return fmt.Errorf("tools cannot be used for %s resources; use a library, notebook, feature or product", source.Type)
+		}
+
+		var tenantID string
+		err = tx.QueryRowContext(ctx, `SELECT tenant_id FROM `+resourceTables[source.Type]+` WHERE id = $1`, source.ID).Scan(&tenantID)
+		if err == sql.ErrNoRows || (err == nil && tenantID != source.TenantID) {
+			return notFound("%s not found: %s", source.Type, source.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get %s: %w", source.Type, err)
+		}
+		if tenantID != invocation.TenantID {
+			return fmt.Errorf("%s %s belongs to tenant %s, not the tool's tenant %s", source.Type, source.ID, tenantID, invocation.TenantID)
+		}
+	}
+
+	query := `
+		INSERT INTO tool_invocation (tenant_id, tool_id, kind, actor, source_uri, status, status_code, error, duration_ms, result_json)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, NULLIF($7, 0), NULLIF($8, ''), $9, $10)
+		RETURNING id, created_at
+	`
+
+	var result interface{}
+	if len(invocation.Result) > 0 {
+		result = []byte(invocation.Result)
+	}
+
+	err = tx.QueryRowContext(ctx, query,
+		invocation.TenantID,
+		invocation.ToolID,
+		invocation.Kind,
+		invocation.Actor,
+		invocation.SourceURI,
+		invocation.Status,
+		invocation.StatusCode,
+		invocation.Error,
+		invocation.DurationMS,
+		result,
+	).Scan(&invocation.ID, &invocation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record tool invocation: %w", err)
+	}
+
+	if source != nil {
+		if err := mergeGraphEdge(ctx, tx, toolSourceLabels[source.Type], source.ID, "USES_TOOL", "Tool", invocation.ToolID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListInvocations retrieves up to limit invocations of a tool, newest first
+func (r *ToolRepository) ListInvocations(ctx context.Context, toolID string, limit int) ([]*domain.ToolInvocation, error) {
+	query := `
+		SELECT id, tenant_id, tool_id, kind, actor, COALESCE(source_uri, ''), status,
+			COALESCE(status_code, 0), COALESCE(error, ''), duration_ms, result_json, created_at
+		FROM tool_invocation
+		WHERE tool_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, toolID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tool invocations: %w", err)
+	}
+	defer rows.Close()
+
+	var invocations []*domain.ToolInvocation
+	for rows.Next() {
+		invocation := &domain.ToolInvocation{}
+		var result []byte
+		err := rows.Scan(
+			&invocation.ID,
+			&invocation.TenantID,
+			&invocation.ToolID,
+			&invocation.Kind,
+			&invocation.Actor,
+			&invocation.SourceURI,
+			&invocation.Status,
+			&invocation.StatusCode,
+			&invocation.Error,
+			&invocation.DurationMS,
+			&result,
+			&invocation.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tool invocation: %w", err)
+		}
+		if len(result) > 0 {
+			invocation.Result = result
+		}
+		invocations = append(invocations, invocation)
+	}
+
+	return invocations, rows.Err()
+}
+
+// RewrapSensitive passes each sensitive string entry of every tool configuration
+// to rewrap, storing those it changes. It returns the number of tools changed.
+func (r *ToolRepository) RewrapSensitive(ctx context.Context, rewrap func(context.Context, string) (string, bool, error)) (int, error) {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + toolColumns + `
+		FROM tool
+		WHERE cardinality(sensitive_keys) > 0
+		FOR UPDATE
+	`
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find sensitive tools: %w", err)
+	}
+
+	var tools []*domain.ToolConfig
+	for rows.Next() {
+		tool, err := scanTool(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan tool: %w", err)
+		}
+		tools = append(tools, tool)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating tools: %w", err)
+	}
+
+	changed := 0
+	for _, tool := range tools {
+		updated := false
+		for _, key := range tool.SensitiveKeys {
+			value, ok := tool.Config[key].(string)
+			if !ok {
+				continue
+			}
+
+			rewrapped, ok, err := rewrap(ctx, value)
+			if err != nil {
+				return 0, fmt.Errorf("failed to rewrap %s of tool %s: %w", key, tool.ID, err)
+			}
+			if ok {
+				tool.Config[key] = rewrapped
+				updated = true
+			}
+		}
+		if !updated {
+			continue
+		}
+
+		configJSON, err := json.Marshal(tool.Config)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal config: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE tool SET config_json = $2 WHERE id = $1`, tool.ID, configJSON); err != nil {
+			return 0, fmt.Errorf("failed to update tool: %w", err)
+		}
+		changed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit rewrapped tools: %w", err)
+	}
+
+	return changed, nil
+}
+
+func scanTool(row rowScanner) (*domain.ToolConfig, error) {
+	tool := &domain.ToolConfig{}
+	var configJSON []byte
+
+	err := row.Scan(
+		&tool.ID,
+		&tool.TenantID,
+		&tool.Kind,
+		&tool.DisplayName,
+		&tool.Description,
+		&configJSON,
+		pq.Array(&tool.SensitiveKeys),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(configJSON, &tool.Config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	return tool, nil
+}
+
+// recordEvent records a tool event, with the tool's sensitive entries redacted
+func (r *ToolRepository) recordEvent(ctx context.Context, tx *sql.Tx, eventType string, tool *domain.ToolConfig) error {
+	event, err := domain.NewEvent(eventType, tool.TenantID, tool.ID, tool.URI(), tool.Redacted())
+	if err != nil {
+		return err
+	}
+
+	return insertEvent(ctx, tx, event)
+}
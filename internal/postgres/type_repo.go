@@ -0,0 +1,307 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// TypeRepository handles type definition persistence. Types are global: every
+// tenant shares them.
+type TypeRepository struct {
+	db *DB
+}
+
+// NewTypeRepository creates a new type repository
+func NewTypeRepository(db *DB) *TypeRepository {
+	return &TypeRepository{db: db}
+}
+
+// typeColumns is the column list read by scanType
+const typeColumns = `name, COALESCE(description, ''), COALESCE(renderers_json, '[]'),
+	COALESCE(editors_json, '[]'), COALESCE(constraints_json, '[]'), COALESCE(labels_json, '{}'),
+	COALESCE(schema_version, 0)`
+
+// Create inserts a new type definition, recording its JSON Schema, if any, as
+// version 1
+func (r *TypeRepository) Create(ctx context.Context, typeDef *domain.TypeDef) error {
+	renderers, editors, constraints, labels, err := marshalType(typeDef)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO type_def (name, description, renderers_json, editors_json, constraints_json, labels_json)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = tx.ExecContext(ctx, query, typeDef.Name, typeDef.Description, renderers, editors, constraints, labels)
+	if err != nil {
+		return fmt.Errorf("failed to create type: %w", asConflict(err))
+	}
+
+	if err := r.recordSchema(ctx, tx, typeDef); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Get retrieves a type definition by name
+func (r *TypeRepository) Get(ctx context.Context, name string) (*domain.TypeDef, error) {
+	query := `SELECT ` + typeColumns + ` FROM type_def WHERE name = $1`
+
+	typeDef, err := scanType(r.db.QueryRowContext(ctx, query, name))
+	if err == sql.ErrNoRows {
+		return nil, notFound("type not found: %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get type: %w", err)
+	}
+
+	return typeDef, nil
+}
+
+// List retrieves every type definition
+func (r *TypeRepository) List(ctx context.Context) ([]*domain.TypeDef, error) {
+	query := `SELECT ` + typeColumns + ` FROM type_def ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list types: %w", err)
+	}
+	defer rows.Close()
+
+	var types []*domain.TypeDef
+
+	for rows.Next() {
+		typeDef, err := scanType(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan type: %w", err)
+		}
+		types = append(types, typeDef)
+	}
+
+	return types, rows.Err()
+}
+
+// Update replaces a type definition's description, renderers, editors, constraints
+// and labels. A changed JSON Schema is recorded as a new version.
+func (r *TypeRepository) Update(ctx context.Context, typeDef *domain.TypeDef) error {
+	renderers, editors, constraints, labels, err := marshalType(typeDef)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE type_def
+		SET description = $2, renderers_json = $3, editors_json = $4, constraints_json = $5, labels_json = $6
+		WHERE name = $1
+	`
+
+	result, err := tx.ExecContext(ctx, query, typeDef.Name, typeDef.Description, renderers, editors, constraints, labels)
+	if err != nil {
+		return fmt.Errorf("failed to update type: %w", err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return notFound("type not found: %s", typeDef.Name)
+	}
+
+	if err := r.recordSchema(ctx, tx, typeDef); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete deletes a type definition. Content blocks tagged with it keep the tag.
+func (r *TypeRepository) Delete(ctx context.Context, name string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM type_def WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete type: %w", err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return notFound("type not found: %s", name)
+	}
+
+	return nil
+}
+
+// ListSchemas retrieves every version of a type's JSON Schema, newest first
+func (r *TypeRepository) ListSchemas(ctx context.Context, name string) ([]*domain.TypeSchema, error) {
+	query := `
+		SELECT type_name, version, schema_json, created_at
+		FROM type_schema
+		WHERE type_name = $1
+		ORDER BY version DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list type schemas: %w", err)
+	}
+	defer rows.Close()
+
+	var schemas []*domain.TypeSchema
+
+	for rows.Next() {
+		schema := &domain.TypeSchema{}
+		if err := rows.Scan(&schema.TypeName, &schema.Version, &schema.Schema, &schema.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan type schema: %w", err)
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, rows.Err()
+}
+
+// GetSchema retrieves one version of a type's JSON Schema
+func (r *TypeRepository) GetSchema(ctx context.Context, name string, version int) (*domain.TypeSchema, error) {
+	query := `
+		SELECT type_name, version, schema_json, created_at
+		FROM type_schema
+		WHERE type_name = $1 AND version = $2
+	`
+
+	schema := &domain.TypeSchema{}
+	err := r.db.QueryRowContext(ctx, query, name, version).Scan(&schema.TypeName, &schema.Version, &schema.Schema, &schema.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, notFound("type schema not found: %s version %d", name, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get type schema: %w", err)
+	}
+
+	return schema, nil
+}
+
+// Helper methods
+
+// recordSchema makes the JSON Schema of typeDef its current schema version,
+// recording the schema as a new version unless it equals the latest one
+func (r *TypeRepository) recordSchema(ctx context.Context, tx *sql.Tx, typeDef *domain.TypeDef) error {
+	schema, ok := typeDef.JSONSchema()
+	if !ok {
+		if _, err := tx.ExecContext(ctx, `UPDATE type_def SET schema_version = NULL WHERE name = $1`, typeDef.Name); err != nil {
+			return fmt.Errorf("failed to clear type schema version: %w", err)
+		}
+		typeDef.SchemaVersion = 0
+		return nil
+	}
+
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("failed to marshal type schema: %w", err)
+	}
+
+	var version int
+	var unchanged bool
+
+	query := `
+		SELECT version, schema_json = $2::jsonb
+		FROM type_schema
+		WHERE type_name = $1
+		ORDER BY version DESC
+		LIMIT 1
+	`
+
+	err = tx.QueryRowContext(ctx, query, typeDef.Name, schemaJSON).Scan(&version, &unchanged)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get latest type schema: %w", err)
+	}
+
+	if !unchanged {
+		version++
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO type_schema (type_name, version, schema_json) VALUES ($1, $2, $3)`,
+			typeDef.Name, version, schemaJSON,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record type schema: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE type_def SET schema_version = $2 WHERE name = $1`, typeDef.Name, version); err != nil {
+		return fmt.Errorf("failed to set type schema version: %w", err)
+	}
+	typeDef.SchemaVersion = version
+
+	return nil
+}
+
+func marshalType(typeDef *domain.TypeDef) (renderers, editors, constraints, labels []byte, err error) {
+	if renderers, err = json.Marshal(nonNil(typeDef.Renderers)); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to marshal renderers: %w", err)
+	}
+	if editors, err = json.Marshal(nonNil(typeDef.Editors)); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to marshal editors: %w", err)
+	}
+	if constraints, err = json.Marshal(nonNil(typeDef.Constraints)); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to marshal constraints: %w", err)
+	}
+	if labels, err = json.Marshal(typeDef.Labels); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to marshal labels: %w", err)
+	}
+	if typeDef.Labels == nil {
+		labels = []byte(`{}`)
+	}
+	return renderers, editors, constraints, labels, nil
+}
+
+// nonNil returns items, or an empty slice in place of nil so it marshals as []
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func scanType(row rowScanner) (*domain.TypeDef, error) {
+	typeDef := &domain.TypeDef{}
+	var renderers, editors, constraints, labels []byte
+
+	err := row.Scan(
+		&typeDef.Name,
+		&typeDef.Description,
+		&renderers,
+		&editors,
+		&constraints,
+		&labels,
+		&typeDef.SchemaVersion,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(renderers, &typeDef.Renderers); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal renderers: %w", err)
+	}
+	if err := json.Unmarshal(editors, &typeDef.Editors); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal editors: %w", err)
+	}
+	if err := json.Unmarshal(constraints, &typeDef.Constraints); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal constraints: %w", err)
+	}
+	if err := json.Unmarshal(labels, &typeDef.Labels); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal labels: %w", err)
+	}
+
+	return typeDef, nil
+}
@@ -0,0 +1,341 @@
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// WebhookRepository handles webhook secrets and the delivery outbox
+type WebhookRepository struct {
+	db *DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// WebhookDeliveryFilter narrows the deliveries returned by ListDeliveries
+type WebhookDeliveryFilter struct {
+	TenantID    string
+	ResourceURI string
+	Status      string
+	Limit       int
+}
+
+// GetSecret returns the tenant's webhook signing secret, generating one on first use
+func (r *WebhookRepository) GetSecret(ctx context.Context, tenantID string) (string, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return "", err
+	}
+
+	insertQuery := `
+		INSERT INTO webhook_secret (tenant_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (tenant_id) DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, insertQuery, tenantID, secret); err != nil {
+		return "", fmt.Errorf("failed to create webhook secret: %w", err)
+	}
+
+	err = r.db.QueryRowContext(ctx, `SELECT secret FROM webhook_secret WHERE tenant_id = $1`, tenantID).Scan(&secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to get webhook secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// RotateSecret replaces the tenant's webhook signing secret and returns the new one
+func (r *WebhookRepository) RotateSecret(ctx context.Context, tenantID string) (string, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		INSERT INTO webhook_secret (tenant_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET secret = EXCLUDED.secret
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, tenantID, secret); err != nil {
+		return "", fmt.Errorf("failed to rotate webhook secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// Enqueue adds deliveries to the outbox
+func (r *WebhookRepository) Enqueue(ctx context.Context, deliveries []*domain.WebhookDelivery) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO webhook_delivery (tenant_id, resource_uri, event_type, url, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, next_attempt_at, created_at
+	`
+
+	for _, delivery := range deliveries {
+		err := tx.QueryRowContext(ctx, query,
+			delivery.TenantID,
+			delivery.ResourceURI,
+			delivery.EventType,
+			delivery.URL,
+			[]byte(delivery.Payload),
+		).Scan(&delivery.ID, &delivery.Status, &delivery.NextAttemptAt, &delivery.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ClaimDue returns up to limit pending deliveries whose next attempt is due.
+// Claimed deliveries are leased for the given duration so that concurrent
+// workers do not pick them up while they are being sent.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_delivery
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM webhook_delivery
+			WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, tenant_id, resource_uri, event_type, url, payload, status, attempts,
+			next_attempt_at, COALESCE(last_error, ''), delivered_at, created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RecordAttempt stores the outcome of a delivery attempt and updates the delivery state
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookAttempt) error {
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	attemptQuery := `
+		INSERT INTO webhook_delivery_attempt (delivery_id, attempt, status_code, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING attempted_at
+	`
+
+	err = tx.QueryRowContext(ctx, attemptQuery,
+		attempt.DeliveryID,
+		attempt.Attempt,
+		nullInt(attempt.StatusCode),
+		nullString(attempt.Error),
+		attempt.DurationMS,
+	).Scan(&attempt.AttemptedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+
+	deliveryQuery := `
+		UPDATE webhook_delivery
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5, delivered_at = $6
+		WHERE id = $1
+	`
+
+	_, err = tx.ExecContext(ctx, deliveryQuery,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		nullString(delivery.LastError),
+		delivery.DeliveredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Retry moves a dead-lettered delivery back to pending so it is attempted again
+func (r *WebhookRepository) Retry(ctx context.Context, tenantID, deliveryID string) error {
+	query := `
+		UPDATE webhook_delivery
+		SET status = 'pending', next_attempt_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND tenant_id = $2 AND status = 'dead'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, deliveryID, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to retry webhook delivery: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return notFound("dead-lettered webhook delivery not found: %s", deliveryID)
+	}
+
+	return nil
+}
+
+// ListDeliveries retrieves deliveries matching the filter, newest first, with their attempts
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]*domain.WebhookDelivery, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+
+	query := `
+		SELECT id, tenant_id, resource_uri, event_type, url, payload, status, attempts,
+			next_attempt_at, COALESCE(last_error, ''), delivered_at, created_at
+		FROM webhook_delivery
+		WHERE tenant_id = $1
+			AND ($2 = '' OR resource_uri = $2)
+			AND ($3 = '' OR status = $3)
+		ORDER BY created_at DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.TenantID, filter.ResourceURI, filter.Status, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		attempts, err := r.listAttempts(ctx, delivery.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhook attempts: %w", err)
+		}
+		delivery.AttemptLog = attempts
+	}
+
+	return deliveries, nil
+}
+
+// Helper methods
+
+func (r *WebhookRepository) listAttempts(ctx context.Context, deliveryID string) ([]domain.WebhookAttempt, error) {
+	query := `
+		SELECT delivery_id, attempt, COALESCE(status_code, 0), COALESCE(error, ''), COALESCE(duration_ms, 0), attempted_at
+		FROM webhook_delivery_attempt
+		WHERE delivery_id = $1
+		ORDER BY attempt
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []domain.WebhookAttempt
+
+	for rows.Next() {
+		var attempt domain.WebhookAttempt
+		err := rows.Scan(
+			&attempt.DeliveryID,
+			&attempt.Attempt,
+			&attempt.StatusCode,
+			&attempt.Error,
+			&attempt.DurationMS,
+			&attempt.AttemptedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, rows.Err()
+}
+
+func scanWebhookDelivery(rows *sql.Rows) (*domain.WebhookDelivery, error) {
+	delivery := &domain.WebhookDelivery{}
+	var payload []byte
+
+	err := rows.Scan(
+		&delivery.ID,
+		&delivery.TenantID,
+		&delivery.ResourceURI,
+		&delivery.EventType,
+		&delivery.URL,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastError,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = payload
+	return delivery, nil
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullInt(i int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(i), Valid: i != 0}
+}
@@ -0,0 +1,37 @@
+package quota
+
+import "time"
+
+// bucket is a token bucket admitting rate requests per second, in bursts of up
+// to one second's worth
+type bucket struct {
+	rate   int64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate int64) *bucket {
+	return &bucket{rate: rate, tokens: float64(rate), last: time.Now()}
+}
+
+// full reports whether the bucket has refilled by now, having had no request
+// for the second it takes: it then admits what a new bucket would
+func (b *bucket) full(now time.Time) bool {
+	return now.Sub(b.last) >= time.Second
+}
+
+// take removes a token, returning 0, or how long until one is available if none is
+func (b *bucket) take(now time.Time) time.Duration {
+	b.tokens += now.Sub(b.last).Seconds() * float64(b.rate)
+	if b.tokens > float64(b.rate) {
+		b.tokens = float64(b.rate)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / float64(b.rate) * float64(time.Second))
+	}
+
+	b.tokens--
+	return 0
+}
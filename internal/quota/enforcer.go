@@ -0,0 +1,252 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prismon/synthesis/internal/auth"
+	"github.com/prismon/synthesis/internal/config"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// overrideTTL is how long a tenant's quota overrides are reused before being read again
+const overrideTTL = 30 * time.Second
+
+// sweepInterval is how often idle token buckets and expired overrides are dropped
+const sweepInterval = time.Minute
+
+// ExceededError reports an action that would take a tenant over one of its limits
+type ExceededError struct {
+	TenantID string
+	// Limit is the limit exceeded, e.g. domain.QuotaNotebooks
+	Limit string
+	Max   int64
+	Used  int64
+	// RetryAfter is when the limit frees up by itself; 0 for limits that do not
+	RetryAfter time.Duration
+}
+
+func (e *ExceededError) Error() string {
+	name := strings.ReplaceAll(e.Limit, "_", " ")
+	if e.Limit == domain.QuotaRequestsPerSecond {
+		return fmt.Sprintf("rate limit exceeded: tenant %s is limited to %d %s", e.TenantID, e.Max, name)
+	}
+	return fmt.Sprintf("quota exceeded: tenant %s is limited to %d %s and has used %d", e.TenantID, e.Max, name, e.Used)
+}
+
+// limits are a tenant's effective limits; 0 means unlimited
+type limits struct {
+	notebooks         int64
+	features          int64
+	contentBytes      int64
+	requestsPerSecond int64
+	expires           time.Time
+}
+
+// Enforcer enforces the per-tenant limits: counts of notebooks and features and
+// the size of notebook content, all shared through the database, and request
+// rates, counted per process.
+type Enforcer struct {
+	repo     *postgres.QuotaRepository
+	defaults config.QuotaConfig
+
+	mu        sync.Mutex
+	limits    map[string]*limits
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewEnforcer creates an enforcer of cfg's limits and the tenants' overrides in repo
+func NewEnforcer(repo *postgres.QuotaRepository, cfg config.QuotaConfig) *Enforcer {
+	return &Enforcer{
+		repo:     repo,
+		defaults: cfg,
+		limits:   make(map[string]*limits),
+		buckets:  make(map[string]*bucket),
+	}
+}
+
+// Allow takes one request from tenantID's request rate, returning an
+// *ExceededError if the tenant is over its limit
+func (e *Enforcer) Allow(ctx context.Context, tenantID string) error {
+	l, err := e.tenantLimits(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if l.requestsPerSecond == 0 {
+		return nil
+	}
+
+	now := time.Now()
+
+	e.mu.Lock()
+	e.sweep(now)
+	b := e.buckets[tenantID]
+	if b == nil || b.rate != l.requestsPerSecond {
+		b = newBucket(l.requestsPerSecond)
+		e.buckets[tenantID] = b
+	}
+	wait := b.take(now)
+	e.mu.Unlock()
+
+	if wait > 0 {
+		return &ExceededError{
+			TenantID:   tenantID,
+			Limit:      domain.QuotaRequestsPerSecond,
+			Max:        l.requestsPerSecond,
+			RetryAfter: wait,
+		}
+	}
+	return nil
+}
+
+// CheckNotebooks returns an *ExceededError if creating n notebooks would take
+// tenantID over its limit
+func (e *Enforcer) CheckNotebooks(ctx context.Context, tenantID string, n int64) error {
+	return e.check(ctx, tenantID, domain.QuotaNotebooks, n, func(l *limits) int64 { return l.notebooks }, e.repo.CountNotebooks)
+}
+
+// CheckFeatures returns an *ExceededError if creating n features would take
+// tenantID over its limit
+func (e *Enforcer) CheckFeatures(ctx context.Context, tenantID string, n int64) error {
+	return e.check(ctx, tenantID, domain.QuotaFeatures, n, func(l *limits) int64 { return l.features }, e.repo.CountFeatures)
+}
+
+// CheckContentBytes returns an *ExceededError if adding n bytes of notebook
+// content would take tenantID over its limit
+func (e *Enforcer) CheckContentBytes(ctx context.Context, tenantID string, n int64) error {
+	return e.check(ctx, tenantID, domain.QuotaContentBytes, n, func(l *limits) int64 { return l.contentBytes }, e.repo.ContentBytes)
+}
+
+// check compares current usage plus n against a limit. Counts are read before
+// the write they guard, so concurrent writes may briefly exceed a limit.
+func (e *Enforcer) check(ctx context.Context, tenantID, limit string, n int64, max func(*limits) int64, used func(context.Context, string) (int64, error)) error {
+	l, err := e.tenantLimits(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if max(l) == 0 || n <= 0 {
+		return nil
+	}
+
+	count, err := used(postgres.WithSystemScope(ctx), tenantID)
+	if err != nil {
+		return err
+	}
+
+	if count+n > max(l) {
+		return &ExceededError{TenantID: tenantID, Limit: limit, Max: max(l), Used: count}
+	}
+	return nil
+}
+
+// Usage returns tenantID's usage of each of its limits
+func (e *Enforcer) Usage(ctx context.Context, tenantID string) (*domain.TenantUsage, error) {
+	l, err := e.tenantLimits(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &domain.TenantUsage{
+		TenantID:          tenantID,
+		Notebooks:         domain.QuotaUsage{Limit: l.notebooks},
+		Features:          domain.QuotaUsage{Limit: l.features},
+		ContentBytes:      domain.QuotaUsage{Limit: l.contentBytes},
+		RequestsPerSecond: domain.QuotaUsage{Limit: l.requestsPerSecond},
+	}
+
+	if usage.Notebooks.Used, err = e.repo.CountNotebooks(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	if usage.Features.Used, err = e.repo.CountFeatures(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	if usage.ContentBytes.Used, err = e.repo.ContentBytes(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// Forget drops the cached overrides of tenantID, so changes to them apply at once
+func (e *Enforcer) Forget(tenantID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.limits, tenantID)
+}
+
+// tenantLimits returns tenantID's effective limits: its overrides, else the defaults
+func (e *Enforcer) tenantLimits(ctx context.Context, tenantID string) (*limits, error) {
+	now := time.Now()
+
+	e.mu.Lock()
+	cached := e.limits[tenantID]
+	e.mu.Unlock()
+	if cached != nil && now.Before(cached.expires) {
+		return cached, nil
+	}
+
+	quota, err := e.repo.Get(postgres.WithSystemScope(ctx), tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &limits{
+		notebooks:         override(quota.MaxNotebooks, e.defaults.MaxNotebooks),
+		features:          override(quota.MaxFeatures, e.defaults.MaxFeatures),
+		contentBytes:      override(quota.MaxContentBytes, e.defaults.MaxContentBytes),
+		requestsPerSecond: override(quota.MaxRequestsPerSecond, e.defaults.MaxRequestsPerSecond),
+		expires:           now.Add(overrideTTL),
+	}
+
+	e.mu.Lock()
+	e.limits[tenantID] = l
+	e.mu.Unlock()
+
+	return l, nil
+}
+
+// sweep drops, at most once per sweepInterval, the token buckets that have
+// refilled, which a new bucket replaces exactly, and expired overrides, so that
+// tenants no longer making requests are not kept. e.mu must be held.
+func (e *Enforcer) sweep(now time.Time) {
+	if now.Sub(e.lastSweep) < sweepInterval {
+		return
+	}
+	e.lastSweep = now
+
+	for tenantID, b := range e.buckets {
+		if b.full(now) {
+			delete(e.buckets, tenantID)
+		}
+	}
+	for tenantID, l := range e.limits {
+		if now.After(l.expires) {
+			delete(e.limits, tenantID)
+		}
+	}
+}
+
+func override(value *int64, fallback int) int64 {
+	if value != nil {
+		return *value
+	}
+	return int64(fallback)
+}
+
+// RequestTenant returns the tenant a request counts against: the tenant of the
+// resource at target, else the caller's tenant. It returns "" for requests
+// counting against no tenant.
+func RequestTenant(ctx context.Context, target string) string {
+	if ref, err := domain.ParseURI(target); err == nil && ref.TenantID != "" {
+		return ref.TenantID
+	}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return principal.TenantID
+	}
+	return ""
+}
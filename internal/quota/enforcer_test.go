@@ -0,0 +1,88 @@
+package quota
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSweepDropsIdleBuckets(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		lastSweep time.Time
+		idle      time.Duration
+		expires   time.Duration
+		kept      bool
+	}{
+		{"bucket used within the second", time.Time{}, 500 * time.Millisecond, time.Minute, true},
+		{"bucket refilled", time.Time{}, time.Second, time.Minute, false},
+		{"long idle bucket", time.Time{}, time.Hour, time.Minute, false},
+		{"sweep not yet due", now.Add(-sweepInterval / 2), time.Hour, -time.Minute, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newBucket(10)
+			b.take(now.Add(-tc.idle))
+
+			e := &Enforcer{
+				limits:    map[string]*limits{"acme": {requestsPerSecond: 10, expires: now.Add(tc.expires)}},
+				buckets:   map[string]*bucket{"acme": b},
+				lastSweep: tc.lastSweep,
+			}
+			e.sweep(now)
+
+			if _, kept := e.buckets["acme"]; kept != tc.kept {
+				t.Errorf("expected bucket kept=%v, got %v", tc.kept, kept)
+			}
+		})
+	}
+}
+
+func TestSweepDropsExpiredLimits(t *testing.T) {
+	now := time.Now()
+	e := &Enforcer{
+		limits: map[string]*limits{
+			"current": {expires: now.Add(time.Second)},
+			"expired": {expires: now.Add(-time.Second)},
+		},
+		buckets: map[string]*bucket{},
+	}
+	e.sweep(now)
+
+	if _, ok := e.limits["current"]; !ok {
+		t.Error("expected current limits to be kept")
+	}
+	if _, ok := e.limits["expired"]; ok {
+		t.Error("expected expired limits to be dropped")
+	}
+}
+
+func TestRefilledBucketAdmitsAsNew(t *testing.T) {
+	now := time.Now()
+	b := &bucket{rate: 3, tokens: 3, last: now}
+
+	burst := func(at time.Time) int {
+		admitted := 0
+		for b.take(at) == 0 {
+			admitted++
+		}
+		return admitted
+	}
+
+	if n := burst(now); n != 3 {
+		t.Fatalf("expected a burst of 3, got %d", n)
+	}
+	if b.full(now.Add(time.Second / 2)) {
+		t.Fatal("expected the bucket not to have refilled after half a second")
+	}
+
+	later := now.Add(time.Second)
+	if !b.full(later) {
+		t.Fatal("expected the bucket to have refilled after a second")
+	}
+	if n := burst(later); n != 3 {
+		t.Errorf("expected a refilled bucket to admit a burst of 3 as a new one does, got %d", n)
+	}
+}
@@ -0,0 +1,282 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+const (
+	// writeWait is the time allowed to write a message to the peer
+	writeWait = 10 * time.Second
+	// pongWait is the time allowed to read the next pong from the peer
+	pongWait = 60 * time.Second
+	// pingPeriod is the heartbeat interval; it must be shorter than pongWait
+	pingPeriod = (pongWait * 9) / 10
+	// maxMessageSize is the largest message accepted from the peer
+	maxMessageSize = 64 * 1024
+	// sendBufferSize is the number of outgoing messages queued per connection
+	sendBufferSize = 256
+	// replayBatchSize is the number of events read per query when resuming
+	replayBatchSize = 500
+	// maxReplay is the most events replayed when resuming a subscription
+	maxReplay = 10000
+)
+
+// client is a single WebSocket connection
+type client struct {
+	hub     *Hub
+	conn    *websocket.Conn
+	request *http.Request
+	send    chan *ServerMessage
+
+	mu            sync.Mutex
+	subscriptions map[string]*subscription
+	sessions      map[string]*session
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newClient(hub *Hub, conn *websocket.Conn, r *http.Request) *client {
+	return &client{
+		hub:           hub,
+		conn:          conn,
+		request:       r,
+		send:          make(chan *ServerMessage, sendBufferSize),
+		subscriptions: make(map[string]*subscription),
+		sessions:      make(map[string]*session),
+		done:          make(chan struct{}),
+	}
+}
+
+// close shuts the connection down; it is safe to call more than once
+func (c *client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// readPump reads client messages until the connection closes
+func (c *client) readPump() {
+	defer c.close()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var msg ClientMessage
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("websocket read failed: %v", err)
+			}
+			return
+		}
+
+		c.handleMessage(&msg)
+	}
+}
+
+// writePump writes queued messages and heartbeat pings until the connection closes
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.close()
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(writeWait))
+			return
+		case msg := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleMessage dispatches a single client message
+func (c *client) handleMessage(msg *ClientMessage) {
+	switch msg.Type {
+	case MessageSubscribe:
+		if err := c.subscribe(msg.URI, msg.LastEventID); err != nil {
+			c.reply(&ServerMessage{Type: MessageError, URI: msg.URI, Error: err.Error()})
+		}
+	case MessageUnsubscribe:
+		c.mu.Lock()
+		delete(c.subscriptions, msg.URI)
+		c.mu.Unlock()
+		c.reply(&ServerMessage{Type: MessageUnsubscribed, URI: msg.URI})
+	case MessageJoin:
+		if err := c.join(msg.URI, msg.Actor, msg.Revision); err != nil {
+			c.reply(&ServerMessage{Type: MessageError, URI: msg.URI, Error: err.Error()})
+		}
+	case MessageLeave:
+		c.mu.Lock()
+		delete(c.sessions, msg.URI)
+		c.mu.Unlock()
+		c.reply(&ServerMessage{Type: MessageLeft, URI: msg.URI})
+	case MessageOperation:
+		if err := c.applyOperation(msg.URI, msg.Operation); err != nil {
+			c.reply(&ServerMessage{Type: MessageError, URI: msg.URI, Operation: msg.Operation, Error: err.Error()})
+		}
+	case MessagePing:
+		c.reply(&ServerMessage{Type: MessagePong})
+	default:
+		c.reply(&ServerMessage{Type: MessageError, Error: fmt.Sprintf("unknown message type %q", msg.Type)})
+	}
+}
+
+// subscribe authorizes and registers a subscription, replaying missed events when resuming
+func (c *client) subscribe(uri, lastEventID string) error {
+	sub, err := newSubscription(uri)
+	if err != nil {
+		return err
+	}
+
+	if err := c.hub.authorize(c.request, ActionSubscribe, sub.ref); err != nil {
+		return fmt.Errorf("not authorized to subscribe to %s: %w", sub.uri, err)
+	}
+
+	ctx := c.request.Context()
+
+	if lastEventID != "" {
+		last, err := c.hub.events.Get(ctx, lastEventID)
+		if err != nil {
+			return err
+		}
+		if last.TenantID != sub.ref.TenantID {
+			return fmt.Errorf("event %s does not belong to tenant %s", lastEventID, sub.ref.TenantID)
+		}
+		sub.lastSequence = last.Sequence
+		sub.replaying = true
+	}
+
+	c.mu.Lock()
+	c.subscriptions[sub.uri] = sub
+	c.mu.Unlock()
+
+	c.reply(&ServerMessage{Type: MessageSubscribed, URI: sub.uri})
+
+	if sub.replaying {
+		return c.replay(ctx, sub)
+	}
+	return nil
+}
+
+// replay sends the events a subscription missed, then releases live events held back meanwhile
+func (c *client) replay(ctx context.Context, sub *subscription) error {
+	after := sub.lastSequence
+	replayed := 0
+
+	for {
+		events, err := c.hub.events.ListSince(ctx, postgres.EventFilter{
+			AfterSequence: after,
+			TenantID:      sub.ref.TenantID,
+			Limit:         replayBatchSize,
+		})
+		if err != nil {
+			c.unsubscribe(sub)
+			return err
+		}
+
+		for _, event := range events {
+			after = event.Sequence
+			if !sub.matches(event) {
+				continue
+			}
+			c.reply(&ServerMessage{Type: MessageEvent, URI: sub.uri, Event: event})
+			replayed++
+		}
+
+		if replayed > maxReplay {
+			c.unsubscribe(sub)
+			return fmt.Errorf("too many events missed since the last event; reload %s and subscribe again without last_event_id", sub.uri)
+		}
+
+		if len(events) < replayBatchSize {
+			break
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sub.lastSequence = after
+	sub.replaying = false
+	for _, event := range sub.pending {
+		c.sendEvent(sub, event)
+	}
+	sub.pending = nil
+
+	return nil
+}
+
+func (c *client) unsubscribe(sub *subscription) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subscriptions[sub.uri] == sub {
+		delete(c.subscriptions, sub.uri)
+	}
+}
+
+// deliver forwards a live event to every subscription that matches it
+func (c *client) deliver(event *domain.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, sub := range c.subscriptions {
+		if !sub.matches(event) {
+			continue
+		}
+		if sub.replaying {
+			sub.pending = append(sub.pending, event)
+			continue
+		}
+		c.sendEvent(sub, event)
+	}
+}
+
+// sendEvent queues an event for a subscription, skipping events it has already seen.
+// A client that cannot keep up is disconnected; it can reconnect and resume. c.mu must be held.
+func (c *client) sendEvent(sub *subscription, event *domain.Event) {
+	if event.Sequence <= sub.lastSequence {
+		return
+	}
+	sub.lastSequence = event.Sequence
+
+	select {
+	case c.send <- &ServerMessage{Type: MessageEvent, URI: sub.uri, Event: event}:
+	default:
+		log.Printf("websocket client too slow, disconnecting")
+		c.close()
+	}
+}
+
+// reply queues a message from the read loop, waiting for room in the send buffer
+func (c *client) reply(msg *ServerMessage) {
+	select {
+	case c.send <- msg:
+	case <-c.done:
+	}
+}
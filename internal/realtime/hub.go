@@ -0,0 +1,108 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/prismon/synthesis/internal/blob"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+	"github.com/prismon/synthesis/internal/quota"
+	"github.com/prismon/synthesis/internal/validation"
+)
+
+// Actions a connection is authorized for
+const (
+	ActionSubscribe = "subscribe"
+	ActionEdit      = "edit"
+)
+
+// AuthorizeFunc decides whether a connection may perform an action (ActionSubscribe
+// or ActionEdit) on a resource. r is the HTTP request that opened the connection.
+type AuthorizeFunc func(r *http.Request, action string, ref *domain.ResourceRef) error
+
+// Hub tracks WebSocket connections, pushes domain events to their subscriptions
+// and runs collaborative editing sessions on notebooks
+type Hub struct {
+	events    *postgres.EventRepository
+	notebooks *postgres.NotebookRepository
+	authorize AuthorizeFunc
+	quotas    *quota.Enforcer
+	validator *validation.Validator
+	blobs     *blob.Service
+	upgrader  websocket.Upgrader
+
+	mu      sync.RWMutex
+	clients map[*client]struct{}
+}
+
+// NewHub creates a new WebSocket hub. Block operations count against the tenant
+// limits quotas enforces, and their content is checked with validator; binary
+// content is stored in blobs.
+func NewHub(events *postgres.EventRepository, notebooks *postgres.NotebookRepository, authorize AuthorizeFunc, quotas *quota.Enforcer, validator *validation.Validator, blobs *blob.Service) *Hub {
+	return &Hub{
+		events:    events,
+		notebooks: notebooks,
+		authorize: authorize,
+		quotas:    quotas,
+		validator: validator,
+		blobs:     blobs,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+			// Browsers connect from the web UI's origin; access is decided per subscription
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		clients: make(map[*client]struct{}),
+	}
+}
+
+// ServeHTTP upgrades the request to a WebSocket connection and serves it until it closes
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written an HTTP error response
+		log.Printf("websocket upgrade failed: %v", err)
+		return
+	}
+
+	c := newClient(h, conn, r)
+
+	h.mu.Lock()
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+
+	go c.writePump()
+	c.readPump()
+
+	h.mu.Lock()
+	delete(h.clients, c)
+	h.mu.Unlock()
+}
+
+// HandleEvent is a live event subscriber that forwards an event to every matching
+// subscription, and applied block operations to every session on the notebook
+func (h *Hub) HandleEvent(ctx context.Context, event *domain.Event) error {
+	var data domain.NotebookBlocksChangedData
+	if event.Type == domain.EventNotebookBlocksChanged {
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return fmt.Errorf("failed to decode %s event: %w", event.Type, err)
+		}
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for c := range h.clients {
+		c.deliver(event)
+		if data.Operation != nil {
+			c.deliverOperation(event.URI, data.Operation)
+		}
+	}
+	return nil
+}
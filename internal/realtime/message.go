@@ -0,0 +1,60 @@
+package realtime
+
+import "github.com/prismon/synthesis/internal/domain"
+
+// Client → server message types
+const (
+	MessageSubscribe   = "subscribe"
+	MessageUnsubscribe = "unsubscribe"
+	MessagePing        = "ping"
+	MessageJoin        = "join"
+	MessageLeave       = "leave"
+)
+
+// Server → client message types
+const (
+	MessageSubscribed   = "subscribed"
+	MessageUnsubscribed = "unsubscribed"
+	MessageEvent        = "event"
+	MessagePong         = "pong"
+	MessageError        = "error"
+	MessageJoined       = "joined"
+	MessageLeft         = "left"
+	MessageAck          = "ack"
+)
+
+// MessageOperation carries a block operation in both directions: clients send the
+// edits they make, and the server broadcasts every applied edit in revision order
+const MessageOperation = "op"
+
+// ClientMessage is a message sent by a WebSocket client
+type ClientMessage struct {
+	Type string `json:"type"`
+	// URI is the tenant, library or notebook URI to (un)subscribe
+	URI string `json:"uri,omitempty"`
+	// LastEventID resumes a subscription after the given event, replaying everything missed
+	LastEventID string `json:"last_event_id,omitempty"`
+
+	// Actor is the email address of the user editing the notebook joined;
+	// the authenticated principal takes precedence when there is one
+	Actor string `json:"actor,omitempty"`
+	// Revision resumes editing a joined notebook after the given revision
+	Revision *int64 `json:"revision,omitempty"`
+	// Operation is the block edit sent with an op message
+	Operation *domain.BlockOperation `json:"op,omitempty"`
+}
+
+// ServerMessage is a message sent to a WebSocket client
+type ServerMessage struct {
+	Type  string        `json:"type"`
+	URI   string        `json:"uri,omitempty"`
+	Event *domain.Event `json:"event,omitempty"`
+	Error string        `json:"error,omitempty"`
+
+	// Revision is the notebook revision a joined message starts from
+	Revision int64 `json:"revision,omitempty"`
+	// Blocks is the notebook's content at Revision, sent on join unless resuming
+	Blocks []domain.ContentBlock `json:"blocks,omitempty"`
+	// Operation is a block operation that was applied (op) or the outcome of the client's own (ack)
+	Operation *domain.BlockOperation `json:"op,omitempty"`
+}
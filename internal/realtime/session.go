@@ -0,0 +1,257 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/prismon/synthesis/internal/auth"
+	"github.com/prismon/synthesis/internal/blob"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// operationBatchSize is the number of operations read per query when catching up
+const operationBatchSize = 500
+
+// session is a client's participation in collaborative editing of a notebook.
+// The server sequences every block operation on the notebook; a session sees all
+// of them, its own included, in revision order without gaps.
+type session struct {
+	ref   *domain.ResourceRef
+	uri   string
+	actor string
+
+	// revision is the last revision sent to the client
+	revision int64
+	// catchingUp holds live operations while missed ones are read from the operation log
+	catchingUp bool
+	pending    []*domain.BlockOperation
+}
+
+// join starts a session on a notebook. Without a revision the client receives the
+// notebook's blocks; with one it receives every operation applied since.
+func (c *client) join(uri, actor string, revision *int64) error {
+	ref, err := domain.ParseURI(uri)
+	if err != nil {
+		return err
+	}
+	if ref.Type != "notebook" {
+		return fmt.Errorf("cannot edit %s resources; use a notebook URI", ref.Type)
+	}
+
+	actor = auth.IdentityFromContext(c.request.Context(), actor)
+	if actor == "" {
+		return fmt.Errorf("actor is required to join %s", uri)
+	}
+
+	if err := c.hub.authorize(c.request, ActionEdit, ref); err != nil {
+		return fmt.Errorf("not authorized to edit %s: %w", ref.URI(), err)
+	}
+
+	ctx := c.request.Context()
+
+	notebook, err := c.hub.notebooks.Get(ctx, ref.ID)
+	if err != nil {
+		return err
+	}
+	if notebook.TenantID != ref.TenantID {
+		return fmt.Errorf("notebook not found: %s", ref.ID)
+	}
+
+	sess := &session{ref: ref, uri: ref.URI(), actor: actor, catchingUp: true}
+
+	c.mu.Lock()
+	c.sessions[sess.uri] = sess
+	c.mu.Unlock()
+
+	joined := &ServerMessage{Type: MessageJoined, URI: sess.uri}
+	if revision != nil {
+		sess.revision = *revision
+		joined.Revision = *revision
+	} else {
+		current, blocks, err := c.hub.notebooks.GetBlocks(ctx, ref.ID)
+		if err != nil {
+			c.leave(sess)
+			return err
+		}
+		sess.revision = current
+		joined.Revision = current
+		joined.Blocks = blocks
+	}
+
+	c.reply(joined)
+
+	return c.catchUp(ctx, sess)
+}
+
+// leave ends a session if it is still the client's session on its notebook
+func (c *client) leave(sess *session) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sessions[sess.uri] == sess {
+		delete(c.sessions, sess.uri)
+	}
+}
+
+// applyOperation sequences a block operation from the client and acknowledges it.
+// The operation reaches every session on the notebook, this one included, through
+// the resulting notebook.blocks_changed event.
+func (c *client) applyOperation(uri string, op *domain.BlockOperation) error {
+	if op == nil {
+		return fmt.Errorf("op is required")
+	}
+
+	c.mu.Lock()
+	sess := c.sessions[uri]
+	c.mu.Unlock()
+
+	if sess == nil {
+		return fmt.Errorf("join %s before editing it", uri)
+	}
+
+	op.NotebookID = sess.ref.ID
+	op.Actor = sess.actor
+
+	if err := op.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.hub.quotas.Allow(c.request.Context(), sess.ref.TenantID); err != nil {
+		return err
+	}
+	if err := c.hub.quotas.CheckContentBytes(c.request.Context(), sess.ref.TenantID, int64(len(op.Data))); err != nil {
+		return err
+	}
+
+	// Binary content is stored as a blob the block references, once the block
+	// is known to be valid
+	pending := &blob.Pending{Data: op.Data}
+	if op.Type == domain.BlockOpInsert || op.Type == domain.BlockOpUpdate {
+		var err error
+		if pending, err = c.hub.blobs.Prepare(c.request.Context(), sess.ref.TenantID, op.ContentType, op.Data); err != nil {
+			return err
+		}
+		op.Data = pending.Data
+	}
+	if err := c.hub.validator.ValidateOperation(c.request.Context(), op, pending.Blob); err != nil {
+		return err
+	}
+	if err := c.hub.blobs.Offload(c.request.Context(), pending); err != nil {
+		return err
+	}
+
+	if err := c.hub.notebooks.ApplyBlockOperation(c.request.Context(), op); err != nil {
+		return err
+	}
+
+	c.reply(&ServerMessage{Type: MessageAck, URI: sess.uri, Operation: op})
+	return nil
+}
+
+// catchUp sends the operations applied after the session's revision, then
+// releases live operations held back meanwhile
+func (c *client) catchUp(ctx context.Context, sess *session) error {
+	after := sess.revision
+
+	for {
+		ops, err := c.hub.notebooks.ListBlockOperations(ctx, sess.ref.ID, after, operationBatchSize)
+		if err != nil {
+			c.leave(sess)
+			return err
+		}
+
+		for _, op := range ops {
+			c.reply(&ServerMessage{Type: MessageOperation, URI: sess.uri, Operation: op})
+			after = op.Revision
+		}
+
+		if len(ops) == operationBatchSize {
+			continue
+		}
+
+		c.mu.Lock()
+
+		for _, op := range sess.release(after) {
+			c.sendOperation(sess, op)
+		}
+		after = sess.revision
+
+		// An operation committed after the last read but missed by the live feed
+		// leaves a gap; read the log again to fill it
+		if len(sess.pending) > 0 {
+			c.mu.Unlock()
+			continue
+		}
+
+		sess.catchingUp = false
+		c.mu.Unlock()
+		return nil
+	}
+}
+
+// release returns the held operations that continue in order from revision after,
+// advancing the session past them. Operations already seen are dropped; those
+// beyond a gap stay held.
+func (sess *session) release(after int64) []*domain.BlockOperation {
+	sort.Slice(sess.pending, func(i, j int) bool {
+		return sess.pending[i].Revision < sess.pending[j].Revision
+	})
+
+	var released, held []*domain.BlockOperation
+	for _, op := range sess.pending {
+		switch {
+		case op.Revision <= after:
+		case op.Revision == after+1 && held == nil:
+			released = append(released, op)
+			after = op.Revision
+		default:
+			held = append(held, op)
+		}
+	}
+
+	sess.revision = after
+	sess.pending = held
+	return released
+}
+
+// deliverOperation forwards an applied operation to the client's session on the notebook
+func (c *client) deliverOperation(uri string, op *domain.BlockOperation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sess := c.sessions[uri]
+	if sess == nil {
+		return
+	}
+
+	switch {
+	case sess.catchingUp:
+		sess.pending = append(sess.pending, op)
+	case op.Revision <= sess.revision:
+	case op.Revision == sess.revision+1:
+		c.sendOperation(sess, op)
+		sess.revision = op.Revision
+	default:
+		// The live feed skipped an operation; fill the gap from the log
+		sess.catchingUp = true
+		sess.pending = append(sess.pending, op)
+		go func() {
+			if err := c.catchUp(c.request.Context(), sess); err != nil {
+				log.Printf("failed to catch up %s: %v", sess.uri, err)
+				c.close()
+			}
+		}()
+	}
+}
+
+// sendOperation queues an operation for a session. A client that cannot keep up
+// is disconnected; it can reconnect and resume from its revision. c.mu must be held.
+func (c *client) sendOperation(sess *session, op *domain.BlockOperation) {
+	select {
+	case c.send <- &ServerMessage{Type: MessageOperation, URI: sess.uri, Operation: op}:
+	default:
+		log.Printf("websocket client too slow, disconnecting")
+		c.close()
+	}
+}
@@ -0,0 +1,108 @@
+package realtime
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+func operations(revisions ...int64) []*domain.BlockOperation {
+	ops := make([]*domain.BlockOperation, len(revisions))
+	for i, revision := range revisions {
+		ops[i] = &domain.BlockOperation{Revision: revision}
+	}
+	return ops
+}
+
+func revisions(ops []*domain.BlockOperation) []int64 {
+	var revisions []int64
+	for _, op := range ops {
+		revisions = append(revisions, op.Revision)
+	}
+	return revisions
+}
+
+func TestReleasePending(t *testing.T) {
+	tests := []struct {
+		name     string
+		after    int64
+		pending  []int64
+		released []int64
+		held     []int64
+		revision int64
+	}{
+		{"nothing held", 4, nil, nil, nil, 4},
+		{"in order", 4, []int64{5, 6, 7}, []int64{5, 6, 7}, nil, 7},
+		{"out of order", 4, []int64{7, 5, 6}, []int64{5, 6, 7}, nil, 7},
+		{"already read from the log", 6, []int64{5, 6, 7}, []int64{7}, nil, 7},
+		{"duplicates", 4, []int64{5, 5, 6}, []int64{5, 6}, nil, 6},
+		{"gap", 4, []int64{5, 7, 8}, []int64{5}, []int64{7, 8}, 5},
+		{"gap before all", 4, []int64{6, 7}, nil, []int64{6, 7}, 4},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sess := &session{pending: operations(tc.pending...)}
+
+			released := sess.release(tc.after)
+
+			if got := revisions(released); !reflect.DeepEqual(got, tc.released) {
+				t.Errorf("expected released %v, got %v", tc.released, got)
+			}
+			if got := revisions(sess.pending); !reflect.DeepEqual(got, tc.held) {
+				t.Errorf("expected held %v, got %v", tc.held, got)
+			}
+			if sess.revision != tc.revision {
+				t.Errorf("expected revision %d, got %d", tc.revision, sess.revision)
+			}
+		})
+	}
+}
+
+func TestDeliverOperation(t *testing.T) {
+	const uri = "synthesis://acme/notebook/n1"
+
+	tests := []struct {
+		name       string
+		revision   int64
+		catchingUp bool
+		delivered  []int64
+		sent       []int64
+		pending    []int64
+	}{
+		{"in order", 4, false, []int64{5, 6}, []int64{5, 6}, nil},
+		{"already sent", 4, false, []int64{3, 4, 5}, []int64{5}, nil},
+		{"redelivered", 4, false, []int64{5, 5}, []int64{5}, nil},
+		{"held while catching up", 4, true, []int64{6, 5}, nil, []int64{6, 5}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sess := &session{uri: uri, revision: tc.revision, catchingUp: tc.catchingUp}
+			c := &client{
+				send:     make(chan *ServerMessage, len(tc.delivered)),
+				sessions: map[string]*session{uri: sess},
+			}
+
+			for _, op := range operations(tc.delivered...) {
+				c.deliverOperation(uri, op)
+			}
+			close(c.send)
+
+			var sent []int64
+			for message := range c.send {
+				if message.Type != MessageOperation || message.URI != uri {
+					t.Errorf("unexpected message %+v", message)
+				}
+				sent = append(sent, message.Operation.Revision)
+			}
+			if !reflect.DeepEqual(sent, tc.sent) {
+				t.Errorf("expected sent %v, got %v", tc.sent, sent)
+			}
+			if got := revisions(sess.pending); !reflect.DeepEqual(got, tc.pending) {
+				t.Errorf("expected pending %v, got %v", tc.pending, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// subscription is a client's interest in a tenant, library or notebook
+type subscription struct {
+	ref *domain.ResourceRef
+	uri string
+
+	// lastSequence is the sequence of the last event sent; older events are dropped
+	lastSequence int64
+	// replaying holds live events that arrive while missed events are being replayed
+	replaying bool
+	pending   []*domain.Event
+}
+
+func newSubscription(uri string) (*subscription, error) {
+	ref, err := domain.ParseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ref.Type {
+	case "tenant", "library", "notebook":
+	default:
+		return nil, fmt.Errorf("cannot subscribe to %s resources; use a tenant, library or notebook URI", ref.Type)
+	}
+
+	return &subscription{ref: ref, uri: ref.URI()}, nil
+}
+
+// matches reports whether an event concerns the subscribed resource
+func (s *subscription) matches(event *domain.Event) bool {
+	if event.TenantID != s.ref.TenantID {
+		return false
+	}
+
+	switch s.ref.Type {
+	case "tenant":
+		return true
+	case "library":
+		if event.URI == s.uri {
+			return true
+		}
+		return event.ResourceType == "notebook" && notebookLibrary(event) == s.ref.ID
+	default:
+		return event.URI == s.uri
+	}
+}
+
+// notebookLibrary returns the library of the notebook an event describes
+func notebookLibrary(event *domain.Event) string {
+	var notebook struct {
+		LibraryID string `json:"libraryId"`
+	}
+	if err := json.Unmarshal(event.Data, &notebook); err != nil {
+		return ""
+	}
+	return notebook.LibraryID
+}
@@ -0,0 +1,247 @@
+package render
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// MarkdownHTML renders markdown as HTML. Raw HTML in the source is escaped, and
+// links and images with unsafe URLs are rendered as their text.
+func MarkdownHTML(source string) string {
+	var b strings.Builder
+	writeBlocksHTML(&b, parseMarkdown(source), false)
+	return b.String()
+}
+
+// MarkdownText renders markdown as plain text, dropping its markup
+func MarkdownText(source string) string {
+	return blocksText(parseMarkdown(source), false)
+}
+
+func writeBlocksHTML(b *strings.Builder, blocks []*node, tight bool) {
+	for i, block := range blocks {
+		switch block.kind {
+		case paragraphNode:
+			// The paragraphs of tight list items are not wrapped in <p>
+			if tight {
+				writeInlineHTML(b, block.children)
+				if i < len(blocks)-1 {
+					b.WriteString("\n")
+				}
+				continue
+			}
+			b.WriteString("<p>")
+			writeInlineHTML(b, block.children)
+			b.WriteString("</p>\n")
+
+		case headingNode:
+			tag := "h" + strconv.Itoa(block.level)
+			b.WriteString("<" + tag + ">")
+			writeInlineHTML(b, block.children)
+			b.WriteString("</" + tag + ">\n")
+
+		case codeBlockNode:
+			b.WriteString("<pre><code")
+			if language := codeLanguage(block.info); language != "" {
+				b.WriteString(` class="language-` + language + `"`)
+			}
+			b.WriteString(">")
+			b.WriteString(html.EscapeString(block.literal))
+			b.WriteString("</code></pre>\n")
+
+		case quoteNode:
+			b.WriteString("<blockquote>\n")
+			writeBlocksHTML(b, block.children, false)
+			b.WriteString("</blockquote>\n")
+
+		case listNode:
+			tag := "ul"
+			if block.ordered {
+				tag = "ol"
+			}
+			b.WriteString("<" + tag)
+			if block.ordered && block.start != 1 {
+				b.WriteString(` start="` + strconv.Itoa(block.start) + `"`)
+			}
+			b.WriteString(">\n")
+			for _, item := range block.children {
+				b.WriteString("<li>")
+				writeBlocksHTML(b, item.children, !block.loose)
+				b.WriteString("</li>\n")
+			}
+			b.WriteString("</" + tag + ">\n")
+
+		case ruleNode:
+			b.WriteString("<hr>\n")
+		}
+	}
+}
+
+func writeInlineHTML(b *strings.Builder, nodes []*node) {
+	for _, n := range nodes {
+		switch n.kind {
+		case textNode:
+			b.WriteString(html.EscapeString(n.literal))
+		case codeNode:
+			b.WriteString("<code>" + html.EscapeString(n.literal) + "</code>")
+		case emphNode, strongNode, strikeNode:
+			tag := map[nodeKind]string{emphNode: "em", strongNode: "strong", strikeNode: "del"}[n.kind]
+			b.WriteString("<" + tag + ">")
+			writeInlineHTML(b, n.children)
+			b.WriteString("</" + tag + ">")
+		case breakNode:
+			b.WriteString("<br>\n")
+
+		case linkNode:
+			if !safeURL(n.url, false) {
+				writeInlineHTML(b, n.children)
+				continue
+			}
+			b.WriteString(`<a href="` + html.EscapeString(n.url) + `"`)
+			if n.title != "" {
+				b.WriteString(` title="` + html.EscapeString(n.title) + `"`)
+			}
+			b.WriteString(` rel="nofollow noopener noreferrer">`)
+			writeInlineHTML(b, n.children)
+			b.WriteString("</a>")
+
+		case imageNode:
+			alt := inlineText(n.children)
+			if !safeURL(n.url, true) {
+				b.WriteString(html.EscapeString(alt))
+				continue
+			}
+			b.WriteString(`<img src="` + html.EscapeString(n.url) + `" alt="` + html.EscapeString(alt) + `"`)
+			if n.title != "" {
+				b.WriteString(` title="` + html.EscapeString(n.title) + `"`)
+			}
+			b.WriteString(">")
+		}
+	}
+}
+
+// blocksText renders blocks as plain text separated by blank lines, or by
+// single line breaks in tight list items
+func blocksText(blocks []*node, tight bool) string {
+	parts := make([]string, 0, len(blocks))
+
+	for _, block := range blocks {
+		switch block.kind {
+		case paragraphNode, headingNode:
+			parts = append(parts, inlineText(block.children))
+
+		case codeBlockNode:
+			parts = append(parts, strings.TrimSuffix(block.literal, "\n"))
+
+		case quoteNode:
+			lines := strings.Split(blocksText(block.children, false), "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimRight("> "+line, " ")
+			}
+			parts = append(parts, strings.Join(lines, "\n"))
+
+		case listNode:
+			items := make([]string, len(block.children))
+			for i, item := range block.children {
+				marker := "- "
+				if block.ordered {
+					marker = strconv.Itoa(block.start+i) + ". "
+				}
+				lines := strings.Split(blocksText(item.children, !block.loose), "\n")
+				for j := range lines {
+					if j == 0 {
+						lines[j] = marker + lines[j]
+					} else if lines[j] != "" {
+						lines[j] = strings.Repeat(" ", len(marker)) + lines[j]
+					}
+				}
+				items[i] = strings.Join(lines, "\n")
+			}
+			separator := "\n"
+			if block.loose {
+				separator = "\n\n"
+			}
+			parts = append(parts, strings.Join(items, separator))
+
+		case ruleNode:
+			parts = append(parts, "---")
+		}
+	}
+
+	if tight {
+		return strings.Join(parts, "\n")
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func inlineText(nodes []*node) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		switch n.kind {
+		case textNode, codeNode:
+			b.WriteString(n.literal)
+		case breakNode:
+			b.WriteString("\n")
+		case linkNode:
+			text := inlineText(n.children)
+			b.WriteString(text)
+			if url := strings.TrimPrefix(n.url, "mailto:"); url != text && safeURL(n.url, false) {
+				b.WriteString(" (" + n.url + ")")
+			}
+		default:
+			b.WriteString(inlineText(n.children))
+		}
+	}
+	return b.String()
+}
+
+// codeLanguage returns the language of a code block's info string when it is
+// safe to use as a class name
+func codeLanguage(info string) string {
+	for _, r := range info {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '+' || r == '.') {
+			return ""
+		}
+	}
+	return info
+}
+
+// safeImageData are the data: URI prefixes images may use
+var safeImageData = []string{"data:image/png;", "data:image/jpeg;", "data:image/gif;", "data:image/webp;", "data:image/avif;", "data:image/bmp;"}
+
+// safeURL reports whether a link, or an image when image is set, may point to
+// url: relative URLs and http, https and (for links) mailto URLs, and for
+// images raster data: URIs
+func safeURL(url string, image bool) bool {
+	url = strings.TrimSpace(url)
+	for _, r := range url {
+		if r < ' ' || r == 0x7f {
+			return false
+		}
+	}
+
+	colon := strings.IndexByte(url, ':')
+	if colon < 0 || strings.ContainsAny(url[:colon], "/?#") {
+		return true
+	}
+
+	scheme := strings.ToLower(url[:colon])
+	switch scheme {
+	case "http", "https":
+		return true
+	case "mailto":
+		return !image
+	case "data":
+		if !image {
+			return false
+		}
+		lower := strings.ToLower(url)
+		for _, prefix := range safeImageData {
+			if strings.HasPrefix(lower, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var (
+	tagPattern       = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)([^>]*)>`)
+	attributePattern = regexp.MustCompile(`^ ([a-z]+)="([^"<>]*)"`)
+
+	// allowedTags and allowedAttributes are the markup the renderer writes
+	allowedTags = map[string]bool{
+		"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+		"pre": true, "code": true, "blockquote": true, "ul": true, "ol": true, "li": true,
+		"hr": true, "br": true, "em": true, "strong": true, "del": true, "a": true, "img": true,
+	}
+	allowedAttributes = map[string]bool{"href": true, "src": true, "alt": true, "title": true, "rel": true, "class": true, "start": true}
+)
+
+// scriptURL reports whether a browser would run url as script or open it as a
+// document of its own. Browsers ignore whitespace and control characters in a
+// URL's scheme, so they are ignored here too. It is kept apart from safeURL, so
+// that a fault in safeURL cannot hide itself.
+func scriptURL(url string) bool {
+	url = strings.ToLower(strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, url))
+	switch {
+	case strings.HasPrefix(url, "javascript:"), strings.HasPrefix(url, "vbscript:"):
+		return true
+	case strings.HasPrefix(url, "data:"):
+		return !strings.HasPrefix(url, "data:image/") || strings.HasPrefix(url, "data:image/svg")
+	}
+	return false
+}
+
+// checkMarkup returns an error if rendered HTML holds markup the renderer does
+// not write: a tag or attribute outside its own, an attribute value that is not
+// closed in double quotes, or a link or image URL running script once decoded
+func checkMarkup(rendered string) error {
+	tags := tagPattern.FindAllStringSubmatch(rendered, -1)
+	if strings.Count(rendered, "<") != len(tags) || strings.Count(rendered, ">") != len(tags) {
+		return fmt.Errorf("unescaped angle brackets")
+	}
+
+	for _, tag := range tags {
+		name, attributes := strings.ToLower(tag[2]), tag[3]
+		if !allowedTags[name] {
+			return fmt.Errorf("tag %s", name)
+		}
+		for attributes != "" {
+			m := attributePattern.FindStringSubmatch(attributes)
+			if m == nil {
+				return fmt.Errorf("malformed attributes of %s: %q", name, attributes)
+			}
+			if !allowedAttributes[m[1]] {
+				return fmt.Errorf("attribute %s", m[1])
+			}
+			if (m[1] == "href" || m[1] == "src") && scriptURL(html.UnescapeString(m[2])) {
+				return fmt.Errorf("unsafe %s %q", m[1], m[2])
+			}
+			attributes = attributes[len(m[0]):]
+		}
+	}
+	return nil
+}
+
+func TestMarkdownHTMLNeutralizesPayloads(t *testing.T) {
+	tests := map[string]string{
+		"script tag":                  "<script>alert(1)</script>",
+		"event handler tag":           `<img src=x onerror="alert(1)">`,
+		"inline tag":                  `a <b onclick="x">b</b>`,
+		"javascript link":             "[x](javascript:alert(1))",
+		"mixed case scheme":           "[x](JaVaScRiPt:alert(1))",
+		"leading space":               "[x]( javascript:alert(1))",
+		"angle bracket destination":   "[x](<javascript:alert(1)>)",
+		"vbscript link":               "[x](vbscript:msgbox(1))",
+		"data link":                   "[x](data:text/html,<script>alert(1)</script>)",
+		"data image of html":          "![x](data:text/html,<script>alert(1)</script>)",
+		"data image of svg":           "![x](data:image/svg+xml;base64,PHN2Zz4=)",
+		"hex entity scheme":           "[x](jav&#x61;script:alert(1))",
+		"decimal entity scheme":       "[x](&#106;avascript:alert(1))",
+		"named entity colon":          "[x](javascript&colon;alert(1))",
+		"entity tab in scheme":        "[x](java&#9;script:alert(1))",
+		"escaped scheme":              `[x](java\script:alert(1))`,
+		"javascript autolink":         "<javascript:alert(1)>",
+		"javascript image":            "![x](javascript:alert(1))",
+		"quote breaking out of href":  `[x](https://a.example/"onmouseover="alert(1))`,
+		"quote breaking out of title": `[x](https://a.example "t\" onmouseover=\"alert(1)")`,
+		"single quote in title":       `[x](https://a.example 'a" onmouseover="alert(1)')`,
+		"quote in image alt":          `![a" onerror="alert(1)](https://a.example/i.png)`,
+		"markup in image alt":         "![<script>alert(1)</script>](https://a.example/i.png)",
+		"markup in link label":        "[<script>alert(1)</script>](https://a.example)",
+		"markup in code block info":   "```\"><script>alert(1)</script>\nx\n```",
+		"markup in code span":         "`<script>alert(1)</script>`",
+		"markup in heading":           "# <script>alert(1)</script>",
+		"entity encoded markup":       "&lt;script&gt;alert(1)&lt;/script&gt;",
+		"markup in nested emphasis":   "***<script>*** **[<svg onload=alert(1)>](/p)**",
+	}
+
+	for name, markdown := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := MarkdownHTML(markdown)
+			if err := checkMarkup(got); err != nil {
+				t.Errorf("MarkdownHTML(%q) = %q: %v", markdown, got, err)
+			}
+		})
+	}
+}
+
+func TestMarkdownHTMLEscapesAttributes(t *testing.T) {
+	tests := map[string]string{
+		`[x](https://a.example/"a'b)`: `<a href="https://a.example/&#34;a&#39;b"`,
+		`[x](/p 'it"s')`:              `title="it&#34;s"`,
+		`![a"b](/i.png "t'")`:         `<img src="/i.png" alt="a&#34;b" title="t&#39;">`,
+		"[x](/p?a=1&b=<c>)":           `href="/p?a=1&amp;b=&lt;c&gt;"`,
+		"```c++\nx\n```":              `<code class="language-c++">`,
+		"```go\" onclick=\"x\nx\n```": "<pre><code>",
+	}
+	for markdown, want := range tests {
+		if got := MarkdownHTML(markdown); !strings.Contains(got, want) {
+			t.Errorf("MarkdownHTML(%q) = %q, want it to contain %q", markdown, got, want)
+		}
+	}
+}
+
+func TestUnsafeLinksRenderAsText(t *testing.T) {
+	tests := map[string]string{
+		"[click](javascript:alert(1))":             "<p>click</p>\n",
+		"[x](jav&#x61;script:alert(1))":            "<p>x</p>\n",
+		"![alt <text>](data:text/html,x)":          "<p>alt &lt;text&gt;</p>\n",
+		"<javascript:alert(1)>":                    "<p>javascript:alert(1)</p>\n",
+		"![x](data:image/png;base64,iVBORw0KGgo=)": "<p><img src=\"data:image/png;base64,iVBORw0KGgo=\" alt=\"x\"></p>\n",
+	}
+	for markdown, want := range tests {
+		if got := MarkdownHTML(markdown); got != want {
+			t.Errorf("MarkdownHTML(%q) = %q, want %q", markdown, got, want)
+		}
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		image bool
+		want  bool
+	}{
+		{"https://a.example/", false, true},
+		{"http://a.example/", true, true},
+		{"/relative/path", false, true},
+		{"relative", false, true},
+		{"#fragment", false, true},
+		{"?q=a:b", false, true},
+		{"path/with:colon", false, true},
+		{"mailto:a@b.example", false, true},
+		{"mailto:a@b.example", true, false},
+		{"javascript:alert(1)", false, false},
+		{"JAVASCRIPT:alert(1)", false, false},
+		{" javascript:alert(1)", false, false},
+		{"java\tscript:alert(1)", false, false},
+		{"java\nscript:alert(1)", false, false},
+		{"\x00javascript:alert(1)", false, false},
+		{"vbscript:x", false, false},
+		{"file:///etc/passwd", false, false},
+		{"data:image/png;base64,AAAA", true, true},
+		{"DATA:IMAGE/PNG;base64,AAAA", true, true},
+		{"data:image/png;base64,AAAA", false, false},
+		{"data:image/svg+xml;base64,AAAA", true, false},
+		{"data:text/html,x", true, false},
+		{"data:image/png", true, false},
+	}
+	for _, tc := range tests {
+		if got := safeURL(tc.url, tc.image); got != tc.want {
+			t.Errorf("safeURL(%q, image=%v) = %v, want %v", tc.url, tc.image, got, tc.want)
+		}
+	}
+}
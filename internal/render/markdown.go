@@ -0,0 +1,621 @@
+package render
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The markdown renderer supports the common CommonMark constructs: ATX and
+// setext headings, paragraphs with hard line breaks, fenced and indented code,
+// block quotes, nested bullet and ordered lists, thematic breaks, and inline
+// code, emphasis, strong emphasis, ~~strikethrough~~, links, images,
+// autolinks, and entity and numeric character references. Raw HTML is not
+// supported: it is escaped like any other text, so rendered markdown cannot
+// inject markup, and link and image URLs are limited to safe schemes once their
+// escapes and references are decoded.
+
+type nodeKind int
+
+const (
+	paragraphNode nodeKind = iota
+	headingNode
+	codeBlockNode
+	quoteNode
+	listNode
+	itemNode
+	ruleNode
+	textNode
+	codeNode
+	emphNode
+	strongNode
+	strikeNode
+	linkNode
+	imageNode
+	breakNode
+)
+
+// node is a markdown block or inline element
+type node struct {
+	kind     nodeKind
+	level    int    // heading level
+	ordered  bool   // ordered list
+	start    int    // first number of an ordered list
+	loose    bool   // list whose items are separated by blank lines
+	literal  string // text, code span and code block content
+	info     string // code block language
+	url      string // link and image destination
+	title    string // link and image title
+	children []*node
+}
+
+var (
+	atxHeading    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	thematicBreak = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fenceOpen     = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*)$")
+	listMarker    = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])(?:[ \t]+|$)`)
+	setextH1      = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	setextH2      = regexp.MustCompile(`^ {0,3}-+[ \t]*$`)
+	autolink      = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^<>\s]*)>`)
+	entityRef     = regexp.MustCompile(`^&(?:#[xX][0-9a-fA-F]{1,6}|#[0-9]{1,7}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+	emailAutolink = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*)>`)
+)
+
+// parseMarkdown parses markdown source into its blocks
+func parseMarkdown(source string) []*node {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+	return parseBlocks(lines)
+}
+
+// expandTabs replaces the tabs in a line's indentation with four spaces each
+func expandTabs(line string) string {
+	indent := 0
+	for indent < len(line) && (line[indent] == ' ' || line[indent] == '\t') {
+		indent++
+	}
+	return strings.ReplaceAll(line[:indent], "\t", "    ") + line[indent:]
+}
+
+func parseBlocks(lines []string) []*node {
+	var blocks []*node
+	var paragraph []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			text := strings.TrimRight(strings.Join(paragraph, "\n"), " ")
+			blocks = append(blocks, &node{kind: paragraphNode, children: parseInline(text)})
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		if isBlank(line) {
+			flush()
+			i++
+			continue
+		}
+
+		if m := fenceOpen.FindStringSubmatch(line); m != nil {
+			flush()
+			var block *node
+			block, i = parseFence(lines, i, m)
+			blocks = append(blocks, block)
+			continue
+		}
+
+		if m := atxHeading.FindStringSubmatch(line); m != nil {
+			flush()
+			blocks = append(blocks, &node{kind: headingNode, level: len(m[1]), children: parseInline(strings.TrimSpace(m[2]))})
+			i++
+			continue
+		}
+
+		if len(paragraph) > 0 && (setextH1.MatchString(line) || setextH2.MatchString(line)) {
+			level := 2
+			if setextH1.MatchString(line) {
+				level = 1
+			}
+			text := strings.TrimSpace(strings.Join(paragraph, "\n"))
+			blocks = append(blocks, &node{kind: headingNode, level: level, children: parseInline(text)})
+			paragraph = nil
+			i++
+			continue
+		}
+
+		if thematicBreak.MatchString(line) {
+			flush()
+			blocks = append(blocks, &node{kind: ruleNode})
+			i++
+			continue
+		}
+
+		if isQuote(line) {
+			flush()
+			var quoted []string
+			for ; i < len(lines) && isQuote(lines[i]); i++ {
+				content := strings.TrimPrefix(strings.TrimLeft(lines[i], " "), ">")
+				quoted = append(quoted, strings.TrimPrefix(content, " "))
+			}
+			blocks = append(blocks, &node{kind: quoteNode, children: parseBlocks(quoted)})
+			continue
+		}
+
+		if listMarker.MatchString(line) {
+			flush()
+			var list *node
+			list, i = parseList(lines, i)
+			blocks = append(blocks, list)
+			continue
+		}
+
+		if len(paragraph) == 0 && indentOf(line) >= 4 {
+			var code []string
+			for ; i < len(lines) && (isBlank(lines[i]) || indentOf(lines[i]) >= 4); i++ {
+				code = append(code, removeIndent(lines[i], 4))
+			}
+			for len(code) > 0 && isBlank(code[len(code)-1]) {
+				code = code[:len(code)-1]
+			}
+			blocks = append(blocks, &node{kind: codeBlockNode, literal: strings.Join(code, "\n") + "\n"})
+			continue
+		}
+
+		paragraph = append(paragraph, strings.TrimLeft(line, " "))
+		i++
+	}
+
+	flush()
+	return blocks
+}
+
+// parseFence parses the fenced code block opening at lines[i]
+func parseFence(lines []string, i int, open []string) (*node, int) {
+	indent, fence := len(open[1]), open[2]
+	info := strings.Fields(open[3])
+
+	block := &node{kind: codeBlockNode}
+	if len(info) > 0 {
+		block.info = info[0]
+	}
+
+	var code []string
+	for i++; i < len(lines); i++ {
+		trimmed := strings.TrimRight(lines[i], " \t")
+		if indentOf(trimmed) <= 3 && strings.HasPrefix(strings.TrimLeft(trimmed, " "), fence) &&
+			strings.Trim(strings.TrimLeft(trimmed, " "), fence[:1]) == "" {
+			i++
+			break
+		}
+		code = append(code, removeIndent(lines[i], indent))
+	}
+
+	if len(code) > 0 {
+		block.literal = strings.Join(code, "\n") + "\n"
+	}
+	return block, i
+}
+
+// parseList parses the list starting at lines[i]
+func parseList(lines []string, i int) (*node, int) {
+	first := listMarker.FindStringSubmatch(lines[i])
+	list := &node{kind: listNode}
+	delimiter := first[2][len(first[2])-1]
+	if delimiter == '.' || delimiter == ')' {
+		list.ordered = true
+		list.start, _ = strconv.Atoi(first[2][:len(first[2])-1])
+	}
+
+	for i < len(lines) {
+		m := listMarker.FindStringSubmatch(lines[i])
+		if m == nil || m[2][len(m[2])-1] != delimiter {
+			break
+		}
+
+		contentIndent := len(m[0])
+		if strings.TrimSpace(lines[i][len(m[0]):]) == "" || len(m[0])-len(m[1])-len(m[2]) > 4 {
+			contentIndent = len(m[1]) + len(m[2]) + 1
+		}
+
+		itemLines := []string{strings.TrimLeft(lines[i][len(m[0]):], " ")}
+		for i++; i < len(lines); {
+			line := lines[i]
+
+			if isBlank(line) {
+				// Blank lines continue the item when it goes on indented after them
+				next := i
+				for next < len(lines) && isBlank(lines[next]) {
+					next++
+				}
+				if next == len(lines) || indentOf(lines[next]) < contentIndent {
+					break
+				}
+				for ; i < next; i++ {
+					itemLines = append(itemLines, "")
+				}
+				list.loose = true
+				continue
+			}
+
+			if indentOf(line) >= contentIndent {
+				itemLines = append(itemLines, removeIndent(line, contentIndent))
+				i++
+				continue
+			}
+
+			// A paragraph continues lazily on unindented lines
+			if !isBlockStart(line) && !isBlank(itemLines[len(itemLines)-1]) {
+				itemLines = append(itemLines, strings.TrimLeft(line, " "))
+				i++
+				continue
+			}
+			break
+		}
+
+		list.children = append(list.children, &node{kind: itemNode, children: parseBlocks(itemLines)})
+
+		// Blank lines between items make the list loose
+		next := i
+		for next < len(lines) && isBlank(lines[next]) {
+			next++
+		}
+		if next > i {
+			m := listMarker.FindStringSubmatch(safeLine(lines, next))
+			if m == nil || m[2][len(m[2])-1] != delimiter {
+				break
+			}
+			list.loose = true
+			i = next
+		}
+	}
+
+	return list, i
+}
+
+func safeLine(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func isQuote(line string) bool {
+	return indentOf(line) <= 3 && strings.HasPrefix(strings.TrimLeft(line, " "), ">")
+}
+
+func isBlockStart(line string) bool {
+	return atxHeading.MatchString(line) || fenceOpen.MatchString(line) || thematicBreak.MatchString(line) ||
+		isQuote(line) || listMarker.MatchString(line)
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// removeIndent removes up to n leading spaces from line
+func removeIndent(line string, n int) string {
+	for i := 0; i < n && strings.HasPrefix(line, " "); i++ {
+		line = line[1:]
+	}
+	return line
+}
+
+// parseInline parses the inline elements of a paragraph or heading
+func parseInline(s string) []*node {
+	var nodes []*node
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, &node{kind: textNode, literal: text.String()})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			flush()
+			nodes = append(nodes, &node{kind: breakNode})
+			i += 2
+
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			text.WriteByte(s[i+1])
+			i += 2
+
+		case c == '\n':
+			// Two or more trailing spaces make a hard line break
+			current := text.String()
+			trimmed := strings.TrimRight(current, " ")
+			text.Reset()
+			text.WriteString(trimmed)
+			if len(current)-len(trimmed) >= 2 {
+				flush()
+				nodes = append(nodes, &node{kind: breakNode})
+			} else {
+				text.WriteByte('\n')
+			}
+			for i++; i < len(s) && s[i] == ' '; i++ {
+			}
+
+		case c == '`':
+			n := runLength(s, i, '`')
+			end := findCodeSpanEnd(s, i+n, n)
+			if end < 0 {
+				text.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+			code := strings.ReplaceAll(s[i+n:end], "\n", " ")
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			flush()
+			nodes = append(nodes, &node{kind: codeNode, literal: code})
+			i = end + n
+
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			label, url, title, end, ok := parseLink(s, i+1)
+			if !ok {
+				text.WriteByte(c)
+				i++
+				continue
+			}
+			flush()
+			nodes = append(nodes, &node{kind: imageNode, url: url, title: title, children: parseInline(label)})
+			i = end
+
+		case c == '[':
+			label, url, title, end, ok := parseLink(s, i)
+			if !ok {
+				text.WriteByte(c)
+				i++
+				continue
+			}
+			flush()
+			nodes = append(nodes, &node{kind: linkNode, url: url, title: title, children: parseInline(label)})
+			i = end
+
+		case c == '<':
+			if m := autolink.FindStringSubmatch(s[i:]); m != nil {
+				flush()
+				nodes = append(nodes, &node{kind: linkNode, url: m[1], children: []*node{{kind: textNode, literal: m[1]}}})
+				i += len(m[0])
+				continue
+			}
+			if m := emailAutolink.FindStringSubmatch(s[i:]); m != nil {
+				flush()
+				nodes = append(nodes, &node{kind: linkNode, url: "mailto:" + m[1], children: []*node{{kind: textNode, literal: m[1]}}})
+				i += len(m[0])
+				continue
+			}
+			text.WriteByte(c)
+			i++
+
+		case c == '&':
+			if ref := entityRef.FindString(s[i:]); ref != "" {
+				if decoded := html.UnescapeString(ref); decoded != ref {
+					text.WriteString(decoded)
+					i += len(ref)
+					continue
+				}
+			}
+			text.WriteByte(c)
+			i++
+
+		case c == '*' || c == '_' || c == '~':
+			n := runLength(s, i, c)
+			delim := string(c)
+			if n >= 2 {
+				delim = strings.Repeat(string(c), 2)
+			}
+			if c == '~' && n < 2 {
+				text.WriteByte(c)
+				i++
+				continue
+			}
+
+			opens := i+len(delim) < len(s) && !isSpace(s[i+len(delim)]) && (c != '_' || i == 0 || !isAlnum(s[i-1]))
+			if opens {
+				if end := findCloser(s, i+len(delim), delim); end >= 0 {
+					kind := emphNode
+					switch {
+					case c == '~':
+						kind = strikeNode
+					case len(delim) == 2:
+						kind = strongNode
+					}
+					flush()
+					nodes = append(nodes, &node{kind: kind, children: parseInline(s[i+len(delim) : end])})
+					i = end + len(delim)
+					continue
+				}
+			}
+			text.WriteString(s[i : i+n])
+			i += n
+
+		default:
+			text.WriteByte(c)
+			i++
+		}
+	}
+
+	flush()
+	return nodes
+}
+
+// findCloser returns the index of the delimiter closing emphasis opened
+// before from, or -1
+func findCloser(s string, from int, delim string) int {
+	c := delim[0]
+	for j := from; j < len(s); {
+		switch {
+		case s[j] == '\\':
+			j += 2
+		case s[j] == '`':
+			n := runLength(s, j, '`')
+			if end := findCodeSpanEnd(s, j+n, n); end >= 0 {
+				j = end + n
+			} else {
+				j += n
+			}
+		case s[j] == c:
+			n := runLength(s, j, c)
+			closes := j > from && !isSpace(s[j-1]) && (c != '_' || j+n >= len(s) || !isAlnum(s[j+n]))
+			// A single delimiter skips a doubled run, which closes nested strong emphasis
+			if closes && n >= len(delim) && (len(delim) == 2 || n != 2) {
+				return j + n - len(delim)
+			}
+			j += n
+		default:
+			j++
+		}
+	}
+	return -1
+}
+
+// findCodeSpanEnd returns the index of the run of n backticks closing a code
+// span that starts at from, or -1
+func findCodeSpanEnd(s string, from, n int) int {
+	for j := from; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		run := runLength(s, j, '`')
+		if run == n {
+			return j
+		}
+		j += run
+	}
+	return -1
+}
+
+// parseLink parses a link "[label](url "title")" starting at s[i] == '['
+// and returns the index after it
+func parseLink(s string, i int) (label, url, title string, end int, ok bool) {
+	depth := 0
+	j := i
+	for ; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+			continue
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	if j >= len(s) || j+1 >= len(s) || s[j+1] != '(' {
+		return "", "", "", 0, false
+	}
+	label = s[i+1 : j]
+
+	k := j + 2
+	for k < len(s) && isSpace(s[k]) {
+		k++
+	}
+
+	if k < len(s) && s[k] == '<' {
+		close := strings.IndexByte(s[k:], '>')
+		if close < 0 {
+			return "", "", "", 0, false
+		}
+		url = s[k+1 : k+close]
+		k += close + 1
+	} else {
+		start, parens := k, 0
+		for ; k < len(s) && !isSpace(s[k]); k++ {
+			if s[k] == '(' {
+				parens++
+			} else if s[k] == ')' {
+				if parens == 0 {
+					break
+				}
+				parens--
+			}
+		}
+		url = s[start:k]
+	}
+
+	for k < len(s) && isSpace(s[k]) {
+		k++
+	}
+	if k < len(s) && (s[k] == '"' || s[k] == '\'') {
+		quote := s[k]
+		close := k + 1
+		for ; close < len(s) && s[close] != quote; close++ {
+			if s[close] == '\\' {
+				close++
+			}
+		}
+		if close >= len(s) {
+			return "", "", "", 0, false
+		}
+		title = s[k+1 : close]
+		k = close + 1
+		for k < len(s) && isSpace(s[k]) {
+			k++
+		}
+	}
+
+	if k >= len(s) || s[k] != ')' {
+		return "", "", "", 0, false
+	}
+	return label, unescapeText(url), unescapeText(title), k + 1, true
+}
+
+func runLength(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+// unescapeText decodes the backslash escapes and character references of a
+// link destination or title
+func unescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isPunct(s[i+1]) {
+			i++
+		} else if ref := entityRef.FindString(s[i:]); ref != "" {
+			b.WriteString(html.UnescapeString(ref))
+			i += len(ref) - 1
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
@@ -0,0 +1,84 @@
+package render
+
+import "testing"
+
+// rel is the rel attribute of every rendered link
+const rel = ` rel="nofollow noopener noreferrer"`
+
+func TestMarkdownHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		html     string
+	}{
+		{"paragraphs", "a\nb\n\nc", "<p>a\nb</p>\n<p>c</p>\n"},
+		{"ATX headings", "# H1\n## H2 ##\n###### H6", "<h1>H1</h1>\n<h2>H2</h2>\n<h6>H6</h6>\n"},
+		{"seven hashes are not a heading", "####### x", "<p>####### x</p>\n"},
+		{"setext headings", "Title\n===\nSub\n---", "<h1>Title</h1>\n<h2>Sub</h2>\n"},
+		{"thematic breaks", "***\n- - -\n___", "<hr>\n<hr>\n<hr>\n"},
+		{"hard line breaks", "one  \ntwo\\\nthree", "<p>one<br>\ntwo<br>\nthree</p>\n"},
+		{"fenced code", "```go\nif a < b {}\n```", "<pre><code class=\"language-go\">if a &lt; b {}\n</code></pre>\n"},
+		{"tilde fence", "~~~\n```\n~~~", "<pre><code>```\n</code></pre>\n"},
+		{"unclosed fence runs to the end", "```\na\n\nb", "<pre><code>a\n\nb\n</code></pre>\n"},
+		{"indented code", "    a <b>\n\n    c", "<pre><code>a &lt;b&gt;\n\nc\n</code></pre>\n"},
+		{"block quote", "> a\n> **b**", "<blockquote>\n<p>a\n<strong>b</strong></p>\n</blockquote>\n"},
+		{"nested block quote", "> > a", "<blockquote>\n<blockquote>\n<p>a</p>\n</blockquote>\n</blockquote>\n"},
+		{"bullet list", "- a\n- b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n"},
+		{"nested list", "- a\n  - b\n- c", "<ul>\n<li>a\n<ul>\n<li>b</li>\n</ul>\n</li>\n<li>c</li>\n</ul>\n"},
+		{"ordered list", "1. a\n2. b", "<ol>\n<li>a</li>\n<li>b</li>\n</ol>\n"},
+		{"ordered list start", "3) a\n4) b", "<ol start=\"3\">\n<li>a</li>\n<li>b</li>\n</ol>\n"},
+		{"loose list", "- a\n\n- b", "<ul>\n<li><p>a</p>\n</li>\n<li><p>b</p>\n</li>\n</ul>\n"},
+		{"changed bullet starts a new list", "- a\n* b", "<ul>\n<li>a</li>\n</ul>\n<ul>\n<li>b</li>\n</ul>\n"},
+		{"lazy continuation", "- a\nb", "<ul>\n<li>a\nb</li>\n</ul>\n"},
+		{"code span", "`a<b`", "<p><code>a&lt;b</code></p>\n"},
+		{"code span with backticks", "`` a`b ``", "<p><code>a`b</code></p>\n"},
+		{"code span is literal", "`*a* &amp;`", "<p><code>*a* &amp;amp;</code></p>\n"},
+		{"emphasis", "*a* _b_", "<p><em>a</em> <em>b</em></p>\n"},
+		{"strong emphasis", "**a** __b__", "<p><strong>a</strong> <strong>b</strong></p>\n"},
+		{"strong within emphasis", "*a **b** c*", "<p><em>a <strong>b</strong> c</em></p>\n"},
+		{"emphasis within strong", "**a *b* c**", "<p><strong>a <em>b</em> c</strong></p>\n"},
+		{"intraword underscores", "snake_case_name", "<p>snake_case_name</p>\n"},
+		{"emphasis needs a closer", "*a", "<p>*a</p>\n"},
+		{"spaced delimiters are literal", "a * b * c", "<p>a * b * c</p>\n"},
+		{"strikethrough", "~~a~~", "<p><del>a</del></p>\n"},
+		{"backslash escapes", `\*a\* \[b\]`, "<p>*a* [b]</p>\n"},
+		{"entity references", "&amp; &copy; &#35; &#x22;", "<p>&amp; © # &#34;</p>\n"},
+		{"unknown entity is literal", "&bogus;", "<p>&amp;bogus;</p>\n"},
+		{"escaped entity is literal", `\&amp;`, "<p>&amp;amp;</p>\n"},
+		{"link", "[a](https://a.example)", "<p><a href=\"https://a.example\"" + rel + ">a</a></p>\n"},
+		{"link with title", `[a](/p "T")`, "<p><a href=\"/p\" title=\"T\"" + rel + ">a</a></p>\n"},
+		{"link with escaped quote in title", `[a](/p "say \"hi\"")`, "<p><a href=\"/p\" title=\"say &#34;hi&#34;\"" + rel + ">a</a></p>\n"},
+		{"link in angle brackets", "[a](<x y>)", "<p><a href=\"x y\"" + rel + ">a</a></p>\n"},
+		{"link with balanced parentheses", "[a](/p(1))", "<p><a href=\"/p(1)\"" + rel + ">a</a></p>\n"},
+		{"link with entity in destination", "[a](/p?x=1&amp;y=2)", "<p><a href=\"/p?x=1&amp;y=2\"" + rel + ">a</a></p>\n"},
+		{"link with emphasis", "[*a* **b**](/p)", "<p><a href=\"/p\"" + rel + "><em>a</em> <strong>b</strong></a></p>\n"},
+		{"nested brackets in label", "[a [b]](/p)", "<p><a href=\"/p\"" + rel + ">a [b]</a></p>\n"},
+		{"unclosed link", "[a](/p", "<p>[a](/p</p>\n"},
+		{"image", `![a *b*](/i.png "T")`, "<p><img src=\"/i.png\" alt=\"a b\" title=\"T\"></p>\n"},
+		{"autolink", "<https://a.example/?a=1&b=2>", "<p><a href=\"https://a.example/?a=1&amp;b=2\"" + rel + ">https://a.example/?a=1&amp;b=2</a></p>\n"},
+		{"email autolink", "<me@a.example>", "<p><a href=\"mailto:me@a.example\"" + rel + ">me@a.example</a></p>\n"},
+		{"CRLF line endings", "a\r\nb", "<p>a\nb</p>\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := MarkdownHTML(tc.markdown); got != tc.html {
+				t.Errorf("MarkdownHTML(%q)\n got %q\nwant %q", tc.markdown, got, tc.html)
+			}
+		})
+	}
+}
+
+func TestMarkdownText(t *testing.T) {
+	tests := map[string]string{
+		"# Title\n\nSome *emphasis* and `code`.":      "Title\n\nSome emphasis and code.",
+		"[docs](https://a.example) and <a@b.example>": "docs (https://a.example) and a@b.example",
+		"[x](javascript:alert(1))":                    "x",
+		"&lt;b&gt;":                                   "<b>",
+	}
+	for markdown, want := range tests {
+		if got := MarkdownText(markdown); got != want {
+			t.Errorf("MarkdownText(%q) = %q, want %q", markdown, got, want)
+		}
+	}
+}
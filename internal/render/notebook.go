@@ -0,0 +1,195 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// fallback is the block renderer used for a content type when none of a
+// block's types names a registered renderer
+type fallback struct {
+	renderer string
+	config   map[string]interface{}
+}
+
+// fallbackFor returns the fallback renderer of a content type
+func fallbackFor(contentType string) fallback {
+	contentType = strings.ToLower(contentType)
+	switch {
+	case contentType == "text/markdown" || contentType == "markdown":
+		return fallback{renderer: "markdown-renderer"}
+	case strings.HasPrefix(contentType, "image/") || contentType == "binary/image":
+		return fallback{renderer: "image-renderer"}
+	case contentType == "application/json" || strings.HasSuffix(contentType, "+json"):
+		return fallback{renderer: "json-viewer", config: map[string]interface{}{"pretty": true}}
+	}
+	return fallback{renderer: "text-renderer"}
+}
+
+// Renderer renders notebooks: their markdown, then their content blocks in
+// tree order. Each block is rendered with the first renderer its types name
+// that is registered, or by its content type when there is none.
+type Renderer struct {
+	types     *postgres.TypeRepository
+	renderers *Registry
+}
+
+// NewRenderer creates a renderer of notebooks whose blocks have the types in
+// types, rendering them with the block renderers in renderers
+func NewRenderer(types *postgres.TypeRepository, renderers *Registry) *Renderer {
+	return &Renderer{types: types, renderers: renderers}
+}
+
+// Renderers returns the renderer's block renderer registry
+func (r *Renderer) Renderers() *Registry {
+	return r.renderers
+}
+
+// RenderNotebook renders a notebook with its content blocks in format
+func (r *Renderer) RenderNotebook(ctx context.Context, notebook *domain.Notebook, format Format) (string, error) {
+	blocks := &blockRenderer{
+		Renderer: r,
+		format:   format,
+		typeDefs: make(map[string]*domain.TypeDef),
+		children: make(map[string][]*domain.ContentBlock),
+		rendered: make(map[string]bool),
+	}
+
+	var roots []*domain.ContentBlock
+	uids := make(map[string]bool)
+	for i := range notebook.Contents.ContentBlocks {
+		uids[notebook.Contents.ContentBlocks[i].UID] = true
+	}
+	for i := range notebook.Contents.ContentBlocks {
+		block := &notebook.Contents.ContentBlocks[i]
+		if block.ParentUID == nil || !uids[*block.ParentUID] {
+			roots = append(roots, block)
+			continue
+		}
+		blocks.children[*block.ParentUID] = append(blocks.children[*block.ParentUID], block)
+	}
+	sortBlocks(roots)
+
+	var parts []string
+	if format == FormatText {
+		if markdown := MarkdownText(notebook.Contents.Data.Markdown); markdown != "" {
+			parts = append(parts, markdown)
+		}
+	} else {
+		parts = append(parts, fmt.Sprintf(`<article class="notebook" data-notebook-uri="%s">`, html.EscapeString(notebook.URI())))
+		if notebook.Contents.Data.Markdown != "" {
+			parts = append(parts, `<div class="notebook-markdown">`+"\n"+MarkdownHTML(notebook.Contents.Data.Markdown)+"</div>")
+		}
+	}
+
+	for _, block := range roots {
+		rendered, err := blocks.render(ctx, block)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, rendered...)
+	}
+
+	if format == FormatText {
+		return strings.Join(parts, "\n\n") + "\n", nil
+	}
+	return strings.Join(append(parts, "</article>"), "\n") + "\n", nil
+}
+
+// blockRenderer renders the blocks of one notebook
+type blockRenderer struct {
+	*Renderer
+	format Format
+	// typeDefs caches the type definitions read, nil for unknown types
+	typeDefs map[string]*domain.TypeDef
+	children map[string][]*domain.ContentBlock
+	rendered map[string]bool
+}
+
+// render renders block and then its children, as one HTML section or as
+// successive text parts
+func (b *blockRenderer) render(ctx context.Context, block *domain.ContentBlock) ([]string, error) {
+	if b.rendered[block.UID] {
+		return nil, nil
+	}
+	b.rendered[block.UID] = true
+
+	name, config := b.rendererOf(ctx, block)
+	renderer, ok := b.renderers.Renderer(name)
+	if !ok {
+		return nil, fmt.Errorf("block %s: renderer %s is not registered", block.UID, name)
+	}
+
+	content, err := renderer(ctx, config, block, b.format)
+	if err != nil {
+		return nil, fmt.Errorf("block %s: %s failed: %w", block.UID, name, err)
+	}
+
+	children := b.children[block.UID]
+	sortBlocks(children)
+
+	var parts []string
+	if b.format == FormatText {
+		if content != "" {
+			parts = append(parts, content)
+		}
+	} else {
+		parts = append(parts, fmt.Sprintf(`<section class="content-block" data-uid="%s" data-types="%s" data-renderer="%s">`,
+			html.EscapeString(block.UID), html.EscapeString(strings.Join(block.Types, " ")), html.EscapeString(name)))
+		parts = append(parts, strings.TrimSuffix(content, "\n"))
+	}
+
+	for _, child := range children {
+		rendered, err := b.render(ctx, child)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, rendered...)
+	}
+
+	if b.format != FormatText {
+		parts = append(parts, "</section>")
+	}
+	return parts, nil
+}
+
+// rendererOf returns the name and config of the renderer of block
+func (b *blockRenderer) rendererOf(ctx context.Context, block *domain.ContentBlock) (string, map[string]interface{}) {
+	for _, typeName := range block.Types {
+		typeDef, ok := b.typeDefs[typeName]
+		if !ok {
+			// Types are global, so they are read outside any tenant scope;
+			// unknown types name no renderer
+			typeDef, _ = b.types.Get(postgres.WithSystemScope(ctx), typeName)
+			b.typeDefs[typeName] = typeDef
+		}
+		if typeDef == nil {
+			continue
+		}
+
+		for _, renderer := range typeDef.Renderers {
+			if _, ok := b.renderers.Renderer(renderer.Name); ok {
+				return renderer.Name, renderer.Config
+			}
+		}
+	}
+
+	fallback := fallbackFor(block.ContentType)
+	return fallback.renderer, fallback.config
+}
+
+// sortBlocks sorts sibling blocks by their order, then UID
+func sortBlocks(blocks []*domain.ContentBlock) {
+	sort.SliceStable(blocks, func(i, j int) bool {
+		if blocks[i].Order != blocks[j].Order {
+			return blocks[i].Order < blocks[j].Order
+		}
+		return blocks[i].UID < blocks[j].UID
+	})
+}
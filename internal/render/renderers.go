@@ -0,0 +1,208 @@
+// Package render renders notebooks to HTML and plain text, rendering each
+// content block with a renderer named by one of its types.
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// Format is an output format of rendered content
+type Format string
+
+const (
+	FormatHTML Format = "html"
+	FormatText Format = "text"
+)
+
+// ParseFormat returns the format called name; "" is HTML
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case "", FormatHTML:
+		return FormatHTML, nil
+	case FormatText:
+		return FormatText, nil
+	}
+	return "", fmt.Errorf("unknown format %q: must be html or text", name)
+}
+
+// BlockRenderer renders a content block's data in a format, configured by the
+// config of the type renderer naming it. HTML output must be safe to embed:
+// data is escaped or sanitized, never passed through.
+type BlockRenderer func(ctx context.Context, config map[string]interface{}, block *domain.ContentBlock, format Format) (string, error)
+
+// Registry holds the block renderers by name, such as "markdown-renderer"
+type Registry struct {
+	mu        sync.RWMutex
+	renderers map[string]BlockRenderer
+}
+
+// NewRegistry creates a registry holding the built-in block renderers:
+//
+//   - markdown-renderer: markdown as sanitized HTML
+//   - image-renderer: base64 image data, a data: URI or a blob reference as an <img>;
+//     {"maxWidth": n} limits its width in pixels and {"alt": "..."} describes it
+//   - json-viewer: JSON in a <pre> block; {"pretty": true} indents it
+//   - text-renderer: the data as preformatted text
+func NewRegistry() *Registry {
+	r := &Registry{renderers: make(map[string]BlockRenderer)}
+	r.Register("markdown-renderer", markdownRenderer)
+	r.Register("image-renderer", imageRenderer)
+	r.Register("json-viewer", jsonViewer)
+	r.Register("text-renderer", textRenderer)
+	return r
+}
+
+// Register adds or replaces a named block renderer
+func (r *Registry) Register(name string, renderer BlockRenderer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.renderers[name] = renderer
+}
+
+// Renderer returns the named block renderer
+func (r *Registry) Renderer(name string) (BlockRenderer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	renderer, ok := r.renderers[name]
+	return renderer, ok
+}
+
+// Names returns the names of the registered block renderers, sorted
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.renderers))
+	for name := range r.renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func markdownRenderer(ctx context.Context, config map[string]interface{}, block *domain.ContentBlock, format Format) (string, error) {
+	if format == FormatText {
+		return MarkdownText(block.Data), nil
+	}
+	return MarkdownHTML(block.Data), nil
+}
+
+// imageTypes are the image media types rendered inline
+var imageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+	"image/avif": true,
+	"image/bmp":  true,
+}
+
+func imageRenderer(ctx context.Context, config map[string]interface{}, block *domain.ContentBlock, format Format) (string, error) {
+	src, mediaType, size := imageSource(block)
+	alt, _ := config["alt"].(string)
+
+	if format == FormatText {
+		if src == "" {
+			return "[image: unsupported data]", nil
+		}
+		description := fmt.Sprintf("%s, %d bytes", mediaType, size)
+		if alt != "" {
+			description = alt + ", " + description
+		}
+		return "[image: " + description + "]", nil
+	}
+
+	if src == "" {
+		return `<p class="render-error">Image data is not a supported image</p>`, nil
+	}
+
+	tag := `<img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(alt) + `"`
+	if maxWidth, ok := config["maxWidth"].(float64); ok && maxWidth > 0 {
+		tag += ` style="max-width: ` + strconv.Itoa(int(maxWidth)) + `px"`
+	}
+	return tag + ">", nil
+}
+
+// imageSource returns the URL showing a block's image with its media type and
+// size in bytes, or "" if the data is not a supported image. Images held in
+// a blob are shown from its download URL, others from a data: URI.
+func imageSource(block *domain.ContentBlock) (src, mediaType string, size int) {
+	data := strings.TrimSpace(block.Data)
+
+	if digest, ok := domain.ParseBlobRef(data); ok {
+		if block.Blob == nil || block.Blob.Digest != digest || !imageTypes[block.Blob.MediaType] {
+			return "", "", 0
+		}
+		return block.Blob.URL(), block.Blob.MediaType, int(block.Blob.Size)
+	}
+
+	if strings.HasPrefix(data, "data:") {
+		i := strings.Index(data, ";base64,")
+		if i < 0 || !imageTypes[strings.ToLower(data[len("data:"):i])] {
+			return "", "", 0
+		}
+		decoded, err := decodeBase64(data[i+len(";base64,"):])
+		if err != nil {
+			return "", "", 0
+		}
+		return data, strings.ToLower(data[len("data:"):i]), len(decoded)
+	}
+
+	decoded, err := decodeBase64(data)
+	if err != nil {
+		return "", "", 0
+	}
+
+	// The content type names the media type when it is an image type;
+	// otherwise it is sniffed from the data
+	mediaType = strings.ToLower(block.ContentType)
+	if !imageTypes[mediaType] {
+		mediaType = http.DetectContentType(decoded)
+	}
+	if !imageTypes[mediaType] {
+		return "", "", 0
+	}
+
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(decoded), mediaType, len(decoded)
+}
+
+func decodeBase64(data string) ([]byte, error) {
+	data = strings.Join(strings.Fields(data), "")
+	if decoded, err := base64.StdEncoding.DecodeString(data); err == nil {
+		return decoded, nil
+	}
+	return base64.RawStdEncoding.DecodeString(data)
+}
+
+func jsonViewer(ctx context.Context, config map[string]interface{}, block *domain.ContentBlock, format Format) (string, error) {
+	data := block.Data
+	if pretty, _ := config["pretty"].(bool); pretty {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, []byte(data), "", "  "); err == nil {
+			data = indented.String()
+		}
+	}
+
+	if format == FormatText {
+		return data, nil
+	}
+	return `<pre class="json"><code class="language-json">` + html.EscapeString(data) + "</code></pre>", nil
+}
+
+func textRenderer(ctx context.Context, config map[string]interface{}, block *domain.ContentBlock, format Format) (string, error) {
+	if format == FormatText {
+		return block.Data, nil
+	}
+	return "<pre>" + html.EscapeString(block.Data) + "</pre>", nil
+}
@@ -0,0 +1,161 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prismon/synthesis/internal/authz"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// maxAuditedError bounds how much of a failed response's body is kept as its error
+const maxAuditedError = 1024
+
+// auditRequest is middleware that records each mutating API request in the audit
+// log. It runs before authorization so that denied requests are recorded too.
+func (s *Server) auditRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		name, args, err := routeAction(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		targets, _ := authz.ResolveTargets(r.Context(), s.attrs, args)
+		target := authz.PrimaryTarget(targets)
+		action := s.auditor.Begin(r.Context(), domain.AuditSourceREST, name, target)
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		var failure string
+		if recorder.status >= http.StatusBadRequest {
+			failure = strings.TrimSpace(recorder.body.String())
+			if failure == "" {
+				failure = http.StatusText(recorder.status)
+			}
+			failure = fmt.Sprintf("%d: %s", recorder.status, failure)
+		}
+		action.End(r.Context(), failure)
+	})
+}
+
+// statusRecorder captures the status of a response and the start of its body
+// when it reports an error
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	if w.status >= http.StatusBadRequest && w.body.Len() < maxAuditedError {
+		w.body.Write(b[:min(len(b), maxAuditedError-w.body.Len())])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (s *Server) queryAuditLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	filter := postgres.AuditFilter{
+		TenantID:  query.Get("tenantId"),
+		Principal: query.Get("principal"),
+		Action:    query.Get("action"),
+		URIPrefix: query.Get("target_uri"),
+		Outcome:   query.Get("outcome"),
+	}
+
+	var err error
+	if filter.Since, err = timeParam(query.Get("since")); err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+	if filter.Until, err = timeParam(query.Get("until")); err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+	if v := query.Get("before_seq"); v != "" {
+		if filter.BeforeSequence, err = strconv.ParseInt(v, 10, 64); err != nil {
+			writeErrorStatus(w, http.StatusBadRequest, fmt.Errorf("invalid before_seq: %v", err))
+			return
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			writeErrorStatus(w, http.StatusBadRequest, fmt.Errorf("invalid limit: %v", err))
+			return
+		}
+	}
+
+	entries, err := s.auditRepo.List(ctx, filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if entries == nil {
+		entries = []*domain.AuditEntry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// auditParameters describes the query of an audit log request
+func auditParameters() []*parameter {
+	text := map[string]interface{}{"type": "string"}
+	dateTime := map[string]interface{}{"type": "string", "format": "date-time"}
+
+	return []*parameter{
+		queryParameter("tenantId", "Only entries of this tenant", text),
+		queryParameter("principal", "Only entries of this principal", text),
+		queryParameter("action", "Only entries of this action", text),
+		queryParameter("target_uri", "Only entries targeting this resource URI or those under it", text),
+		queryParameter("outcome", "Only entries with this outcome", map[string]interface{}{
+			"type": "string",
+			"enum": []string{domain.AuditOutcomeSucceeded, domain.AuditOutcomeFailed},
+		}),
+		queryParameter("since", "Only entries at or after this time", dateTime),
+		queryParameter("until", "Only entries before this time", dateTime),
+		queryParameter("before_seq", "Only entries before this sequence number, to page back from a previous result", map[string]interface{}{"type": "integer"}),
+		queryParameter("limit", "Maximum number of entries", map[string]interface{}{"type": "integer", "minimum": 0}),
+	}
+}
+
+// timeParam parses an optional RFC 3339 query parameter
+func timeParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time %q: use RFC 3339", value)
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,91 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// blobContentSecurity is the Content-Security-Policy of downloaded blobs: a
+// blob the browser renders, such as an SVG, runs sandboxed and loads nothing
+const blobContentSecurity = "default-src 'none'; img-src 'self'; style-src 'unsafe-inline'; sandbox"
+
+// uploadBlobResponse is an uploaded blob, with the ref content blocks hold it by
+// and the URL it downloads from
+type uploadBlobResponse struct {
+	Blob *domain.Blob `json:"blob"`
+	Ref  string       `json:"ref"`
+	URL  string       `json:"url"`
+}
+
+// uploadBlob stores the request body as a blob of a tenant. Each ?type= names
+// a type the blob is uploaded for; it must meet their file-size constraints.
+func (s *Server) uploadBlob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenantID := mux.Vars(r)["tenantId"]
+	typeNames := r.URL.Query()["type"]
+
+	check := func(b *domain.Blob) error {
+		if err := s.quotas.CheckContentBytes(ctx, tenantID, b.Size); err != nil {
+			return err
+		}
+		return s.validator.ValidateBlob(ctx, typeNames, b)
+	}
+
+	uploaded, err := s.blobs.Put(ctx, tenantID, r.Body, check)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", uploaded.URL())
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(uploadBlobResponse{Blob: uploaded, Ref: uploaded.Ref(), URL: uploaded.URL()})
+}
+
+// downloadBlob serves a blob's bytes as its sniffed media type. Blobs never
+// change, so they are cached for good.
+func (s *Server) downloadBlob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	b, err := s.blobs.Stat(ctx, vars["tenantId"], vars["digest"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	etag := `"` + b.Digest + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body, err := s.blobs.Open(ctx, b)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer body.Close()
+
+	// The blob is read whole before anything is sent, so bytes failing the
+	// digest check are never served
+	data, err := io.ReadAll(body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", b.MediaType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", blobContentSecurity)
+	w.Write(data)
+}
@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prismon/synthesis/internal/authz"
+	"github.com/prismon/synthesis/internal/blob"
+	"github.com/prismon/synthesis/internal/postgres"
+	"github.com/prismon/synthesis/internal/quota"
+	"github.com/prismon/synthesis/internal/validation"
+)
+
+// errorResponse is the body of every API error
+type errorResponse struct {
+	Error   string                  `json:"error" description:"What went wrong"`
+	Code    string                  `json:"code" description:"The status as snake case, e.g. not_found"`
+	Details []*validation.Violation `json:"details,omitempty" description:"The violations of a request that failed validation"`
+}
+
+// writeError responds with err in the error envelope, at the status its type
+// calls for: 404 for a missing resource or blob content, 409 for a conflict,
+// 400 for malformed list options, 422 for a validation failure, 403 when
+// policy denies, 429 with Retry-After when a quota is exceeded, 413 for an
+// oversized blob, and 500 otherwise
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+
+	var (
+		invalid  *validation.Error
+		denied   *authz.DeniedError
+		exceeded *quota.ExceededError
+	)
+	switch {
+	case errors.Is(err, postgres.ErrNotFound), errors.Is(err, blob.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, postgres.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, postgres.ErrInvalidArgument):
+		status = http.StatusBadRequest
+	case errors.As(err, &invalid):
+		status = http.StatusUnprocessableEntity
+	case errors.As(err, &denied):
+		status = http.StatusForbidden
+	case errors.As(err, &exceeded):
+		status = http.StatusTooManyRequests
+		if exceeded.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(exceeded.RetryAfter.Seconds()))))
+		}
+	case errors.Is(err, blob.ErrTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	}
+
+	writeErrorStatus(w, status, err)
+}
+
+// writeErrorStatus responds with err in the error envelope at status, such as
+// 400 for a malformed request
+func writeErrorStatus(w http.ResponseWriter, status int, err error) {
+	response := errorResponse{
+		Error: err.Error(),
+		Code:  strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"),
+	}
+
+	var invalid *validation.Error
+	if errors.As(err, &invalid) {
+		response.Details = invalid.Violations
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// decodeBody decodes a JSON request body into v, rejecting unknown fields and
+// anything after the JSON value
+func decodeBody(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("invalid request body: unexpected data after the JSON value")
+	}
+
+	return nil
+}
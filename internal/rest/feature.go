@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// revealAction is the action policies must allow for a caller to see sensitive values decrypted
+const revealAction = "reveal_secrets"
+
+// getFeature returns a feature with its sensitive values redacted, or decrypted
+// when ?reveal=true is given and the caller may reveal them
+func (s *Server) getFeature(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	feature, err := s.featureRepo.Get(ctx, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if r.URL.Query().Get("reveal") == "true" {
+		if s.authorizer != nil {
+			if err := s.authorizer.Check(ctx, revealAction, feature.URI()); err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+
+		if err := s.sealer.OpenValues(ctx, feature.URI(), feature.Values, feature.SensitiveKeys); err != nil {
+			writeError(w, err)
+			return
+		}
+	} else {
+		feature = feature.Redacted()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(feature)
+}
@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// listOptions reads the filters, sort and page of a list request from its
+// query: label_selector, owner, status, sort, cursor and limit
+func listOptions(r *http.Request) (postgres.ListOptions, error) {
+	query := r.URL.Query()
+
+	opts := postgres.ListOptions{
+		Selector: query.Get("label_selector"),
+		Owner:    query.Get("owner"),
+		Status:   query.Get("status"),
+		Sort:     query.Get("sort"),
+		Cursor:   query.Get("cursor"),
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid limit: %v", err)
+		}
+		opts.Limit = limit
+	}
+
+	return opts, nil
+}
+
+// listFilters describes the filters a list request may give
+var listFilters = map[string]string{
+	"label_selector": "Only list resources whose labels match this selector, e.g. environment=production,app in (nginx)",
+	"owner":          "Only list resources with this owner",
+	"status":         "Only list resources with this status",
+}
+
+// listParameters describes the query of a list request that sorts by any of
+// sorts, and offers only the filters named
+func listParameters(sorts []string, filters ...string) []*parameter {
+	var params []*parameter
+	for _, filter := range filters {
+		params = append(params, queryParameter(filter, listFilters[filter], map[string]interface{}{"type": "string"}))
+	}
+
+	enum := make([]string, 0, 2*len(sorts))
+	for _, field := range sorts {
+		enum = append(enum, field, "-"+field)
+	}
+
+	return append(params,
+		queryParameter("sort", "Field to sort by, descending with a \"-\" prefix", map[string]interface{}{"type": "string", "enum": enum}),
+		queryParameter("cursor", "next_cursor of the previous page, to continue the listing with the same sort", map[string]interface{}{"type": "string"}),
+		queryParameter("limit", fmt.Sprintf("Maximum number of results (default: %d, max: %d)", postgres.DefaultListLimit, postgres.MaxListLimit), map[string]interface{}{"type": "integer", "minimum": 0}),
+	)
+}
@@ -0,0 +1,202 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/prismon/synthesis/internal/jsonschema"
+)
+
+// schemaPrefix is where the OpenAPI document defines the schemas of the types
+// its operations refer to
+const schemaPrefix = "#/components/schemas/"
+
+// pathVariable matches a route variable, e.g. {id} or {digest:[0-9a-f]+}
+var pathVariable = regexp.MustCompile(`\{([^}:]+)(?::[^}]*)?\}`)
+
+// operation describes a route in the API's OpenAPI document. The schemas of its
+// request and response bodies are generated from the types its handler decodes
+// and encodes, so the document cannot describe other bodies than those served.
+type operation struct {
+	// Summary says what the route does
+	Summary string
+	// Query lists the query parameters the handler reads
+	Query []*parameter
+	// Body is a value of the type the request body is decoded into, if any
+	Body interface{}
+	// BodyMedia is the media type of a request body that is not JSON
+	BodyMedia string
+	// Status is the status of a successful response; 200 when unset
+	Status int
+	// Response is a value of the type the response body is encoded from, if any
+	Response interface{}
+	// ResponseMedia lists the media types of a response body that is not JSON
+	ResponseMedia []string
+	// Public routes are served without authentication
+	Public bool
+}
+
+// parameter is an OpenAPI parameter object
+type parameter struct {
+	Name        string                 `json:"name"`
+	In          string                 `json:"in"`
+	Description string                 `json:"description,omitempty"`
+	Required    bool                   `json:"required,omitempty"`
+	Schema      map[string]interface{} `json:"schema"`
+}
+
+// queryParameter returns an optional query parameter
+func queryParameter(name, description string, schema map[string]interface{}) *parameter {
+	return &parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+// openAPIDocument is the OpenAPI 3.1 document of the API, built up as its
+// routes are registered
+type openAPIDocument struct {
+	paths  map[string]map[string]interface{}
+	input  *jsonschema.Reflector
+	output *jsonschema.Reflector
+}
+
+func newOpenAPIDocument() *openAPIDocument {
+	schemas := make(map[string]interface{})
+	return &openAPIDocument{
+		paths: make(map[string]map[string]interface{}),
+		// Request bodies are decoded with decodeBody, which rejects unknown
+		// fields; their schemas are apart from those of responses
+		input: &jsonschema.Reflector{
+			DisallowUnknownFields: true,
+			RefPrefix:             schemaPrefix,
+			Suffix:                "Input",
+			Definitions:           schemas,
+		},
+		output: &jsonschema.Reflector{
+			Output:      true,
+			RefPrefix:   schemaPrefix,
+			Definitions: schemas,
+		},
+	}
+}
+
+// add describes the route of method and path template, served by handler, as op
+func (d *openAPIDocument) add(method, template string, handler http.HandlerFunc, op operation) {
+	path := pathVariable.ReplaceAllString(template, "{$1}")
+
+	var params []*parameter
+	for _, match := range pathVariable.FindAllStringSubmatch(template, -1) {
+		params = append(params, &parameter{Name: match[1], In: "path", Required: true, Schema: map[string]interface{}{"type": "string"}})
+	}
+	params = append(params, op.Query...)
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	switch {
+	case op.Response != nil:
+		success["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": d.output.Reflect(op.Response)},
+		}
+	case len(op.ResponseMedia) > 0:
+		content := make(map[string]interface{}, len(op.ResponseMedia))
+		for _, media := range op.ResponseMedia {
+			content[media] = map[string]interface{}{}
+		}
+		success["content"] = content
+	}
+
+	spec := map[string]interface{}{
+		"operationId": handlerName(handler),
+		"summary":     op.Summary,
+		"responses": map[string]interface{}{
+			strconv.Itoa(status): success,
+			"default":            map[string]interface{}{"$ref": "#/components/responses/Error"},
+		},
+	}
+	if collection := strings.TrimPrefix(path, "/api/v1/"); collection != path {
+		spec["tags"] = []string{strings.SplitN(collection, "/", 2)[0]}
+	}
+	if len(params) > 0 {
+		spec["parameters"] = params
+	}
+	switch {
+	case op.Body != nil:
+		spec["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": d.input.Reflect(op.Body)},
+			},
+		}
+	case op.BodyMedia != "":
+		spec["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  map[string]interface{}{op.BodyMedia: map[string]interface{}{}},
+		}
+	}
+	if op.Public {
+		spec["security"] = []interface{}{}
+	}
+
+	if d.paths[path] == nil {
+		d.paths[path] = make(map[string]interface{})
+	}
+	d.paths[path][strings.ToLower(method)] = spec
+}
+
+// MarshalJSON encodes the document, with the schemas of the error envelope and
+// of every body its operations refer to
+func (d *openAPIDocument) MarshalJSON() ([]byte, error) {
+	errorSchema := d.output.Reflect(errorResponse{})
+
+	return json.Marshal(map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":   "Synthesis API",
+			"version": "1.0.0",
+		},
+		"paths": d.paths,
+		"components": map[string]interface{}{
+			"schemas": d.output.Definitions,
+			"responses": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"description": "An error, at the status its kind calls for",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": errorSchema},
+					},
+				},
+			},
+			"securitySchemes": map[string]interface{}{
+				"bearer": map[string]interface{}{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "An OIDC access token or an API key",
+				},
+			},
+		},
+		"security": []interface{}{map[string]interface{}{"bearer": []string{}}},
+	})
+}
+
+// handlerName returns the name of a Server method handler, e.g. listTenants
+func handlerName(handler http.HandlerFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	return strings.TrimSuffix(name[strings.LastIndex(name, ".")+1:], "-fm")
+}
+
+// serveOpenAPI serves the OpenAPI document of the API
+func (s *Server) serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	document, err := json.Marshal(s.openapi)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(document)
+}
@@ -0,0 +1,161 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prismon/synthesis/internal/jsonschema"
+)
+
+// newOpenAPITestServer returns a server with every route registered and no
+// dependencies: handlers decode their request bodies and then fail
+func newOpenAPITestServer() *Server {
+	s := &Server{router: mux.NewRouter(), openapi: newOpenAPIDocument()}
+	s.registerRoutes()
+	return s
+}
+
+// routeHandlers returns the handler of each route by method and OpenAPI path,
+// e.g. "POST /api/v1/tenants"
+func routeHandlers(t *testing.T, router *mux.Router) map[string]http.Handler {
+	t.Helper()
+
+	handlers := map[string]http.Handler{}
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil || route.GetHandler() == nil {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		for _, method := range methods {
+			handlers[method+" "+pathVariable.ReplaceAllString(template, "{$1}")] = route.GetHandler()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk routes: %v", err)
+	}
+	return handlers
+}
+
+// decodeError sends body to a route's handler and returns the error its
+// decoding of the body reported, if any. Whatever the handler does once
+// the body is decoded fails without dependencies, and is ignored.
+func decodeError(handler http.Handler, method, path string, body interface{}) (message string) {
+	defer func() {
+		// Handlers past decoding reach the missing dependencies
+		recover()
+	}()
+
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, "/", bytes.NewReader(data))
+	vars := map[string]string{}
+	for _, match := range pathVariable.FindAllStringSubmatch(path, -1) {
+		vars[match[1]] = "sample"
+	}
+	req = mux.SetURLVars(req, vars)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	var response errorResponse
+	if recorder.Code == http.StatusBadRequest && json.Unmarshal(recorder.Body.Bytes(), &response) == nil &&
+		strings.HasPrefix(response.Error, "invalid request body") {
+		return response.Error
+	}
+	return ""
+}
+
+func TestRouteSchemasMatchHandlers(t *testing.T) {
+	s := newOpenAPITestServer()
+	handlers := routeHandlers(t, s.router)
+
+	data, err := json.Marshal(s.openapi)
+	if err != nil {
+		t.Fatalf("failed to encode the OpenAPI document: %v", err)
+	}
+	var document struct {
+		Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatalf("failed to decode the OpenAPI document: %v", err)
+	}
+
+	var routes []string
+	described := map[string]bool{}
+	for path, operations := range document.Paths {
+		for method := range operations {
+			route := strings.ToUpper(method) + " " + path
+			routes = append(routes, route)
+			described[route] = true
+		}
+	}
+	sort.Strings(routes)
+
+	for route := range handlers {
+		if !described[route] {
+			t.Errorf("%s: routed but not described", route)
+		}
+	}
+
+	bodies := 0
+	for _, route := range routes {
+		method, path, _ := strings.Cut(route, " ")
+		handler := handlers[route]
+		if handler == nil {
+			t.Errorf("%s: described but not routed", route)
+			continue
+		}
+
+		content, _ := document.Paths[path][strings.ToLower(method)]["requestBody"].(map[string]interface{})
+		media, _ := content["content"].(map[string]interface{})
+		jsonBody, _ := media["application/json"].(map[string]interface{})
+		schema, _ := jsonBody["schema"].(map[string]interface{})
+		if schema == nil {
+			continue
+		}
+		bodies++
+
+		t.Run(route, func(t *testing.T) {
+			// References resolve against the document's components
+			root := map[string]interface{}{"components": map[string]interface{}{"schemas": document.Components.Schemas}}
+			for keyword, value := range schema {
+				root[keyword] = value
+			}
+			compiled, err := jsonschema.CompileValue(root)
+			if err != nil {
+				t.Fatalf("request body schema does not compile: %v", err)
+			}
+
+			sample, _ := jsonschema.Sample(schema, document.Components.Schemas).(map[string]interface{})
+			if errs := compiled.ValidateValue(sample); len(errs) > 0 {
+				t.Fatalf("sample %v does not meet the schema: %v", sample, errs)
+			}
+			if message := decodeError(handler, method, path, sample); message != "" {
+				t.Fatalf("handler does not decode a sample of its schema %v: %s", sample, message)
+			}
+
+			// The schema closes the body to unknown fields, and so must the handler
+			sample["unknownField"] = "sample"
+			if errs := compiled.ValidateValue(sample); len(errs) == 0 {
+				t.Error("schema admits unknown fields")
+			}
+			if message := decodeError(handler, method, path, sample); message == "" {
+				t.Error("handler admits unknown fields the schema does not")
+			}
+		})
+	}
+
+	if bodies == 0 {
+		t.Fatal("expected routes with request bodies")
+	}
+}
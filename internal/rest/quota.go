@@ -0,0 +1,111 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/prismon/synthesis/internal/authz"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/quota"
+	"github.com/prismon/synthesis/internal/validation"
+)
+
+// limitRequest is middleware that counts each API request against the request
+// rate of the tenant it addresses
+func (s *Server) limitRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, args, err := routeAction(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		targets, _ := authz.ResolveTargets(r.Context(), s.attrs, args)
+		target := authz.PrimaryTarget(targets)
+
+		if tenantID := quota.RequestTenant(r.Context(), target); tenantID != "" {
+			if err := s.quotas.Allow(r.Context(), tenantID); err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) getTenantUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if _, err := s.tenantRepo.Get(ctx, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	usage, err := s.quotas.Usage(ctx, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(usage)
+}
+
+func (s *Server) setTenantQuota(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	var quota domain.TenantQuota
+	if err := decodeBody(r, &quota); err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+	quota.TenantID = vars["id"]
+
+	if _, err := s.tenantRepo.Get(ctx, quota.TenantID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := checkQuota(&quota); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.quotaRepo.Set(ctx, &quota); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.quotas.Forget(quota.TenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quota)
+}
+
+// checkQuota returns a *validation.Error if a limit of quota is negative
+func checkQuota(quota *domain.TenantQuota) error {
+	limits := []struct {
+		path  string
+		value *int64
+	}{
+		{"/max_notebooks", quota.MaxNotebooks},
+		{"/max_features", quota.MaxFeatures},
+		{"/max_content_bytes", quota.MaxContentBytes},
+		{"/max_requests_per_second", quota.MaxRequestsPerSecond},
+	}
+
+	var violations []*validation.Violation
+	for _, limit := range limits {
+		if limit.value != nil && *limit.value < 0 {
+			violations = append(violations, &validation.Violation{Path: limit.path, Message: "must not be negative"})
+		}
+	}
+	if len(violations) > 0 {
+		return &validation.Error{Violations: violations}
+	}
+	return nil
+}
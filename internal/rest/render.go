@@ -0,0 +1,47 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/prismon/synthesis/internal/render"
+)
+
+// renderContentSecurity is the Content-Security-Policy of rendered HTML: it
+// runs no scripts and loads only images, so rendered content cannot act on
+// the API's origin even if it got past the renderers' escaping. Images held
+// in blobs load from the API itself.
+const renderContentSecurity = "default-src 'none'; img-src 'self' data: http: https:; style-src 'unsafe-inline'"
+
+// renderNotebook renders a notebook as HTML or, with ?format=text, plain text
+func (s *Server) renderNotebook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	format, err := render.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+
+	notebook, err := s.notebookRepo.Get(ctx, vars["id"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	rendered, err := s.renderer.RenderNotebook(ctx, notebook, format)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if format == render.FormatText {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", renderContentSecurity)
+	}
+	w.Write([]byte(rendered))
+}
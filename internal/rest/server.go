@@ -5,63 +5,304 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/prismon/synthesis/internal/audit"
+	"github.com/prismon/synthesis/internal/auth"
+	"github.com/prismon/synthesis/internal/authz"
+	"github.com/prismon/synthesis/internal/blob"
+	"github.com/prismon/synthesis/internal/domain"
 	"github.com/prismon/synthesis/internal/postgres"
+	"github.com/prismon/synthesis/internal/quota"
+	"github.com/prismon/synthesis/internal/realtime"
+	"github.com/prismon/synthesis/internal/render"
+	"github.com/prismon/synthesis/internal/secrets"
+	"github.com/prismon/synthesis/internal/validation"
 )
 
 // Server represents the REST API server
 type Server struct {
 	router       *mux.Router
 	tenantRepo   *postgres.TenantRepository
+	libraryRepo  *postgres.LibraryRepository
 	notebookRepo *postgres.NotebookRepository
 	vectorRepo   *postgres.VectorRepository
 	graphRepo    *postgres.GraphRepository
+	apiKeyRepo   *postgres.APIKeyRepository
+	auditRepo    *postgres.AuditRepository
+	quotaRepo    *postgres.QuotaRepository
+	featureRepo  *postgres.FeatureRepository
+	typeRepo     *postgres.TypeRepository
+	attrs        *postgres.AuthzRepository
+	auditor      *audit.Recorder
+	hub          *realtime.Hub
+	authn        *auth.Authenticator
+	authorizer   *authz.Authorizer
+	quotas       *quota.Enforcer
+	sealer       *secrets.Sealer
+	validator    *validation.Validator
+	renderer     *render.Renderer
+	blobs        *blob.Service
+	openapi      *openAPIDocument
 }
 
-// NewServer creates a new REST API server
-func NewServer(db *postgres.DB) *Server {
+// NewServer creates a new REST API server. When authn is nil, requests are not
+// authenticated; when authorizer is nil, requests are not checked against policy.
+// Requests and WebSocket edits count against the tenant limits quotas enforces.
+// Sensitive values are opened with sealer for callers who may reveal them, and
+// content is checked against the constraints of its types with validator.
+// Notebooks are rendered with renderer, and binary content is stored in blobs.
+func NewServer(db *postgres.DB, authn *auth.Authenticator, authorizer *authz.Authorizer, quotas *quota.Enforcer, sealer *secrets.Sealer, validator *validation.Validator, renderer *render.Renderer, blobs *blob.Service) *Server {
 	s := &Server{
 		router:       mux.NewRouter(),
+		authn:        authn,
+		authorizer:   authorizer,
+		quotas:       quotas,
+		sealer:       sealer,
+		validator:    validator,
+		renderer:     renderer,
+		blobs:        blobs,
+		openapi:      newOpenAPIDocument(),
 		tenantRepo:   postgres.NewTenantRepository(db),
+		libraryRepo:  postgres.NewLibraryRepository(db),
 		notebookRepo: postgres.NewNotebookRepository(db),
 		vectorRepo:   postgres.NewVectorRepository(db),
 		graphRepo:    postgres.NewGraphRepository(db),
+		apiKeyRepo:   postgres.NewAPIKeyRepository(db),
+		auditRepo:    postgres.NewAuditRepository(db),
+		quotaRepo:    postgres.NewQuotaRepository(db),
+		featureRepo:  postgres.NewFeatureRepository(db),
+		typeRepo:     postgres.NewTypeRepository(db),
+		attrs:        postgres.NewAuthzRepository(db),
 	}
+	s.auditor = audit.NewRecorder(s.auditRepo)
+
+	s.hub = realtime.NewHub(postgres.NewEventRepository(db), s.notebookRepo, s.authorizeSubscription, quotas, validator, blobs)
 
 	s.registerRoutes()
 
 	return s
 }
 
-// registerRoutes registers all API routes
+// registerRoutes registers all API routes, describing each in the API's
+// OpenAPI document
 func (s *Server) registerRoutes() {
 	// API v1 routes
 	api := s.router.PathPrefix("/api/v1").Subrouter()
+	api.Use(audit.RequestIDMiddleware)
+	if s.authn != nil {
+		api.Use(s.authn.Middleware)
+	}
+	api.Use(authz.ScopeMiddleware)
+	api.Use(s.auditRequest)
+	if s.authorizer != nil {
+		api.Use(s.authorize)
+	}
+	api.Use(s.limitRequest)
 
 	// Tenant routes
-	api.HandleFunc("/tenants", s.listTenants).Methods("GET")
-	api.HandleFunc("/tenants", s.createTenant).Methods("POST")
-	api.HandleFunc("/tenants/{id}", s.getTenant).Methods("GET")
-	api.HandleFunc("/tenants/{id}", s.updateTenant).Methods("PUT")
-	api.HandleFunc("/tenants/{id}", s.deleteTenant).Methods("DELETE")
+	s.handle(api, "GET", "/tenants", s.listTenants, operation{
+		Summary:  "List a page of tenants",
+		Query:    listParameters(postgres.TenantSorts(), "label_selector", "owner"),
+		Response: domain.Page[*domain.Tenant]{},
+	})
+	s.handle(api, "POST", "/tenants", s.createTenant, operation{
+		Summary:  "Create a tenant owned by the caller",
+		Body:     domain.Tenant{},
+		Status:   http.StatusCreated,
+		Response: domain.Tenant{},
+	})
+	s.handle(api, "GET", "/tenants/{id}", s.getTenant, operation{
+		Summary:  "Get a tenant",
+		Response: domain.Tenant{},
+	})
+	s.handle(api, "PUT", "/tenants/{id}", s.updateTenant, operation{
+		Summary:  "Replace a tenant's display name, description, labels and owner",
+		Body:     domain.Tenant{},
+		Response: domain.Tenant{},
+	})
+	s.handle(api, "DELETE", "/tenants/{id}", s.deleteTenant, operation{
+		Summary: "Delete a tenant",
+		Status:  http.StatusNoContent,
+	})
+	s.handle(api, "GET", "/tenants/{id}/usage", s.getTenantUsage, operation{
+		Summary:  "Get a tenant's usage against its quota",
+		Response: domain.TenantUsage{},
+	})
+	s.handle(api, "PUT", "/tenants/{id}/quota", s.setTenantQuota, operation{
+		Summary:  "Set a tenant's quota",
+		Body:     domain.TenantQuota{},
+		Response: domain.TenantQuota{},
+	})
 
 	// Library routes
-	api.HandleFunc("/libraries/by-tenant/{tenantId}", s.listLibraries).Methods("GET")
-	api.HandleFunc("/libraries/by-tenant/{tenantId}", s.createLibrary).Methods("POST")
-	api.HandleFunc("/libraries/{id}", s.getLibrary).Methods("GET")
-	api.HandleFunc("/libraries/{id}", s.updateLibrary).Methods("PUT")
-	api.HandleFunc("/libraries/{id}", s.deleteLibrary).Methods("DELETE")
+	s.handle(api, "GET", "/libraries/by-tenant/{tenantId}", s.listLibraries, operation{
+		Summary:  "List a page of a tenant's libraries",
+		Query:    listParameters(postgres.LibrarySorts(), "label_selector", "owner"),
+		Response: domain.Page[*domain.Library]{},
+	})
+	s.handle(api, "POST", "/libraries/by-tenant/{tenantId}", s.createLibrary, operation{
+		Summary:  "Create a library in a tenant, owned by the caller",
+		Body:     domain.Library{},
+		Status:   http.StatusCreated,
+		Response: domain.Library{},
+	})
+	s.handle(api, "GET", "/libraries/{id}", s.getLibrary, operation{
+		Summary:  "Get a library",
+		Response: domain.Library{},
+	})
+	s.handle(api, "PUT", "/libraries/{id}", s.updateLibrary, operation{
+		Summary:  "Replace a library's display name, description, labels and owner",
+		Body:     domain.Library{},
+		Response: domain.Library{},
+	})
+	s.handle(api, "DELETE", "/libraries/{id}", s.deleteLibrary, operation{
+		Summary: "Delete a library with its notebooks",
+		Status:  http.StatusNoContent,
+	})
 
 	// Notebook routes
-	api.HandleFunc("/notebooks/by-library/{libraryId}", s.listNotebooks).Methods("GET")
-	api.HandleFunc("/notebooks/by-library/{libraryId}", s.createNotebook).Methods("POST")
-	api.HandleFunc("/notebooks/{id}", s.getNotebook).Methods("GET")
-	api.HandleFunc("/notebooks/{id}", s.updateNotebook).Methods("PUT")
-	api.HandleFunc("/notebooks/{id}", s.deleteNotebook).Methods("DELETE")
+	s.handle(api, "GET", "/notebooks/by-library/{libraryId}", s.listNotebooks, operation{
+		Summary:  "List a page of a library's notebooks",
+		Query:    listParameters(postgres.NotebookSorts(), "owner", "status"),
+		Response: domain.Page[*domain.Notebook]{},
+	})
+	s.handle(api, "POST", "/notebooks/by-library/{libraryId}", s.createNotebook, operation{
+		Summary:  "Create a draft notebook in a library, owned by the caller",
+		Body:     domain.Notebook{},
+		Status:   http.StatusCreated,
+		Response: domain.Notebook{},
+	})
+	s.handle(api, "GET", "/notebooks/{id}", s.getNotebook, operation{
+		Summary:  "Get a notebook",
+		Response: domain.Notebook{},
+	})
+	s.handle(api, "PUT", "/notebooks/{id}", s.updateNotebook, operation{
+		Summary:  "Replace a notebook's display name, description, markdown and owner",
+		Body:     domain.Notebook{},
+		Response: domain.Notebook{},
+	})
+	s.handle(api, "DELETE", "/notebooks/{id}", s.deleteNotebook, operation{
+		Summary: "Delete a notebook",
+		Status:  http.StatusNoContent,
+	})
+	s.handle(api, "GET", "/notebooks/{id}/render", s.renderNotebook, operation{
+		Summary: "Render a notebook as HTML or plain text",
+		Query: []*parameter{
+			queryParameter("format", "Format to render in", map[string]interface{}{
+				"type":    "string",
+				"enum":    []render.Format{render.FormatHTML, render.FormatText},
+				"default": render.FormatHTML,
+			}),
+		},
+		ResponseMedia: []string{"text/html", "text/plain"},
+	})
+
+	// Type routes
+	s.handle(api, "GET", "/types", s.listTypes, operation{
+		Summary:  "List the type definitions",
+		Response: []*domain.TypeDef{},
+	})
+	s.handle(api, "POST", "/types", s.createType, operation{
+		Summary:  "Create a type definition",
+		Body:     domain.TypeDef{},
+		Status:   http.StatusCreated,
+		Response: domain.TypeDef{},
+	})
+	s.handle(api, "GET", "/types/{id}", s.getType, operation{
+		Summary:  "Get a type definition by name",
+		Response: domain.TypeDef{},
+	})
+	s.handle(api, "PUT", "/types/{id}", s.updateType, operation{
+		Summary:  "Replace a type definition",
+		Body:     domain.TypeDef{},
+		Response: domain.TypeDef{},
+	})
+	s.handle(api, "DELETE", "/types/{id}", s.deleteType, operation{
+		Summary: "Delete a type definition",
+		Status:  http.StatusNoContent,
+	})
+	s.handle(api, "GET", "/types/{id}/schemas", s.listTypeSchemas, operation{
+		Summary:  "List every version of a type's JSON Schema, newest first",
+		Response: []*domain.TypeSchema{},
+	})
+
+	// Feature routes
+	s.handle(api, "GET", "/features/{id}", s.getFeature, operation{
+		Summary: "Get a feature with its sensitive values redacted, or revealed",
+		Query: []*parameter{
+			queryParameter("reveal", "Decrypt sensitive values, if the caller may reveal them", map[string]interface{}{"type": "boolean"}),
+		},
+		Response: domain.Feature{},
+	})
+
+	// Blob routes
+	s.handle(api, "POST", "/blobs/by-tenant/{tenantId}", s.uploadBlob, operation{
+		Summary: "Upload the request body as a blob of a tenant",
+		Query: []*parameter{
+			queryParameter("type", "Types the blob is uploaded for, whose file-size constraints it must meet", map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			}),
+		},
+		BodyMedia: "application/octet-stream",
+		Status:    http.StatusCreated,
+		Response:  uploadBlobResponse{},
+	})
+	s.handle(api, "GET", "/blobs/by-tenant/{tenantId}/{digest}", s.downloadBlob, operation{
+		Summary:       "Download a blob's bytes as their sniffed media type",
+		ResponseMedia: []string{"*/*"},
+	})
+
+	// API key routes
+	s.handle(api, "GET", "/api-keys/by-tenant/{tenantId}", s.listAPIKeys, operation{
+		Summary:  "List a tenant's API keys",
+		Response: []*domain.APIKey{},
+	})
+	s.handle(api, "POST", "/api-keys/by-tenant/{tenantId}", s.createAPIKey, operation{
+		Summary:  "Create an API key for a tenant; its secret is returned only once",
+		Body:     createAPIKeyRequest{},
+		Status:   http.StatusCreated,
+		Response: createAPIKeyResponse{},
+	})
+	s.handle(api, "DELETE", "/api-keys/by-tenant/{tenantId}/{id}", s.revokeAPIKey, operation{
+		Summary: "Revoke an API key",
+		Status:  http.StatusNoContent,
+	})
 
-	// Health check
-	s.router.HandleFunc("/health", s.healthCheck).Methods("GET")
+	// Audit routes
+	s.handle(api, "GET", "/audit", s.queryAuditLog, operation{
+		Summary:  "Query the audit log, newest first",
+		Query:    auditParameters(),
+		Response: []*domain.AuditEntry{},
+	})
+
+	// Health check and API description
+	s.handle(s.router, "GET", "/health", s.healthCheck, operation{
+		Summary:  "Check the service's health",
+		Response: healthResponse{},
+		Public:   true,
+	})
+	s.handle(s.router, "GET", "/openapi.json", s.serveOpenAPI, operation{
+		Summary:       "Get the OpenAPI document of the API",
+		ResponseMedia: []string{"application/json"},
+		Public:        true,
+	})
+}
+
+// handle routes method and path on router to handler, and describes the route
+// as op in the API's OpenAPI document
+func (s *Server) handle(router *mux.Router, method, path string, handler http.HandlerFunc, op operation) {
+	route := router.HandleFunc(path, handler).Methods(method)
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		panic(fmt.Sprintf("rest: route %s %s: %v", method, path, err))
+	}
+	s.openapi.add(method, template, handler, op)
 }
 
 // Start starts the HTTP server
@@ -71,23 +312,127 @@ func (s *Server) Start(port int) error {
 	return http.ListenAndServe(addr, s.router)
 }
 
+// StartWebSocket starts the WebSocket change feed server
+func (s *Server) StartWebSocket(port int) error {
+	var hub http.Handler = authz.ScopeMiddleware(s.hub)
+	if s.authn != nil {
+		hub = s.authn.Middleware(hub)
+	}
+
+	ws := http.NewServeMux()
+	ws.Handle("/ws", hub)
+
+	addr := fmt.Sprintf(":%d", port)
+	fmt.Printf("Starting WebSocket server on %s\n", addr)
+	return http.ListenAndServe(addr, ws)
+}
+
+// HandleEvent is a live event subscriber that pushes domain events to WebSocket clients
+func (s *Server) HandleEvent(ctx context.Context, event *domain.Event) error {
+	return s.hub.HandleEvent(ctx, event)
+}
+
+// authorizeSubscription allows WebSocket actions on resources of existing tenants
+// that the policies allow
+func (s *Server) authorizeSubscription(r *http.Request, action string, ref *domain.ResourceRef) error {
+	if _, err := s.tenantRepo.Get(r.Context(), ref.TenantID); err != nil {
+		return err
+	}
+
+	if s.authorizer != nil {
+		return s.authorizer.Check(r.Context(), action, ref.URI())
+	}
+
+	return nil
+}
+
+// routeResources maps a route's collection to the resource argument its {id} names
+var routeResources = map[string]string{
+	"tenants":   "tenantId",
+	"libraries": "libraryId",
+	"notebooks": "notebookId",
+	"features":  "featureId",
+	"types":     "typeName",
+}
+
+// routeAction returns the action of an API request, its method and route template,
+// and the resource arguments its route variables hold
+func routeAction(r *http.Request) (string, map[string]interface{}, error) {
+	template, err := mux.CurrentRoute(r).GetPathTemplate()
+	if err != nil {
+		return "", nil, err
+	}
+
+	args := make(map[string]interface{})
+	for name, value := range mux.Vars(r) {
+		args[name] = value
+	}
+	if id, ok := args["id"]; ok {
+		collection := strings.SplitN(strings.TrimPrefix(template, "/api/v1/"), "/", 2)[0]
+		if name, ok := routeResources[collection]; ok {
+			args[name] = id
+		}
+	}
+
+	return r.Method + " " + template, args, nil
+}
+
+// authorize is middleware that checks each API request against policy. The action
+// is the method and route template, e.g. "GET /api/v1/notebooks/{id}", checked on
+// every resource the route variables address.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		action, args, err := routeAction(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		targets, err := authz.ResolveTargets(r.Context(), s.attrs, args)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := s.authorizer.CheckAll(r.Context(), action, targets); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// healthResponse is the body of a health check
+type healthResponse struct {
+	Status  string `json:"status"`
+	Service string `json:"service"`
+}
+
 // Health check handler
 func (s *Server) healthCheck(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"status": "healthy",
-		"service": "synthesis-api",
+	json.NewEncoder(w).Encode(healthResponse{
+		Status:  "healthy",
+		Service: "synthesis-api",
 	})
 }
 
 // Tenant handlers
 
+// listTenants lists a page of tenants, filtered by ?label_selector= and ?owner=
 func (s *Server) listTenants(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	tenants, err := s.tenantRepo.List(ctx)
+	opts, err := listOptions(r)
+	if err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tenants, err := s.tenantRepo.List(ctx, opts)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -95,12 +440,33 @@ func (s *Server) listTenants(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(tenants)
 }
 
+// createTenant creates the tenant in the request body, owned by the caller
 func (s *Server) createTenant(w http.ResponseWriter, r *http.Request) {
-	// Placeholder implementation
+	ctx := r.Context()
+
+	var tenant domain.Tenant
+	if err := decodeBody(r, &tenant); err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+	tenant.Owner = auth.IdentityFromContext(ctx, tenant.Owner)
+	tenant.Version = "1"
+	tenant.LastModified = time.Now()
+
+	if err := s.validator.ValidateTenant(&tenant); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.tenantRepo.Create(ctx, &tenant); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Create tenant not yet implemented",
-	})
+	w.Header().Set("Location", "/api/v1/tenants/"+url.PathEscape(tenant.ID))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tenant)
 }
 
 func (s *Server) getTenant(w http.ResponseWriter, r *http.Request) {
@@ -110,7 +476,7 @@ func (s *Server) getTenant(w http.ResponseWriter, r *http.Request) {
 
 	tenant, err := s.tenantRepo.Get(ctx, id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 
@@ -118,63 +484,226 @@ func (s *Server) getTenant(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(tenant)
 }
 
+// updateTenant replaces a tenant's display name, description and labels with
+// the request body's, and its owner when the body names one
 func (s *Server) updateTenant(w http.ResponseWriter, r *http.Request) {
-	// Placeholder implementation
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	var tenant domain.Tenant
+	if err := decodeBody(r, &tenant); err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := pathMismatch("/tenantId", tenant.ID, vars["id"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	tenant.ID = vars["id"]
+
+	existing, err := s.tenantRepo.Get(ctx, tenant.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if tenant.Owner == "" {
+		tenant.Owner = existing.Owner
+	}
+	tenant.Version = existing.Version
+	tenant.LastModified = time.Now()
+
+	if err := s.validator.ValidateTenant(&tenant); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.tenantRepo.Update(ctx, &tenant); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Update tenant not yet implemented",
-	})
+	json.NewEncoder(w).Encode(tenant)
 }
 
 func (s *Server) deleteTenant(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 	vars := mux.Vars(r)
 	id := vars["id"]
 
 	if err := s.tenantRepo.Delete(ctx, id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// Library handlers (placeholders)
+// Library handlers
 
+// listLibraries lists a page of a tenant's libraries, filtered by
+// ?label_selector= and ?owner=
 func (s *Server) listLibraries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	opts, err := listOptions(r)
+	if err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := s.tenantRepo.Get(ctx, tenantID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	libraries, err := s.libraryRepo.ListByTenant(ctx, tenantID, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode([]interface{}{})
+	json.NewEncoder(w).Encode(libraries)
 }
 
+// createLibrary creates the library in the request body in the route's tenant,
+// owned by the caller
 func (s *Server) createLibrary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	var library domain.Library
+	if err := decodeBody(r, &library); err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := pathMismatch("/tenantId", library.TenantID, vars["tenantId"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	library.TenantID = vars["tenantId"]
+	library.Owner = auth.IdentityFromContext(ctx, library.Owner)
+
+	if err := s.validator.ValidateLibrary(&library); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if _, err := s.tenantRepo.Get(ctx, library.TenantID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.libraryRepo.Create(ctx, &library); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "Create library not yet implemented"})
+	w.Header().Set("Location", "/api/v1/libraries/"+url.PathEscape(library.ID))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(library)
 }
 
 func (s *Server) getLibrary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	library, err := s.libraryRepo.Get(ctx, vars["id"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "Get library not yet implemented"})
+	json.NewEncoder(w).Encode(library)
 }
 
+// updateLibrary replaces a library's display name, description and labels with
+// the request body's, and its owner when the body names one. A library stays
+// in its tenant.
 func (s *Server) updateLibrary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	var library domain.Library
+	if err := decodeBody(r, &library); err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := pathMismatch("/libraryId", library.ID, vars["id"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	library.ID = vars["id"]
+
+	existing, err := s.libraryRepo.Get(ctx, library.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := pathMismatch("/tenantId", library.TenantID, existing.TenantID); err != nil {
+		writeError(w, err)
+		return
+	}
+	library.TenantID = existing.TenantID
+	if library.Owner == "" {
+		library.Owner = existing.Owner
+	}
+
+	if err := s.validator.ValidateLibrary(&library); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.libraryRepo.Update(ctx, &library); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "Update library not yet implemented"})
+	json.NewEncoder(w).Encode(library)
 }
 
+// deleteLibrary deletes a library with its notebooks
 func (s *Server) deleteLibrary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	if err := s.libraryRepo.Delete(ctx, vars["id"]); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
 // Notebook handlers
 
+// listNotebooks lists a page of a library's notebooks, filtered by ?owner=
+// and ?status=
 func (s *Server) listNotebooks(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	vars := mux.Vars(r)
 	libraryID := vars["libraryId"]
 
-	notebooks, err := s.notebookRepo.ListByLibrary(ctx, libraryID)
+	opts, err := listOptions(r)
+	if err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := s.libraryRepo.Get(ctx, libraryID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	notebooks, err := s.notebookRepo.ListByLibrary(ctx, libraryID, opts)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -182,9 +711,71 @@ func (s *Server) listNotebooks(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(notebooks)
 }
 
+// createNotebook creates the notebook in the request body in the route's
+// library, owned by the caller. New notebooks start as drafts.
 func (s *Server) createNotebook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	var notebook domain.Notebook
+	if err := decodeBody(r, &notebook); err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := pathMismatch("/libraryId", notebook.LibraryID, vars["libraryId"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := pathMismatch("/status", notebook.Status, domain.NotebookStatusDraft); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	library, err := s.libraryRepo.Get(ctx, vars["libraryId"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := pathMismatch("/tenantId", notebook.TenantID, library.TenantID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	notebook.TenantID = library.TenantID
+	notebook.LibraryID = library.ID
+	notebook.Status = domain.NotebookStatusDraft
+	notebook.Owner = auth.IdentityFromContext(ctx, notebook.Owner)
+	notebook.Revision = 0
+
+	if err := s.validator.ValidateNotebook(ctx, &notebook); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.quotas.CheckNotebooks(ctx, notebook.TenantID, 1); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.quotas.CheckContentBytes(ctx, notebook.TenantID, notebookContentBytes(&notebook)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.notebookRepo.Create(ctx, &notebook); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	created, err := s.notebookRepo.Get(ctx, notebook.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "Create notebook not yet implemented"})
+	w.Header().Set("Location", "/api/v1/notebooks/"+url.PathEscape(created.ID))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
 }
 
 func (s *Server) getNotebook(w http.ResponseWriter, r *http.Request) {
@@ -194,7 +785,7 @@ func (s *Server) getNotebook(w http.ResponseWriter, r *http.Request) {
 
 	notebook, err := s.notebookRepo.Get(ctx, id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 
@@ -202,18 +793,176 @@ func (s *Server) getNotebook(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(notebook)
 }
 
+// updateNotebook replaces a notebook's display name, description and markdown
+// with the request body's, and its owner when the body names one. A notebook
+// stays in its library; its status changes through the workflow, and its
+// content blocks through block operations, so those in the body must match.
 func (s *Server) updateNotebook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	var notebook domain.Notebook
+	if err := decodeBody(r, &notebook); err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := pathMismatch("/notebookId", notebook.ID, vars["id"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	notebook.ID = vars["id"]
+
+	existing, err := s.notebookRepo.Get(ctx, notebook.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	for _, field := range []struct{ path, value, current string }{
+		{"/tenantId", notebook.TenantID, existing.TenantID},
+		{"/libraryId", notebook.LibraryID, existing.LibraryID},
+		{"/status", notebook.Status, existing.Status},
+	} {
+		if err := pathMismatch(field.path, field.value, field.current); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	notebook.TenantID = existing.TenantID
+	notebook.LibraryID = existing.LibraryID
+	notebook.Status = existing.Status
+	notebook.Revision = existing.Revision
+	notebook.Contents.ContentBlocks = existing.Contents.ContentBlocks
+	notebook.Notifications = existing.Notifications
+	if notebook.Owner == "" {
+		notebook.Owner = existing.Owner
+	}
+
+	if err := s.validator.ValidateNotebook(ctx, &notebook); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	grown := int64(len(notebook.Contents.Data.Markdown) - len(existing.Contents.Data.Markdown))
+	if err := s.quotas.CheckContentBytes(ctx, notebook.TenantID, grown); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.notebookRepo.Update(ctx, &notebook); err != nil {
+		writeError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "Update notebook not yet implemented"})
+	json.NewEncoder(w).Encode(notebook)
 }
 
 func (s *Server) deleteNotebook(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 	vars := mux.Vars(r)
 	id := vars["id"]
 
 	if err := s.notebookRepo.Delete(ctx, id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// notebookContentBytes returns the size of a notebook's markdown and content blocks
+func notebookContentBytes(notebook *domain.Notebook) int64 {
+	n := int64(len(notebook.Contents.Data.Markdown))
+	for _, block := range notebook.Contents.ContentBlocks {
+		n += int64(len(block.Data))
+	}
+	return n
+}
+
+// pathMismatch returns a *validation.Error if a body field set to value names
+// another resource than want, such as an ID other than the route's
+func pathMismatch(path, value, want string) error {
+	if value == "" || value == want {
+		return nil
+	}
+	return &validation.Error{Violations: []*validation.Violation{
+		{Path: path, Message: fmt.Sprintf("is %q but must be %q", value, want)},
+	}}
+}
+
+// API key handlers
+
+func (s *Server) listAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	keys, err := s.apiKeyRepo.List(ctx, tenantID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if keys == nil {
+		keys = []*domain.APIKey{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// createAPIKeyRequest is the body of a request to create an API key
+type createAPIKeyRequest struct {
+	Name      string     `json:"name" jsonschema:"required" description:"Name of the key, unique within the tenant, e.g. nightly-feature-import"`
+	Scopes    []string   `json:"scopes" description:"Roles granted to the key within its tenant, e.g. editor (default read-only)"`
+	ExpiresAt *time.Time `json:"expires_at" description:"When the key stops working (default never)"`
+}
+
+// createAPIKeyResponse is a created API key with its secret, which is shown only once
+type createAPIKeyResponse struct {
+	APIKey *domain.APIKey `json:"apiKey"`
+	Key    string         `json:"key" description:"The secret key, to pass as a bearer token"`
+}
+
+func (s *Server) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	var body createAPIKeyRequest
+	if err := decodeBody(r, &body); err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+
+	key, secret, err := auth.NewAPIKey(tenantID, body.Name, body.Scopes, body.ExpiresAt, auth.IdentityFromContext(ctx, ""))
+	if err != nil {
+		writeErrorStatus(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	if _, err := s.tenantRepo.Get(ctx, tenantID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createAPIKeyResponse{APIKey: key, Key: secret})
+}
+
+func (s *Server) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	if err := s.apiKeyRepo.Revoke(ctx, vars["tenantId"], vars["id"]); err != nil {
+		writeError(w, err)
 		return
 	}
 
@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+func (s *Server) listTypes(w http.ResponseWriter, r *http.Request) {
+	types, err := s.typeRepo.List(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types)
+}
+
+func (s *Server) createType(w http.ResponseWriter, r *http.Request) {
+	var typeDef domain.TypeDef
+	if err := decodeBody(r, &typeDef); err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.validator.ValidateType(&typeDef); err != nil {
+		writeErrorStatus(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	if err := s.typeRepo.Create(r.Context(), &typeDef); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(typeDef)
+}
+
+func (s *Server) getType(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["id"]
+
+	typeDef, err := s.typeRepo.Get(r.Context(), name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(typeDef)
+}
+
+// listTypeSchemas lists every version of a type's JSON Schema, newest first
+func (s *Server) listTypeSchemas(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	schemas, err := s.typeRepo.ListSchemas(r.Context(), vars["id"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if schemas == nil {
+		schemas = []*domain.TypeSchema{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schemas)
+}
+
+// updateType replaces a type definition with the request body; the name is the route's
+func (s *Server) updateType(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var typeDef domain.TypeDef
+	if err := decodeBody(r, &typeDef); err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err)
+		return
+	}
+	typeDef.Name = vars["id"]
+
+	if err := s.validator.ValidateType(&typeDef); err != nil {
+		writeErrorStatus(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	if err := s.typeRepo.Update(r.Context(), &typeDef); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(typeDef)
+}
+
+func (s *Server) deleteType(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := s.typeRepo.Delete(r.Context(), vars["id"]); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,267 @@
+package secrets
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sealedPrefix marks a sealed value: enc:v1:<key ID>:<wrapped data key>:<ciphertext>
+const sealedPrefix = "enc:v1:"
+
+// ErrNotConfigured is returned when sealing without a key provider
+var ErrNotConfigured = errors.New("encryption is not configured: set ENCRYPTION_KEY_FILE")
+
+// ErrSealedInput is returned for input that already looks sealed. Only the
+// server seals values, so such input is never trusted as ciphertext.
+var ErrSealedInput = fmt.Errorf("sensitive values must not start with %q", sealedPrefix)
+
+// Sealer encrypts sensitive values with envelope encryption: each value is
+// encrypted with its own AES-256 data key, stored alongside it wrapped by a
+// master key of the key provider. Each value is bound to the field of the
+// resource it belongs to, whose URI names its tenant, so it cannot be opened
+// after being moved to another tenant, resource or field. A nil *Sealer seals nothing.
+type Sealer struct {
+	keys KeyProvider
+}
+
+// NewSealer creates a sealer wrapping data keys with the master keys of keys
+func NewSealer(keys KeyProvider) *Sealer {
+	return &Sealer{keys: keys}
+}
+
+// IsSealed reports whether value was produced by Seal
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// Seal encrypts plaintext under a new data key wrapped with the current master
+// key, bound to field of the resource at resourceURI
+func (s *Sealer) Seal(ctx context.Context, resourceURI, field, plaintext string) (string, error) {
+	if s == nil {
+		return "", ErrNotConfigured
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(aead, []byte(plaintext), binding(resourceURI, field))
+	if err != nil {
+		return "", err
+	}
+
+	keyID := s.keys.CurrentKeyID()
+	wrapped, err := s.keys.WrapKey(ctx, keyID, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	return format(keyID, wrapped, ciphertext), nil
+}
+
+// Open decrypts a value Seal bound to field of the resource at resourceURI
+func (s *Sealer) Open(ctx context.Context, resourceURI, field, sealed string) (string, error) {
+	if s == nil {
+		return "", ErrNotConfigured
+	}
+
+	keyID, wrapped, ciphertext, err := parse(sealed)
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := s.keys.UnwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(aead, ciphertext, binding(resourceURI, field))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// Rewrap rewraps the data key of a sealed value with the current master key,
+// leaving its ciphertext as it is. It reports whether the value changed; values
+// that are not sealed are left as they are.
+func (s *Sealer) Rewrap(ctx context.Context, sealed string) (string, bool, error) {
+	if s == nil {
+		return "", false, ErrNotConfigured
+	}
+	if !IsSealed(sealed) {
+		return sealed, false, nil
+	}
+
+	keyID, wrapped, ciphertext, err := parse(sealed)
+	if err != nil {
+		return "", false, err
+	}
+
+	current := s.keys.CurrentKeyID()
+	if keyID == current {
+		return sealed, false, nil
+	}
+
+	dataKey, err := s.keys.UnwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	rewrapped, err := s.keys.WrapKey(ctx, current, dataKey)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	return format(current, rewrapped, ciphertext), true, nil
+}
+
+// SealValues seals the values of keys in values, the values of the resource at
+// resourceURI, in place. Values that look sealed already are rejected with
+// ErrSealedInput.
+func (s *Sealer) SealValues(ctx context.Context, resourceURI string, values map[string]string, keys []string) error {
+	for _, key := range keys {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		if IsSealed(value) {
+			return fmt.Errorf("%s: %w", key, ErrSealedInput)
+		}
+
+		sealed, err := s.Seal(ctx, resourceURI, key, value)
+		if err != nil {
+			return fmt.Errorf("failed to seal %s: %w", key, err)
+		}
+		values[key] = sealed
+	}
+	return nil
+}
+
+// OpenValues opens the sealed values of keys in values, the values of the
+// resource at resourceURI, in place
+func (s *Sealer) OpenValues(ctx context.Context, resourceURI string, values map[string]string, keys []string) error {
+	for _, key := range keys {
+		value, ok := values[key]
+		if !ok || !IsSealed(value) {
+			continue
+		}
+
+		plaintext, err := s.Open(ctx, resourceURI, key, value)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", key, err)
+		}
+		values[key] = plaintext
+	}
+	return nil
+}
+
+// SealConfig seals the values of keys in config, the config of the resource at
+// resourceURI, in place. Each value is sealed as its JSON encoding, so it may be
+// of any type. Values that look sealed already are rejected with ErrSealedInput.
+func (s *Sealer) SealConfig(ctx context.Context, resourceURI string, config map[string]interface{}, keys []string) error {
+	if err := CheckUnsealed(config, keys); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		value, ok := config[key]
+		if !ok {
+			continue
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", key, err)
+		}
+
+		sealed, err := s.Seal(ctx, resourceURI, key, string(encoded))
+		if err != nil {
+			return fmt.Errorf("failed to seal %s: %w", key, err)
+		}
+		config[key] = sealed
+	}
+	return nil
+}
+
+// OpenConfig opens the sealed values of keys in config, the config of the
+// resource at resourceURI, in place
+func (s *Sealer) OpenConfig(ctx context.Context, resourceURI string, config map[string]interface{}, keys []string) error {
+	for _, key := range keys {
+		sealed, ok := config[key].(string)
+		if !ok || !IsSealed(sealed) {
+			continue
+		}
+
+		plaintext, err := s.Open(ctx, resourceURI, key, sealed)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", key, err)
+		}
+
+		var value interface{}
+		if err := json.Unmarshal([]byte(plaintext), &value); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", key, err)
+		}
+		config[key] = value
+	}
+	return nil
+}
+
+// CheckUnsealed returns ErrSealedInput if a value of keys in config looks sealed
+func CheckUnsealed(config map[string]interface{}, keys []string) error {
+	for _, key := range keys {
+		if str, ok := config[key].(string); ok && IsSealed(str) {
+			return fmt.Errorf("%s: %w", key, ErrSealedInput)
+		}
+	}
+	return nil
+}
+
+// binding is the additional data a value is sealed with: the URI of its
+// resource, which names the tenant, and its field
+func binding(resourceURI, field string) []byte {
+	return []byte(resourceURI + "#" + field)
+}
+
+func format(keyID string, wrapped, ciphertext []byte) string {
+	return sealedPrefix + keyID + ":" +
+		base64.RawURLEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawURLEncoding.EncodeToString(ciphertext)
+}
+
+func parse(sealed string) (string, []byte, []byte, error) {
+	if !IsSealed(sealed) {
+		return "", nil, nil, fmt.Errorf("value is not sealed")
+	}
+
+	parts := strings.Split(strings.TrimPrefix(sealed, sealedPrefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, fmt.Errorf("malformed sealed value")
+	}
+
+	wrapped, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("malformed wrapped data key: %w", err)
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("malformed ciphertext: %w", err)
+	}
+
+	return parts[0], wrapped, ciphertext, nil
+}
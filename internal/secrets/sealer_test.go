@@ -0,0 +1,99 @@
+package secrets
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func newTestSealer(t *testing.T) *Sealer {
+	t.Helper()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+	return NewSealer(&LocalKeyProvider{current: "k1", keys: map[string]cipher.AEAD{"k1": aead}})
+}
+
+func TestSealIsBoundToResourceAndField(t *testing.T) {
+	ctx := context.Background()
+	sealer := newTestSealer(t)
+
+	const uri = "synthesis://tenant/acme/feature/f1"
+	sealed, err := sealer.Seal(ctx, uri, "apiKey", "s3cret")
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+
+	plaintext, err := sealer.Open(ctx, uri, "apiKey", sealed)
+	if err != nil || plaintext != "s3cret" {
+		t.Fatalf("expected to open the value, got %q, %v", plaintext, err)
+	}
+
+	moved := map[string][2]string{
+		"other tenant":   {"synthesis://tenant/globex/feature/f1", "apiKey"},
+		"other resource": {"synthesis://tenant/acme/feature/f2", "apiKey"},
+		"other field":    {uri, "password"},
+	}
+	for name, target := range moved {
+		if _, err := sealer.Open(ctx, target[0], target[1], sealed); err == nil {
+			t.Errorf("%s: expected a moved value not to open", name)
+		}
+	}
+}
+
+func TestSealValuesRejectsSealedInput(t *testing.T) {
+	ctx := context.Background()
+	sealer := newTestSealer(t)
+
+	values := map[string]string{"apiKey": "enc:v1:k1:AAAA:BBBB"}
+	err := sealer.SealValues(ctx, "synthesis://tenant/acme/feature/f1", values, []string{"apiKey"})
+	if !errors.Is(err, ErrSealedInput) {
+		t.Fatalf("expected ErrSealedInput, got %v", err)
+	}
+
+	config := map[string]interface{}{"url": "enc:v1:k1:AAAA:BBBB"}
+	err = sealer.SealConfig(ctx, "synthesis://tenant/acme/tool/t1", config, []string{"url"})
+	if !errors.Is(err, ErrSealedInput) {
+		t.Fatalf("expected ErrSealedInput, got %v", err)
+	}
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	sealer := newTestSealer(t)
+
+	const uri = "synthesis://tenant/acme/tool/t1"
+	config := map[string]interface{}{
+		"url":     "https://hooks.example.com/x",
+		"headers": map[string]interface{}{"Authorization": "Bearer t"},
+		"method":  "POST",
+	}
+	keys := []string{"url", "headers"}
+
+	if err := sealer.SealConfig(ctx, uri, config, keys); err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+	for _, key := range keys {
+		if value, _ := config[key].(string); !IsSealed(value) {
+			t.Errorf("expected %s to be sealed, got %v", key, config[key])
+		}
+	}
+
+	if err := sealer.OpenConfig(ctx, uri, config, keys); err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	if config["url"] != "https://hooks.example.com/x" {
+		t.Errorf("unexpected url %v", config["url"])
+	}
+	if headers, _ := config["headers"].(map[string]interface{}); headers["Authorization"] != "Bearer t" {
+		t.Errorf("unexpected headers %v", config["headers"])
+	}
+}
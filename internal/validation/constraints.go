@@ -0,0 +1,169 @@
+package validation
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/prismon/synthesis/internal/domain"
+)
+
+// ConstraintHandler checks data against one kind of type constraint, configured
+// by the constraint's config. It returns a *Violation error when the data breaks
+// the constraint, and other errors when it cannot tell, such as for a bad config.
+type ConstraintHandler func(ctx context.Context, config map[string]interface{}, contentType, data string) error
+
+// Registry holds the constraint handlers by constraint type, such as "max-length"
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]ConstraintHandler
+}
+
+// NewRegistry creates a registry holding the built-in constraint handlers:
+//
+//   - max-length, min-length: {"max": n} / {"min": n} characters
+//   - pattern: {"regex": "..."} the data must match
+//   - file-size: {"maxBytes": n}; base64 data: URIs count their decoded size
+//     and blob references the size of the blob
+//   - json-schema: the data must be JSON, valid against the JSON Schema
+//     (draft 2020-12) in {"schema": {...}} when one is given
+func NewRegistry() *Registry {
+	r := &Registry{handlers: make(map[string]ConstraintHandler)}
+	r.Register("max-length", maxLength)
+	r.Register("min-length", minLength)
+	r.Register("pattern", pattern)
+	r.Register("file-size", fileSize)
+	r.Register("json-schema", jsonSchema)
+	return r
+}
+
+// Register adds or replaces the handler of a constraint type
+func (r *Registry) Register(constraintType string, handler ConstraintHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[constraintType] = handler
+}
+
+// Handler returns the handler of a constraint type
+func (r *Registry) Handler(constraintType string) (ConstraintHandler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	handler, ok := r.handlers[constraintType]
+	return handler, ok
+}
+
+// Types returns the constraint types with a handler
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]string, 0, len(r.handlers))
+	for constraintType := range r.handlers {
+		types = append(types, constraintType)
+	}
+	return types
+}
+
+func maxLength(ctx context.Context, config map[string]interface{}, contentType, data string) error {
+	max, err := intConfig(config, "max")
+	if err != nil {
+		return err
+	}
+	if length := utf8.RuneCountInString(data); length > max {
+		return violationf("is %d characters long; the maximum is %d", length, max)
+	}
+	return nil
+}
+
+func minLength(ctx context.Context, config map[string]interface{}, contentType, data string) error {
+	min, err := intConfig(config, "min")
+	if err != nil {
+		return err
+	}
+	if length := utf8.RuneCountInString(data); length < min {
+		return violationf("is %d characters long; the minimum is %d", length, min)
+	}
+	return nil
+}
+
+func pattern(ctx context.Context, config map[string]interface{}, contentType, data string) error {
+	expr, _ := config["regex"].(string)
+	if expr == "" {
+		return fmt.Errorf("pattern constraint requires a regex")
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid pattern constraint: %w", err)
+	}
+	if !re.MatchString(data) {
+		return violationf("does not match %s", expr)
+	}
+	return nil
+}
+
+func fileSize(ctx context.Context, config map[string]interface{}, contentType, data string) error {
+	max, err := intConfig(config, "maxBytes")
+	if err != nil {
+		return err
+	}
+	size := dataSize(data)
+	if blob, ok := BlobFromContext(ctx); ok {
+		size = int(blob.Size)
+	}
+	if size > max {
+		return violationf("is %d bytes; the maximum is %d", size, max)
+	}
+	return nil
+}
+
+func jsonSchema(ctx context.Context, config map[string]interface{}, contentType, data string) error {
+	schema, ok := config["schema"]
+	if !ok || schema == nil {
+		if !json.Valid([]byte(data)) {
+			return violationf("is not valid JSON")
+		}
+		return nil
+	}
+
+	compiled, err := compiledSchemas.compile(schema)
+	if err != nil {
+		return fmt.Errorf("invalid json-schema constraint: %w", err)
+	}
+	return matchSchema(compiled, data)
+}
+
+type blobKey struct{}
+
+// withBlob returns a context carrying the blob the content being checked references
+func withBlob(ctx context.Context, blob *domain.Blob) context.Context {
+	return context.WithValue(ctx, blobKey{}, blob)
+}
+
+// BlobFromContext returns the blob referenced by the content a constraint
+// handler is checking, if it references one
+func BlobFromContext(ctx context.Context) (*domain.Blob, bool) {
+	blob, ok := ctx.Value(blobKey{}).(*domain.Blob)
+	return blob, ok
+}
+
+// dataSize returns the size of data in bytes, decoded for base64 data: URIs
+func dataSize(data string) int {
+	if strings.HasPrefix(data, "data:") {
+		if i := strings.Index(data, ";base64,"); i >= 0 {
+			return base64.StdEncoding.DecodedLen(len(data) - i - len(";base64,"))
+		}
+	}
+	return len(data)
+}
+
+func intConfig(config map[string]interface{}, key string) (int, error) {
+	value, ok := config[key].(float64)
+	if !ok || value < 0 {
+		return 0, fmt.Errorf("constraint requires a non-negative number %s", key)
+	}
+	return int(value), nil
+}
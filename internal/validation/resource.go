@@ -0,0 +1,250 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/egress"
+)
+
+// Kinds of resource that can be validated before they are written
+const (
+	KindNotebook     = "notebook"
+	KindContentBlock = "content_block"
+	KindFeature      = "feature"
+)
+
+// Kinds lists the kinds of resource
+var Kinds = []string{KindNotebook, KindContentBlock, KindFeature}
+
+// CheckTenant returns the violations of a candidate tenant: its ID format and
+// required fields
+func (v *Validator) CheckTenant(tenant *domain.Tenant) []*Violation {
+	var violations []*Violation
+
+	violations = append(violations, checkID("/tenantId", tenant.ID)...)
+	violations = append(violations, checkRequired("/owner", tenant.Owner)...)
+	violations = append(violations, checkRequired("/display_name", tenant.DisplayName)...)
+
+	return violations
+}
+
+// CheckLibrary returns the violations of a candidate library: ID formats and
+// required fields
+func (v *Validator) CheckLibrary(library *domain.Library) []*Violation {
+	var violations []*Violation
+
+	violations = append(violations, checkID("/tenantId", library.TenantID)...)
+	violations = append(violations, checkID("/libraryId", library.ID)...)
+	violations = append(violations, checkRequired("/owner", library.Owner)...)
+	violations = append(violations, checkRequired("/display_name", library.DisplayName)...)
+
+	return violations
+}
+
+// CheckNotebook returns the violations of a candidate notebook: required fields,
+// ID formats, status, notification URLs, and the rules of each content block
+func (v *Validator) CheckNotebook(ctx context.Context, notebook *domain.Notebook) ([]*Violation, error) {
+	var violations []*Violation
+
+	violations = append(violations, checkID("/tenantId", notebook.TenantID)...)
+	violations = append(violations, checkID("/notebookId", notebook.ID)...)
+	violations = append(violations, checkID("/libraryId", notebook.LibraryID)...)
+	violations = append(violations, checkRequired("/display_name", notebook.DisplayName)...)
+
+	if notebook.Status != "" && !domain.IsValidNotebookStatus(notebook.Status) {
+		violations = append(violations, &Violation{Path: "/status", Message: fmt.Sprintf("unknown status %q", notebook.Status)})
+	}
+
+	for i, notif := range notebook.Notifications {
+		violations = append(violations, checkNotificationURL("/notification/"+strconv.Itoa(i)+"/nurl", notif.URL)...)
+	}
+
+	found, err := v.checkBlocks(ctx, "/contents/content_blocks", notebook.Contents.ContentBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(violations, found...), nil
+}
+
+// CheckContentBlock returns the violations of a candidate content block to be
+// added to a notebook holding existing: required fields, ID format, that its
+// UID is new and its parent exists, and the constraints of its types
+func (v *Validator) CheckContentBlock(ctx context.Context, block *domain.ContentBlock, existing []domain.ContentBlock) ([]*Violation, error) {
+	violations, err := v.checkBlock(ctx, "", block)
+	if err != nil {
+		return nil, err
+	}
+
+	uids := make(map[string]bool, len(existing))
+	for _, other := range existing {
+		uids[other.UID] = true
+	}
+
+	if uids[block.UID] {
+		violations = append(violations, &Violation{Path: "/uid", Message: fmt.Sprintf("block %s already exists in the notebook", block.UID)})
+	}
+	if block.ParentUID != nil && *block.ParentUID != block.UID && !uids[*block.ParentUID] {
+		violations = append(violations, &Violation{Path: "/parent_uid", Message: fmt.Sprintf("parent block %s does not exist in the notebook", *block.ParentUID)})
+	}
+
+	return violations, nil
+}
+
+// CheckFeature returns the violations of a candidate feature: required fields,
+// ID formats, TTL, resource and notification URLs, that its sensitive keys and
+// value types name values, and the constraints of its value types
+func (v *Validator) CheckFeature(ctx context.Context, feature *domain.Feature) ([]*Violation, error) {
+	var violations []*Violation
+
+	violations = append(violations, checkID("/tenantId", feature.TenantID)...)
+	violations = append(violations, checkID("/featureId", feature.ID)...)
+	violations = append(violations, checkRequired("/display_name", feature.DisplayName)...)
+
+	if feature.TTL < 0 {
+		violations = append(violations, &Violation{Path: "/ttl", Message: "must not be negative"})
+	}
+
+	for i, resource := range feature.Resources {
+		violations = append(violations, checkURL("/resources/"+strconv.Itoa(i)+"/url", resource.URL)...)
+	}
+	for i, notif := range feature.Notifications {
+		violations = append(violations, checkNotificationURL("/notification/"+strconv.Itoa(i)+"/nurl", notif.URL)...)
+	}
+
+	for i, key := range feature.SensitiveKeys {
+		if _, ok := feature.Values[key]; !ok {
+			violations = append(violations, &Violation{Path: "/sensitive_keys/" + strconv.Itoa(i), Message: fmt.Sprintf("names no value: %s", key)})
+		}
+	}
+
+	keys := make([]string, 0, len(feature.ValueTypes))
+	for key := range feature.ValueTypes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, ok := feature.Values[key]
+		if !ok {
+			violations = append(violations, &Violation{Path: "/value_types/" + pointerEscape(key), Message: fmt.Sprintf("names no value: %s", key)})
+			continue
+		}
+
+		found, err := v.Check(ctx, "/values/"+pointerEscape(key), feature.ValueTypes[key], "", value)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, found...)
+	}
+
+	return violations, nil
+}
+
+// checkBlocks returns the violations of a notebook's blocks, each at path/i:
+// the rules of each block, unique UIDs, and parents among the blocks
+func (v *Validator) checkBlocks(ctx context.Context, path string, blocks []domain.ContentBlock) ([]*Violation, error) {
+	var violations []*Violation
+
+	parents := make(map[string]*string, len(blocks))
+
+	for i := range blocks {
+		block := &blocks[i]
+		blockPath := path + "/" + strconv.Itoa(i)
+
+		found, err := v.checkBlock(ctx, blockPath, block)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, found...)
+
+		if _, ok := parents[block.UID]; ok && block.UID != "" {
+			violations = append(violations, &Violation{Path: blockPath + "/uid", Message: fmt.Sprintf("duplicate uid %s", block.UID)})
+		}
+		parents[block.UID] = block.ParentUID
+	}
+
+	for i := range blocks {
+		block := &blocks[i]
+		if block.ParentUID == nil || *block.ParentUID == block.UID {
+			continue
+		}
+		blockPath := path + "/" + strconv.Itoa(i) + "/parent_uid"
+
+		if _, ok := parents[*block.ParentUID]; !ok {
+			violations = append(violations, &Violation{Path: blockPath, Message: fmt.Sprintf("parent block %s does not exist", *block.ParentUID)})
+			continue
+		}
+
+		// Follow the parents up; coming back to the block means a cycle
+		seen := map[string]bool{block.UID: true}
+		for parent := block.ParentUID; parent != nil; parent = parents[*parent] {
+			if seen[*parent] {
+				violations = append(violations, &Violation{Path: blockPath, Message: fmt.Sprintf("the ancestors of block %s form a cycle", block.UID)})
+				break
+			}
+			seen[*parent] = true
+		}
+	}
+
+	return violations, nil
+}
+
+// checkBlock returns the violations of a block on its own, with paths beneath path
+func (v *Validator) checkBlock(ctx context.Context, path string, block *domain.ContentBlock) ([]*Violation, error) {
+	var violations []*Violation
+
+	violations = append(violations, checkID(path+"/uid", block.UID)...)
+	violations = append(violations, checkRequired(path+"/content_type", block.ContentType)...)
+
+	if block.ParentUID != nil && *block.ParentUID == block.UID {
+		violations = append(violations, &Violation{Path: path + "/parent_uid", Message: "a block cannot be its own parent"})
+	}
+
+	found, err := v.Check(ctx, path+"/data", block.Types, block.ContentType, block.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(violations, found...), nil
+}
+
+func checkID(path, id string) []*Violation {
+	if err := domain.ValidateID(id); err != nil {
+		return []*Violation{{Path: path, Message: err.Error()}}
+	}
+	return nil
+}
+
+func checkRequired(path, value string) []*Violation {
+	if value == "" {
+		return []*Violation{{Path: path, Message: "is required"}}
+	}
+	return nil
+}
+
+// checkURL checks that value is an absolute http or https URL
+func checkURL(path, value string) []*Violation {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return []*Violation{{Path: path, Message: fmt.Sprintf("must be an absolute http or https URL: %q", value)}}
+	}
+	return nil
+}
+
+// checkNotificationURL returns a violation unless value is a URL webhooks may be
+// delivered to, which excludes loopback, private and link-local addresses
+func checkNotificationURL(path, value string) []*Violation {
+	if violations := checkURL(path, value); violations != nil {
+		return violations
+	}
+	if err := egress.CheckURL(value); err != nil {
+		return []*Violation{{Path: path, Message: fmt.Sprintf("webhooks cannot be delivered to %q: %v", value, err)}}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prismon/synthesis/internal/jsonschema"
+)
+
+// maxCachedSchemas bounds the compiled schemas kept; the cache is emptied when full
+const maxCachedSchemas = 256
+
+// compiledSchemas caches compiled JSON Schemas, since each write to a typed
+// block would otherwise compile its types' schemas again
+var compiledSchemas = &schemaCache{schemas: make(map[string]*jsonschema.Schema)}
+
+// schemaCache holds compiled JSON Schemas by their JSON text
+type schemaCache struct {
+	mu      sync.Mutex
+	schemas map[string]*jsonschema.Schema
+}
+
+// compile returns schema compiled, from the cache if it was compiled before
+func (c *schemaCache) compile(schema interface{}) (*jsonschema.Schema, error) {
+	key, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	c.mu.Lock()
+	compiled, ok := c.schemas[string(key)]
+	c.mu.Unlock()
+	if ok {
+		return compiled, nil
+	}
+
+	compiled, err = jsonschema.CompileValue(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if len(c.schemas) >= maxCachedSchemas {
+		c.schemas = make(map[string]*jsonschema.Schema)
+	}
+	c.schemas[string(key)] = compiled
+	c.mu.Unlock()
+
+	return compiled, nil
+}
+
+// matchSchema returns a *Violation listing where data breaks schema, or
+// where it is not JSON
+func matchSchema(schema *jsonschema.Schema, data string) error {
+	failures, err := schema.Validate([]byte(data))
+	if err != nil {
+		return violationf("is not valid JSON")
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(failures))
+	for i, failure := range failures {
+		messages[i] = failure.Error()
+	}
+	return violationf("does not match the JSON Schema: %s", strings.Join(messages, "; "))
+}
+
+// CheckSchema returns the violations of data against a JSON Schema, each with
+// path path. Use it to try a schema on content before making it a type's.
+func (v *Validator) CheckSchema(path string, schema interface{}, data string) ([]*Violation, error) {
+	compiled, err := compiledSchemas.compile(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON Schema: %w", err)
+	}
+
+	if err := matchSchema(compiled, data); err != nil {
+		violation := err.(*Violation)
+		violation.Path = path
+		return []*Violation{violation}, nil
+	}
+	return nil, nil
+}
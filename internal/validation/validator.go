@@ -0,0 +1,245 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// Violation is a rule some content breaks
+type Violation struct {
+	// Path is a JSON pointer to the content within the resource, e.g. "/data"
+	Path string `json:"path"`
+	// Type and Constraint name the type and constraint broken, if any
+	Type       string `json:"type,omitempty"`
+	Constraint string `json:"constraint,omitempty"`
+	Message    string `json:"message"`
+}
+
+func (v *Violation) Error() string {
+	var where []string
+	if v.Path != "" {
+		where = append(where, v.Path)
+	}
+	if v.Type != "" {
+		where = append(where, "type "+v.Type)
+	}
+	if v.Constraint != "" {
+		where = append(where, v.Constraint)
+	}
+	if len(where) == 0 {
+		return v.Message
+	}
+	return strings.Join(where, " ") + ": " + v.Message
+}
+
+func violationf(format string, args ...interface{}) *Violation {
+	return &Violation{Message: fmt.Sprintf(format, args...)}
+}
+
+// Error reports content that breaks the constraints of its types
+type Error struct {
+	Violations []*Violation
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Error()
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// asError returns violations as an *Error, or nil when there are none
+func asError(violations []*Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return &Error{Violations: violations}
+}
+
+// Validator checks content against the constraints of the types it is tagged with
+type Validator struct {
+	types       *postgres.TypeRepository
+	blobs       *postgres.BlobRepository
+	constraints *Registry
+}
+
+// NewValidator creates a validator of the types in types, checking their
+// constraints with the handlers in constraints. Content referencing a blob is
+// checked with the blob's metadata from blobs.
+func NewValidator(types *postgres.TypeRepository, blobs *postgres.BlobRepository, constraints *Registry) *Validator {
+	return &Validator{types: types, blobs: blobs, constraints: constraints}
+}
+
+// Constraints returns the validator's constraint handler registry
+func (v *Validator) Constraints() *Registry {
+	return v.constraints
+}
+
+// Check returns the violations of data against every constraint of each of the
+// named types. Each violation's path is path. Data referencing a blob must
+// reference one the caller may see; constraint handlers find it with
+// BlobFromContext.
+func (v *Validator) Check(ctx context.Context, path string, typeNames []string, contentType, data string) ([]*Violation, error) {
+	if digest, ok := domain.ParseBlobRef(data); ok {
+		blob, err := v.blobs.Find(ctx, digest)
+		if err != nil {
+			return []*Violation{{Path: path, Message: "references a missing blob"}}, nil
+		}
+		ctx = withBlob(ctx, blob)
+	}
+
+	return v.check(ctx, path, typeNames, contentType, data, nil)
+}
+
+// ValidateBlob returns an *Error if blob, uploaded for content of the named
+// types, breaks one of their file-size constraints
+func (v *Validator) ValidateBlob(ctx context.Context, typeNames []string, blob *domain.Blob) error {
+	fileSizeOnly := func(constraintType string) bool { return constraintType == "file-size" }
+
+	violations, err := v.check(withBlob(ctx, blob), "", typeNames, blob.MediaType, blob.Ref(), fileSizeOnly)
+	if err != nil {
+		return err
+	}
+	return asError(violations)
+}
+
+// check returns the violations of data against the constraints of the named
+// types that include accepts; a nil include accepts every constraint
+func (v *Validator) check(ctx context.Context, path string, typeNames []string, contentType, data string, include func(string) bool) ([]*Violation, error) {
+	var violations []*Violation
+
+	for _, name := range typeNames {
+		// Types are global, so they are read outside any tenant scope
+		typeDef, err := v.types.Get(postgres.WithSystemScope(ctx), name)
+		if err != nil {
+			violations = append(violations, &Violation{Path: path, Type: name, Message: "unknown type"})
+			continue
+		}
+
+		for _, constraint := range typeDef.Constraints {
+			if include != nil && !include(constraint.Type) {
+				continue
+			}
+
+			handler, ok := v.constraints.Handler(constraint.Type)
+			if !ok {
+				violations = append(violations, &Violation{Path: path, Type: name, Constraint: constraint.Type, Message: "no handler for this constraint"})
+				continue
+			}
+
+			err := handler(ctx, constraint.Config, contentType, data)
+			if err == nil {
+				continue
+			}
+
+			var violation *Violation
+			if !errors.As(err, &violation) {
+				return nil, fmt.Errorf("failed to check %s constraint of type %s: %w", constraint.Type, name, err)
+			}
+			violations = append(violations, &Violation{Path: path, Type: name, Constraint: constraint.Type, Message: violation.Message})
+		}
+	}
+
+	return violations, nil
+}
+
+// ValidateTenant returns an *Error if tenant breaks a rule of CheckTenant
+func (v *Validator) ValidateTenant(tenant *domain.Tenant) error {
+	return asError(v.CheckTenant(tenant))
+}
+
+// ValidateLibrary returns an *Error if library breaks a rule of CheckLibrary
+func (v *Validator) ValidateLibrary(library *domain.Library) error {
+	return asError(v.CheckLibrary(library))
+}
+
+// ValidateNotebook returns an *Error if notebook breaks a rule of CheckNotebook,
+// such as a constraint of the types of one of its content blocks
+func (v *Validator) ValidateNotebook(ctx context.Context, notebook *domain.Notebook) error {
+	violations, err := v.CheckNotebook(ctx, notebook)
+	if err != nil {
+		return err
+	}
+	return asError(violations)
+}
+
+// ValidateBlock returns an *Error if block breaks a constraint of its types
+func (v *Validator) ValidateBlock(ctx context.Context, block *domain.ContentBlock) error {
+	violations, err := v.Check(ctx, "/data", block.Types, block.ContentType, block.Data)
+	if err != nil {
+		return err
+	}
+	return asError(violations)
+}
+
+// ValidateOperation returns an *Error if the content an insert or update
+// operation writes breaks a constraint of its types. blob, if not nil, is the
+// blob the content references, which need not be stored yet.
+func (v *Validator) ValidateOperation(ctx context.Context, op *domain.BlockOperation, blob *domain.Blob) error {
+	if op.Type != domain.BlockOpInsert && op.Type != domain.BlockOpUpdate {
+		return nil
+	}
+	if blob == nil {
+		return v.ValidateBlock(ctx, &domain.ContentBlock{ContentType: op.ContentType, Data: op.Data, Types: op.Types})
+	}
+
+	violations, err := v.check(withBlob(ctx, blob), "/data", op.Types, op.ContentType, op.Data, nil)
+	if err != nil {
+		return err
+	}
+	return asError(violations)
+}
+
+// ValidateFeature returns an *Error if feature breaks a rule of CheckFeature,
+// such as a constraint of the types listed for a value in ValueTypes
+func (v *Validator) ValidateFeature(ctx context.Context, feature *domain.Feature) error {
+	violations, err := v.CheckFeature(ctx, feature)
+	if err != nil {
+		return err
+	}
+	return asError(violations)
+}
+
+// pointerEscape escapes a JSON pointer reference token
+func pointerEscape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// ValidateType returns an error if typeDef is malformed, has a constraint
+// without a handler, or has more than one JSON Schema or one that does not compile
+func (v *Validator) ValidateType(typeDef *domain.TypeDef) error {
+	if typeDef.Name == "" || strings.ContainsAny(typeDef.Name, "/ ") {
+		return fmt.Errorf("type name %q must be non-empty and contain no '/' or spaces", typeDef.Name)
+	}
+
+	schemas := 0
+	for i, constraint := range typeDef.Constraints {
+		if _, ok := v.constraints.Handler(constraint.Type); !ok {
+			known := v.constraints.Types()
+			sort.Strings(known)
+			return fmt.Errorf("constraint %d: unknown constraint type %q; known types are %s", i, constraint.Type, strings.Join(known, ", "))
+		}
+
+		if constraint.Type != domain.ConstraintJSONSchema {
+			continue
+		}
+		// A type's schema is versioned as one document
+		if schemas++; schemas > 1 {
+			return fmt.Errorf("constraint %d: a type has at most one %s constraint", i, domain.ConstraintJSONSchema)
+		}
+		if schema, ok := constraint.Config["schema"]; ok && schema != nil {
+			if _, err := compiledSchemas.compile(schema); err != nil {
+				return fmt.Errorf("constraint %d: invalid JSON Schema: %w", i, err)
+			}
+		}
+	}
+
+	return nil
+}
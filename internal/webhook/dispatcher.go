@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/prismon/synthesis/internal/config"
+	"github.com/prismon/synthesis/internal/domain"
+	"github.com/prismon/synthesis/internal/egress"
+	"github.com/prismon/synthesis/internal/postgres"
+)
+
+// claimBatchSize is the maximum number of deliveries sent per poll
+const claimBatchSize = 50
+
+// Dispatcher queues events in the webhook outbox and delivers them with
+// exponential-backoff retries, dead-lettering deliveries that keep failing
+type Dispatcher struct {
+	repo   *postgres.WebhookRepository
+	sender *Sender
+	cfg    config.WebhookConfig
+}
+
+// NewDispatcher creates a new webhook dispatcher
+func NewDispatcher(repo *postgres.WebhookRepository, cfg config.WebhookConfig) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		sender: NewSender(time.Duration(cfg.Timeout) * time.Second),
+		cfg:    cfg,
+	}
+}
+
+// HandleEvent is an event subscriber that queues a domain event for every
+// notification URL registered on the notebook or feature it describes
+func (d *Dispatcher) HandleEvent(ctx context.Context, event *domain.Event) error {
+	if event.ResourceType != "notebook" && event.ResourceType != "feature" {
+		return nil
+	}
+
+	// Notebook and feature payloads both carry their notification list
+	var resource struct {
+		Notifications []domain.Notification `json:"notification"`
+	}
+	if err := json.Unmarshal(event.Data, &resource); err != nil {
+		return fmt.Errorf("failed to decode %s event: %w", event.Type, err)
+	}
+
+	if len(resource.Notifications) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	deliveries := make([]*domain.WebhookDelivery, 0, len(resource.Notifications))
+	for _, notif := range resource.Notifications {
+		deliveries = append(deliveries, &domain.WebhookDelivery{
+			TenantID:    event.TenantID,
+			ResourceURI: event.URI,
+			EventType:   event.Type,
+			URL:         notif.URL,
+			Payload:     payload,
+		})
+	}
+
+	return d.repo.Enqueue(ctx, deliveries)
+}
+
+// Run delivers due webhooks until the context is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(d.cfg.PollInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("webhook delivery failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue claims and sends all deliveries whose next attempt is due
+func (d *Dispatcher) DeliverDue(ctx context.Context) error {
+	// Lease claimed deliveries for longer than a send can take
+	lease := 2 * time.Duration(d.cfg.Timeout) * time.Second
+
+	for {
+		deliveries, err := d.repo.ClaimDue(ctx, claimBatchSize, lease)
+		if err != nil {
+			return err
+		}
+
+		for _, delivery := range deliveries {
+			if err := d.deliver(ctx, delivery); err != nil {
+				return err
+			}
+		}
+
+		if len(deliveries) < claimBatchSize {
+			return nil
+		}
+	}
+}
+
+// deliver makes one attempt to send a delivery and records the outcome
+func (d *Dispatcher) deliver(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	secret, err := d.repo.GetSecret(ctx, delivery.TenantID)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	statusCode, sendErr := d.sender.Send(ctx, delivery.URL, secret, delivery.EventType, delivery.ID, delivery.Payload)
+
+	delivery.Attempts++
+	attempt := &domain.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+		StatusCode: statusCode,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+
+	switch {
+	case sendErr == nil:
+		now := time.Now()
+		delivery.Status = domain.WebhookStatusDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	case delivery.Attempts >= d.cfg.MaxAttempts || permanent(sendErr):
+		attempt.Error = sendErr.Error()
+		delivery.Status = domain.WebhookStatusDead
+		delivery.LastError = sendErr.Error()
+		log.Printf("webhook delivery %s to %s dead-lettered after %d attempts: %v",
+			delivery.ID, delivery.URL, delivery.Attempts, sendErr)
+	default:
+		attempt.Error = sendErr.Error()
+		delivery.Status = domain.WebhookStatusPending
+		delivery.NextAttemptAt = time.Now().Add(d.backoff(delivery.Attempts))
+		delivery.LastError = sendErr.Error()
+	}
+
+	return d.repo.RecordAttempt(ctx, delivery, attempt)
+}
+
+// permanent reports whether a failed send would fail the same way if retried:
+// its URL was refused, or its host resolved to a forbidden address
+func permanent(err error) bool {
+	return errors.Is(err, ErrRefused) || errors.Is(err, egress.ErrForbiddenAddress)
+}
+
+// backoff returns the delay before the next attempt after the given number of failed attempts
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := time.Duration(d.cfg.InitialBackoff) * time.Second
+	max := time.Duration(d.cfg.MaxBackoff) * time.Second
+
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/prismon/synthesis/internal/egress"
+)
+
+// ErrRefused is returned by Send for a URL it will never post to, such as one
+// that is malformed, is not http(s) or names a forbidden address
+var ErrRefused = errors.New("refusing to post event")
+
+// Sender posts signed event payloads to notification URLs
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender creates a new webhook sender. Its client refuses loopback, private
+// and link-local destinations and does not follow redirects.
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{
+		client: egress.NewClient(timeout),
+	}
+}
+
+// Send posts a payload to a single URL, signing it with the given secret.
+// It returns the HTTP status code of the response, or 0 if no response was received.
+func (s *Sender) Send(ctx context.Context, url, secret, eventType, deliveryID string, body []byte) (int, error) {
+	if err := egress.CheckURL(url); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrRefused, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrRefused, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderSignature, Sign(secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
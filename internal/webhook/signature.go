@@ -0,0 +1,34 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Headers set on every webhook request
+const (
+	HeaderSignature = "X-Synthesis-Signature"
+	HeaderEvent     = "X-Synthesis-Event"
+	HeaderDelivery  = "X-Synthesis-Delivery"
+)
+
+const signaturePrefix = "sha256="
+
+// Sign computes the signature header value for a payload: "sha256=" followed by
+// the hex-encoded HMAC-SHA256 of the body keyed with the tenant secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is a valid signature of body for the secret.
+// Receivers can use it to authenticate incoming webhooks.
+func Verify(secret string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
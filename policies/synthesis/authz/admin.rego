@@ -0,0 +1,44 @@
+# Administrators and the local operator
+package synthesis.authz
+
+# Tools and routes reserved for administrators
+admin_only_actions := {
+	"create_tenant",
+	"get_webhook_secret",
+	"rotate_webhook_secret",
+	"retry_webhook_delivery",
+	"create_api_key",
+	"list_api_keys",
+	"revoke_api_key",
+	"query_audit_log",
+	"set_tenant_quota",
+	"rotate_encryption_key",
+	"create_type",
+	"update_type",
+	"delete_type",
+	# The graph is not row-secured, so its traversals may cross tenants
+	"graph_query_resources",
+	"POST /api/v1/tenants",
+	"DELETE /api/v1/tenants/{id}",
+	"PUT /api/v1/tenants/{id}/quota",
+	"POST /api/v1/types",
+	"PUT /api/v1/types/{id}",
+	"DELETE /api/v1/types/{id}",
+	"GET /api/v1/api-keys/by-tenant/{tenantId}",
+	"POST /api/v1/api-keys/by-tenant/{tenantId}",
+	"DELETE /api/v1/api-keys/by-tenant/{tenantId}/{id}",
+	"GET /api/v1/audit",
+}
+
+admin if "admin" in input.subject.roles
+
+allow_reasons contains "subject has the admin role" if admin
+
+# Whoever runs the server over stdio already has its database credentials
+allow_reasons contains "local operator (stdio transport)" if input.subject.local
+
+deny_reasons contains sprintf("%s requires the admin role", [input.action]) if {
+	input.action in admin_only_actions
+	not admin
+	not input.subject.local
+}
@@ -0,0 +1,61 @@
+# Authorization decision for Synthesis.
+#
+# The servers query data.synthesis.authz.decision with input:
+#
+#   subject:  {id, email, tenant, roles, authenticated, local}
+#   action:   MCP tool name ("approve_notebook"), REST method and route template
+#             ("DELETE /api/v1/notebooks/{id}"), or WebSocket action ("subscribe", "edit")
+#   resource: {uri, type, tenant, id, owner, tenant_owner, product_role}
+#
+# Other files in this package add to allow_reasons and deny_reasons. An action is
+# allowed when some rule allows it and no rule denies it.
+package synthesis.authz
+
+default decision := {"allow": false, "reasons": ["no policy allows this action"]}
+
+decision := {"allow": true, "reasons": allow_reasons} if {
+	count(allow_reasons) > 0
+	count(deny_reasons) == 0
+}
+
+decision := {"allow": false, "reasons": deny_reasons} if {
+	count(deny_reasons) > 0
+}
+
+# Names the subject may be recorded under as an owner
+subject_names contains input.subject.email if input.subject.email != ""
+
+subject_names contains input.subject.id if input.subject.id != ""
+
+# Actions that only read
+read_tools := {
+	"get_tenant",
+	"get_tenant_usage",
+	"get_product",
+	"list_products",
+	"list_libraries",
+	"list_notebooks",
+	"get_feature",
+	"get_type",
+	"list_types",
+	"list_type_schemas",
+	"find_invalid_blocks",
+	"validate_resource",
+	"list_notebook_transitions",
+	"render_notebook",
+	"list_tool_kinds",
+	"get_tool",
+	"list_tools",
+	"list_tool_invocations",
+	"list_webhook_deliveries",
+	"semantic_search_notebooks",
+	"graph_query_resources",
+	"subscribe",
+}
+
+read_action if input.action in read_tools
+
+read_action if startswith(input.action, "GET ")
+
+# Anyone may ask what they are allowed to do; the tenant boundary still applies
+allow_reasons contains "subject may check its own permissions" if input.action == "check_policy"
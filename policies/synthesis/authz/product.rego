@@ -0,0 +1,37 @@
+# Product membership roles (product_user table). A member's role on a product
+# extends to the notebooks and features attached to it.
+package synthesis.authz
+
+product_read_roles := {"viewer", "contributor", "owner"}
+
+product_write_roles := {"contributor", "owner"}
+
+product_resource_types := {"notebook", "feature"}
+
+# Any member may read the product
+allow_reasons contains sprintf("subject is a product %s", [input.resource.product_role]) if {
+	input.resource.type == "product"
+	input.resource.product_role in product_read_roles
+	read_action
+}
+
+# Owners manage the product: its details, members and attachments
+allow_reasons contains "subject is a product owner" if {
+	input.resource.type == "product"
+	input.resource.product_role == "owner"
+	not read_action
+}
+
+# Any member may read the product's notebooks and features
+allow_reasons contains sprintf("subject is a %s of a product %s %s is part of", [input.resource.product_role, input.resource.type, input.resource.id]) if {
+	input.resource.type in product_resource_types
+	input.resource.product_role in product_read_roles
+	read_action
+}
+
+# Contributors and owners may also change them
+allow_reasons contains sprintf("subject is a %s of a product %s %s is part of", [input.resource.product_role, input.resource.type, input.resource.id]) if {
+	input.resource.type in product_resource_types
+	input.resource.product_role in product_write_roles
+	not read_action
+}
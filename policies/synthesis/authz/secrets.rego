@@ -0,0 +1,22 @@
+# Revealing sensitive feature values and tool secrets
+package synthesis.authz
+
+# Sensitive values are redacted unless the caller may reveal them: administrators,
+# the local operator, and owners of the resource or its tenant. Editors may change
+# a resource without seeing its secrets.
+deny_reasons contains "reveal_secrets requires the admin role or ownership of the resource or its tenant" if {
+	input.action == "reveal_secrets"
+	not admin
+	not input.subject.local
+	not owns_resource
+}
+
+owns_resource if {
+	input.resource.owner != ""
+	input.resource.owner in subject_names
+}
+
+owns_resource if {
+	input.resource.tenant_owner != ""
+	input.resource.tenant_owner in subject_names
+}
@@ -0,0 +1,40 @@
+# Tenant ownership and membership
+package synthesis.authz
+
+# Tenant owners may do anything within their tenant
+allow_reasons contains sprintf("subject owns tenant %s", [input.resource.tenant]) if {
+	input.resource.tenant_owner != ""
+	input.resource.tenant_owner in subject_names
+}
+
+# Owners of a library or notebook may do anything to it
+allow_reasons contains sprintf("subject owns %s %s", [input.resource.type, input.resource.id]) if {
+	input.resource.owner != ""
+	input.resource.owner in subject_names
+}
+
+# Members of a tenant (by token tenant claim) may read its resources
+allow_reasons contains sprintf("subject is a member of tenant %s", [input.resource.tenant]) if {
+	member
+	read_action
+}
+
+# Members with the editor role may also change its resources
+allow_reasons contains sprintf("subject is an editor in tenant %s", [input.resource.tenant]) if {
+	member
+	"editor" in input.subject.roles
+}
+
+member if {
+	input.subject.authenticated
+	input.resource.tenant != ""
+	input.subject.tenant == input.resource.tenant
+}
+
+# Nobody outside the tenant gets in through another rule's back door, except admins
+deny_reasons contains sprintf("subject belongs to tenant %s, not %s", [input.subject.tenant, input.resource.tenant]) if {
+	input.subject.tenant != ""
+	input.resource.tenant != ""
+	input.subject.tenant != input.resource.tenant
+	not admin
+}
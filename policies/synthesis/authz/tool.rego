@@ -0,0 +1,12 @@
+# Registered tools
+#
+# Calls of the tools federated from a downstream MCP server are checked as
+# invoke_tool on the tool the server is registered as, so the tenant rules apply.
+package synthesis.authz
+
+# The kinds of tool are built into the servers and belong to no tenant, so any
+# authenticated caller may list them
+allow_reasons contains "tool kinds are readable by authenticated callers" if {
+	input.subject.authenticated
+	input.action == "list_tool_kinds"
+}
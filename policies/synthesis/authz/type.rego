@@ -0,0 +1,22 @@
+# Type definitions
+package synthesis.authz
+
+# Types are shared by every tenant, so any authenticated caller may read them;
+# only administrators change them (see admin.rego)
+allow_reasons contains "type definitions are readable by authenticated callers" if {
+	input.subject.authenticated
+	read_action
+	type_action
+}
+
+type_action if input.resource.type == "type"
+
+type_action if input.action in {"list_types", "GET /api/v1/types"}
+
+# Validating a candidate resource writes nothing and, without a notebook to
+# append a block to, reads only types
+allow_reasons contains "validating a candidate resource reads only type definitions" if {
+	input.subject.authenticated
+	input.action == "validate_resource"
+	not input.resource.uri
+}